@@ -0,0 +1,171 @@
+// ABOUTME: A/B subcommand running the optimizer with two configs on the same playlist and seed
+// ABOUTME: Compares final fitness, per-component breakdowns and how much the resulting orders overlap
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+const defaultABDuration = 30 * time.Second // Default optimizer run time per config for ab
+
+// abRun is the outcome of optimizing with one of the compared configs
+type abRun struct {
+	config    config.GAConfig
+	order     []playlist.Track
+	breakdown playlist.Breakdown // Breakdown of order under its own config
+}
+
+// runAB implements `playlist-sorter ab -config-a <file> -config-b <file> [flags] <playlist>`
+func runAB(args []string) int {
+	fs := newFlagSet("ab", "ab -config-a a.json -config-b b.json [flags] <playlist.m3u8>")
+	configA := fs.String("config-a", "", "config file for run A, overlaid on the current config like a sidecar")
+	configB := fs.String("config-b", "", "config file for run B, overlaid on the current config like a sidecar")
+	duration := fs.Duration("duration", defaultABDuration, "optimizer run time of each config (max 5m)")
+	seed := fs.Uint64("seed", 1, "random seed shared by both runs")
+
+	playlistPath, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	if *configA == "" || *configB == "" {
+		fs.Usage()
+
+		return 2
+	}
+
+	data, err := InitializePlaylist(PlaylistOptions{Path: playlistPath})
+	if err != nil {
+		log.Printf("A/B error: %v", err)
+
+		return 1
+	}
+
+	var runs [2]abRun
+
+	for i, path := range []string{*configA, *configB} {
+		runs[i].config, err = config.ApplyFile(data.Config, path)
+		if err != nil {
+			log.Printf("A/B error: %v", err)
+
+			return 1
+		}
+	}
+
+	fmt.Printf("Comparing A (%s) and B (%s) on %s (%d tracks), %s each with seed %d...\n",
+		*configA, *configB, playlistPath, len(data.Tracks), min(*duration, maxDuration), *seed)
+
+	for i := range runs {
+		if runs[i].order, err = optimizeWithSeed(data, runs[i].config, *duration, *seed); err != nil {
+			log.Printf("A/B error: %v", err)
+
+			return 1
+		}
+
+		runs[i].breakdown = calculateFitnessWithBreakdown(runs[i].order, runs[i].config, data.GACtx)
+	}
+
+	printABComparison(data, runs)
+
+	return 0
+}
+
+// optimizeWithSeed runs the GA on data's tracks with cfg for duration, drawing all randomness
+// (initial population, mutations, shuffle jitter) from seed so runs differ only by config
+func optimizeWithSeed(data *OptimizationContext, cfg config.GAConfig, duration time.Duration, seed uint64) ([]playlist.Track, error) {
+	sharedCfg := &config.SharedConfig{}
+	sharedCfg.Update(cfg)
+
+	data.GACtx.rng = rand.New(rand.NewPCG(seed, seed))
+	data.GACtx.edgeNoise = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), min(duration, maxDuration))
+	defer cancel()
+
+	return geneticSort(ctx, data.Tracks, sharedCfg, nil, 0, data.GACtx)
+}
+
+// printABComparison prints both runs' fitness (each order scored under both configs, since scores
+// under different weights aren't comparable), their breakdowns and the overlap of the two orders
+func printABComparison(data *OptimizationContext, runs [2]abRun) {
+	var crossScores [2][2]float64 // [config][run]
+
+	for c := range runs {
+		updateNormalizedWeights(data.GACtx, runs[c].config)
+
+		for r := range runs {
+			crossScores[c][r] = calculateFitness(runs[r].order, runs[c].config, data.GACtx)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	lines := []string{"\nFitness\tA\tB"}
+
+	for c, name := range []string{"A", "B"} {
+		lines = append(lines, fmt.Sprintf("under config %s\t%.6f\t%.6f", name, crossScores[c][0], crossScores[c][1]))
+	}
+
+	lines = append(lines, "\nComponent\tA\tB")
+
+	componentsA, componentsB := breakdownComponents(runs[0].breakdown), breakdownComponents(runs[1].breakdown)
+	for i := range componentsA {
+		lines = append(lines, fmt.Sprintf("%s\t%.6f\t%.6f", componentsA[i].name, componentsA[i].value, componentsB[i].value))
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			log.Printf("Warning: failed to write comparison: %v", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Printf("Warning: failed to flush output: %v", err)
+	}
+
+	n := len(runs[0].order)
+	samePosition, sharedTransitions := orderOverlap(runs[0].order, runs[1].order)
+
+	fmt.Printf("\nSame position:      %d/%d tracks (%.0f%%)\n", samePosition, n, percent(samePosition, n))
+	fmt.Printf("Shared transitions: %d/%d (%.0f%%, either direction)\n", sharedTransitions, n-1, percent(sharedTransitions, n-1))
+}
+
+// orderOverlap compares two orders of the same tracks: how many tracks sit at the same position,
+// and how many transitions of a also occur in b in either direction (tracks are matched by Identity)
+func orderOverlap(a, b []playlist.Track) (samePosition, sharedTransitions int) {
+	adjacent := make(map[[2]string]bool, 2*len(b))
+	for i := 1; i < len(b); i++ {
+		adjacent[[2]string{b[i-1].Identity(), b[i].Identity()}] = true
+		adjacent[[2]string{b[i].Identity(), b[i-1].Identity()}] = true
+	}
+
+	for i := range a {
+		if i < len(b) && a[i].Identity() == b[i].Identity() {
+			samePosition++
+		}
+
+		if i > 0 && adjacent[[2]string{a[i-1].Identity(), a[i].Identity()}] {
+			sharedTransitions++
+		}
+	}
+
+	return samePosition, sharedTransitions
+}
+
+// percent returns part as a percentage of total (0 for an empty total)
+func percent(part, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return float64(part) * 100 / float64(total)
+}
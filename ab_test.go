@@ -0,0 +1,36 @@
+// ABOUTME: Tests for the A/B config comparison subcommand
+// ABOUTME: Validates order overlap counting
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOrderOverlap(t *testing.T) {
+	a := benchmarkTracks(5)
+
+	samePosition, sharedTransitions := orderOverlap(a, a)
+	if samePosition != 5 || sharedTransitions != 4 {
+		t.Errorf("Expected identical orders to overlap fully (5, 4), got (%d, %d)", samePosition, sharedTransitions)
+	}
+
+	reversed := slices.Clone(a)
+	slices.Reverse(reversed)
+
+	// Only the middle track stays put, but every transition occurs in the other direction
+	samePosition, sharedTransitions = orderOverlap(a, reversed)
+	if samePosition != 1 || sharedTransitions != 4 {
+		t.Errorf("Expected a reversed order to share position 1 and all 4 transitions, got (%d, %d)", samePosition, sharedTransitions)
+	}
+
+	// 0 1 2 3 4 -> 1 0 2 3 4: tracks 2-4 keep their position; 0-1, 2-3 and 3-4 stay adjacent
+	swapped := slices.Clone(a)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+
+	samePosition, sharedTransitions = orderOverlap(a, swapped)
+	if samePosition != 3 || sharedTransitions != 3 {
+		t.Errorf("Expected (3, 3) after swapping the first two tracks, got (%d, %d)", samePosition, sharedTransitions)
+	}
+}
@@ -0,0 +1,285 @@
+// ABOUTME: Analyze subcommand scoring a playlist's current order without optimizing it
+// ABOUTME: Prints the fitness breakdown and the most expensive transitions
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+	"playlist-sorter/termtext"
+)
+
+const defaultAnalyzeTop = 10 // Default number of worst transitions listed by analyze
+
+// transitionCost is the weighted cost of moving from the track at Position-1 to the track at Position
+type transitionCost struct {
+	Position  int                // 0-based position of the destination track
+	From      *playlist.Track    // Outgoing track
+	To        *playlist.Track    // Incoming track
+	Breakdown playlist.Breakdown // Edge components only (position bias excluded)
+}
+
+// transitionCosts scores every adjacent pair of tracks in their current order.
+// ctx must have normalized weights for cfg (see updateNormalizedWeights).
+func transitionCosts(tracks []playlist.Track, cfg config.GAConfig, ctx *GAContext) []transitionCost {
+	if len(tracks) < 2 {
+		return nil
+	}
+
+	costs := make([]transitionCost, 0, len(tracks)-1)
+
+	for j := 1; j < len(tracks); j++ {
+		b := segmentFitnessWithBreakdown(tracks, j, j, cfg, ctx)
+
+		// Position bias belongs to the track, not the transition
+		b.Total -= b.PositionBias
+		b.PositionBias = 0
+
+		costs = append(costs, transitionCost{Position: j, From: &tracks[j-1], To: &tracks[j], Breakdown: b})
+	}
+
+	return costs
+}
+
+// worstTransitions returns up to n transitions with the highest cost, most expensive first
+func worstTransitions(costs []transitionCost, n int) []transitionCost {
+	sorted := slices.Clone(costs)
+	slices.SortStableFunc(sorted, func(a, b transitionCost) int {
+		return cmp.Compare(b.Breakdown.Total, a.Breakdown.Total)
+	})
+
+	return sorted[:min(n, len(sorted))]
+}
+
+// printExactSearch runs branch and bound for up to limit, starting from order, and reports how far
+// order is from the optimum (or at most, when the search doesn't finish in time)
+func printExactSearch(data *OptimizationContext, order namedOrder, limit time.Duration) {
+	if len(data.Tracks) > exactSearchMaxTracks {
+		fmt.Printf("\nExact search skipped: %d tracks (at most %d)\n", len(data.Tracks), exactSearchMaxTracks)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), limit)
+	defer cancel()
+
+	startTime := time.Now()
+	result := branchAndBound(ctx, data.Tracks, order.tracks, data.Config, data.GACtx)
+	elapsed := time.Since(startTime).Round(time.Millisecond)
+	fitness := calculateFitness(order.tracks, data.Config, data.GACtx)
+	name := strings.ToLower(order.name)
+
+	switch {
+	case result.Complete && result.Fitness < fitness:
+		fmt.Printf("\nExact optimum:       %.8f (%s order is %.2f%% above it; %d nodes in %s)\n",
+			result.Fitness, name, (fitness-result.Fitness)*100/fitness, result.Nodes, elapsed)
+	case result.Complete:
+		fmt.Printf("\nExact optimum:       %.8f (%s order is optimal; %d nodes in %s)\n", fitness, name, result.Nodes, elapsed)
+	default:
+		fmt.Printf("\nExact search:        best %.8f, lower bound %.8f after %s (%d nodes, not finished)\n",
+			result.Fitness, result.LowerBound, elapsed, result.Nodes)
+		fmt.Printf("                     %s order is at most %.2f%% above optimal\n", name, result.Gap(fitness))
+	}
+}
+
+// runAnalyze implements `playlist-sorter analyze [flags] <playlist>`
+func runAnalyze(args []string) int {
+	fs := newFlagSet("analyze", "analyze [flags] <playlist.m3u8>")
+	top := fs.Int("top", defaultAnalyzeTop, "number of worst transitions to list")
+	imputeEnergy := fs.Bool("impute-energy", false, "estimate missing energy values from genre/BPM (shown as ~N)")
+	preset := fs.String("preset", "", "score with a built-in weight preset: "+presetNames())
+	optimize := fs.Duration("optimize", 0, "also optimize for this long (nothing is written) and compare key statistics with the original order")
+	exact := fs.Duration("exact", 0, fmt.Sprintf("also search up to this long for the optimal order (branch and bound, up to %d tracks) and report how far the optimized (or original) order is from it", exactSearchMaxTracks))
+
+	playlistPath, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	data, err := InitializePlaylist(PlaylistOptions{Path: playlistPath, ImputeEnergy: *imputeEnergy, Preset: *preset})
+	if err != nil {
+		log.Printf("Analyze error: %v", err)
+
+		return 1
+	}
+
+	updateNormalizedWeights(data.GACtx, data.Config)
+
+	breakdown := calculateFitnessWithBreakdown(data.Tracks, data.Config, data.GACtx)
+	theoreticalMin := calculateTheoreticalMinimum(data.Tracks, data.Config, data.GACtx)
+
+	fmt.Printf("Playlist: %s (%d tracks)\n\n", playlistPath, len(data.Tracks))
+	fmt.Printf("Fitness:             %.8f (lower is better)\n", breakdown.Total)
+	fmt.Printf("Theoretical minimum: %.8f (not achievable, conflicting constraints)\n", theoreticalMin.Total)
+
+	if disabled := data.GACtx.normalizers.disabledComponents(data.Config); len(disabled) > 0 {
+		fmt.Printf("Disabled for lack of data: %s\n", strings.Join(disabled, ", "))
+	}
+
+	fmt.Println()
+
+	printBreakdownTable(breakdown, theoreticalMin)
+
+	orders := []namedOrder{{name: "Original", tracks: data.Tracks}}
+
+	if *optimize > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), min(*optimize, maxDuration))
+		optimized, err := geneticSort(ctx, data.Tracks, data.SharedConfig, nil, 0, data.GACtx)
+
+		cancel()
+
+		if err != nil {
+			log.Printf("Analyze error: %v", err)
+
+			return 1
+		}
+
+		fmt.Printf("\nOptimized fitness:   %.8f (after %s, not written)\n", calculateFitness(optimized, data.Config, data.GACtx), min(*optimize, maxDuration))
+
+		orders = append(orders, namedOrder{name: "Optimized", tracks: optimized})
+	}
+
+	if *exact > 0 {
+		printExactSearch(data, orders[len(orders)-1], *exact)
+	}
+
+	printKeyStats(data.Tracks, orders)
+
+	worst := worstTransitions(transitionCosts(data.Tracks, data.Config, data.GACtx), *top)
+	if len(worst) == 0 {
+		return 0
+	}
+
+	fmt.Printf("\nWorst %d transitions:\n", len(worst))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintf(w, "#\tCost\tKey\tBPM\tEng\t%s  To\n", termtext.Fit("From", 35)); err != nil {
+		log.Printf("Warning: failed to write header: %v", err)
+	}
+
+	for _, c := range worst {
+		if _, err := fmt.Fprintf(w, "%d>%d\t%.4f\t%s>%s\t%.0f>%.0f\t%s>%s\t%s  %s\n",
+			c.Position, c.Position+1,
+			c.Breakdown.Total,
+			c.From.Key, c.To.Key,
+			c.From.BPM, c.To.BPM,
+			c.From.EnergyLabel(), c.To.EnergyLabel(),
+			termtext.Fit(c.From.Artist+" - "+c.From.Title, 35),
+			termtext.Truncate(c.To.Artist+" - "+c.To.Title, 35),
+		); err != nil {
+			log.Printf("Warning: failed to write transition %d: %v", c.Position, err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Printf("Warning: failed to flush output: %v", err)
+	}
+
+	return 0
+}
+
+// breakdownComponent is one named fitness component of a breakdown
+type breakdownComponent struct {
+	name  string
+	value float64
+	raw   string // The component's raw counterpart, e.g. "3 clashes" ("" when it has none)
+}
+
+// breakdownComponents lists the components of b in display order
+func breakdownComponents(b playlist.Breakdown) []breakdownComponent {
+	return []breakdownComponent{
+		{"Harmonic", b.Harmonic, fmt.Sprintf("%d clashes", b.BadKeyTransitions)},
+		{"Energy", b.EnergyDelta, fmt.Sprintf("%.0f levels", b.TotalEnergyChange)},
+		{"BPM", b.BPMDelta, fmt.Sprintf("%.1f BPM", b.TotalBPMDrift)},
+		{"Genre", b.GenreChange, fmt.Sprintf("%d changes", b.GenreChanges)},
+		{"Same artist", b.SameArtist, fmt.Sprintf("%d pairs", b.SameArtistAdjacencies)},
+		{"Same album", b.SameAlbum, fmt.Sprintf("%d pairs", b.SameAlbumAdjacencies)},
+		{"Position bias", b.PositionBias, ""},
+		{"Shuffle", b.Shuffle, ""},
+		{"Novelty", b.Novelty, fmt.Sprintf("%d repeated", b.RepeatedAdjacencies)},
+		{"Mix length", b.MixLength, ""},
+		{"Energy wave", b.EnergyWave, ""},
+		{"BPM bands", b.BPMBand, fmt.Sprintf("%d changes", b.BPMBandChanges)},
+		{"Favorites", b.Favorites, ""},
+		{"Freshness", b.Freshness, fmt.Sprintf("%d pairs", b.FreshAdjacencies)},
+		{"Bookends", b.Bookends, ""},
+	}
+}
+
+// printBreakdownTable prints each fitness component with its raw counterpart, its share of the total,
+// its theoretical minimum and the headroom left above it, followed by the component with the most headroom
+func printBreakdownTable(b, minimum playlist.Breakdown) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "Component\tScore\tRaw\tShare\tMinimum\tHeadroom"); err != nil {
+		log.Printf("Warning: failed to write header: %v", err)
+	}
+
+	minima := breakdownComponents(minimum)
+	headroom := breakdownComponents(breakdownHeadroom(b, minimum))
+
+	for i, c := range breakdownComponents(b) {
+		share := 0.0
+		if b.Total != 0 {
+			share = c.value * 100 / b.Total
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\t%.6f\t%s\t%.1f%%\t%.6f\t%.6f\n", c.name, c.value, c.raw, share, minima[i].value, headroom[i].value); err != nil {
+			log.Printf("Warning: failed to write component %s: %v", c.name, err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Printf("Warning: failed to flush output: %v", err)
+	}
+
+	if top := mostHeadroom(headroom); top.value > 0 {
+		fmt.Printf("\nMost headroom: %s (could improve by up to %.6f)\n", strings.ToLower(top.name), top.value)
+	}
+}
+
+// breakdownHeadroom returns how far each component of current is above its theoretical minimum
+func breakdownHeadroom(current, minimum playlist.Breakdown) playlist.Breakdown {
+	h := playlist.Breakdown{
+		Harmonic:     current.Harmonic - minimum.Harmonic,
+		EnergyDelta:  current.EnergyDelta - minimum.EnergyDelta,
+		BPMDelta:     current.BPMDelta - minimum.BPMDelta,
+		GenreChange:  current.GenreChange - minimum.GenreChange,
+		SameArtist:   current.SameArtist - minimum.SameArtist,
+		SameAlbum:    current.SameAlbum - minimum.SameAlbum,
+		PositionBias: current.PositionBias - minimum.PositionBias,
+		Shuffle:      current.Shuffle - minimum.Shuffle,
+		Novelty:      current.Novelty - minimum.Novelty,
+		MixLength:    current.MixLength - minimum.MixLength,
+		EnergyWave:   current.EnergyWave - minimum.EnergyWave,
+		BPMBand:      current.BPMBand - minimum.BPMBand,
+		Favorites:    current.Favorites - minimum.Favorites,
+		Freshness:    current.Freshness - minimum.Freshness,
+		Bookends:     current.Bookends - minimum.Bookends,
+	}
+	h.Total = h.Sum()
+
+	return h
+}
+
+// mostHeadroom returns the component with the largest headroom (the first one on ties)
+func mostHeadroom(headroom []breakdownComponent) breakdownComponent {
+	var top breakdownComponent
+
+	for i, c := range headroom {
+		if i == 0 || c.value > top.value {
+			top = c
+		}
+	}
+
+	return top
+}
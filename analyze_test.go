@@ -0,0 +1,91 @@
+// ABOUTME: Tests for the analyze subcommand's per-transition scoring
+// ABOUTME: Verifies transition costs exclude position bias, worst transitions are ranked and minima add up
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+func TestTransitionCostsRankClashFirst(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	tracks := []playlist.Track{
+		{Path: "A", Key: "8A", ParsedKey: parseKey("8A"), BPM: 124, Energy: 4, Artist: "A", Album: "A"},
+		{Path: "B", Key: "9A", ParsedKey: parseKey("9A"), BPM: 125, Energy: 5, Artist: "B", Album: "B"},
+		{Path: "C", Key: "3B", ParsedKey: parseKey("3B"), BPM: 140, Energy: 9, Artist: "C", Album: "C"}, // Clash
+		{Path: "D", Key: "3B", ParsedKey: parseKey("3B"), BPM: 140, Energy: 8, Artist: "D", Album: "D"},
+	}
+	for i := range tracks {
+		tracks[i].Index = i
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(ctx, cfg)
+
+	costs := transitionCosts(tracks, cfg, ctx)
+	if len(costs) != len(tracks)-1 {
+		t.Fatalf("Expected %d transitions, got %d", len(tracks)-1, len(costs))
+	}
+
+	// Edge costs plus position bias add up to the full fitness
+	sum := 0.0
+	for _, c := range costs {
+		if c.Breakdown.PositionBias != 0 {
+			t.Error("Transition cost should not include position bias")
+		}
+
+		sum += c.Breakdown.Total
+	}
+
+	full := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+	if math.Abs(sum+full.PositionBias-full.Total) > floatingPointEpsilon {
+		t.Errorf("Transition costs (%.6f) + bias (%.6f) != total (%.6f)", sum, full.PositionBias, full.Total)
+	}
+
+	worst := worstTransitions(costs, 1)
+	if len(worst) != 1 || worst[0].To.Path != "C" {
+		t.Errorf("Expected the B>C key clash to rank worst, got %+v", worst)
+	}
+
+	if got := worstTransitions(costs, 10); len(got) != len(costs) {
+		t.Errorf("Expected all %d transitions when n exceeds count, got %d", len(costs), len(got))
+	}
+}
+
+func TestTheoreticalMinimumPerComponent(t *testing.T) {
+	cfg := config.DefaultConfig()
+	tracks := benchmarkTracks(12)
+
+	ctx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(ctx, cfg)
+
+	minimum := calculateTheoreticalMinimum(tracks, cfg, ctx)
+	if math.Abs(minimum.Sum()-minimum.Total) > floatingPointEpsilon {
+		t.Errorf("Expected the total (%.6f) to be the sum of the per-component minima (%.6f)", minimum.Total, minimum.Sum())
+	}
+
+	current := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+	headroom := breakdownHeadroom(current, minimum)
+
+	for _, c := range breakdownComponents(headroom) {
+		if c.value < -floatingPointEpsilon {
+			t.Errorf("%s: current score is below its theoretical minimum (headroom %.6f)", c.name, c.value)
+		}
+	}
+
+	if math.Abs(headroom.Total-(current.Total-minimum.Total)) > floatingPointEpsilon {
+		t.Errorf("Expected total headroom %.6f, got %.6f", current.Total-minimum.Total, headroom.Total)
+	}
+
+	top := mostHeadroom(breakdownComponents(headroom))
+	for _, c := range breakdownComponents(headroom) {
+		if c.value > top.value {
+			t.Errorf("mostHeadroom picked %s (%.6f) over %s (%.6f)", top.name, top.value, c.name, c.value)
+		}
+	}
+}
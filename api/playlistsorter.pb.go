@@ -0,0 +1,700 @@
+// ABOUTME: gRPC service definition mirroring the REST API served by `playlist-sorter serve`
+// ABOUTME: Regenerate the Go code with `make proto` (protoc, protoc-gen-go, protoc-gen-go-grpc) after editing
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: api/playlistsorter.proto
+
+package api
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type OptimizeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Playlist      string                 `protobuf:"bytes,1,opt,name=playlist,proto3" json:"playlist,omitempty"` // Path to the playlist on the server
+	Duration      *durationpb.Duration   `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"` // Optimizer run time (unset = 30s, max 5m)
+	Output        string                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`     // Write the result here (empty = don't write)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptimizeRequest) Reset() {
+	*x = OptimizeRequest{}
+	mi := &file_api_playlistsorter_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptimizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizeRequest) ProtoMessage() {}
+
+func (x *OptimizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_playlistsorter_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizeRequest.ProtoReflect.Descriptor instead.
+func (*OptimizeRequest) Descriptor() ([]byte, []int) {
+	return file_api_playlistsorter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OptimizeRequest) GetPlaylist() string {
+	if x != nil {
+		return x.Playlist
+	}
+	return ""
+}
+
+func (x *OptimizeRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *OptimizeRequest) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+type AnalyzeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Playlist      string                 `protobuf:"bytes,1,opt,name=playlist,proto3" json:"playlist,omitempty"` // Path to the playlist on the server
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeRequest) Reset() {
+	*x = AnalyzeRequest{}
+	mi := &file_api_playlistsorter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeRequest) ProtoMessage() {}
+
+func (x *AnalyzeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_playlistsorter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeRequest) Descriptor() ([]byte, []int) {
+	return file_api_playlistsorter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AnalyzeRequest) GetPlaylist() string {
+	if x != nil {
+		return x.Playlist
+	}
+	return ""
+}
+
+// Breakdown holds the weighted fitness components (lower is better) and their raw counterparts
+type Breakdown struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Total                 float64                `protobuf:"fixed64,1,opt,name=total,proto3" json:"total,omitempty"`
+	Harmonic              float64                `protobuf:"fixed64,2,opt,name=harmonic,proto3" json:"harmonic,omitempty"`
+	EnergyDelta           float64                `protobuf:"fixed64,3,opt,name=energy_delta,json=energyDelta,proto3" json:"energy_delta,omitempty"`
+	BpmDelta              float64                `protobuf:"fixed64,4,opt,name=bpm_delta,json=bpmDelta,proto3" json:"bpm_delta,omitempty"`
+	GenreChange           float64                `protobuf:"fixed64,5,opt,name=genre_change,json=genreChange,proto3" json:"genre_change,omitempty"`
+	SameArtist            float64                `protobuf:"fixed64,6,opt,name=same_artist,json=sameArtist,proto3" json:"same_artist,omitempty"`
+	SameAlbum             float64                `protobuf:"fixed64,7,opt,name=same_album,json=sameAlbum,proto3" json:"same_album,omitempty"`
+	PositionBias          float64                `protobuf:"fixed64,8,opt,name=position_bias,json=positionBias,proto3" json:"position_bias,omitempty"`
+	Shuffle               float64                `protobuf:"fixed64,9,opt,name=shuffle,proto3" json:"shuffle,omitempty"`
+	Novelty               float64                `protobuf:"fixed64,10,opt,name=novelty,proto3" json:"novelty,omitempty"`
+	MixLength             float64                `protobuf:"fixed64,11,opt,name=mix_length,json=mixLength,proto3" json:"mix_length,omitempty"`
+	EnergyWave            float64                `protobuf:"fixed64,12,opt,name=energy_wave,json=energyWave,proto3" json:"energy_wave,omitempty"`
+	BpmBand               float64                `protobuf:"fixed64,13,opt,name=bpm_band,json=bpmBand,proto3" json:"bpm_band,omitempty"`
+	Bookends              float64                `protobuf:"fixed64,14,opt,name=bookends,proto3" json:"bookends,omitempty"`
+	Freshness             float64                `protobuf:"fixed64,15,opt,name=freshness,proto3" json:"freshness,omitempty"`
+	Favorites             float64                `protobuf:"fixed64,16,opt,name=favorites,proto3" json:"favorites,omitempty"`
+	Transitions           int32                  `protobuf:"varint,17,opt,name=transitions,proto3" json:"transitions,omitempty"`
+	BadKeyTransitions     int32                  `protobuf:"varint,18,opt,name=bad_key_transitions,json=badKeyTransitions,proto3" json:"bad_key_transitions,omitempty"`
+	TotalEnergyChange     float64                `protobuf:"fixed64,19,opt,name=total_energy_change,json=totalEnergyChange,proto3" json:"total_energy_change,omitempty"`
+	TotalBpmDrift         float64                `protobuf:"fixed64,20,opt,name=total_bpm_drift,json=totalBpmDrift,proto3" json:"total_bpm_drift,omitempty"`
+	GenreChanges          int32                  `protobuf:"varint,21,opt,name=genre_changes,json=genreChanges,proto3" json:"genre_changes,omitempty"`
+	SameArtistAdjacencies int32                  `protobuf:"varint,22,opt,name=same_artist_adjacencies,json=sameArtistAdjacencies,proto3" json:"same_artist_adjacencies,omitempty"`
+	SameAlbumAdjacencies  int32                  `protobuf:"varint,23,opt,name=same_album_adjacencies,json=sameAlbumAdjacencies,proto3" json:"same_album_adjacencies,omitempty"`
+	RepeatedAdjacencies   int32                  `protobuf:"varint,24,opt,name=repeated_adjacencies,json=repeatedAdjacencies,proto3" json:"repeated_adjacencies,omitempty"`
+	BpmBandChanges        int32                  `protobuf:"varint,25,opt,name=bpm_band_changes,json=bpmBandChanges,proto3" json:"bpm_band_changes,omitempty"`
+	FreshAdjacencies      int32                  `protobuf:"varint,26,opt,name=fresh_adjacencies,json=freshAdjacencies,proto3" json:"fresh_adjacencies,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Breakdown) Reset() {
+	*x = Breakdown{}
+	mi := &file_api_playlistsorter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Breakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Breakdown) ProtoMessage() {}
+
+func (x *Breakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_api_playlistsorter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Breakdown.ProtoReflect.Descriptor instead.
+func (*Breakdown) Descriptor() ([]byte, []int) {
+	return file_api_playlistsorter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Breakdown) GetTotal() float64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Breakdown) GetHarmonic() float64 {
+	if x != nil {
+		return x.Harmonic
+	}
+	return 0
+}
+
+func (x *Breakdown) GetEnergyDelta() float64 {
+	if x != nil {
+		return x.EnergyDelta
+	}
+	return 0
+}
+
+func (x *Breakdown) GetBpmDelta() float64 {
+	if x != nil {
+		return x.BpmDelta
+	}
+	return 0
+}
+
+func (x *Breakdown) GetGenreChange() float64 {
+	if x != nil {
+		return x.GenreChange
+	}
+	return 0
+}
+
+func (x *Breakdown) GetSameArtist() float64 {
+	if x != nil {
+		return x.SameArtist
+	}
+	return 0
+}
+
+func (x *Breakdown) GetSameAlbum() float64 {
+	if x != nil {
+		return x.SameAlbum
+	}
+	return 0
+}
+
+func (x *Breakdown) GetPositionBias() float64 {
+	if x != nil {
+		return x.PositionBias
+	}
+	return 0
+}
+
+func (x *Breakdown) GetShuffle() float64 {
+	if x != nil {
+		return x.Shuffle
+	}
+	return 0
+}
+
+func (x *Breakdown) GetNovelty() float64 {
+	if x != nil {
+		return x.Novelty
+	}
+	return 0
+}
+
+func (x *Breakdown) GetMixLength() float64 {
+	if x != nil {
+		return x.MixLength
+	}
+	return 0
+}
+
+func (x *Breakdown) GetEnergyWave() float64 {
+	if x != nil {
+		return x.EnergyWave
+	}
+	return 0
+}
+
+func (x *Breakdown) GetBpmBand() float64 {
+	if x != nil {
+		return x.BpmBand
+	}
+	return 0
+}
+
+func (x *Breakdown) GetBookends() float64 {
+	if x != nil {
+		return x.Bookends
+	}
+	return 0
+}
+
+func (x *Breakdown) GetFreshness() float64 {
+	if x != nil {
+		return x.Freshness
+	}
+	return 0
+}
+
+func (x *Breakdown) GetFavorites() float64 {
+	if x != nil {
+		return x.Favorites
+	}
+	return 0
+}
+
+func (x *Breakdown) GetTransitions() int32 {
+	if x != nil {
+		return x.Transitions
+	}
+	return 0
+}
+
+func (x *Breakdown) GetBadKeyTransitions() int32 {
+	if x != nil {
+		return x.BadKeyTransitions
+	}
+	return 0
+}
+
+func (x *Breakdown) GetTotalEnergyChange() float64 {
+	if x != nil {
+		return x.TotalEnergyChange
+	}
+	return 0
+}
+
+func (x *Breakdown) GetTotalBpmDrift() float64 {
+	if x != nil {
+		return x.TotalBpmDrift
+	}
+	return 0
+}
+
+func (x *Breakdown) GetGenreChanges() int32 {
+	if x != nil {
+		return x.GenreChanges
+	}
+	return 0
+}
+
+func (x *Breakdown) GetSameArtistAdjacencies() int32 {
+	if x != nil {
+		return x.SameArtistAdjacencies
+	}
+	return 0
+}
+
+func (x *Breakdown) GetSameAlbumAdjacencies() int32 {
+	if x != nil {
+		return x.SameAlbumAdjacencies
+	}
+	return 0
+}
+
+func (x *Breakdown) GetRepeatedAdjacencies() int32 {
+	if x != nil {
+		return x.RepeatedAdjacencies
+	}
+	return 0
+}
+
+func (x *Breakdown) GetBpmBandChanges() int32 {
+	if x != nil {
+		return x.BpmBandChanges
+	}
+	return 0
+}
+
+func (x *Breakdown) GetFreshAdjacencies() int32 {
+	if x != nil {
+		return x.FreshAdjacencies
+	}
+	return 0
+}
+
+type PlaylistResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Playlist  string                 `protobuf:"bytes,1,opt,name=playlist,proto3" json:"playlist,omitempty"`
+	Tracks    []string               `protobuf:"bytes,2,rep,name=tracks,proto3" json:"tracks,omitempty"` // Track paths in order
+	Fitness   float64                `protobuf:"fixed64,3,opt,name=fitness,proto3" json:"fitness,omitempty"`
+	Breakdown *Breakdown             `protobuf:"bytes,4,opt,name=breakdown,proto3" json:"breakdown,omitempty"`
+	Written   string                 `protobuf:"bytes,5,opt,name=written,proto3" json:"written,omitempty"` // Output path if the result was written
+	Skipped   []string               `protobuf:"bytes,6,rep,name=skipped,proto3" json:"skipped,omitempty"` // Tracks with unreadable metadata (not in tracks, kept in place when written)
+	// Analyze only: per-component theoretical minima and how far the breakdown is above them
+	TheoreticalMinimum *Breakdown `protobuf:"bytes,7,opt,name=theoretical_minimum,json=theoreticalMinimum,proto3" json:"theoretical_minimum,omitempty"`
+	Headroom           *Breakdown `protobuf:"bytes,8,opt,name=headroom,proto3" json:"headroom,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *PlaylistResult) Reset() {
+	*x = PlaylistResult{}
+	mi := &file_api_playlistsorter_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaylistResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaylistResult) ProtoMessage() {}
+
+func (x *PlaylistResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_playlistsorter_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaylistResult.ProtoReflect.Descriptor instead.
+func (*PlaylistResult) Descriptor() ([]byte, []int) {
+	return file_api_playlistsorter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PlaylistResult) GetPlaylist() string {
+	if x != nil {
+		return x.Playlist
+	}
+	return ""
+}
+
+func (x *PlaylistResult) GetTracks() []string {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+func (x *PlaylistResult) GetFitness() float64 {
+	if x != nil {
+		return x.Fitness
+	}
+	return 0
+}
+
+func (x *PlaylistResult) GetBreakdown() *Breakdown {
+	if x != nil {
+		return x.Breakdown
+	}
+	return nil
+}
+
+func (x *PlaylistResult) GetWritten() string {
+	if x != nil {
+		return x.Written
+	}
+	return ""
+}
+
+func (x *PlaylistResult) GetSkipped() []string {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+func (x *PlaylistResult) GetTheoreticalMinimum() *Breakdown {
+	if x != nil {
+		return x.TheoreticalMinimum
+	}
+	return nil
+}
+
+func (x *PlaylistResult) GetHeadroom() *Breakdown {
+	if x != nil {
+		return x.Headroom
+	}
+	return nil
+}
+
+type Progress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    int64                  `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	BestFitness   float64                `protobuf:"fixed64,2,opt,name=best_fitness,json=bestFitness,proto3" json:"best_fitness,omitempty"`
+	GenPerSec     float64                `protobuf:"fixed64,3,opt,name=gen_per_sec,json=genPerSec,proto3" json:"gen_per_sec,omitempty"`
+	Breakdown     *Breakdown             `protobuf:"bytes,4,opt,name=breakdown,proto3" json:"breakdown,omitempty"`
+	Tracks        []string               `protobuf:"bytes,5,rep,name=tracks,proto3" json:"tracks,omitempty"`    // Best order so far
+	Optimal       bool                   `protobuf:"varint,6,opt,name=optimal,proto3" json:"optimal,omitempty"` // The order is provably optimal (small playlists), the run is over
+	Result        *PlaylistResult        `protobuf:"bytes,7,opt,name=result,proto3" json:"result,omitempty"`    // Set on the final message only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_api_playlistsorter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_api_playlistsorter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_api_playlistsorter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Progress) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *Progress) GetBestFitness() float64 {
+	if x != nil {
+		return x.BestFitness
+	}
+	return 0
+}
+
+func (x *Progress) GetGenPerSec() float64 {
+	if x != nil {
+		return x.GenPerSec
+	}
+	return 0
+}
+
+func (x *Progress) GetBreakdown() *Breakdown {
+	if x != nil {
+		return x.Breakdown
+	}
+	return nil
+}
+
+func (x *Progress) GetTracks() []string {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+func (x *Progress) GetOptimal() bool {
+	if x != nil {
+		return x.Optimal
+	}
+	return false
+}
+
+func (x *Progress) GetResult() *PlaylistResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+var File_api_playlistsorter_proto protoreflect.FileDescriptor
+
+const file_api_playlistsorter_proto_rawDesc = "" +
+	"\n" +
+	"\x18api/playlistsorter.proto\x12\x11playlistsorter.v1\x1a\x1egoogle/protobuf/duration.proto\"|\n" +
+	"\x0fOptimizeRequest\x12\x1a\n" +
+	"\bplaylist\x18\x01 \x01(\tR\bplaylist\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x16\n" +
+	"\x06output\x18\x03 \x01(\tR\x06output\",\n" +
+	"\x0eAnalyzeRequest\x12\x1a\n" +
+	"\bplaylist\x18\x01 \x01(\tR\bplaylist\"\xb3\a\n" +
+	"\tBreakdown\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x01R\x05total\x12\x1a\n" +
+	"\bharmonic\x18\x02 \x01(\x01R\bharmonic\x12!\n" +
+	"\fenergy_delta\x18\x03 \x01(\x01R\venergyDelta\x12\x1b\n" +
+	"\tbpm_delta\x18\x04 \x01(\x01R\bbpmDelta\x12!\n" +
+	"\fgenre_change\x18\x05 \x01(\x01R\vgenreChange\x12\x1f\n" +
+	"\vsame_artist\x18\x06 \x01(\x01R\n" +
+	"sameArtist\x12\x1d\n" +
+	"\n" +
+	"same_album\x18\a \x01(\x01R\tsameAlbum\x12#\n" +
+	"\rposition_bias\x18\b \x01(\x01R\fpositionBias\x12\x18\n" +
+	"\ashuffle\x18\t \x01(\x01R\ashuffle\x12\x18\n" +
+	"\anovelty\x18\n" +
+	" \x01(\x01R\anovelty\x12\x1d\n" +
+	"\n" +
+	"mix_length\x18\v \x01(\x01R\tmixLength\x12\x1f\n" +
+	"\venergy_wave\x18\f \x01(\x01R\n" +
+	"energyWave\x12\x19\n" +
+	"\bbpm_band\x18\r \x01(\x01R\abpmBand\x12\x1a\n" +
+	"\bbookends\x18\x0e \x01(\x01R\bbookends\x12\x1c\n" +
+	"\tfreshness\x18\x0f \x01(\x01R\tfreshness\x12\x1c\n" +
+	"\tfavorites\x18\x10 \x01(\x01R\tfavorites\x12 \n" +
+	"\vtransitions\x18\x11 \x01(\x05R\vtransitions\x12.\n" +
+	"\x13bad_key_transitions\x18\x12 \x01(\x05R\x11badKeyTransitions\x12.\n" +
+	"\x13total_energy_change\x18\x13 \x01(\x01R\x11totalEnergyChange\x12&\n" +
+	"\x0ftotal_bpm_drift\x18\x14 \x01(\x01R\rtotalBpmDrift\x12#\n" +
+	"\rgenre_changes\x18\x15 \x01(\x05R\fgenreChanges\x126\n" +
+	"\x17same_artist_adjacencies\x18\x16 \x01(\x05R\x15sameArtistAdjacencies\x124\n" +
+	"\x16same_album_adjacencies\x18\x17 \x01(\x05R\x14sameAlbumAdjacencies\x121\n" +
+	"\x14repeated_adjacencies\x18\x18 \x01(\x05R\x13repeatedAdjacencies\x12(\n" +
+	"\x10bpm_band_changes\x18\x19 \x01(\x05R\x0ebpmBandChanges\x12+\n" +
+	"\x11fresh_adjacencies\x18\x1a \x01(\x05R\x10freshAdjacencies\"\xd7\x02\n" +
+	"\x0ePlaylistResult\x12\x1a\n" +
+	"\bplaylist\x18\x01 \x01(\tR\bplaylist\x12\x16\n" +
+	"\x06tracks\x18\x02 \x03(\tR\x06tracks\x12\x18\n" +
+	"\afitness\x18\x03 \x01(\x01R\afitness\x12:\n" +
+	"\tbreakdown\x18\x04 \x01(\v2\x1c.playlistsorter.v1.BreakdownR\tbreakdown\x12\x18\n" +
+	"\awritten\x18\x05 \x01(\tR\awritten\x12\x18\n" +
+	"\askipped\x18\x06 \x03(\tR\askipped\x12M\n" +
+	"\x13theoretical_minimum\x18\a \x01(\v2\x1c.playlistsorter.v1.BreakdownR\x12theoreticalMinimum\x128\n" +
+	"\bheadroom\x18\b \x01(\v2\x1c.playlistsorter.v1.BreakdownR\bheadroom\"\x96\x02\n" +
+	"\bProgress\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x03R\n" +
+	"generation\x12!\n" +
+	"\fbest_fitness\x18\x02 \x01(\x01R\vbestFitness\x12\x1e\n" +
+	"\vgen_per_sec\x18\x03 \x01(\x01R\tgenPerSec\x12:\n" +
+	"\tbreakdown\x18\x04 \x01(\v2\x1c.playlistsorter.v1.BreakdownR\tbreakdown\x12\x16\n" +
+	"\x06tracks\x18\x05 \x03(\tR\x06tracks\x12\x18\n" +
+	"\aoptimal\x18\x06 \x01(\bR\aoptimal\x129\n" +
+	"\x06result\x18\a \x01(\v2!.playlistsorter.v1.PlaylistResultR\x06result2\x89\x02\n" +
+	"\x0ePlaylistSorter\x12Q\n" +
+	"\bOptimize\x12\".playlistsorter.v1.OptimizeRequest\x1a!.playlistsorter.v1.PlaylistResult\x12S\n" +
+	"\x0eStreamProgress\x12\".playlistsorter.v1.OptimizeRequest\x1a\x1b.playlistsorter.v1.Progress0\x01\x12O\n" +
+	"\aAnalyze\x12!.playlistsorter.v1.AnalyzeRequest\x1a!.playlistsorter.v1.PlaylistResultB\x15Z\x13playlist-sorter/apib\x06proto3"
+
+var (
+	file_api_playlistsorter_proto_rawDescOnce sync.Once
+	file_api_playlistsorter_proto_rawDescData []byte
+)
+
+func file_api_playlistsorter_proto_rawDescGZIP() []byte {
+	file_api_playlistsorter_proto_rawDescOnce.Do(func() {
+		file_api_playlistsorter_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_playlistsorter_proto_rawDesc), len(file_api_playlistsorter_proto_rawDesc)))
+	})
+	return file_api_playlistsorter_proto_rawDescData
+}
+
+var file_api_playlistsorter_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_api_playlistsorter_proto_goTypes = []any{
+	(*OptimizeRequest)(nil),     // 0: playlistsorter.v1.OptimizeRequest
+	(*AnalyzeRequest)(nil),      // 1: playlistsorter.v1.AnalyzeRequest
+	(*Breakdown)(nil),           // 2: playlistsorter.v1.Breakdown
+	(*PlaylistResult)(nil),      // 3: playlistsorter.v1.PlaylistResult
+	(*Progress)(nil),            // 4: playlistsorter.v1.Progress
+	(*durationpb.Duration)(nil), // 5: google.protobuf.Duration
+}
+var file_api_playlistsorter_proto_depIdxs = []int32{
+	5, // 0: playlistsorter.v1.OptimizeRequest.duration:type_name -> google.protobuf.Duration
+	2, // 1: playlistsorter.v1.PlaylistResult.breakdown:type_name -> playlistsorter.v1.Breakdown
+	2, // 2: playlistsorter.v1.PlaylistResult.theoretical_minimum:type_name -> playlistsorter.v1.Breakdown
+	2, // 3: playlistsorter.v1.PlaylistResult.headroom:type_name -> playlistsorter.v1.Breakdown
+	2, // 4: playlistsorter.v1.Progress.breakdown:type_name -> playlistsorter.v1.Breakdown
+	3, // 5: playlistsorter.v1.Progress.result:type_name -> playlistsorter.v1.PlaylistResult
+	0, // 6: playlistsorter.v1.PlaylistSorter.Optimize:input_type -> playlistsorter.v1.OptimizeRequest
+	0, // 7: playlistsorter.v1.PlaylistSorter.StreamProgress:input_type -> playlistsorter.v1.OptimizeRequest
+	1, // 8: playlistsorter.v1.PlaylistSorter.Analyze:input_type -> playlistsorter.v1.AnalyzeRequest
+	3, // 9: playlistsorter.v1.PlaylistSorter.Optimize:output_type -> playlistsorter.v1.PlaylistResult
+	4, // 10: playlistsorter.v1.PlaylistSorter.StreamProgress:output_type -> playlistsorter.v1.Progress
+	3, // 11: playlistsorter.v1.PlaylistSorter.Analyze:output_type -> playlistsorter.v1.PlaylistResult
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_api_playlistsorter_proto_init() }
+func file_api_playlistsorter_proto_init() {
+	if File_api_playlistsorter_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_playlistsorter_proto_rawDesc), len(file_api_playlistsorter_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_playlistsorter_proto_goTypes,
+		DependencyIndexes: file_api_playlistsorter_proto_depIdxs,
+		MessageInfos:      file_api_playlistsorter_proto_msgTypes,
+	}.Build()
+	File_api_playlistsorter_proto = out.File
+	file_api_playlistsorter_proto_goTypes = nil
+	file_api_playlistsorter_proto_depIdxs = nil
+}
@@ -0,0 +1,218 @@
+// ABOUTME: gRPC service definition mirroring the REST API served by `playlist-sorter serve`
+// ABOUTME: Regenerate the Go code with `make proto` (protoc, protoc-gen-go, protoc-gen-go-grpc) after editing
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/playlistsorter.proto
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PlaylistSorter_Optimize_FullMethodName       = "/playlistsorter.v1.PlaylistSorter/Optimize"
+	PlaylistSorter_StreamProgress_FullMethodName = "/playlistsorter.v1.PlaylistSorter/StreamProgress"
+	PlaylistSorter_Analyze_FullMethodName        = "/playlistsorter.v1.PlaylistSorter/Analyze"
+)
+
+// PlaylistSorterClient is the client API for PlaylistSorter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PlaylistSorter sorts and analyzes playlists referenced by path on the server's filesystem
+type PlaylistSorterClient interface {
+	// Optimize sorts a playlist for the requested duration and returns the best order found,
+	// optionally writing it to disk. Cancelling the call stops the optimizer early.
+	Optimize(ctx context.Context, in *OptimizeRequest, opts ...grpc.CallOption) (*PlaylistResult, error)
+	// StreamProgress sorts like Optimize, streaming a message on every improvement (and every 50
+	// generations), and ends with a message carrying the result
+	StreamProgress(ctx context.Context, in *OptimizeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Progress], error)
+	// Analyze scores a playlist's current order against its theoretical minimum
+	Analyze(ctx context.Context, in *AnalyzeRequest, opts ...grpc.CallOption) (*PlaylistResult, error)
+}
+
+type playlistSorterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlaylistSorterClient(cc grpc.ClientConnInterface) PlaylistSorterClient {
+	return &playlistSorterClient{cc}
+}
+
+func (c *playlistSorterClient) Optimize(ctx context.Context, in *OptimizeRequest, opts ...grpc.CallOption) (*PlaylistResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlaylistResult)
+	err := c.cc.Invoke(ctx, PlaylistSorter_Optimize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playlistSorterClient) StreamProgress(ctx context.Context, in *OptimizeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Progress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PlaylistSorter_ServiceDesc.Streams[0], PlaylistSorter_StreamProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[OptimizeRequest, Progress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PlaylistSorter_StreamProgressClient = grpc.ServerStreamingClient[Progress]
+
+func (c *playlistSorterClient) Analyze(ctx context.Context, in *AnalyzeRequest, opts ...grpc.CallOption) (*PlaylistResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlaylistResult)
+	err := c.cc.Invoke(ctx, PlaylistSorter_Analyze_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PlaylistSorterServer is the server API for PlaylistSorter service.
+// All implementations must embed UnimplementedPlaylistSorterServer
+// for forward compatibility.
+//
+// PlaylistSorter sorts and analyzes playlists referenced by path on the server's filesystem
+type PlaylistSorterServer interface {
+	// Optimize sorts a playlist for the requested duration and returns the best order found,
+	// optionally writing it to disk. Cancelling the call stops the optimizer early.
+	Optimize(context.Context, *OptimizeRequest) (*PlaylistResult, error)
+	// StreamProgress sorts like Optimize, streaming a message on every improvement (and every 50
+	// generations), and ends with a message carrying the result
+	StreamProgress(*OptimizeRequest, grpc.ServerStreamingServer[Progress]) error
+	// Analyze scores a playlist's current order against its theoretical minimum
+	Analyze(context.Context, *AnalyzeRequest) (*PlaylistResult, error)
+	mustEmbedUnimplementedPlaylistSorterServer()
+}
+
+// UnimplementedPlaylistSorterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPlaylistSorterServer struct{}
+
+func (UnimplementedPlaylistSorterServer) Optimize(context.Context, *OptimizeRequest) (*PlaylistResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Optimize not implemented")
+}
+func (UnimplementedPlaylistSorterServer) StreamProgress(*OptimizeRequest, grpc.ServerStreamingServer[Progress]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamProgress not implemented")
+}
+func (UnimplementedPlaylistSorterServer) Analyze(context.Context, *AnalyzeRequest) (*PlaylistResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Analyze not implemented")
+}
+func (UnimplementedPlaylistSorterServer) mustEmbedUnimplementedPlaylistSorterServer() {}
+func (UnimplementedPlaylistSorterServer) testEmbeddedByValue()                        {}
+
+// UnsafePlaylistSorterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlaylistSorterServer will
+// result in compilation errors.
+type UnsafePlaylistSorterServer interface {
+	mustEmbedUnimplementedPlaylistSorterServer()
+}
+
+func RegisterPlaylistSorterServer(s grpc.ServiceRegistrar, srv PlaylistSorterServer) {
+	// If the following call pancis, it indicates UnimplementedPlaylistSorterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PlaylistSorter_ServiceDesc, srv)
+}
+
+func _PlaylistSorter_Optimize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OptimizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlaylistSorterServer).Optimize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlaylistSorter_Optimize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlaylistSorterServer).Optimize(ctx, req.(*OptimizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlaylistSorter_StreamProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(OptimizeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlaylistSorterServer).StreamProgress(m, &grpc.GenericServerStream[OptimizeRequest, Progress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PlaylistSorter_StreamProgressServer = grpc.ServerStreamingServer[Progress]
+
+func _PlaylistSorter_Analyze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlaylistSorterServer).Analyze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlaylistSorter_Analyze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlaylistSorterServer).Analyze(ctx, req.(*AnalyzeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PlaylistSorter_ServiceDesc is the grpc.ServiceDesc for PlaylistSorter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlaylistSorter_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "playlistsorter.v1.PlaylistSorter",
+	HandlerType: (*PlaylistSorterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Optimize",
+			Handler:    _PlaylistSorter_Optimize_Handler,
+		},
+		{
+			MethodName: "Analyze",
+			Handler:    _PlaylistSorter_Analyze_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamProgress",
+			Handler:       _PlaylistSorter_StreamProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/playlistsorter.proto",
+}
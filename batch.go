@@ -0,0 +1,181 @@
+// ABOUTME: Directory input: optimizes every playlist found in a folder in one run
+// ABOUTME: Discovers .m3u8/.m3u files, sorts each in place (with sidecar configs), and prints a summary table
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// playlistExtensions are the file extensions treated as playlists in directory mode
+var playlistExtensions = []string{".m3u8", ".m3u"}
+
+// batchEntry is the outcome of optimizing one playlist in directory mode
+type batchEntry struct {
+	Path   string
+	Result sortResult
+	Err    error
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+
+	return err == nil && info.IsDir()
+}
+
+// findPlaylists returns all playlist files under dir (recursively), sorted by path.
+// Hidden files and directories are skipped.
+func findPlaylists(dir string) ([]string, error) {
+	var found []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.IsDir() && slices.Contains(playlistExtensions, strings.ToLower(filepath.Ext(path))) {
+			found = append(found, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	slices.Sort(found)
+
+	return found, nil
+}
+
+// RunDirectory optimizes every playlist in dir in place, one after another, and prints a summary.
+// opts applies to each playlist; Ctrl+C stops the current playlist (keeping its best order) and skips the rest.
+func RunDirectory(dir string, opts RunOptions) error {
+	if opts.OutputPath != "" {
+		return errors.New("-output can't be used with a directory (playlists are updated in place)")
+	}
+
+	if opts.ExportTransitions != "" {
+		return errors.New("-export-transitions can't be used with a directory (one export file per run)")
+	}
+
+	if opts.Landscape != "" {
+		return errors.New("-landscape can't be used with a directory (one snapshot file per run)")
+	}
+
+	if opts.OutputFormat != "" && opts.OutputFormat != "m3u8" {
+		return errors.New("-output-format can't be used with a directory (playlists are updated in place)")
+	}
+
+	paths, err := findPlaylists(dir)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no playlists (.m3u8, .m3u) found in %s", dir)
+	}
+
+	if opts.DebugLog {
+		if err := SetupDebugLog("playlist-sorter-debug.log"); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	fmt.Printf("Optimizing %d playlists in %s\n", len(paths), dir)
+
+	entries := make([]batchEntry, 0, len(paths))
+
+	for i, path := range paths {
+		if ctx.Err() != nil {
+			entries = append(entries, batchEntry{Path: path, Err: errors.New("skipped (interrupted)")})
+
+			continue
+		}
+
+		fmt.Printf("[%d/%d] %s\n", i+1, len(paths), path)
+
+		playlistOpts := opts
+		playlistOpts.PlaylistPath = path
+		playlistOpts.Quiet = true
+
+		result, err := sortPlaylist(ctx, playlistOpts)
+		entries = append(entries, batchEntry{Path: path, Result: result, Err: err})
+	}
+
+	fmt.Println()
+	printBatchSummary(os.Stdout, dir, entries)
+
+	for _, e := range entries {
+		if e.Err != nil {
+			return errors.New("some playlists could not be optimized")
+		}
+	}
+
+	for _, e := range entries {
+		if err := checkThresholds(e.Result, opts); err != nil {
+			var thresholdErr *thresholdError
+			if errors.As(err, &thresholdErr) {
+				thresholdErr.msg = e.Path + ": " + thresholdErr.msg
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printBatchSummary prints one row per playlist with fitness before/after
+func printBatchSummary(out io.Writer, dir string, entries []batchEntry) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "Playlist\tTracks\tBefore\tAfter\tChange\tTime\tStatus"); err != nil {
+		log.Printf("Warning: failed to write header: %v", err)
+	}
+
+	for _, e := range entries {
+		name, err := filepath.Rel(dir, e.Path)
+		if err != nil {
+			name = e.Path
+		}
+
+		row := fmt.Sprintf("%s\t-\t-\t-\t-\t-\t%s\n", name, e.Err)
+
+		if e.Err == nil {
+			r := e.Result
+
+			row = fmt.Sprintf("%s\t%d\t%.6f\t%.6f\t%+.1f%%\t%s\tok\n",
+				name, r.Tracks, r.InitialFitness, r.FinalFitness, -r.Improvement(), r.Elapsed.Round(time.Second))
+		}
+
+		if _, err := fmt.Fprint(w, row); err != nil {
+			log.Printf("Warning: failed to write summary row: %v", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Printf("Warning: failed to flush output: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Tests for directory input mode
+// ABOUTME: Verifies playlist discovery, option validation, and the summary table
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindPlaylists(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{
+		"b.m3u8",
+		"a.M3U",
+		"notes.txt",
+		"crate/c.m3u8",
+		".hidden/d.m3u8",
+		".playlist-sorter-123.m3u8",
+		"a.sorter.json",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := findPlaylists(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "a.M3U"), filepath.Join(dir, "b.m3u8"), filepath.Join(dir, "crate", "c.m3u8")}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRunDirectoryRejectsOutput(t *testing.T) {
+	if err := RunDirectory(t.TempDir(), RunOptions{OutputPath: "x.m3u8"}); err == nil {
+		t.Error("Expected error for -output with a directory")
+	}
+
+	if err := RunDirectory(t.TempDir(), RunOptions{}); err == nil {
+		t.Error("Expected error for a directory without playlists")
+	}
+}
+
+func TestPrintBatchSummary(t *testing.T) {
+	var buf bytes.Buffer
+
+	printBatchSummary(&buf, "/crate", []batchEntry{
+		{Path: "/crate/friday.m3u8", Result: sortResult{Tracks: 40, InitialFitness: 0.5, FinalFitness: 0.25, Elapsed: 90 * time.Second}},
+		{Path: "/crate/broken.m3u8", Err: errors.New("playlist is empty")},
+	})
+
+	out := buf.String()
+
+	for _, want := range []string{"friday.m3u8", "-50.0%", "1m30s", "broken.m3u8", "playlist is empty"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Summary missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// ABOUTME: Exports the optimized order to beets as a flexible attribute
+// ABOUTME: Runs `beet modify` per track so beets smart playlists can sort by the stored position
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"playlist-sorter/playlist"
+)
+
+// beetCommand is the beets CLI executable
+const beetCommand = "beet"
+
+// validBeetsAttr matches attribute names beets accepts as flexattrs
+var validBeetsAttr = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// beetModifyArgs builds the `beet modify` arguments that set attr=position on the item at path.
+// The path query must be absolute since beets stores absolute paths.
+func beetModifyArgs(path, attr string, position int) []string {
+	return []string{"modify", "-y", "path:" + path, attr + "=" + strconv.Itoa(position)}
+}
+
+// exportToBeets stores each track's 1-based position in the beets flexattr attr.
+// Continues past failures and returns the number of tracks updated and the first error.
+func exportToBeets(tracks []playlist.Track, playlistPath, attr string) (int, error) {
+	if !validBeetsAttr.MatchString(attr) {
+		return 0, fmt.Errorf("invalid beets attribute name %q (use lowercase letters, digits and _)", attr)
+	}
+
+	if _, err := exec.LookPath(beetCommand); err != nil {
+		return 0, errors.New("beet command not found in PATH")
+	}
+
+	var firstErr error
+
+	updated := 0
+
+	for i := range tracks {
+		path := tracks[i].Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(playlistPath), path)
+		}
+
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+
+		cmd := exec.Command(beetCommand, beetModifyArgs(path, attr, i+1)...) //nolint:gosec // Arguments are built from playlist paths
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("beet modify %s: %w: %s", tracks[i].Path, err, strings.TrimSpace(string(out)))
+			}
+
+			continue
+		}
+
+		updated++
+	}
+
+	return updated, firstErr
+}
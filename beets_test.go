@@ -0,0 +1,26 @@
+// ABOUTME: Tests for exporting the optimized order to beets flexattrs
+// ABOUTME: Verifies beet modify argument building and attribute name validation
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBeetModifyArgs(t *testing.T) {
+	got := beetModifyArgs("/music/My Track.flac", "set_position", 7)
+	want := []string{"modify", "-y", "path:/music/My Track.flac", "set_position=7"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExportToBeetsRejectsInvalidAttr(t *testing.T) {
+	for _, attr := range []string{"", "Set Position", "pos=1", "9lives"} {
+		if _, err := exportToBeets(nil, "set.m3u8", attr); err == nil {
+			t.Errorf("Expected error for attribute %q", attr)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// ABOUTME: Bench subcommand running the optimizer for a fixed time without writing output
+// ABOUTME: Reports generation throughput and fitness reached, for comparing builds and settings
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const defaultBenchDuration = 30 * time.Second // Default optimizer run time for bench
+
+// benchResult summarizes one bench run
+type benchResult struct {
+	Tracks         int
+	Elapsed        time.Duration
+	Generations    int     // Last generation reported by the GA (reported every 50 gens or on improvement)
+	InitialFitness float64 // Fitness of the playlist's current order
+	FinalFitness   float64
+	Improvements   int // Number of updates with a better fitness
+}
+
+// GenPerSec returns the average generation throughput
+func (r benchResult) GenPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(r.Generations) / r.Elapsed.Seconds()
+}
+
+// runBench implements `playlist-sorter bench [flags] <playlist>`
+func runBench(args []string) int {
+	fs := newFlagSet("bench", "bench [flags] <playlist.m3u8>")
+	profile := addProfileFlags(fs)
+	duration := fs.Duration("duration", defaultBenchDuration, "how long to run the optimizer (max 5m)")
+	landscape := fs.String("landscape", "", "write a snapshot of the whole population's scores and diversity to this file (JSON lines) every -landscape-every generations")
+	landscapeEvery := fs.Int("landscape-every", defaultLandscapeEvery, "generations between -landscape snapshots")
+
+	playlistPath, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	data, err := InitializePlaylist(PlaylistOptions{Path: playlistPath})
+	if err != nil {
+		log.Printf("Bench error: %v", err)
+
+		return 1
+	}
+
+	if *landscape != "" {
+		recorder, closeLandscape, err := openLandscape(*landscape, *landscapeEvery)
+		if err != nil {
+			log.Printf("Bench error: %v", err)
+
+			return 1
+		}
+
+		defer func() {
+			if err := closeLandscape(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}()
+
+		data.GACtx.landscape = recorder
+	}
+
+	updateNormalizedWeights(data.GACtx, data.Config)
+
+	result := benchResult{
+		Tracks:         len(data.Tracks),
+		InitialFitness: calculateFitness(data.Tracks, data.Config, data.GACtx),
+	}
+
+	fmt.Printf("Benchmarking %s (%d tracks) for %s...\n", playlistPath, result.Tracks, *duration)
+
+	stopProfiling := profile.start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), min(*duration, maxDuration))
+	defer cancel()
+
+	updates := make(chan GAUpdate, 100)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		best := result.InitialFitness
+
+		for update := range updates {
+			result.Generations = update.Generation
+
+			if hasFitnessImproved(update.BestFitness, best, fitnessImprovementEpsilon) {
+				best = update.BestFitness
+				result.Improvements++
+			}
+		}
+	}()
+
+	start := time.Now()
+	best, err := geneticSort(ctx, data.Tracks, data.SharedConfig, updates, 0, data.GACtx)
+	result.Elapsed = time.Since(start)
+
+	close(updates)
+	<-done
+
+	stopProfiling()
+
+	if err != nil {
+		log.Printf("Bench error: %v", err)
+
+		return 1
+	}
+
+	result.FinalFitness = calculateFitness(best, data.Config, data.GACtx)
+
+	fmt.Printf("Elapsed:       %s\n", result.Elapsed.Round(time.Millisecond))
+	fmt.Printf("Generations:   ~%d (%.1f gen/s)\n", result.Generations, result.GenPerSec())
+	fmt.Printf("Improvements:  %d\n", result.Improvements)
+	fmt.Printf("Fitness:       %.8f -> %.8f\n", result.InitialFitness, result.FinalFitness)
+
+	return 0
+}
@@ -0,0 +1,344 @@
+// ABOUTME: Divide-and-conquer optimization for playlists too large for a single GA run
+// ABOUTME: Clusters similar tracks into chunks, sorts them in parallel, then orders chunks and polishes the joins
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// boundaryWindow is how many tracks on each side of a chunk join are re-optimized after joining
+const boundaryWindow = 8
+
+// minChunkSize is the smallest -chunk-size: smaller chunks leave each GA run little to order and
+// make the joins between them most of the playlist
+const minChunkSize = 20
+
+// pathScorer scores orderings without an n² edge cache: each transition is computed on demand,
+// with normalizers taken over the whole playlist so totals match the cached fitness.
+// Shuffle jitter is per run and not part of the score.
+type pathScorer struct {
+	config   config.GAConfig
+	ctx      *GAContext // Normalizers and weights only (no edge cache)
+	previous map[[2]string]bool
+}
+
+// newPathScorer creates a scorer for orderings of tracks. previousOrder holds the track paths of the
+// last saved output for the novelty component.
+func newPathScorer(tracks []playlist.Track, cfg config.GAConfig, previousOrder []string) *pathScorer {
+	ctx := &GAContext{normalizers: computeNormalizers(tracks)}
+	ctx.weights = normalizedWeights(&ctx.normalizers, cfg)
+	ctx.genreSources, ctx.genreNames = resolveGenreOverrides(cfg)
+	ctx.genreWeights = genreOverrideWeights(&ctx.normalizers, cfg, ctx.genreSources)
+
+	previous := make(map[[2]string]bool, len(previousOrder))
+	for i := 1; i < len(previousOrder); i++ {
+		previous[[2]string{previousOrder[i-1], previousOrder[i]}] = true
+		previous[[2]string{previousOrder[i], previousOrder[i-1]}] = true
+	}
+
+	return &pathScorer{config: cfg, ctx: ctx, previous: previous}
+}
+
+// addEdge adds the weighted components of the transition a -> b to breakdown
+func (s *pathScorer) addEdge(breakdown *playlist.Breakdown, a, b *playlist.Track) {
+	edge := computeEdge(a, b)
+	edge.PreviousAdjacent = s.previous[[2]string{a.Path, b.Path}]
+
+	w := &s.ctx.weights
+	if s.ctx.genreNames != nil {
+		if k := genreOverrideIndex(a.Genre, s.ctx.genreNames); k >= 0 && k == genreOverrideIndex(b.Genre, s.ctx.genreNames) {
+			w = &s.ctx.genreWeights[k]
+		}
+	}
+
+	addWeightedEdge(breakdown, &edge, 0, w)
+}
+
+// edgeCost returns the weighted cost of the transition a -> b
+func (s *pathScorer) edgeCost(a, b *playlist.Track) float64 {
+	var breakdown playlist.Breakdown
+
+	s.addEdge(&breakdown, a, b)
+
+	return breakdown.Sum()
+}
+
+// breakdown computes the fitness breakdown of tracks in their current order
+func (s *pathScorer) breakdown(tracks []playlist.Track) playlist.Breakdown {
+	var breakdown playlist.Breakdown
+
+	for j := range tracks {
+		if j > 0 {
+			s.addEdge(&breakdown, &tracks[j-1], &tracks[j])
+		}
+
+		positionalCost(&tracks[j], j, len(tracks), s.config, s.ctx).addTo(&breakdown)
+	}
+
+	breakdown.Total = breakdown.Sum()
+
+	return breakdown
+}
+
+// segmentCost returns the cost of tracks[start:end+1]: the transitions into, within and out of the
+// segment plus the position-dependent costs of its tracks
+func (s *pathScorer) segmentCost(tracks []playlist.Track, start, end int) float64 {
+	total := 0.0
+
+	for j := max(start, 1); j <= min(end+1, len(tracks)-1); j++ {
+		total += s.edgeCost(&tracks[j-1], &tracks[j])
+	}
+
+	for j := start; j <= end; j++ {
+		total += positionalCost(&tracks[j], j, len(tracks), s.config, s.ctx).total()
+	}
+
+	return total
+}
+
+// chunkedSort optimizes a large playlist by divide and conquer, trading optimality for tractability:
+// tracks are clustered into chunks of about chunkSize similar tracks, each chunk is sorted by its own
+// GA run, up to one per worker thread in parallel (so only chunk-sized edge caches are ever built), and
+// the sorted chunks are then ordered to minimize the cost of the joins, which are finally polished with 2-opt.
+func chunkedSort(ctx context.Context, tracks []playlist.Track, sharedCfg *config.SharedConfig, chunkSize int, scorer *pathScorer, previousOrder []string, out cliPrinter) ([]playlist.Track, error) {
+	chunks := clusterTracks(tracks, chunkSize)
+	errs := make([]error, len(chunks))
+
+	// Each GA run already uses every worker thread, so at most that many chunks run at once. Further
+	// chunks run in waves that split the time left, rather than all starting and sharing the CPUs.
+	limit := min(throttle.workers(), len(chunks))
+	waves := (len(chunks) + limit - 1) / limit
+
+	var budget time.Duration
+	if deadline, ok := ctx.Deadline(); ok && waves > 1 {
+		budget = time.Until(deadline) / time.Duration(waves)
+	}
+
+	out.Printf("Optimizing %d chunks of ~%d tracks, %d at a time...\n", len(chunks), len(tracks)/len(chunks), limit)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+		slot = make(chan struct{}, limit)
+	)
+
+	for i := range chunks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			slot <- struct{}{}
+			defer func() { <-slot }()
+
+			chunkCtx := ctx
+			if budget > 0 {
+				var cancel context.CancelFunc
+
+				chunkCtx, cancel = context.WithTimeout(ctx, budget)
+				defer cancel()
+			}
+
+			chunks[i], errs[i] = optimizeChunk(chunkCtx, chunks[i], sharedCfg, previousOrder)
+
+			mu.Lock()
+			done++
+			out.Printf("  Chunk %d/%d done (%d tracks)\n", done, len(chunks), len(chunks[i]))
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	out.Println("Ordering chunks and polishing chunk boundaries...")
+
+	chunks = orderChunks(chunks, scorer)
+
+	sorted := make([]playlist.Track, 0, len(tracks))
+	joins := make([]int, 0, len(chunks)-1)
+
+	for i, chunk := range chunks {
+		if i > 0 {
+			joins = append(joins, len(sorted))
+		}
+
+		sorted = append(sorted, chunk...)
+	}
+
+	polishJoins(sorted, joins, scorer)
+
+	// Restore the playlist-wide indices the rest of the pipeline expects
+	playlist.ReindexTracks(sorted)
+
+	return sorted, nil
+}
+
+// clusterTracks splits tracks into chunks of similar tracks: sorted by energy, then BPM, then key,
+// and cut into equal slices of at most chunkSize tracks (so no chunk is left with a single track)
+func clusterTracks(tracks []playlist.Track, chunkSize int) [][]playlist.Track {
+	sorted := slices.Clone(tracks)
+	slices.SortStableFunc(sorted, func(a, b playlist.Track) int {
+		return cmp.Or(
+			cmp.Compare(a.Energy, b.Energy),
+			cmp.Compare(a.BPM, b.BPM),
+			cmp.Compare(keyOrder(a.ParsedKey), keyOrder(b.ParsedKey)),
+		)
+	})
+
+	numChunks := (len(sorted) + chunkSize - 1) / chunkSize
+	chunks := make([][]playlist.Track, 0, numChunks)
+
+	for i := range numChunks {
+		start := i * len(sorted) / numChunks
+		end := (i + 1) * len(sorted) / numChunks
+		chunks = append(chunks, sorted[start:end:end])
+	}
+
+	return chunks
+}
+
+// keyOrder returns a sort key placing Camelot keys by wheel position (unknown keys first)
+func keyOrder(key *playlist.CamelotKey) int {
+	if key == nil {
+		return 0
+	}
+
+	return key.Number*2 + int(key.Letter-'A')
+}
+
+// optimizeChunk sorts one chunk with its own GA run and chunk-local edge cache until ctx is done
+func optimizeChunk(ctx context.Context, chunk []playlist.Track, sharedCfg *config.SharedConfig, previousOrder []string) ([]playlist.Track, error) {
+	chunk = slices.Clone(chunk)
+	if len(chunk) < 2 {
+		return chunk, nil
+	}
+
+	playlist.ReindexTracks(chunk)
+
+	gaCtx := buildEdgeFitnessCache(chunk)
+	markPreviousAdjacencies(gaCtx, chunk, previousOrder)
+
+	return geneticSort(ctx, chunk, sharedCfg, nil, 0, gaCtx)
+}
+
+// orderChunks chooses the order and direction of the sorted chunks that minimizes the cost of the
+// transitions between them. The lowest-energy chunk stays first; the rest are placed greedily by
+// cheapest join, then improved with 2-opt over the chunk sequence (reversing a run of chunks also
+// reverses each chunk in it).
+func orderChunks(chunks [][]playlist.Track, scorer *pathScorer) [][]playlist.Track {
+	if len(chunks) < 2 {
+		return chunks
+	}
+
+	ordered := [][]playlist.Track{chunks[0]}
+	remaining := slices.Clone(chunks[1:])
+
+	for len(remaining) > 0 {
+		tail := lastTrack(ordered[len(ordered)-1])
+
+		bestIdx, bestReversed, bestCost := 0, false, 0.0
+
+		for i, chunk := range remaining {
+			if cost := scorer.edgeCost(tail, &chunk[0]); i == 0 || cost < bestCost {
+				bestIdx, bestReversed, bestCost = i, false, cost
+			}
+
+			if cost := scorer.edgeCost(tail, lastTrack(chunk)); cost < bestCost {
+				bestIdx, bestReversed, bestCost = i, true, cost
+			}
+		}
+
+		next := remaining[bestIdx]
+		if bestReversed {
+			next = reversedTracks(next)
+		}
+
+		ordered = append(ordered, next)
+		remaining = slices.Delete(remaining, bestIdx, bestIdx+1)
+	}
+
+	joinsCost := func(seq [][]playlist.Track) float64 {
+		total := 0.0
+		for i := 1; i < len(seq); i++ {
+			total += scorer.edgeCost(lastTrack(seq[i-1]), &seq[i][0])
+		}
+
+		return total
+	}
+
+	for improved := true; improved; {
+		improved = false
+		current := joinsCost(ordered)
+
+		for i := 1; i < len(ordered)-1; i++ {
+			for j := i + 1; j < len(ordered); j++ {
+				candidate := slices.Clone(ordered)
+				slices.Reverse(candidate[i : j+1])
+
+				for k := i; k <= j; k++ {
+					candidate[k] = reversedTracks(candidate[k])
+				}
+
+				if cost := joinsCost(candidate); hasFitnessImproved(cost, current, floatingPointEpsilon) {
+					ordered, current, improved = candidate, cost, true
+				}
+			}
+		}
+	}
+
+	return ordered
+}
+
+// polishJoins re-optimizes the tracks around each chunk join with 2-opt segment reversals
+// confined to a window of boundaryWindow tracks on either side, so chunk interiors keep their order
+func polishJoins(tracks []playlist.Track, joins []int, scorer *pathScorer) {
+	for _, join := range joins {
+		lo := max(join-boundaryWindow, 0)
+		hi := min(join+boundaryWindow-1, len(tracks)-1)
+
+		for improved := true; improved; {
+			improved = false
+
+			for i := lo; i < hi; i++ {
+				for j := i + 1; j <= hi; j++ {
+					before := scorer.segmentCost(tracks, i, j)
+
+					reverseSegment(tracks, i, j)
+
+					if hasFitnessImproved(scorer.segmentCost(tracks, i, j), before, floatingPointEpsilon) {
+						improved = true
+					} else {
+						reverseSegment(tracks, i, j)
+					}
+				}
+			}
+		}
+	}
+}
+
+// lastTrack returns a pointer to the final track of a non-empty chunk
+func lastTrack(chunk []playlist.Track) *playlist.Track {
+	return &chunk[len(chunk)-1]
+}
+
+// reversedTracks returns a reversed copy of tracks
+func reversedTracks(tracks []playlist.Track) []playlist.Track {
+	reversed := slices.Clone(tracks)
+	slices.Reverse(reversed)
+
+	return reversed
+}
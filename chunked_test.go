@@ -0,0 +1,162 @@
+// ABOUTME: Tests for divide-and-conquer optimization of large playlists
+// ABOUTME: Validates clustering, on-demand scoring, chunk ordering and the end-to-end chunked sort
+
+package main
+
+import (
+	"context"
+	"io"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+func TestClusterTracks(t *testing.T) {
+	tracks := benchmarkTracks(101)
+
+	chunks := clusterTracks(tracks, 25)
+	if len(chunks) != 5 {
+		t.Fatalf("Expected 5 chunks for 101 tracks of chunk size 25, got %d", len(chunks))
+	}
+
+	total := 0
+	prevMaxEnergy := 0
+
+	for i, chunk := range chunks {
+		if len(chunk) < 20 || len(chunk) > 25 {
+			t.Errorf("Chunk %d has %d tracks, expected 20-25", i, len(chunk))
+		}
+
+		if chunk[0].Energy < prevMaxEnergy {
+			t.Errorf("Chunk %d starts at energy %d, below the previous chunk's %d", i, chunk[0].Energy, prevMaxEnergy)
+		}
+
+		prevMaxEnergy = chunk[len(chunk)-1].Energy
+		total += len(chunk)
+	}
+
+	if total != len(tracks) {
+		t.Errorf("Expected chunks to hold all %d tracks, got %d", len(tracks), total)
+	}
+}
+
+func TestPathScorerMatchesCachedFitness(t *testing.T) {
+	tracks := benchmarkTracks(60)
+	cfg := config.DefaultConfig()
+	cfg.NoveltyWeight = 0.5
+
+	previous := make([]string, 0, 10)
+	for _, track := range tracks[20:30] {
+		previous = append(previous, track.Path)
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	markPreviousAdjacencies(ctx, tracks, previous)
+	updateNormalizedWeights(ctx, cfg)
+
+	want := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+	got := newPathScorer(tracks, cfg, previous).breakdown(tracks)
+
+	if math.Abs(got.Total-want.Total) > 1e-9 || math.Abs(got.Novelty-want.Novelty) > 1e-9 {
+		t.Errorf("Expected on-demand score %.10f (novelty %.6f) to match cached %.10f (novelty %.6f)",
+			got.Total, got.Novelty, want.Total, want.Novelty)
+	}
+}
+
+func TestUniformEnergyFitnessIsFinite(t *testing.T) {
+	tracks := benchmarkTracks(10)
+	for i := range tracks {
+		tracks[i].Energy = 5
+		tracks[i].BPM = 120
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(ctx, config.DefaultConfig())
+
+	if fitness := calculateFitness(tracks, config.DefaultConfig(), ctx); math.IsNaN(fitness) || math.IsInf(fitness, 0) {
+		t.Errorf("Expected finite fitness when all tracks share one energy and BPM, got %v", fitness)
+	}
+}
+
+func TestOrderChunksPrefersCheapJoins(t *testing.T) {
+	tracks := benchmarkTracks(30)
+	scorer := newPathScorer(tracks, config.DefaultConfig(), nil)
+
+	chunks := clusterTracks(tracks, 10)
+	shuffled := [][]playlist.Track{chunks[0], chunks[2], chunks[1]}
+
+	joins := func(seq [][]playlist.Track) float64 {
+		total := 0.0
+		for i := 1; i < len(seq); i++ {
+			total += scorer.edgeCost(lastTrack(seq[i-1]), &seq[i][0])
+		}
+
+		return total
+	}
+
+	ordered := orderChunks(shuffled, scorer)
+
+	if ordered[0][0].Path != chunks[0][0].Path {
+		t.Error("Expected the first chunk to stay in place")
+	}
+
+	if joins(ordered) > joins(shuffled)+floatingPointEpsilon {
+		t.Errorf("Expected ordering not to worsen joins: %.6f > %.6f", joins(ordered), joins(shuffled))
+	}
+}
+
+func TestChunkedSort(t *testing.T) {
+	tracks := benchmarkTracks(90)
+	cfg := config.DefaultConfig()
+
+	sharedCfg := &config.SharedConfig{}
+	sharedCfg.Update(cfg)
+
+	scorer := newPathScorer(tracks, cfg, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	sorted, err := chunkedSort(ctx, tracks, sharedCfg, 30, scorer, nil, cliPrinter{w: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sorted) != len(tracks) {
+		t.Fatalf("Expected %d tracks, got %d", len(tracks), len(sorted))
+	}
+
+	paths := trackPathsOf(sorted)
+	slices.Sort(paths)
+
+	want := trackPathsOf(tracks)
+	slices.Sort(want)
+
+	if !slices.Equal(paths, want) {
+		t.Error("Expected the chunked sort to return a permutation of the input")
+	}
+
+	for i, track := range sorted {
+		if track.Index != i {
+			t.Fatalf("Expected track %d to have Index %d, got %d", i, i, track.Index)
+		}
+	}
+
+	if before, after := scorer.breakdown(tracks).Total, scorer.breakdown(sorted).Total; after >= before {
+		t.Errorf("Expected chunked sort to improve fitness: %.6f -> %.6f", before, after)
+	}
+}
+
+// trackPathsOf returns the paths of tracks in order
+func trackPathsOf(tracks []playlist.Track) []string {
+	paths := make([]string, len(tracks))
+	for i, track := range tracks {
+		paths[i] = track.Path
+	}
+
+	return paths
+}
@@ -5,17 +5,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"text/tabwriter"
 	"time"
 
 	"playlist-sorter/config"
+	"playlist-sorter/live"
 	"playlist-sorter/playlist"
+	"playlist-sorter/termtext"
 )
 
 const (
@@ -32,6 +38,159 @@ func isTTY(f *os.File) bool {
 	return (stat.Mode() & os.ModeCharDevice) != 0
 }
 
+// cliPrinter writes human-readable progress. It discards output when the sorted playlist
+// itself is written to stdout, so the tool composes in pipelines.
+type cliPrinter struct {
+	w     io.Writer
+	plain bool // No spinner or ANSI control sequences (NO_COLOR / -no-color)
+}
+
+// Printf formats to the progress output
+func (p cliPrinter) Printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(p.w, format, args...)
+}
+
+// Println prints a line to the progress output
+func (p cliPrinter) Println(args ...any) {
+	_, _ = fmt.Fprintln(p.w, args...)
+}
+
+// Print prints to the progress output
+func (p cliPrinter) Print(args ...any) {
+	_, _ = fmt.Fprint(p.w, args...)
+}
+
+// animated reports whether progress may use the spinner and ANSI line clearing: only when it goes
+// to stdout on a terminal, and not in plain mode (non-interactive runs like cron and pipes are plain too)
+func (p cliPrinter) animated(terminal bool) bool {
+	return p.w == os.Stdout && !p.plain && terminal
+}
+
+// Where a CLI run sends each improvement as it is found, for view mode (-live)
+const (
+	liveWritesSocket      = "socket"       // A local socket view mode subscribes to; nothing is written (default)
+	liveWritesWorkingCopy = "working-copy" // The playlist's working copy, removed when the run ends
+	liveWritesInPlace     = "in-place"     // The output playlist: the playlist itself unless -output is set (not in -dry-run)
+	liveWritesOff         = "off"          // Nowhere
+)
+
+// parseLiveWrites validates a -live value (empty means the socket)
+func parseLiveWrites(name string) (string, error) {
+	switch name {
+	case "":
+		return liveWritesSocket, nil
+	case liveWritesSocket, liveWritesWorkingCopy, liveWritesInPlace, liveWritesOff:
+		return name, nil
+	}
+
+	return "", fmt.Errorf("unknown -live mode %q (available: %s, %s, %s, %s)", name,
+		liveWritesSocket, liveWritesWorkingCopy, liveWritesInPlace, liveWritesOff)
+}
+
+// Exit codes for playlists failing a -fail-if-* threshold, distinct from errors (1) and bad usage (2)
+const (
+	exitFitnessAbove     = 3
+	exitImprovementBelow = 4
+)
+
+// sortResult summarizes one CLI optimization run
+type sortResult struct {
+	Tracks         int
+	InitialFitness float64
+	FinalFitness   float64
+	Elapsed        time.Duration
+	OutputPath     string
+	Skipped        int  // Tracks left out of optimization (unreadable metadata)
+	BPMCorrected   int  // Tracks whose half/double-time BPM was corrected (see bpm_ranges)
+	Optimal        bool // Order found by exhaustive search, so no better one exists
+}
+
+// Improvement returns how much the fitness dropped, in percent of the initial fitness
+// (0 when the initial order already scored 0)
+func (r sortResult) Improvement() float64 {
+	if r.InitialFitness == 0 {
+		return 0
+	}
+
+	return (r.InitialFitness - r.FinalFitness) * 100 / r.InitialFitness
+}
+
+// Summary formats the run on one line, e.g. "set.m3u8: 120 tracks, fitness 0.51 -> 0.30 (-41.2%) in 5m0s"
+func (r sortResult) Summary() string {
+	summary := fmt.Sprintf("%s: %d tracks, fitness %.6f -> %.6f (%+.1f%%) in %s",
+		r.OutputPath, r.Tracks, r.InitialFitness, r.FinalFitness, -r.Improvement(), r.Elapsed.Round(time.Second))
+
+	if r.Optimal {
+		summary += " (optimal)"
+	}
+
+	if r.Skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped (unreadable metadata)", r.Skipped)
+	}
+
+	if r.BPMCorrected > 0 {
+		summary += fmt.Sprintf(", %d BPMs corrected (half/double time)", r.BPMCorrected)
+	}
+
+	return summary
+}
+
+// thresholdError reports a playlist failing a -fail-if-* threshold. The sorted playlist has
+// still been written; code is the exit code to report it with.
+type thresholdError struct {
+	code int
+	msg  string
+}
+
+func (e *thresholdError) Error() string {
+	return e.msg
+}
+
+// checkThresholds returns a *thresholdError if r fails one of the thresholds set in opts
+func checkThresholds(r sortResult, opts RunOptions) error {
+	if opts.FailIfAbove > 0 && r.FinalFitness > opts.FailIfAbove {
+		return &thresholdError{
+			code: exitFitnessAbove,
+			msg:  fmt.Sprintf("final fitness %.6f is above -fail-if-above %g", r.FinalFitness, opts.FailIfAbove),
+		}
+	}
+
+	if opts.FailIfImprovementBelow > 0 && r.InitialFitness > 0 && r.Improvement() < opts.FailIfImprovementBelow {
+		return &thresholdError{
+			code: exitImprovementBelow,
+			msg: fmt.Sprintf("fitness improved by %.2f%%, below -fail-if-improvement-below %g%%",
+				r.Improvement(), opts.FailIfImprovementBelow),
+		}
+	}
+
+	return nil
+}
+
+// printSkipped reports the tracks left out of optimization. The warning goes to stderr so it is
+// seen in -quiet runs and pipelines too; the list of tracks follows on out.
+func printSkipped(out cliPrinter, skipped []playlist.SkippedTrack, placement playlist.SkippedPlacement) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	log.Printf("Warning: %d tracks skipped because their metadata could not be read; %s",
+		len(skipped), placement.Description())
+
+	unread := 0
+
+	for _, s := range skipped {
+		out.Printf("  #%d %s: %v\n", s.Position+1, s.Path, s.Err)
+
+		if errors.Is(s.Err, playlist.ErrReadSkipped) {
+			unread++
+		}
+	}
+
+	if unread > 0 {
+		log.Printf("Warning: %d of them were not read at all after earlier reads timed out (unresponsive mount?)", unread)
+	}
+}
+
 // RunCLI executes CLI mode optimization
 func RunCLI(opts RunOptions) error {
 	if opts.DebugLog {
@@ -40,87 +199,418 @@ func RunCLI(opts RunOptions) error {
 		}
 	}
 
-	data, err := InitializePlaylist(PlaylistOptions{
-		Path:    opts.PlaylistPath,
-		Verbose: true,
-	})
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	result, err := sortPlaylist(ctx, opts)
 	if err != nil {
 		return err
 	}
 
+	// Quiet mode replaces the progress output with one line (stdout stays clean for a piped playlist)
+	if opts.Quiet && result.OutputPath != playlist.StdioPath {
+		fmt.Println(result.Summary())
+	}
+
+	return checkThresholds(result, opts)
+}
+
+// newSignalContext returns a context cancelled on Ctrl+C or SIGTERM, so the best order so far is kept
+func newSignalContext() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		<-stop
-		cancel()
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+
+		signal.Stop(stop)
 	}()
 
-	theoreticalMin := calculateTheoreticalMinimum(data.Tracks, data.Config, data.GACtx)
-	initialFitness := calculateFitness(data.Tracks, data.Config, data.GACtx)
+	return ctx, cancel
+}
 
-	fmt.Println("\nOptimizing playlist... (press Ctrl+C to stop early, or wait up to 5 minutes)")
-	fmt.Printf("Initial fitness: %.10f\n", initialFitness)
-	fmt.Printf("Theoretical minimum: %.10f (not achievable, conflicting constraints)\n", theoreticalMin)
-	fmt.Println()
+// sortPlaylist optimizes one playlist and writes the result. Stops early when ctx is cancelled.
+func sortPlaylist(ctx context.Context, opts RunOptions) (sortResult, error) {
+	startTime := time.Now()
 
-	sortedTracks := cliGeneticSort(ctx, data.Tracks, data.SharedConfig, data.GACtx, opts.PlaylistPath)
+	outputPath := opts.PlaylistPath
+	if opts.OutputPath != "" {
+		outputPath = opts.OutputPath
+	}
 
-	fmt.Println("\nSorted playlist:")
+	// Playlist on stdout: keep stdout clean for the playlist (errors still go to stderr)
+	toStdout := outputPath == playlist.StdioPath && !opts.DryRun
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	if _, err := fmt.Fprintln(w, "#\tKey\tBPM\tEng\tArtist\tTitle\tAlbum\tGenre"); err != nil {
+	out := cliPrinter{w: os.Stdout, plain: opts.NoColor}
+	if toStdout || opts.Quiet {
+		out.w = io.Discard
+	}
+
+	format, err := playlist.ParseFormat(opts.OutputFormat)
+	if err != nil {
+		return sortResult{}, err
+	}
+
+	// Never replace the input playlist with a file in another format
+	if format != playlist.FormatM3U8 && opts.OutputPath == "" && opts.PlaylistPath != playlist.StdioPath {
+		return sortResult{}, fmt.Errorf("-output-format %s requires -output", format)
+	}
+
+	// The report goes next to the output, or next to the input when the output is stdout
+	reportTarget := outputPath
+	if opts.Report != "" && reportTarget == playlist.StdioPath {
+		if opts.PlaylistPath == playlist.StdioPath {
+			return sortResult{}, errors.New("-report needs a playlist file to write next to (not stdin)")
+		}
+
+		reportTarget = opts.PlaylistPath
+	}
+
+	// Fail now rather than at the final write, after the whole optimization run
+	if !opts.DryRun && outputPath != playlist.StdioPath {
+		if err := checkWritable(outputPath); err != nil {
+			return sortResult{}, err
+		}
+
+		release, err := acquirePlaylistLock(outputPath)
+		if err != nil {
+			return sortResult{}, err
+		}
+
+		defer release()
+	}
+
+	// Live updates for view mode only make sense for a playlist file
+	var (
+		livePath  string
+		publisher *live.Publisher
+	)
+
+	if opts.PlaylistPath != playlist.StdioPath {
+		switch opts.LiveWrites {
+		case liveWritesOff:
+		case liveWritesInPlace:
+			// In place is the file the run writes (and holds the lock on): -output when set. Other
+			// formats and stdout are only written once, at the end.
+			if !opts.DryRun && !toStdout && format == playlist.FormatM3U8 {
+				livePath = outputPath
+			}
+		case liveWritesWorkingCopy:
+			// Other readers of the playlist only ever see the original order or the final result
+			if !opts.DryRun {
+				livePath = playlist.WorkingCopyPath(opts.PlaylistPath)
+
+				defer func() { _ = os.Remove(livePath) }()
+			}
+		default:
+			p, err := live.Listen(opts.PlaylistPath)
+			if err != nil {
+				log.Printf("Warning: no live view for this run: %v", err)
+
+				break
+			}
+
+			publisher = p
+
+			defer func() { _ = publisher.Close() }()
+		}
+	}
+
+	previousOrder := loadPreviousOrder(outputPath)
+
+	data, err := InitializePlaylist(PlaylistOptions{
+		Path:          opts.PlaylistPath,
+		Verbose:       out.w != io.Discard,
+		PreviousOrder: previousOrder,
+		ImputeEnergy:  opts.ImputeEnergy,
+		SkipEdgeCache: opts.ChunkSize > 0,
+		KeyOnly:       opts.KeyOnly,
+		Preset:        opts.Preset,
+		AutoProfile:   opts.AutoProfile,
+		PathMap:       opts.PathMap,
+		Overrides:     opts.Overrides,
+	})
+	if err != nil {
+		return sortResult{}, err
+	}
+
+	// Unreadable entries are not optimized but go back into every written playlist
+	printSkipped(out, data.Skipped, opts.Skipped)
+
+	withSkipped := func(tracks []playlist.Track) []playlist.Track {
+		return playlist.WithSkipped(tracks, data.Skipped, opts.Skipped)
+	}
+
+	var writeLive func([]playlist.Track)
+
+	switch {
+	case publisher != nil:
+		writeLive = func(tracks []playlist.Track) {
+			// Only called by the single-run GA, whose weights are normalized before its first update
+			update := live.Update{Tracks: tracks, Breakdown: calculateFitnessWithBreakdown(tracks, data.Config, data.GACtx)}
+			if err := publisher.Publish(update); err != nil {
+				log.Printf("Warning: failed to publish live update: %v", err)
+			}
+		}
+	case livePath != "":
+		writeLive = func(tracks []playlist.Track) {
+			if err := playlist.WritePlaylist(livePath, withSkipped(tracks)); err != nil {
+				log.Printf("Warning: failed to write playlist: %v", err)
+			}
+		}
+	}
+
+	chunked := opts.ChunkSize > 0 && len(data.Tracks) > opts.ChunkSize
+	if opts.ChunkSize > 0 && !chunked {
+		// Small enough for a single run after all
+		data.GACtx = buildEdgeFitnessCache(data.Tracks)
+		markPreviousAdjacencies(data.GACtx, data.Tracks, previousOrder)
+	}
+
+	if len(opts.SeedPlaylists) > 0 {
+		orders, err := loadSeedOrders(opts.SeedPlaylists)
+		if err != nil {
+			return sortResult{}, err
+		}
+
+		if chunked {
+			out.Printf("Seed playlists are ignored in chunked mode\n")
+		} else if added := addSeedOrders(data.GACtx, data.Tracks, orders); added < len(orders) {
+			out.Printf("Seeded %d of %d orderings (the rest share no tracks with the playlist)\n", added, len(orders))
+		} else {
+			out.Printf("Seeded %d orderings\n", added)
+		}
+	}
+
+	if opts.Landscape != "" {
+		if chunked {
+			out.Printf("Landscape snapshots are not taken in chunked mode\n")
+		} else {
+			recorder, closeLandscape, err := openLandscape(opts.Landscape, opts.LandscapeEvery)
+			if err != nil {
+				return sortResult{}, err
+			}
+
+			defer func() {
+				if err := closeLandscape(); err != nil {
+					log.Printf("Warning: %v", err)
+				}
+			}()
+
+			data.GACtx.landscape = recorder
+		}
+	}
+
+	if opts.Shuffle > 0 {
+		data.Config.ShuffleTemperature = opts.Shuffle
+		data.SharedConfig.Update(data.Config)
+		out.Printf("Smart shuffle enabled (temperature %.2f)\n", opts.Shuffle)
+	}
+
+	if opts.Novelty > 0 {
+		data.Config.NoveltyWeight = opts.Novelty
+		data.SharedConfig.Update(data.Config)
+		out.Printf("Novelty penalty enabled (weight %.2f)\n", opts.Novelty)
+	}
+
+	runLimit := maxDuration
+	if opts.Duration > 0 {
+		runLimit = min(opts.Duration, maxDuration)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, runLimit)
+	defer cancel()
+
+	// Chunked mode has no playlist-wide edge cache, so it scores transitions on demand
+	var (
+		scorer *pathScorer
+		score  func([]playlist.Track) float64
+	)
+
+	if chunked {
+		scorer = newPathScorer(data.Tracks, data.Config, previousOrder)
+		score = func(tracks []playlist.Track) float64 { return scorer.breakdown(tracks).Total }
+	} else {
+		// Weights must be normalized before scoring outside the GA
+		updateNormalizedWeights(data.GACtx, data.Config)
+
+		score = func(tracks []playlist.Track) float64 { return calculateFitness(tracks, data.Config, data.GACtx) }
+	}
+
+	initialFitness := score(data.Tracks)
+
+	out.Printf("\nOptimizing playlist... (press Ctrl+C to stop early, or wait up to %s)\n", runLimit)
+	out.Printf("Initial fitness: %.10f\n", initialFitness)
+
+	if !chunked {
+		theoreticalMin := calculateTheoreticalMinimum(data.Tracks, data.Config, data.GACtx)
+		out.Printf("Theoretical minimum: %.10f (not achievable, conflicting constraints)\n", theoreticalMin.Total)
+
+		if disabled := data.GACtx.normalizers.disabledComponents(data.Config); len(disabled) > 0 {
+			out.Printf("Disabled for lack of data: %s\n", strings.Join(disabled, ", "))
+		}
+	}
+
+	out.Println()
+
+	mainCfg, _ := config.LoadConfig(config.GetConfigPath())
+	notify := newNotifier(opts, mainCfg, data.Config)
+
+	onImprove := writeLive
+	if notify != nil {
+		onImprove = func(tracks []playlist.Track) {
+			if writeLive != nil {
+				writeLive(tracks)
+			}
+
+			notify.improved(score(tracks))
+		}
+	}
+
+	var sortedTracks []playlist.Track
+	if chunked {
+		if sortedTracks, err = chunkedSort(ctx, data.Tracks, data.SharedConfig, opts.ChunkSize, scorer, previousOrder, out); err != nil {
+			return sortResult{}, err
+		}
+
+		out.Printf("Final fitness: %.10f\n", score(sortedTracks))
+	} else if opts.SimpleUI {
+		dash := &simpleDashboard{Playlist: opts.PlaylistPath, Start: time.Now(), InitialFitness: initialFitness}
+
+		if sortedTracks, err = simpleUIGeneticSort(ctx, data.Tracks, data.SharedConfig, data.GACtx, onImprove, out, dash); err != nil {
+			return sortResult{}, err
+		}
+	} else if sortedTracks, err = cliGeneticSort(ctx, data.Tracks, data.SharedConfig, data.GACtx, onImprove, out); err != nil {
+		return sortResult{}, err
+	}
+
+	out.Println("\nSorted playlist:")
+
+	w := tabwriter.NewWriter(out.w, 0, 0, 2, ' ', 0)
+	// tabwriter aligns by rune count, not display width, so the free-text columns are
+	// padded with termtext and kept in the last (unaligned) cell
+	if _, err := fmt.Fprintf(w, "#\tKey\tBPM\tEng\tTime\tAt\t%s  %s  %s  %s\n",
+		termtext.Fit("Artist", 20), termtext.Fit("Title", 30), termtext.Fit("Album", 20), "Genre"); err != nil {
 		log.Printf("Warning: failed to write header: %v", err)
 	}
 
-	if _, err := fmt.Fprintln(w, "---\t---\t---\t---\t------\t-----\t-----\t-----"); err != nil {
+	if _, err := fmt.Fprintf(w, "---\t---\t---\t---\t----\t--\t%s  %s  %s  %s\n",
+		termtext.Fit("------", 20), termtext.Fit("-----", 30), termtext.Fit("-----", 20), "-----"); err != nil {
 		log.Printf("Warning: failed to write separator: %v", err)
 	}
 
+	// At is the running total: when the track starts, counting only known lengths
+	var startsAt time.Duration
+
 	for i, track := range sortedTracks {
-		if _, err := fmt.Fprintf(w, "%d\t%s\t%.0f\t%d\t%s\t%s\t%s\t%s\n",
+		if _, err := fmt.Fprintf(w, "%d\t%s\t%.0f\t%s\t%s\t%s\t%s  %s  %s  %s\n",
 			i+1,
 			track.Key,
 			track.BPM,
-			track.Energy,
-			truncate(track.Artist, 20),
-			truncate(track.Title, 30),
-			truncate(track.Album, 20),
-			truncate(track.Genre, 15),
+			track.EnergyLabel(),
+			track.DurationLabel(),
+			playlist.FormatDuration(startsAt),
+			termtext.Fit(track.Artist, 20),
+			termtext.Fit(track.Title, 30),
+			termtext.Fit(track.Album, 20),
+			termtext.Truncate(track.Genre, 15),
 		); err != nil {
 			log.Printf("Warning: failed to write track %d: %v", i+1, err)
 		}
+
+		startsAt += track.Duration
 	}
 
 	if err := w.Flush(); err != nil {
 		log.Printf("Warning: failed to flush output: %v", err)
 	}
 
+	out.Printf("Total time: %s\n", playlist.TotalDurationLabel(sortedTracks))
+
+	printDisplacement(out, data.Tracks, sortedTracks)
+
+	result := sortResult{
+		Tracks:         len(sortedTracks),
+		InitialFitness: initialFitness,
+		FinalFitness:   score(sortedTracks),
+		Elapsed:        time.Since(startTime),
+		OutputPath:     outputPath,
+		Skipped:        len(data.Skipped),
+		BPMCorrected:   playlist.CountBPMCorrected(data.Tracks),
+		Optimal:        !chunked && len(data.Tracks) <= exactSolverMaxTracks,
+	}
+
+	// The export describes the result, so it is written in dry runs too
+	if opts.ExportTransitions != "" {
+		out.Printf("\nExporting transitions to: %s\n", opts.ExportTransitions)
+
+		if err := writeTransitionExport(opts.ExportTransitions, sortedTracks, data.Config, previousOrder); err != nil {
+			return result, err
+		}
+	}
+
+	if opts.Report != "" {
+		path := reportPath(reportTarget)
+		out.Printf("\nWriting report to: %s\n", path)
+
+		var breakdown playlist.Breakdown
+		if chunked {
+			breakdown = scorer.breakdown(sortedTracks)
+		} else {
+			breakdown = calculateFitnessWithBreakdown(sortedTracks, data.Config, data.GACtx)
+		}
+
+		if err := writeMarkdownReport(path, filepath.Base(reportTarget), result, sortedTracks, breakdown, data.Config, previousOrder); err != nil {
+			return result, err
+		}
+	}
+
 	if opts.DryRun {
-		fmt.Println("\n--dry-run mode: playlist not modified")
+		out.Println("\n--dry-run mode: playlist not modified")
 	} else {
-		outputPath := opts.PlaylistPath
-		if opts.OutputPath != "" {
-			outputPath = opts.OutputPath
+		out.Printf("\nWriting sorted playlist to: %s (%s)\n", outputPath, format)
+
+		if err := playlist.WritePlaylistFormat(outputPath, withSkipped(sortedTracks), format); err != nil {
+			return result, fmt.Errorf("failed to write playlist: %w", err)
+		}
+
+		if opts.WriteTags {
+			out.Printf("Writing positions to file tags (%d tracks)...\n", len(sortedTracks))
+
+			written, err := writeTags(sortedTracks, opts.PlaylistPath, data.Config.TagWriteCommand, opts.TagNotes)
+			if err != nil {
+				return result, fmt.Errorf("failed to write tags (%d/%d written): %w", written, len(sortedTracks), err)
+			}
 		}
 
-		fmt.Printf("\nWriting sorted playlist to: %s\n", outputPath)
+		if opts.BeetsAttr != "" {
+			out.Printf("Storing positions in beets attribute %q...\n", opts.BeetsAttr)
 
-		if err := playlist.WritePlaylist(outputPath, sortedTracks); err != nil {
-			return fmt.Errorf("failed to write playlist: %w", err)
+			updated, err := exportToBeets(sortedTracks, opts.PlaylistPath, opts.BeetsAttr)
+			if err != nil {
+				return result, fmt.Errorf("failed to export to beets (%d/%d updated): %w", updated, len(sortedTracks), err)
+			}
 		}
 
-		fmt.Println("Done!")
+		out.Println("Done!")
 	}
 
-	return nil
+	// Chunked runs only report their final fitness, so the threshold is checked here too
+	notify.improved(result.FinalFitness)
+	notify.finished(result)
+
+	return result, nil
 }
 
-// cliGeneticSort wraps geneticSort with CLI-specific progress display
-func cliGeneticSort(ctx context.Context, tracks []playlist.Track, sharedCfg *config.SharedConfig, gaCtx *GAContext, playlistPath string) []playlist.Track {
+// cliGeneticSort wraps geneticSort with CLI-specific progress display.
+// Each improvement is passed to onImprove, which saves it for view mode and fires notifications (skipped if nil).
+func cliGeneticSort(ctx context.Context, tracks []playlist.Track, sharedCfg *config.SharedConfig, gaCtx *GAContext, onImprove func([]playlist.Track), out cliPrinter) ([]playlist.Track, error) {
 	startTime := time.Now()
 
 	// Create update channel for tracking progress
@@ -130,13 +620,16 @@ func cliGeneticSort(ctx context.Context, tracks []playlist.Track, sharedCfg *con
 	previousBestFitness := math.MaxFloat64
 	minPrecision := 2 // Start with 2 decimals, increase monotonically as needed (max 10)
 
-	// Detect if stdout is a TTY - no spinner needed in non-interactive contexts (cron, pipes, etc.)
-	isTerminal := isTTY(os.Stdout)
+	// No spinner in non-interactive contexts or when plain output was requested (screen readers, log capture)
+	isTerminal := out.animated(isTTY(os.Stdout))
 
 	// Status line animation and ticker
 	spinnerFrames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 	spinnerIdx := 0
 
+	// Best fitness at each status tick, for the sparkline
+	var fitnessHistory []float64
+
 	var statusTicker *time.Ticker
 	if isTerminal {
 		statusTicker = time.NewTicker(spinnerUpdateInterval)
@@ -162,20 +655,31 @@ func cliGeneticSort(ctx context.Context, tracks []playlist.Track, sharedCfg *con
 			return
 		}
 
+		if previousBestFitness != math.MaxFloat64 {
+			fitnessHistory = append(fitnessHistory, previousBestFitness)
+			if len(fitnessHistory) > sparklineSamples {
+				fitnessHistory = fitnessHistory[1:]
+			}
+		}
+
 		elapsed := time.Since(startTime)
-		fmt.Printf("\r%s Gen %d %s     ", formatElapsed(elapsed), gen, spinnerFrames[spinnerIdx])
+		out.Printf("\r%s Gen %d %s %s     ", formatElapsed(elapsed), gen, spinnerFrames[spinnerIdx], brailleSparkline(fitnessHistory))
 		spinnerIdx = (spinnerIdx + 1) % len(spinnerFrames)
 	}
 
 	// Start GA in goroutine
-	var bestIndividual []playlist.Track
+	var (
+		bestIndividual []playlist.Track
+		sortErr        error // Set before the result is sent on done
+	)
 
 	done := make(chan []playlist.Track)
 
 	defer close(updateChan)
 
 	go func() {
-		result := geneticSort(ctx, tracks, sharedCfg, updateChan, 0, gaCtx)
+		result, err := geneticSort(ctx, tracks, sharedCfg, updateChan, 0, gaCtx)
+		sortErr = err
 		done <- result
 	}()
 
@@ -193,6 +697,10 @@ loop:
 			}
 			currentGen = update.Generation
 
+			if update.Optimal {
+				out.Printf("Optimal order found (exhaustive search over all %d! orderings)\n", len(tracks))
+			}
+
 			// Print progress when fitness improves
 			fitnessImproved := hasFitnessImproved(update.BestFitness, previousBestFitness, fitnessImprovementEpsilon)
 
@@ -202,17 +710,17 @@ loop:
 
 				if isTerminal {
 					// Clear status line before printing progress (TTY only)
-					fmt.Print("\r\033[K")
+					out.Print("\r\033[K")
 				}
 
 				var fitnessStr string
 				fitnessStr, minPrecision = FormatWithMonotonicPrecision(previousBestFitness, update.BestFitness, minPrecision)
-				fmt.Printf("%s Gen %7d - fitness: %s\n", elapsedStr, currentGen, fitnessStr)
+				out.Printf("%s Gen %7d - fitness: %s\n", elapsedStr, currentGen, fitnessStr)
 				previousBestFitness = update.BestFitness
 
-				// Save playlist to disk for live monitoring with --view mode
-				if err := playlist.WritePlaylist(playlistPath, update.BestPlaylist); err != nil {
-					log.Printf("Warning: failed to write playlist: %v", err)
+				// Save playlist to disk for live monitoring with view mode, notify hooks
+				if onImprove != nil {
+					onImprove(update.BestPlaylist)
 				}
 			}
 
@@ -234,11 +742,11 @@ loop:
 
 	// Clear status line at end (TTY only)
 	if isTerminal {
-		fmt.Print("\r\033[K")
+		out.Print("\r\033[K")
 	}
 
-	fmt.Printf("\nCompleted %d generations in %v\n", currentGen, time.Since(startTime).Round(time.Millisecond))
+	out.Printf("\nCompleted %d generations in %v\n", currentGen, time.Since(startTime).Round(time.Millisecond))
 
 	// Return best individual
-	return bestIndividual
+	return bestIndividual, sortErr
 }
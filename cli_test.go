@@ -0,0 +1,234 @@
+// ABOUTME: Tests for CLI mode helpers
+// ABOUTME: Validates fitness threshold checks, their exit codes, the early writability check and plain output
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"playlist-sorter/playlist"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	result := sortResult{InitialFitness: 0.5, FinalFitness: 0.4} // 20% improvement
+
+	tests := []struct {
+		name string
+		opts RunOptions
+		code int // 0 = passes
+	}{
+		{"no thresholds", RunOptions{}, 0},
+		{"fitness below limit", RunOptions{FailIfAbove: 0.45}, 0},
+		{"fitness above limit", RunOptions{FailIfAbove: 0.3}, exitFitnessAbove},
+		{"enough improvement", RunOptions{FailIfImprovementBelow: 15}, 0},
+		{"too little improvement", RunOptions{FailIfImprovementBelow: 25}, exitImprovementBelow},
+		{"fitness checked first", RunOptions{FailIfAbove: 0.3, FailIfImprovementBelow: 25}, exitFitnessAbove},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkThresholds(result, tt.opts)
+
+			var thresholdErr *thresholdError
+
+			switch {
+			case tt.code == 0 && err != nil:
+				t.Errorf("Expected no threshold failure, got %v", err)
+			case tt.code != 0 && !errors.As(err, &thresholdErr):
+				t.Errorf("Expected a threshold error with code %d, got %v", tt.code, err)
+			case tt.code != 0 && thresholdErr.code != tt.code:
+				t.Errorf("Expected exit code %d, got %d (%v)", tt.code, thresholdErr.code, err)
+			}
+		})
+	}
+}
+
+func TestImprovementThresholdIgnoresPerfectPlaylist(t *testing.T) {
+	// Nothing to improve on an already perfect order
+	if err := checkThresholds(sortResult{}, RunOptions{FailIfImprovementBelow: 5}); err != nil {
+		t.Errorf("Expected a playlist with fitness 0 to pass the improvement check, got %v", err)
+	}
+}
+
+func TestSortResultSummary(t *testing.T) {
+	result := sortResult{Tracks: 120, InitialFitness: 0.5, FinalFitness: 0.4, Elapsed: 90 * time.Second, OutputPath: "set.m3u8"}
+
+	want := "set.m3u8: 120 tracks, fitness 0.500000 -> 0.400000 (-20.0%) in 1m30s"
+	if got := result.Summary(); got != want {
+		t.Errorf("Expected summary %q, got %q", want, got)
+	}
+
+	result.Skipped = 2
+
+	want += ", 2 skipped (unreadable metadata)"
+	if got := result.Summary(); got != want {
+		t.Errorf("Expected summary %q, got %q", want, got)
+	}
+
+	result.BPMCorrected = 3
+
+	want += ", 3 BPMs corrected (half/double time)"
+	if got := result.Summary(); got != want {
+		t.Errorf("Expected summary %q, got %q", want, got)
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+
+	existing := filepath.Join(dir, "set.m3u8")
+	if err := os.WriteFile(existing, []byte("#EXTM3U\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{existing, filepath.Join(dir, "new.m3u8")} {
+		if err := checkWritable(path); err != nil {
+			t.Errorf("%s: expected writable, got %v", path, err)
+		}
+	}
+
+	// The check leaves nothing behind and doesn't touch existing files
+	entries, _ := os.ReadDir(dir)
+	if data, _ := os.ReadFile(existing); len(entries) != 1 || string(data) != "#EXTM3U\n" {
+		t.Errorf("Expected the directory untouched, got %d entries and %q", len(entries), data)
+	}
+
+	err := checkWritable(filepath.Join(dir, "unmounted", "set.m3u8"))
+	if err == nil || !strings.Contains(err.Error(), "doesn't exist") {
+		t.Errorf("Expected a missing directory error, got %v", err)
+	}
+
+	if os.Geteuid() == 0 {
+		return // Root ignores file permissions
+	}
+
+	if err := os.Chmod(existing, 0o444); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkWritable(existing); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected a permission error for a read-only file, got %v", err)
+	}
+}
+
+func TestParseLiveWrites(t *testing.T) {
+	for _, name := range []string{"", liveWritesSocket, liveWritesWorkingCopy, liveWritesInPlace, liveWritesOff} {
+		if _, err := parseLiveWrites(name); err != nil {
+			t.Errorf("Expected %q to be valid: %v", name, err)
+		}
+	}
+
+	if mode, _ := parseLiveWrites(""); mode != liveWritesSocket {
+		t.Errorf("Expected the socket by default, got %q", mode)
+	}
+
+	if _, err := parseLiveWrites("sidecar"); err == nil {
+		t.Error("Expected an unknown mode to be rejected")
+	}
+}
+
+func TestDryRunLeavesWorkingCopyAlone(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // Keep the user's config out of the run
+
+	path := writeTestPlaylist(t, 5)
+
+	// Another session's working copy must survive a dry run
+	working := playlist.WorkingCopyPath(path)
+	if err := os.WriteFile(working, []byte("other\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := sortPlaylist(context.Background(), RunOptions{
+		PlaylistPath: path,
+		DryRun:       true,
+		Duration:     time.Second,
+		Quiet:        true,
+		LiveWrites:   liveWritesWorkingCopy,
+	})
+	if err != nil {
+		t.Fatalf("sortPlaylist: %v", err)
+	}
+
+	if data, err := os.ReadFile(working); err != nil || string(data) != "other\n" {
+		t.Errorf("Expected the working copy untouched, got %q (%v)", data, err)
+	}
+}
+
+func TestLiveInPlaceWritesOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	path := writeTestPlaylist(t, exactSolverMaxTracks+4) // Enough for the GA, which writes each improvement
+	output := filepath.Join(filepath.Dir(path), "sorted.m3u8")
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sortPlaylist(context.Background(), RunOptions{
+		PlaylistPath: path,
+		OutputPath:   output,
+		Duration:     200 * time.Millisecond,
+		Quiet:        true,
+		LiveWrites:   liveWritesInPlace,
+	})
+	if err != nil {
+		t.Fatalf("sortPlaylist: %v", err)
+	}
+
+	if data, err := os.ReadFile(path); err != nil || string(data) != string(original) {
+		t.Errorf("Expected the input playlist untouched with -output, got %q (%v)", data, err)
+	}
+
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("Expected the output written: %v", err)
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	unset := "<unset>"
+
+	tests := []struct {
+		name         string
+		flag         bool
+		env          string
+		terminal     bool
+		wantPlain    bool
+		wantAnimated bool
+	}{
+		{"defaults on a terminal", false, unset, true, false, true},
+		{"flag", true, unset, true, true, false},
+		{"NO_COLOR empty", false, "", true, false, true},
+		{"NO_COLOR set", false, "1", true, true, false},
+		{"flag and NO_COLOR", true, "1", true, true, false},
+		{"non-TTY", false, unset, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.env) // Restored after the test, also when unset below
+
+			if tt.env == unset {
+				if err := os.Unsetenv("NO_COLOR"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			plain := noColor(tt.flag)
+			if plain != tt.wantPlain {
+				t.Errorf("Expected plain %v, got %v", tt.wantPlain, plain)
+			}
+
+			out := cliPrinter{w: os.Stdout, plain: plain}
+			if got := out.animated(tt.terminal); got != tt.wantAnimated {
+				t.Errorf("Expected animated %v, got %v", tt.wantAnimated, got)
+			}
+		})
+	}
+}
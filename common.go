@@ -4,10 +4,17 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
 
 	"playlist-sorter/config"
 	"playlist-sorter/playlist"
@@ -15,18 +22,77 @@ import (
 
 var debugLog *log.Logger
 
+// playlistSettingsMu serializes InitializePlaylist setting the playlist package's mix tags, genre
+// aliases and path map from each playlist's config and resolving the loaded tracks against them
+// (server jobs load concurrently). The metadata reads themselves run outside it, with their own settings.
+var playlistSettingsMu sync.Mutex
+
 // RunOptions contains command-line options for all modes
 type RunOptions struct {
 	PlaylistPath string
 	DryRun       bool
 	OutputPath   string
+	OutputFormat string        // Output playlist format (m3u8, m3u, pls, xspf, json, txt, cue; empty = m3u8)
+	Duration     time.Duration // Optimizer run time limit (0 = maxDuration)
+	Quiet        bool          // Suppress progress output
+	NoColor      bool          // Plain output: no spinner or ANSI control sequences
+	SimpleUI     bool          // Print a plain dashboard every few seconds instead of a line per improvement
 	DebugLog     bool
+	Shuffle      float64  // Smart-shuffle temperature override (0 = use config)
+	Novelty      float64  // Novelty weight override (0 = use config)
+	ImputeEnergy bool     // Estimate missing energy values (in addition to config setting)
+	WriteTags    bool     // Write final positions into file tags via tag_write_command
+	TagNotes     bool     // Include transition notes when writing tags
+	BeetsAttr    string   // Beets flexattr to store final positions in (empty = disabled)
+	ChunkSize    int      // Divide-and-conquer chunk size for huge playlists (0 = off)
+	KeyOnly      bool     // Read only key/BPM and score only harmonic and BPM components
+	Preset       string   // Built-in weight preset applied over the config and sidecar (empty = none)
+	AutoProfile  bool     // Pick a preset from the playlist's characteristics when none is set (opt-in)
+	PathMap      []string // Track path rewrites ("from=>to") in addition to the config's path_map
+	Overrides    string   // Per-track metadata corrections file (empty = the playlist's <name>.overrides.json, if any)
+
+	SeedPlaylists []string // Playlists of the same tracks whose orders join the first generation
+
+	Landscape      string // Write population snapshots (scores, diversity) as JSON lines here (empty = off)
+	LandscapeEvery int    // Generations between landscape snapshots (0 = defaultLandscapeEvery)
+
+	Skipped    playlist.SkippedPlacement // Where unreadable tracks go in the written playlist
+	LiveWrites string                    // Where improvements go during the run (see liveWritesSocket)
+
+	ExportTransitions string // Write every transition of the final order with its edge data as JSON here (empty = off)
+	Report            string // Write a report of the result in this format next to the output (md; empty = off)
+
+	// Notification hooks, overriding the config's (empty/0 = use config)
+	NotifyCommand string
+	NotifyURL     string
+	NotifyBelow   float64
+
+	// Quality gates for automation: exit with a distinct code after writing (0 = off)
+	FailIfAbove            float64 // Final fitness above which the run fails
+	FailIfImprovementBelow float64 // Minimum fitness improvement in percent
 }
 
 // PlaylistOptions contains options for loading playlists
 type PlaylistOptions struct {
-	Path    string
-	Verbose bool
+	Path          string
+	Verbose       bool
+	PreviousOrder []string               // Track paths of the last saved output, for the novelty component
+	ImputeEnergy  bool                   // Estimate missing energy values (flagged as estimated)
+	EnergyByGenre map[string]int         // Genre -> energy mapping used by imputation
+	BPMRanges     map[string][2]float64  // Genre -> expected BPM range for half/double-time correction
+	MinRating     int                    // Mark tracks rated at least this many stars as favorites (0 = none)
+	Favorites     []string               // Favorite tracks by playlist path or "Artist - Title"
+	FreshDays     int                    // Mark tracks added within this many days as fresh (0 = none)
+	SkipEdgeCache bool                   // Leave GACtx nil (chunked mode builds per-chunk caches instead)
+	KeyOnly       bool                   // Read only key, energy and BPM tags and weight only harmonic and BPM components
+	Preset        string                 // Built-in weight preset applied over the config and sidecar (empty = none)
+	AutoProfile   bool                   // Without Preset or sidecar, apply the preset suiting the playlist's characteristics
+	PathMap       []string               // Track path rewrites ("from=>to") in addition to the config's path_map
+	Overrides     string                 // Per-track metadata corrections file (empty = <playlist>.overrides.json, if any)
+	TrackTimeout  time.Duration          // Skip tracks whose metadata takes longer to read (0 = no limit)
+	LoadSettings  *playlist.LoadSettings // Mix tags and path map to read with (nil = the process-wide ones)
+
+	Progress func(playlist.LoadProgress) // Called after each track's metadata is read (replaces Verbose progress output)
 }
 
 // OptimizationContext contains the loaded playlist and associated data
@@ -35,54 +101,324 @@ type OptimizationContext struct {
 	Config       config.GAConfig
 	SharedConfig *config.SharedConfig
 	GACtx        *GAContext
+	Skipped      []playlist.SkippedTrack // Entries whose metadata could not be read (not optimized)
 }
 
-// InitializePlaylist loads playlist, config, and builds edge cache for optimization
+// InitializePlaylist loads playlist, config, and builds edge cache for optimization (unless SkipEdgeCache)
 func InitializePlaylist(opts PlaylistOptions) (*OptimizationContext, error) {
-	tracks, err := LoadPlaylistForMode(opts, false)
+	cfg, _ := config.LoadConfig(config.GetConfigPath())
+
+	// Per-playlist overrides from a sidecar file next to the playlist
+	sidecarCfg, hasSidecar, err := config.ApplySidecar(cfg, opts.Path)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	} else if hasSidecar {
+		cfg = sidecarCfg
+
+		if opts.Verbose {
+			fmt.Printf("Using sidecar config: %s\n", config.SidecarPath(opts.Path))
+		}
+	}
+
+	// An explicitly chosen preset wins over both
+	if opts.Preset != "" {
+		presetCfg, err := config.ApplyPreset(cfg, opts.Preset)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg = presetCfg
+
+		if opts.Verbose {
+			fmt.Printf("Using preset: %s\n", opts.Preset)
+		}
+	}
+
+	mappings, err := pathMappings(cfg, opts.PathMap)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg, _ := config.LoadConfig(config.GetConfigPath())
+	opts.LoadSettings = &playlist.LoadSettings{IntroTag: cfg.IntroTag, OutroTag: cfg.OutroTag, PathMap: mappings}
+
+	if opts.KeyOnly {
+		cfg = cfg.KeyOnly()
+	}
+
+	opts.ImputeEnergy = opts.ImputeEnergy || cfg.ImputeEnergy
+	opts.EnergyByGenre = cfg.EnergyByGenre
+	opts.BPMRanges = cfg.BPMRanges
+	opts.MinRating = cfg.FavoriteMinRating
+	opts.Favorites = cfg.Favorites
+	opts.FreshDays = cfg.FreshDays
+	opts.TrackTimeout = cfg.TrackTimeout()
+
+	tracks, skipped, err := readPlaylistTracks(context.Background(), opts, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// The genre aliases (and the tag names and path map later reloads use) are process-wide:
+	// resolving one playlist's tracks against them must not interleave with another's
+	playlistSettingsMu.Lock()
+	defer playlistSettingsMu.Unlock()
+
+	playlist.SetMixTags(cfg.IntroTag, cfg.OutroTag)
+	playlist.SetGenreAliases(cfg.GenreAliases)
+	playlist.SetPathMap(mappings)
+
+	if err := prepareTracks(tracks, opts); err != nil {
+		return nil, err
+	}
+
+	// Asked to pick a preset suiting the playlist: only without an explicit choice of weights, and
+	// always saying what was chosen, since it changes the weights the run is scored by
+	if opts.AutoProfile {
+		switch name, reason := profilePlaylist(tracks).preset(); {
+		case opts.Preset != "":
+			fmt.Fprintf(os.Stderr, "Auto profile: skipped, -preset %s chosen\n", opts.Preset)
+		case hasSidecar:
+			fmt.Fprintf(os.Stderr, "Auto profile: skipped, weights set by %s\n", config.SidecarPath(opts.Path))
+		case opts.KeyOnly:
+			fmt.Fprintln(os.Stderr, "Auto profile: skipped with -key-only")
+		case name == "":
+			fmt.Fprintln(os.Stderr, "Auto profile: no preset suits this playlist, keeping the configured weights")
+		default:
+			if cfg, err = config.ApplyPreset(cfg, name); err != nil {
+				return nil, err
+			}
+
+			fmt.Fprintf(os.Stderr, "Auto profile: %s (%s). Use -preset to pick another, or drop -auto-profile to keep your weights.\n", name, reason)
+		}
+	}
 
 	sharedConfig := &config.SharedConfig{}
 	sharedConfig.Update(cfg)
 
-	gaCtx := buildEdgeFitnessCache(tracks)
+	var gaCtx *GAContext
+	if !opts.SkipEdgeCache {
+		gaCtx = buildEdgeFitnessCache(tracks)
+		markPreviousAdjacencies(gaCtx, tracks, opts.PreviousOrder)
+
+		// Resolve genre overrides while this playlist's genre aliases are the ones set
+		updateNormalizedWeights(gaCtx, cfg)
+	}
 
 	return &OptimizationContext{
 		Tracks:       tracks,
 		Config:       cfg,
 		SharedConfig: sharedConfig,
 		GACtx:        gaCtx,
+		Skipped:      skipped,
 	}, nil
 }
 
 // LoadPlaylistForMode loads playlist with validation and index assignment
 func LoadPlaylistForMode(opts PlaylistOptions, allowSingle bool) ([]playlist.Track, error) {
+	tracks, _, err := LoadPlaylistForModeContext(context.Background(), opts, allowSingle)
+
+	return tracks, err
+}
+
+// LoadPlaylistForModeContext is LoadPlaylistForMode that stops when ctx is cancelled, reports
+// progress to opts.Progress and also returns the entries whose metadata could not be read
+func LoadPlaylistForModeContext(ctx context.Context, opts PlaylistOptions, allowSingle bool) ([]playlist.Track, []playlist.SkippedTrack, error) {
+	tracks, skipped, err := readPlaylistTracks(ctx, opts, allowSingle)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := prepareTracks(tracks, opts); err != nil {
+		return nil, nil, err
+	}
+
+	return tracks, skipped, nil
+}
+
+// readPlaylistTracks reads the playlist's tracks and their metadata, failing if too few could be read
+func readPlaylistTracks(ctx context.Context, opts PlaylistOptions, allowSingle bool) ([]playlist.Track, []playlist.SkippedTrack, error) {
 	if opts.Verbose {
 		fmt.Printf("Reading playlist: %s\n", opts.Path)
 	}
 
-	tracks, err := playlist.LoadPlaylistWithMetadata(opts.Path, opts.Verbose)
+	tracks, skipped, err := playlist.LoadPlaylistContext(ctx, opts.Path, playlist.LoadOptions{
+		KeyOnly:      opts.KeyOnly,
+		Verbose:      opts.Verbose && opts.Progress == nil,
+		Progress:     opts.Progress,
+		TrackTimeout: opts.TrackTimeout,
+		Settings:     opts.LoadSettings,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to load playlist: %w", err)
+		return nil, nil, fmt.Errorf("failed to load playlist: %w", err)
+	}
+
+	if len(tracks) == 0 && len(skipped) > 0 {
+		return nil, nil, fmt.Errorf("metadata could not be read for any of the %d tracks (first: %s: %w)", len(skipped), skipped[0].Path, skipped[0].Err)
 	}
 
 	if len(tracks) == 0 {
-		return nil, errors.New("playlist is empty")
+		return nil, nil, errors.New("playlist is empty")
 	}
 
 	if len(tracks) == 1 && !allowSingle {
-		return nil, errors.New("playlist has only one track, nothing to optimize")
+		return nil, nil, errors.New("playlist has only one track, nothing to optimize")
 	}
 
-	for i := range tracks {
-		tracks[i].Index = i
+	return tracks, skipped, nil
+}
+
+// prepareTracks indexes freshly read tracks and applies the overrides file, favorites, fresh marks,
+// BPM correction and (if asked to) energy imputation
+func prepareTracks(tracks []playlist.Track, opts PlaylistOptions) error {
+	playlist.ReindexTracks(tracks)
+
+	overrides, err := playlist.LoadPlaylistOverrides(opts.Overrides, opts.Path)
+	if err != nil {
+		return err
+	}
+
+	if applied := overrides.Apply(tracks, filepath.Dir(opts.Path)); opts.Verbose && applied > 0 {
+		fmt.Printf("Applied metadata overrides to %d tracks\n", applied)
+	}
+
+	if favorites := playlist.MarkFavorites(tracks, opts.MinRating, opts.Favorites); opts.Verbose && favorites > 0 {
+		fmt.Printf("Marked %d favorite tracks (shown as *)\n", favorites)
+	}
+
+	if fresh := playlist.MarkFresh(tracks, time.Now(), opts.FreshDays); opts.Verbose && fresh > 0 {
+		fmt.Printf("Marked %d tracks added in the last %d days as fresh\n", fresh, opts.FreshDays)
+	}
+
+	// Before imputation, which estimates energy from tracks of similar BPM
+	if corrections := playlist.CorrectBPM(tracks, opts.BPMRanges); opts.Verbose && len(corrections) > 0 {
+		fmt.Printf("Corrected half/double-time BPM of %d tracks:\n", len(corrections))
+
+		for _, c := range corrections {
+			fmt.Printf("  %s: %.1f -> %.1f\n", c.Path, c.From, c.To)
+		}
+	}
+
+	if opts.ImputeEnergy {
+		imputed := playlist.ImputeEnergy(tracks, opts.EnergyByGenre)
+		if opts.Verbose && imputed > 0 {
+			fmt.Printf("Estimated energy for %d tracks without an energy tag (shown as ~N)\n", imputed)
+		}
 	}
 
-	return tracks, nil
+	return nil
+}
+
+// loadPreviousOrder returns the track paths of a previously written playlist, or nil if there is none
+func loadPreviousOrder(path string) []string {
+	// stdin/stdout has no previous version (and stdin can only be read once)
+	if path == playlist.StdioPath {
+		return nil
+	}
+
+	tracks, err := playlist.ReadPlaylist(path)
+	if err != nil {
+		return nil
+	}
+
+	paths := make([]string, len(tracks))
+	for i, t := range tracks {
+		paths[i] = t.Path
+	}
+
+	return paths
+}
+
+// loadSeedOrders returns the track paths of each seed playlist
+func loadSeedOrders(paths []string) ([][]string, error) {
+	orders := make([][]string, 0, len(paths))
+
+	for _, path := range paths {
+		tracks, err := playlist.ReadPlaylist(path)
+		if err != nil {
+			return nil, fmt.Errorf("seed playlist %s: %w", path, err)
+		}
+
+		order := make([]string, len(tracks))
+		for i, t := range tracks {
+			order[i] = t.Path
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// scorePlaylist computes the fitness breakdown of tracks in their current order
+func scorePlaylist(tracks []playlist.Track, cfg config.GAConfig) playlist.Breakdown {
+	if len(tracks) < 2 {
+		return playlist.Breakdown{}
+	}
+
+	tracks = slices.Clone(tracks)
+	playlist.ReindexTracks(tracks)
+
+	gaCtx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(gaCtx, cfg)
+
+	return calculateFitnessWithBreakdown(tracks, cfg, gaCtx)
+}
+
+// newCachedTrackLoader returns a playlist loader that reads each track's tags only once,
+// so repeatedly reloading a playlist that keeps changing (view mode) stays cheap.
+// Tracks whose metadata can't be read are skipped, as in LoadPlaylistWithMetadata.
+func newCachedTrackLoader(cfg config.GAConfig) func(string) ([]playlist.Track, error) {
+	var mu sync.Mutex
+
+	cache := make(map[string]playlist.Track)
+
+	return func(path string) ([]playlist.Track, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		entries, err := playlist.ReadPlaylist(path)
+		if err != nil {
+			return nil, err
+		}
+
+		tracks := make([]playlist.Track, 0, len(entries))
+		reader := &playlist.TrackReader{Timeout: cfg.TrackTimeout()}
+
+		for _, entry := range entries {
+			track, ok := cache[entry.Path]
+			if !ok {
+				metadata, err := reader.Read(context.Background(), entry.Path, filepath.Dir(path))
+				if err != nil {
+					continue
+				}
+
+				track = *metadata
+				cache[entry.Path] = track
+			}
+
+			tracks = append(tracks, track)
+		}
+
+		playlist.AssignIDs(tracks, filepath.Dir(path))
+		playlist.ReindexTracks(tracks)
+
+		// Read on every load, so edits to the overrides file show up like edits to the playlist
+		overrides, err := playlist.LoadPlaylistOverrides("", path)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides.Apply(tracks, filepath.Dir(path))
+		playlist.MarkFavorites(tracks, cfg.FavoriteMinRating, cfg.Favorites)
+		playlist.MarkFresh(tracks, time.Now(), cfg.FreshDays)
+		playlist.CorrectBPM(tracks, cfg.BPMRanges)
+
+		if cfg.ImputeEnergy {
+			playlist.ImputeEnergy(tracks, cfg.EnergyByGenre)
+		}
+
+		return tracks, nil
+	}
 }
 
 // SetupDebugLog initializes debug logging
@@ -120,20 +456,70 @@ func debugf(format string, args ...interface{}) {
 	}
 }
 
-// truncate shortens string to maxLen, adding "..." if needed
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-
-	if maxLen <= 3 {
-		return s[:maxLen]
-	}
-
-	return s[:maxLen-3] + "..."
+// noColor reports whether colors and ANSI effects are disabled, by flag or the NO_COLOR
+// convention (https://no-color.org: set and non-empty)
+func noColor(flag bool) bool {
+	return flag || os.Getenv("NO_COLOR") != ""
 }
 
 // hasFitnessImproved returns true if newFitness significantly better (uses epsilon for float comparison)
 func hasFitnessImproved(newFitness, oldFitness, epsilon float64) bool {
 	return newFitness < oldFitness-epsilon
 }
+
+// setPathMap applies the config's path_map followed by extra "from=>to" mappings (from -path-map)
+// to every playlist read from now on. The first matching mapping wins, so extra ones come first.
+func setPathMap(cfg config.GAConfig, extra []string) error {
+	mappings, err := pathMappings(cfg, extra)
+	if err != nil {
+		return err
+	}
+
+	playlist.SetPathMap(mappings)
+
+	return nil
+}
+
+// pathMappings parses extra "from=>to" mappings followed by the config's path_map (see setPathMap)
+func pathMappings(cfg config.GAConfig, extra []string) ([]playlist.PathMapping, error) {
+	return playlist.ParsePathMap(append(slices.Clone(extra), cfg.PathMap...))
+}
+
+// checkWritable fails early, before a long optimization, if the playlist can't be written to path:
+// missing permissions, a read-only filesystem (e.g. a NAS mounted read-only) or a missing
+// directory (e.g. an unmounted drive). Nothing is modified.
+func checkWritable(path string) error {
+	var (
+		f   *os.File
+		err error
+	)
+
+	if _, statErr := os.Stat(path); statErr == nil {
+		// Opening for writing without truncating leaves the file untouched
+		f, err = os.OpenFile(path, os.O_WRONLY, 0)
+	} else {
+		f, err = os.CreateTemp(filepath.Dir(path), ".playlist-sorter-write-check-*")
+	}
+
+	if err == nil {
+		name := f.Name()
+		_ = f.Close()
+
+		if name != path {
+			_ = os.Remove(name)
+		}
+
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, syscall.EROFS):
+		return fmt.Errorf("can't write %s: the filesystem is read-only (is the drive mounted read-write?)", path)
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("can't write %s: permission denied (check permissions or use -output to write elsewhere)", path)
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("can't write %s: directory %s doesn't exist (is the drive mounted?)", path, filepath.Dir(path))
+	default:
+		return fmt.Errorf("can't write %s: %w", path, err)
+	}
+}
@@ -6,14 +6,21 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 )
 
 // GAConfig holds all tunable genetic algorithm parameters
 type GAConfig struct {
+	// Schema version of the file the config was read from, upgraded on load (see SchemaVersion)
+	SchemaVersion int `json:"schema_version"`
+
 	// Fitness penalty weights
 	HarmonicWeight    float64 `json:"harmonic_weight"`
 	SameArtistPenalty float64 `json:"same_artist_penalty"`
@@ -21,12 +28,186 @@ type GAConfig struct {
 	EnergyDeltaWeight float64 `json:"energy_delta_weight"`
 	BPMDeltaWeight    float64 `json:"bpm_delta_weight"`
 	GenreWeight       float64 `json:"genre_weight"` // -1.0 (spread) to +1.0 (cluster)
+	GenreBlocks       bool    `json:"genre_blocks"` // With positive GenreWeight: contiguous genre blocks with smooth boundaries
+
+	// Genre tag aliases: tag -> the genre it means, e.g. {"Neurofunk": "drum and bass"}. Case and
+	// punctuation are ignored, and common variants ("DnB", "Drum & Bass") are recognized without one.
+	GenreAliases map[string]string `json:"genre_aliases,omitempty"`
+
+	// Per-genre overrides of the transition weights above, keyed by genre (parent genres match too).
+	// They apply to transitions between two tracks of the genre, e.g. a stricter BPM weight within DnB.
+	GenreOverrides map[string]GenreOverride `json:"genre_overrides,omitempty"`
 
 	// Position bias
 	LowEnergyBiasPortion float64 `json:"low_energy_bias_portion"`
 	LowEnergyBiasWeight  float64 `json:"low_energy_bias_weight"`
+
+	// Smart shuffle: random jitter added to every transition so each run yields a different good order
+	ShuffleTemperature float64 `json:"shuffle_temperature"` // 0 = off, 1 = jitter as strong as a full weight
+
+	// Energy imputation for tracks without an energy tag
+	ImputeEnergy  bool           `json:"impute_energy"`
+	EnergyByGenre map[string]int `json:"energy_by_genre,omitempty"` // Genre -> energy estimate (parent genres match too)
+
+	// BPM sanity: genre -> expected [min, max] BPM (parent genres match too). BPMs tagged at half or
+	// double time, like 87 for a 174 BPM DnB track, are doubled or halved into the range on load.
+	BPMRanges map[string][2]float64 `json:"bpm_ranges,omitempty"`
+
+	// Novelty: penalize transitions that were already adjacent in the last saved output
+	NoveltyWeight float64 `json:"novelty_weight"`
+
+	// Energy wave: penalize deviation from a baseline rising from the lowest to the highest energy,
+	// oscillating by amplitude energy levels every period tracks (how real sets breathe)
+	EnergyWaveWeight    float64 `json:"energy_wave_weight"`
+	EnergyWaveAmplitude float64 `json:"energy_wave_amplitude"`
+	EnergyWavePeriod    int     `json:"energy_wave_period"` // Tracks per oscillation (0 = straight ramp)
+
+	// BPM bands: penalize moving between bands of BPMBandWidth (bands start at multiples of the width,
+	// e.g. 170-172 and 174-176), so the set settles into a few tempo bands with few band changes
+	BPMBandWeight float64 `json:"bpm_band_weight"`
+	BPMBandWidth  float64 `json:"bpm_band_width"`
+
+	// Mix length: penalize transitions whose outro and next intro lengths differ (blend compatibility)
+	MixLengthWeight float64 `json:"mix_length_weight"`
+	IntroTag        string  `json:"intro_tag,omitempty"` // Tag holding the intro length (default INTRO)
+	OutroTag        string  `json:"outro_tag,omitempty"` // Tag holding the outro length (default OUTRO)
+
+	// Favorites: penalize favorite tracks (rated at least FavoriteMinRating stars, or listed in
+	// Favorites by playlist path or "Artist - Title") by how far they sit outside the prime region,
+	// PrimeStart to PrimeEnd as fractions of the set (default the final third)
+	FavoriteWeight    float64  `json:"favorite_weight"`
+	FavoriteMinRating int      `json:"favorite_min_rating"` // Stars 1-5 (0 = ratings don't mark favorites)
+	Favorites         []string `json:"favorites,omitempty"`
+	PrimeStart        float64  `json:"prime_start"`
+	PrimeEnd          float64  `json:"prime_end"`
+
+	// Freshness: spread tracks added within FreshDays days (date-added tag or file modification time)
+	// through the set by penalizing back-to-back ones, or with FreshFront play them early instead
+	FreshnessWeight float64 `json:"freshness_weight"`
+	FreshDays       int     `json:"fresh_days"`
+	FreshFront      bool    `json:"fresh_front"`
+
+	// Bookends: penalize an unsuitable first track (high energy, intro shorter than OpenerIntro
+	// seconds) and last track (low energy, low rating and not a favorite)
+	BookendWeight float64 `json:"bookend_weight"`
+	OpenerIntro   float64 `json:"opener_intro"`
+
+	// Niching: elites must differ in at least NicheRadius of their transitions (0 = off), and
+	// individuals closer than that to an elite are penalized in parent selection, so the population
+	// keeps genuinely different good orderings instead of near-duplicates of the best
+	NicheRadius float64 `json:"niche_radius"`
+
+	// 2-opt local search on the elites, both opt-in: each invocation stops after TwoOptBudgetMs
+	// milliseconds (0 = only the iteration cap). With TwoOptAdaptive, how often it runs follows its
+	// measured cost and benefit instead of the fixed schedule (every 5000 generations). Both depend on
+	// the clock, so runs using them vary with machine load.
+	TwoOptBudgetMs int  `json:"two_opt_budget_ms"`
+	TwoOptAdaptive bool `json:"two_opt_adaptive"`
+
+	// Metadata reads: a track whose tags take longer than TrackTimeoutMs milliseconds to read (e.g. on
+	// an unresponsive network mount) is skipped and reported instead of stalling the load (0 = no limit)
+	TrackTimeoutMs int `json:"track_timeout_ms"`
+
+	// Track path rewrites for playlists exported on another machine, as "from=>to" (e.g. `D:\Music=>/mnt/music`)
+	PathMap []string `json:"path_map,omitempty"`
+
+	// TUI settings
+	PreviewCommand string `json:"preview_command,omitempty"` // External player for auditioning tracks (e.g. "mpv --start=60")
+
+	// Status bar contents (empty = the full default line). Placeholders: {gen}, {gen/s}, {fitness},
+	// {delta} (last improvement), {since} (time since it) and {tracks}, e.g. "{gen} {fitness} {since}".
+	// Flags like [EDIT] and [STALE] are always shown in front.
+	StatusTemplate string `json:"status_template,omitempty"`
+
+	// Focus mode (f): the GA only reorders tracks within FocusRadius positions of the cursor
+	FocusRadius int `json:"focus_radius"`
+
+	// Tag write-back: command run per track after sorting with -write-tags
+	// Placeholders: {path}, {position}, {total}, {note}
+	TagWriteCommand string `json:"tag_write_command,omitempty"`
+
+	// Notifications for long runs, sent when a CLI run finishes and when its best fitness first
+	// drops to NotifyBelow (0 = only when finished). Command placeholders: {event}, {playlist},
+	// {fitness}, {message}; the webhook receives the same fields as a JSON POST.
+	NotifyCommand string  `json:"notify_command,omitempty"` // e.g. "notify-send playlist-sorter {message}"
+	NotifyURL     string  `json:"notify_url,omitempty"`
+	NotifyBelow   float64 `json:"notify_below,omitempty"`
+}
+
+// GenreOverride holds the transition weights a genre overrides; nil fields keep the global value
+type GenreOverride struct {
+	HarmonicWeight    *float64 `json:"harmonic_weight,omitempty"`
+	SameArtistPenalty *float64 `json:"same_artist_penalty,omitempty"`
+	SameAlbumPenalty  *float64 `json:"same_album_penalty,omitempty"`
+	EnergyDeltaWeight *float64 `json:"energy_delta_weight,omitempty"`
+	BPMDeltaWeight    *float64 `json:"bpm_delta_weight,omitempty"`
+	GenreWeight       *float64 `json:"genre_weight,omitempty"`
+	MixLengthWeight   *float64 `json:"mix_length_weight,omitempty"`
+	BPMBandWeight     *float64 `json:"bpm_band_weight,omitempty"`
+}
+
+// Apply returns base with the override's weights set
+func (o GenreOverride) Apply(base GAConfig) GAConfig {
+	for _, f := range []struct {
+		dst *float64
+		src *float64
+	}{
+		{&base.HarmonicWeight, o.HarmonicWeight},
+		{&base.SameArtistPenalty, o.SameArtistPenalty},
+		{&base.SameAlbumPenalty, o.SameAlbumPenalty},
+		{&base.EnergyDeltaWeight, o.EnergyDeltaWeight},
+		{&base.BPMDeltaWeight, o.BPMDeltaWeight},
+		{&base.GenreWeight, o.GenreWeight},
+		{&base.MixLengthWeight, o.MixLengthWeight},
+		{&base.BPMBandWeight, o.BPMBandWeight},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+
+	return base
+}
+
+// KeyOnly returns the config with only the harmonic and BPM components weighted, for sorting tracks
+// loaded with nothing but their key and BPM. Run-level settings (shuffle, novelty) are kept.
+func (c GAConfig) KeyOnly() GAConfig {
+	c.SameArtistPenalty = 0
+	c.SameAlbumPenalty = 0
+	c.EnergyDeltaWeight = 0
+	c.GenreWeight = 0
+	c.GenreOverrides = nil
+	c.LowEnergyBiasWeight = 0
+	c.EnergyWaveWeight = 0
+	c.MixLengthWeight = 0
+	c.FavoriteWeight = 0
+	c.FreshnessWeight = 0
+	c.BookendWeight = 0
+
+	return c
 }
 
+// DefaultBPMBandWidth is the BPM band width used when none is configured
+const DefaultBPMBandWidth = 2.0
+
+// BandWidth returns the configured BPM band width, or DefaultBPMBandWidth for configs saved before
+// bands existed (which have no width)
+func (c GAConfig) BandWidth() float64 {
+	if c.BPMBandWidth <= 0 {
+		return DefaultBPMBandWidth
+	}
+
+	return c.BPMBandWidth
+}
+
+// TrackTimeout returns how long reading one track's metadata may take (0 = no limit)
+func (c GAConfig) TrackTimeout() time.Duration {
+	return time.Duration(max(c.TrackTimeoutMs, 0)) * time.Millisecond
+}
+
+// DefaultPreviewCommand is used when no preview command is configured
+const DefaultPreviewCommand = "mpv --no-video --start=60"
+
 // GetConfigPath returns the default config file path
 // First tries current directory, then falls back to ~/.config/playlist-sorter/config.json
 func GetConfigPath() string {
@@ -44,7 +225,28 @@ func GetConfigPath() string {
 	return filepath.Join(home, ".config", "playlist-sorter", "config.json")
 }
 
-// LoadConfig loads configuration from a JSON file
+// GetStateDir returns the directory for per-playlist session state such as undo history
+func GetStateDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".playlist-sorter-state")
+	}
+
+	return filepath.Join(home, ".config", "playlist-sorter", "state")
+}
+
+// GetCacheDir returns the directory for data fetched from online services, such as looked up genres
+func GetCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".playlist-sorter-cache")
+	}
+
+	return filepath.Join(home, ".cache", "playlist-sorter")
+}
+
+// LoadConfig loads configuration from a JSON file, migrating files saved by older versions.
+// Keys missing from the file take their defaults.
 // If the file doesn't exist or fails to load, returns default config
 func LoadConfig(path string) (GAConfig, error) {
 	// Try to read the file
@@ -58,9 +260,9 @@ func LoadConfig(path string) (GAConfig, error) {
 		return DefaultConfig(), fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse JSON
-	var config GAConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	// Files without schema_version predate versioning
+	config, err := decodeConfig(data, DefaultConfig(), 0)
+	if err != nil {
 		return DefaultConfig(), fmt.Errorf("failed to parse config file: %w", err)
 	}
 
@@ -78,6 +280,7 @@ func SaveConfig(path string, config GAConfig) error {
 	// Round all float values to 2 decimal places to match UI precision
 	// This prevents floating point rounding errors from accumulating
 	config = roundConfigPrecision(config)
+	config.SchemaVersion = SchemaVersion
 
 	// Create file
 	f, err := os.Create(path)
@@ -104,6 +307,7 @@ func SaveConfig(path string, config GAConfig) error {
 // DefaultConfig returns the default GA configuration with normalized fitness weights
 func DefaultConfig() GAConfig {
 	return GAConfig{
+		SchemaVersion:        SchemaVersion,
 		HarmonicWeight:       0.3,
 		SameArtistPenalty:    0.2,
 		SameAlbumPenalty:     0.2,
@@ -112,9 +316,62 @@ func DefaultConfig() GAConfig {
 		GenreWeight:          0.0,
 		LowEnergyBiasPortion: 0.2,
 		LowEnergyBiasWeight:  0.0,
+		ShuffleTemperature:   0.0,
+		NoveltyWeight:        0.0,
+		MixLengthWeight:      0.0,
+		EnergyWaveWeight:     0.0,
+		EnergyWaveAmplitude:  1.0,
+		EnergyWavePeriod:     8,
+		BPMBandWeight:        0.0,
+		BPMBandWidth:         DefaultBPMBandWidth,
+		FavoriteMinRating:    5,
+		PrimeStart:           0.67,
+		PrimeEnd:             1.0,
+		FreshDays:            30,
+		OpenerIntro:          30,
+		TrackTimeoutMs:       10000,
+		FocusRadius:          5,
 	}
 }
 
+// SidecarPath returns the per-playlist config file for a playlist: "set.m3u8" -> "set.sorter.json"
+func SidecarPath(playlistPath string) string {
+	return strings.TrimSuffix(playlistPath, filepath.Ext(playlistPath)) + ".sorter.json"
+}
+
+// ApplySidecar overlays the playlist's sidecar config (if any) onto base.
+// The sidecar may set any subset of fields; the rest keep their base values.
+// Returns whether a sidecar was found.
+func ApplySidecar(base GAConfig, playlistPath string) (GAConfig, bool, error) {
+	cfg, err := ApplyFile(base, SidecarPath(playlistPath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return base, false, nil
+		}
+
+		return base, false, err
+	}
+
+	return cfg, true, nil
+}
+
+// ApplyFile overlays the config file at path onto base, like a sidecar: fields missing from the
+// file keep their base values. Overlays are usually written by hand, so one without schema_version
+// is read as the current schema.
+func ApplyFile(base GAConfig, path string) (GAConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return base, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	cfg, err := decodeConfig(data, base, SchemaVersion)
+	if err != nil {
+		return base, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
 // roundConfigPrecision rounds all float64 fields to 2 decimal places
 func roundConfigPrecision(config GAConfig) GAConfig {
 	round := func(x float64) float64 {
@@ -129,6 +386,20 @@ func roundConfigPrecision(config GAConfig) GAConfig {
 	config.GenreWeight = round(config.GenreWeight)
 	config.LowEnergyBiasPortion = round(config.LowEnergyBiasPortion)
 	config.LowEnergyBiasWeight = round(config.LowEnergyBiasWeight)
+	config.ShuffleTemperature = round(config.ShuffleTemperature)
+	config.NoveltyWeight = round(config.NoveltyWeight)
+	config.MixLengthWeight = round(config.MixLengthWeight)
+	config.EnergyWaveWeight = round(config.EnergyWaveWeight)
+	config.EnergyWaveAmplitude = round(config.EnergyWaveAmplitude)
+	config.BPMBandWeight = round(config.BPMBandWeight)
+	config.BPMBandWidth = round(config.BPMBandWidth)
+	config.FavoriteWeight = round(config.FavoriteWeight)
+	config.PrimeStart = round(config.PrimeStart)
+	config.PrimeEnd = round(config.PrimeEnd)
+	config.FreshnessWeight = round(config.FreshnessWeight)
+	config.BookendWeight = round(config.BookendWeight)
+	config.OpenerIntro = round(config.OpenerIntro)
+	config.NicheRadius = round(config.NicheRadius)
 
 	return config
 }
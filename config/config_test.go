@@ -1,10 +1,15 @@
 // ABOUTME: Tests for configuration load/save functionality
-// ABOUTME: Validates JSON parsing and default config fallback behavior
+// ABOUTME: Validates JSON parsing, default config fallback and schema migration
 
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -57,3 +62,203 @@ func TestLoadNonExistentConfig(t *testing.T) {
 		t.Errorf("Expected default HarmonicWeight %.2f, got %.2f", defaults.HarmonicWeight, cfg.HarmonicWeight)
 	}
 }
+
+func TestApplySidecar(t *testing.T) {
+	dir := t.TempDir()
+	playlistPath := filepath.Join(dir, "friday.m3u8")
+
+	if got := SidecarPath(playlistPath); got != filepath.Join(dir, "friday.sorter.json") {
+		t.Errorf("Unexpected sidecar path: %s", got)
+	}
+
+	base := DefaultConfig()
+
+	// No sidecar: base is returned unchanged
+	cfg, found, err := ApplySidecar(base, playlistPath)
+	if err != nil || found || cfg.GenreWeight != base.GenreWeight {
+		t.Fatalf("Expected base config without sidecar, got found=%v err=%v", found, err)
+	}
+
+	if err := os.WriteFile(SidecarPath(playlistPath), []byte(`{"genre_weight": 0.8}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, found, err = ApplySidecar(base, playlistPath)
+	if err != nil || !found {
+		t.Fatalf("Expected sidecar to be applied, got found=%v err=%v", found, err)
+	}
+
+	if cfg.GenreWeight != 0.8 {
+		t.Errorf("Expected sidecar GenreWeight 0.8, got %.2f", cfg.GenreWeight)
+	}
+
+	if cfg.HarmonicWeight != base.HarmonicWeight {
+		t.Errorf("Fields missing from the sidecar should keep base values, got HarmonicWeight %.2f", cfg.HarmonicWeight)
+	}
+}
+
+func TestGenreOverrideApply(t *testing.T) {
+	var cfg GAConfig
+	if err := json.Unmarshal([]byte(`{"bpm_delta_weight": 0.1, "genre_overrides": {"drum and bass": {"bpm_delta_weight": 0.6}}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	base := DefaultConfig()
+	base.BPMDeltaWeight = cfg.BPMDeltaWeight
+
+	got := cfg.GenreOverrides["drum and bass"].Apply(base)
+
+	if got.BPMDeltaWeight != 0.6 {
+		t.Errorf("Expected overridden BPMDeltaWeight 0.6, got %.2f", got.BPMDeltaWeight)
+	}
+
+	if got.HarmonicWeight != base.HarmonicWeight {
+		t.Errorf("Weights missing from the override should keep base values, got HarmonicWeight %.2f", got.HarmonicWeight)
+	}
+}
+
+func TestLoadConfigMigratesLegacyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	// Saved before schema_version and the energy wave / BPM band settings existed
+	legacy := `{"harmonic_weight": 0.5, "same_artist_penalty": 0.2, "low_energy_bias_portion": 0.3}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	defaults := DefaultConfig()
+	if cfg.HarmonicWeight != 0.5 || cfg.LowEnergyBiasPortion != 0.3 {
+		t.Errorf("Expected values from the file to be kept, got %+v", cfg)
+	}
+
+	if cfg.BPMBandWidth != defaults.BPMBandWidth || cfg.EnergyWavePeriod != defaults.EnergyWavePeriod ||
+		cfg.EnergyWaveAmplitude != defaults.EnergyWaveAmplitude {
+		t.Errorf("Expected keys missing from a legacy file to take defaults, got %+v", cfg)
+	}
+
+	if cfg.SchemaVersion != SchemaVersion {
+		t.Errorf("Expected schema version %d after migration, got %d", SchemaVersion, cfg.SchemaVersion)
+	}
+
+	// A file from a newer build still loads; unknown keys are ignored
+	if err := os.WriteFile(path, []byte(`{"schema_version": 99, "harmonic_weight": 0.4, "future_weight": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg, err := LoadConfig(path); err != nil || cfg.HarmonicWeight != 0.4 {
+		t.Errorf("Expected a newer file to load, got %.2f, %v", cfg.HarmonicWeight, err)
+	}
+}
+
+func TestMigrationRenamesAndScales(t *testing.T) {
+	m := migration{
+		renames: map[string]string{"artist_weight": "same_artist_penalty", "bpm_weight": "bpm_delta_weight"},
+		scales:  map[string]float64{"bpm_delta_weight": 0.5},
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(`{"artist_weight": 0.4, "bpm_weight": 0.6, "harmonic_weight": 0.3}`), &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.apply(raw); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg GAConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.SameArtistPenalty != 0.4 || cfg.BPMDeltaWeight != 0.3 || cfg.HarmonicWeight != 0.3 {
+		t.Errorf("Expected renamed and re-scaled weights, got %+v", cfg)
+	}
+
+	if _, ok := raw["artist_weight"]; ok {
+		t.Error("Expected the old key to be removed")
+	}
+}
+
+func TestPresets(t *testing.T) {
+	want := []string{"artist-spread", "club-peak", "drum-and-bass", "energy-flow", "harmonic-strict"}
+
+	presets := Presets()
+	if len(presets) != len(want) {
+		t.Fatalf("Expected %d presets, got %d", len(want), len(presets))
+	}
+
+	base := DefaultConfig()
+	base.ShuffleTemperature = 0.4
+
+	for i, p := range presets {
+		if p.Name != want[i] || p.Description == "" {
+			t.Errorf("Preset %d: expected %s with a description, got %+v", i, want[i], p)
+		}
+
+		cfg, err := ApplyPreset(base, p.Name)
+		if err != nil {
+			t.Errorf("%s: %v", p.Name, err)
+
+			continue
+		}
+
+		if cfg.ShuffleTemperature != base.ShuffleTemperature {
+			t.Errorf("%s: presets should keep run-level settings", p.Name)
+		}
+
+		if reflect.DeepEqual(cfg, base) {
+			t.Errorf("%s: preset changed nothing", p.Name)
+		}
+	}
+
+	if cfg, _ := ApplyPreset(base, "harmonic-strict"); cfg.HarmonicWeight != 0.8 {
+		t.Errorf("Expected harmonic-strict HarmonicWeight 0.8, got %.2f", cfg.HarmonicWeight)
+	}
+
+	for _, name := range []string{"nope", "../config", ""} {
+		if _, err := ApplyPreset(base, name); err == nil {
+			t.Errorf("Expected an error for preset %q", name)
+		}
+	}
+}
+
+func TestPresetsSetEveryWeight(t *testing.T) {
+	// Fitness weights by JSON name; novelty is a per-run choice presets leave alone
+	var weights []string
+
+	typ := reflect.TypeFor[GAConfig]()
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if (strings.HasSuffix(name, "_weight") || strings.HasSuffix(name, "_penalty")) && name != "novelty_weight" {
+			weights = append(weights, name)
+		}
+	}
+
+	for _, p := range Presets() {
+		data, err := presetFiles.ReadFile(path.Join("presets", p.Name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatalf("%s: %v", p.Name, err)
+		}
+
+		for _, w := range weights {
+			if _, ok := fields[w]; !ok {
+				t.Errorf("%s: expected %s to be set", p.Name, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// ABOUTME: Config schema versioning and migration of config files written by older versions
+// ABOUTME: Renames and re-scales keys step by step up to SchemaVersion before decoding
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the config schema this build reads and writes.
+// Bump it together with a new entry in migrations when a key is renamed or a weight changes scale.
+const SchemaVersion = 1
+
+// migration upgrades a config file from one schema version to the next
+type migration struct {
+	renames map[string]string  // Old key -> new key
+	scales  map[string]float64 // Key -> factor applied to its value (after renames)
+}
+
+// migrations[v] upgrades a version v file to version v+1.
+//
+// Version 0 is every file saved before schema_version existed. Its keys are still current; it
+// just lacks the keys added over time, which now take their defaults instead of zero.
+var migrations = []migration{
+	{},
+}
+
+// decodeConfig decodes a config file onto base after migrating it to SchemaVersion. Keys missing
+// from the file keep their base values. A file without schema_version is read as missingVersion.
+func decodeConfig(data []byte, base GAConfig, missingVersion int) (GAConfig, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return base, err
+	}
+
+	version := missingVersion
+	if v, ok := raw["schema_version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return base, fmt.Errorf("invalid schema_version: %w", err)
+		}
+	}
+
+	if version < 0 {
+		return base, fmt.Errorf("invalid schema_version %d", version)
+	}
+
+	// Files from a newer build are read as-is: keys this build doesn't know are ignored
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v].apply(raw); err != nil {
+			return base, fmt.Errorf("failed to migrate config from schema version %d: %w", v, err)
+		}
+	}
+
+	delete(raw, "schema_version")
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return base, err
+	}
+
+	cfg := base
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
+		return base, err
+	}
+
+	cfg.SchemaVersion = SchemaVersion
+
+	return cfg, nil
+}
+
+// apply renames and re-scales the keys of raw in place
+func (m migration) apply(raw map[string]json.RawMessage) error {
+	for from, to := range m.renames {
+		if v, ok := raw[from]; ok {
+			delete(raw, from)
+
+			// A file with both keys was edited by hand after the rename: the new key wins
+			if _, exists := raw[to]; !exists {
+				raw[to] = v
+			}
+		}
+	}
+
+	for key, factor := range m.scales {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+
+		var value float64
+		if err := json.Unmarshal(v, &value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+
+		scaled, err := json.Marshal(value * factor)
+		if err != nil {
+			return err
+		}
+
+		raw[key] = scaled
+	}
+
+	return nil
+}
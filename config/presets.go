@@ -0,0 +1,70 @@
+// ABOUTME: Built-in weight presets embedded in the binary as starting points for new users
+// ABOUTME: Each preset is a partial config overlaid onto the current one, like a sidecar
+
+package config
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+//go:embed presets/*.json
+var presetFiles embed.FS
+
+// Preset is a built-in set of weights
+type Preset struct {
+	Name        string
+	Description string
+}
+
+// Presets lists the built-in presets by name
+func Presets() []Preset {
+	entries, err := fs.ReadDir(presetFiles, "presets")
+	if err != nil {
+		return nil
+	}
+
+	presets := make([]Preset, 0, len(entries))
+
+	for _, e := range entries {
+		data, err := presetFiles.ReadFile(path.Join("presets", e.Name()))
+		if err != nil {
+			continue
+		}
+
+		var info struct {
+			Description string `json:"description"`
+		}
+
+		_ = json.Unmarshal(data, &info) // Embedded files are checked by tests
+
+		presets = append(presets, Preset{Name: strings.TrimSuffix(e.Name(), ".json"), Description: info.Description})
+	}
+
+	return presets
+}
+
+// ApplyPreset overlays the named preset onto base. Presets set every fitness weight except
+// novelty, which like shuffle is a per-run choice; other settings (tags, ...) keep their base values.
+func ApplyPreset(base GAConfig, name string) (GAConfig, error) {
+	data, err := presetFiles.ReadFile(path.Join("presets", name+".json"))
+	if err != nil || strings.ContainsAny(name, `/\`) {
+		names := make([]string, 0, len(Presets()))
+		for _, p := range Presets() {
+			names = append(names, p.Name)
+		}
+
+		return base, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	cfg, err := decodeConfig(data, base, SchemaVersion)
+	if err != nil {
+		return base, fmt.Errorf("failed to parse preset %s: %w", name, err)
+	}
+
+	return cfg, nil
+}
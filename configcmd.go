@@ -0,0 +1,78 @@
+// ABOUTME: Config subcommand for inspecting and resetting the persisted configuration
+// ABOUTME: Supports printing the config path, the effective config as JSON, resetting to defaults and listing presets
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"playlist-sorter/config"
+)
+
+// runConfig implements `playlist-sorter config [path|show|reset|presets]`
+func runConfig(args []string) int {
+	fs := newFlagSet("config", "config [path|show|reset|presets]")
+
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+
+	action := "show"
+	if fs.NArg() > 0 {
+		action = fs.Arg(0)
+	}
+
+	configPath := config.GetConfigPath()
+
+	switch action {
+	case "path":
+		fmt.Println(configPath)
+
+	case "show":
+		cfg, err := config.LoadConfig(configPath)
+		if err != nil {
+			log.Printf("Warning: %v (showing defaults)", err)
+		}
+
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			log.Printf("Config error: %v", err)
+
+			return 1
+		}
+
+		fmt.Println(string(data))
+
+	case "reset":
+		if err := config.SaveConfig(configPath, config.DefaultConfig()); err != nil {
+			log.Printf("Config error: %v", err)
+
+			return 1
+		}
+
+		fmt.Printf("Reset %s to defaults\n", configPath)
+
+	case "presets":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, p := range config.Presets() {
+			if _, err := fmt.Fprintf(w, "%s\t%s\n", p.Name, p.Description); err != nil {
+				log.Printf("Warning: failed to write preset %s: %v", p.Name, err)
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			log.Printf("Warning: failed to flush output: %v", err)
+		}
+
+	default:
+		fs.Usage()
+
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,188 @@
+// ABOUTME: Daemon subcommand re-optimizing playlists whenever they change: cron without cron
+// ABOUTME: Polls modification times, waits for edits to settle, skips locked playlists, logs with log/slog
+
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"playlist-sorter/playlist"
+)
+
+// Daemon defaults
+const (
+	defaultDaemonInterval = 5 * time.Second  // How often playlists are checked for changes
+	defaultDaemonDebounce = 30 * time.Second // How long a playlist must stay unchanged before it is optimized
+	defaultDaemonDuration = time.Minute      // Optimizer run time per change
+)
+
+// watchedPlaylist is a playlist the daemon watches
+type watchedPlaylist struct {
+	path         string
+	modTime      time.Time // Modification time last seen
+	changedSince time.Time // When the current burst of changes was last seen (zero = nothing pending)
+}
+
+// daemon re-optimizes watched playlists after they change
+type daemon struct {
+	playlists []*watchedPlaylist
+	opts      RunOptions    // Options for every run (PlaylistPath is set per playlist)
+	debounce  time.Duration // Quiet period after the last change before optimizing
+	logger    *slog.Logger
+
+	// Injectable for tests
+	now      func() time.Time
+	optimize func(context.Context, RunOptions) (sortResult, error)
+}
+
+// newDaemon watches paths, recording their current state so only later changes trigger a run
+func newDaemon(paths []string, opts RunOptions, debounce time.Duration, logger *slog.Logger) *daemon {
+	d := &daemon{opts: opts, debounce: debounce, logger: logger, now: time.Now, optimize: sortPlaylist}
+
+	for _, path := range paths {
+		p := &watchedPlaylist{path: path}
+
+		if info, err := os.Stat(path); err == nil {
+			p.modTime = info.ModTime()
+		} else {
+			logger.Warn("playlist not found, waiting for it", "playlist", path, "error", err)
+		}
+
+		d.playlists = append(d.playlists, p)
+	}
+
+	return d
+}
+
+// run polls every interval until ctx is cancelled
+func (d *daemon) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.poll(ctx)
+		}
+	}
+}
+
+// poll notes changed playlists and optimizes those that have been quiet for the debounce period
+func (d *daemon) poll(ctx context.Context) {
+	for _, p := range d.playlists {
+		if ctx.Err() != nil {
+			return
+		}
+
+		info, err := os.Stat(p.path)
+		if err != nil {
+			continue // Missing for now (e.g. being replaced); picked up when it's back
+		}
+
+		if !info.ModTime().Equal(p.modTime) {
+			if p.changedSince.IsZero() {
+				d.logger.Info("change detected", "playlist", p.path)
+			}
+
+			p.modTime = info.ModTime()
+			p.changedSince = d.now()
+
+			continue
+		}
+
+		if p.changedSince.IsZero() || d.now().Sub(p.changedSince) < d.debounce {
+			continue
+		}
+
+		d.optimizePlaylist(ctx, p)
+	}
+}
+
+// optimizePlaylist runs the optimizer on p. A locked playlist stays pending and is retried at the next poll.
+func (d *daemon) optimizePlaylist(ctx context.Context, p *watchedPlaylist) {
+	opts := d.opts
+	opts.PlaylistPath = p.path
+
+	d.logger.Info("optimizing", "playlist", p.path, "duration", opts.Duration)
+
+	result, err := d.optimize(ctx, opts)
+
+	switch {
+	case errors.Is(err, errPlaylistLocked):
+		d.logger.Info("playlist locked by another run, retrying", "playlist", p.path)
+
+		return
+	case err != nil:
+		d.logger.Error("optimization failed", "playlist", p.path, "error", err)
+	default:
+		d.logger.Info("optimized", "playlist", p.path,
+			"tracks", result.Tracks,
+			"skipped", result.Skipped,
+			"initial_fitness", result.InitialFitness,
+			"final_fitness", result.FinalFitness,
+			"improvement_pct", result.Improvement(),
+			"elapsed", result.Elapsed.Round(time.Millisecond))
+	}
+
+	// Our own writes are not a change to react to
+	p.changedSince = time.Time{}
+
+	if info, err := os.Stat(p.path); err == nil {
+		p.modTime = info.ModTime()
+	}
+}
+
+// runDaemon implements `playlist-sorter daemon [flags] <playlist>...`
+func runDaemon(args []string) int {
+	fs := newFlagSet("daemon", "daemon [flags] <playlist.m3u8>...")
+	interval := fs.Duration("interval", defaultDaemonInterval, "how often to check the playlists for changes")
+	debounce := fs.Duration("debounce", defaultDaemonDebounce, "wait until a playlist has been unchanged this long before optimizing it")
+	duration := fs.Duration("duration", defaultDaemonDuration, "optimizer run time per change (max 5m)")
+	preset := fs.String("preset", "", "start from a built-in weight preset: "+presetNames())
+	skippedFlag := fs.String("skipped", "keep", "where tracks with unreadable metadata go in the output: keep (original position), end, drop")
+	logJSON := fs.Bool("log-json", false, "log as JSON lines instead of key=value text")
+
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+
+		return 2
+	}
+
+	skipped, err := playlist.ParseSkippedPlacement(*skippedFlag)
+	if err != nil {
+		slog.Error("invalid -skipped", "error", err)
+
+		return 2
+	}
+
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, nil)
+	if *logJSON {
+		handler = slog.NewJSONHandler(os.Stderr, nil)
+	}
+
+	logger := slog.New(handler)
+
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	opts := RunOptions{Duration: *duration, Quiet: true, Preset: *preset, Skipped: skipped}
+	d := newDaemon(fs.Args(), opts, *debounce, logger)
+
+	logger.Info("watching playlists", "count", len(d.playlists), "interval", *interval, "debounce", *debounce)
+
+	d.run(ctx, *interval)
+
+	logger.Info("stopped")
+
+	return 0
+}
@@ -0,0 +1,184 @@
+// ABOUTME: Tests for the daemon subcommand and playlist lock files
+// ABOUTME: Drives polls with a fake clock and optimizer to check debouncing and lock handling
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testDaemon watches one playlist with a fake clock and an optimizer that records its runs and
+// rewrites the playlist like a real run
+func testDaemon(t *testing.T) (*daemon, string, *time.Time, *[]string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "set.m3u8")
+	if err := os.WriteFile(path, []byte("a.mp3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	runs := []string{}
+
+	d := newDaemon([]string{path}, RunOptions{}, 30*time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.now = func() time.Time { return now }
+	d.optimize = func(_ context.Context, opts RunOptions) (sortResult, error) {
+		runs = append(runs, opts.PlaylistPath)
+		touch(t, opts.PlaylistPath, now.Add(time.Hour))
+
+		return sortResult{}, nil
+	}
+
+	return d, path, &now, &runs
+}
+
+// touch bumps path's modification time
+func touch(t *testing.T, path string, at time.Time) {
+	t.Helper()
+
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDaemonDebouncesChanges(t *testing.T) {
+	d, path, now, runs := testDaemon(t)
+	ctx := context.Background()
+
+	d.poll(ctx)
+
+	if len(*runs) != 0 {
+		t.Fatal("Expected no run for an unchanged playlist")
+	}
+
+	touch(t, path, now.Add(time.Minute))
+	d.poll(ctx)
+
+	// Still being edited: another change restarts the quiet period
+	*now = now.Add(20 * time.Second)
+	touch(t, path, now.Add(2*time.Minute))
+	d.poll(ctx)
+
+	*now = now.Add(20 * time.Second)
+	d.poll(ctx)
+
+	if len(*runs) != 0 {
+		t.Fatal("Expected no run before the playlist settled")
+	}
+
+	*now = now.Add(15 * time.Second)
+	d.poll(ctx)
+
+	if len(*runs) != 1 || (*runs)[0] != path {
+		t.Fatalf("Expected one run for %s after settling, got %v", path, *runs)
+	}
+
+	// The run's own write is not a change
+	*now = now.Add(time.Minute)
+	d.poll(ctx)
+
+	if len(*runs) != 1 {
+		t.Errorf("Expected no further runs, got %v", *runs)
+	}
+}
+
+func TestDaemonRetriesLockedPlaylist(t *testing.T) {
+	d, path, now, runs := testDaemon(t)
+	ctx := context.Background()
+
+	locked := true
+	optimize := d.optimize
+	d.optimize = func(ctx context.Context, opts RunOptions) (sortResult, error) {
+		if locked {
+			return sortResult{}, errPlaylistLocked
+		}
+
+		return optimize(ctx, opts)
+	}
+
+	touch(t, path, now.Add(time.Minute))
+	d.poll(ctx)
+
+	*now = now.Add(time.Minute)
+	d.poll(ctx)
+
+	if d.playlists[0].changedSince.IsZero() {
+		t.Fatal("Expected a locked playlist to stay pending")
+	}
+
+	locked = false
+
+	d.poll(ctx)
+
+	if len(*runs) != 1 {
+		t.Errorf("Expected the run to go ahead once unlocked, got %v", *runs)
+	}
+}
+
+func TestPlaylistLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "set.m3u8")
+
+	release, err := acquirePlaylistLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := acquirePlaylistLock(path); !errors.Is(err, errPlaylistLocked) {
+		t.Fatalf("Expected a held lock to be refused, got %v", err)
+	}
+
+	release()
+
+	release, err = acquirePlaylistLock(path)
+	if err != nil {
+		t.Fatalf("Expected a released lock to be available: %v", err)
+	}
+
+	// A lock older than any run is left behind by a crash and taken over
+	old := time.Now().Add(-lockStaleAfter - time.Minute)
+	if err := os.Chtimes(playlistLockPath(path), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	release2, err := acquirePlaylistLock(path)
+	if err != nil {
+		t.Fatalf("Expected a stale lock to be taken over: %v", err)
+	}
+
+	release2()
+	release()
+}
+
+func TestHoldPlaylistLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "set.m3u8")
+
+	release, err := holdPlaylistLock(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An aging lock is refreshed while held, so it isn't taken over as stale
+	old := time.Now().Add(-lockStaleAfter - time.Minute)
+	if err := os.Chtimes(playlistLockPath(path), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := acquirePlaylistLock(path); !errors.Is(err, errPlaylistLocked) {
+		t.Fatalf("Expected a held lock to be refreshed and refused, got %v", err)
+	}
+
+	release()
+
+	if _, err := os.Stat(playlistLockPath(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the lock removed on release, got %v", err)
+	}
+}
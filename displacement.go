@@ -0,0 +1,92 @@
+// ABOUTME: Summary of how far tracks moved between the original and the optimized order
+// ABOUTME: Counts tracks that kept their position, the average displacement and the largest moves
+
+package main
+
+import (
+	"cmp"
+	"slices"
+
+	"playlist-sorter/playlist"
+	"playlist-sorter/termtext"
+)
+
+const largestMovesShown = 5 // Largest moves listed after a CLI run
+
+// trackMove is a track's 0-based position before and after optimizing
+type trackMove struct {
+	track    *playlist.Track
+	from, to int
+}
+
+// distance is how many positions the track moved
+func (m trackMove) distance() int {
+	return max(m.from-m.to, m.to-m.from)
+}
+
+// displacementSummary describes how aggressive a reorder was
+type displacementSummary struct {
+	kept    int         // Tracks at the same position
+	average float64     // Mean positions moved per track
+	largest []trackMove // Largest moves, furthest first
+}
+
+// summarizeDisplacement compares the sorted order with the original, matching tracks by Identity
+// (repeated identities are matched in order of appearance)
+func summarizeDisplacement(original, sorted []playlist.Track, n int) displacementSummary {
+	positions := make(map[string][]int, len(original))
+	for i := range original {
+		positions[original[i].Identity()] = append(positions[original[i].Identity()], i)
+	}
+
+	var (
+		summary displacementSummary
+		moves   []trackMove
+		total   int
+	)
+
+	for i := range sorted {
+		queue := positions[sorted[i].Identity()]
+		if len(queue) == 0 {
+			continue
+		}
+
+		move := trackMove{track: &sorted[i], from: queue[0], to: i}
+		positions[sorted[i].Identity()] = queue[1:]
+
+		if move.distance() == 0 {
+			summary.kept++
+		} else {
+			moves = append(moves, move)
+		}
+
+		total += move.distance()
+	}
+
+	if len(sorted) > 0 {
+		summary.average = float64(total) / float64(len(sorted))
+	}
+
+	slices.SortStableFunc(moves, func(a, b trackMove) int { return cmp.Compare(b.distance(), a.distance()) })
+	summary.largest = moves[:min(n, len(moves))]
+
+	return summary
+}
+
+// printDisplacement prints the displacement summary of a run
+func printDisplacement(out cliPrinter, original, sorted []playlist.Track) {
+	summary := summarizeDisplacement(original, sorted, largestMovesShown)
+
+	out.Printf("\nKept position: %d/%d tracks, average move %.1f positions\n", summary.kept, len(sorted), summary.average)
+
+	if len(summary.largest) == 0 {
+		return
+	}
+
+	out.Println("Largest moves:")
+
+	for _, m := range summary.largest {
+		out.Printf("  %3d -> %-3d (%+d)  %s\n", m.from+1, m.to+1, m.to-m.from,
+			termtext.Truncate(m.track.Artist+" - "+m.track.Title, 50))
+	}
+}
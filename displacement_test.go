@@ -0,0 +1,48 @@
+// ABOUTME: Tests for the track displacement summary printed after a CLI run
+// ABOUTME: Verifies kept positions, average displacement, largest moves and repeated paths
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func TestSummarizeDisplacement(t *testing.T) {
+	tracks := func(paths ...string) []playlist.Track {
+		out := make([]playlist.Track, len(paths))
+		for i, p := range paths {
+			out[i] = playlist.Track{Path: p}
+		}
+
+		return out
+	}
+
+	original := tracks("a", "b", "c", "d", "e", "a")
+	sorted := tracks("e", "b", "a", "d", "a", "c")
+
+	// e 4->0, b kept, a 0->2, d kept, a 5->4, c 2->5
+	summary := summarizeDisplacement(original, sorted, 2)
+
+	if summary.kept != 2 {
+		t.Errorf("Expected 2 tracks to keep their position, got %d", summary.kept)
+	}
+
+	if want := float64(4+0+2+0+1+3) / 6; math.Abs(summary.average-want) > 1e-9 {
+		t.Errorf("Expected average displacement %.3f, got %.3f", want, summary.average)
+	}
+
+	if len(summary.largest) != 2 || summary.largest[0].track.Path != "e" || summary.largest[1].track.Path != "c" {
+		t.Fatalf("Expected the e and c moves to be largest, got %+v", summary.largest)
+	}
+
+	if m := summary.largest[1]; m.from != 2 || m.to != 5 {
+		t.Errorf("Expected c to move 2 -> 5, got %d -> %d", m.from, m.to)
+	}
+
+	if got := summarizeDisplacement(original, original, 5); got.kept != len(original) || got.average != 0 || len(got.largest) != 0 {
+		t.Errorf("Expected an unchanged order to keep every position, got %+v", got)
+	}
+}
@@ -0,0 +1,472 @@
+// ABOUTME: Enrich subcommand looking up genres on MusicBrainz or Last.fm for tracks without a genre tag
+// ABOUTME: Keeps the tags the genre hierarchy knows, caches every lookup locally and writes the genres as overrides
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// Genre tag sources
+const (
+	sourceMusicBrainz = "musicbrainz" // Community tags of the best matching recording; no account needed
+	sourceLastFM      = "lastfm"      // Top tags of the track; needs an API key
+)
+
+// Lookup limits and defaults
+const (
+	musicBrainzURL       = "https://musicbrainz.org/ws/2"
+	lastFMURL            = "https://ws.audioscrobbler.com/2.0/"
+	musicBrainzInterval  = time.Second            // MusicBrainz allows one request per second
+	lastFMInterval       = 200 * time.Millisecond // Last.fm asks for no more than five per second
+	musicBrainzMinScore  = 90                     // Search score (0-100) a recording needs to count as the track
+	lastFMNotFound       = 6                      // Last.fm error code for an unknown track
+	enrichRequestTimeout = 15 * time.Second
+	maxTagResponseSize   = 1 << 20
+	enrichMaxGenres      = 2 // Genres kept per track, most popular first
+	enrichUserAgent      = "playlist-sorter (https://github.com/stojg/playlist-sorter)"
+)
+
+// genreLookup fetches the tags of a recording from a source, keeping to the source's rate limit
+type genreLookup struct {
+	source   string
+	baseURL  string
+	apiKey   string // Last.fm API key
+	client   *http.Client
+	interval time.Duration // Minimum time between requests
+	last     time.Time     // When the previous request was sent
+}
+
+// newGenreLookup returns a lookup against the source's public API
+func newGenreLookup(source, apiKey string) (*genreLookup, error) {
+	l := &genreLookup{source: source, apiKey: apiKey, client: &http.Client{Timeout: enrichRequestTimeout}}
+
+	switch source {
+	case sourceMusicBrainz:
+		l.baseURL, l.interval = musicBrainzURL, musicBrainzInterval
+	case sourceLastFM:
+		if apiKey == "" {
+			return nil, errors.New("lastfm needs an API key (-lastfm-key or LASTFM_API_KEY)")
+		}
+
+		l.baseURL, l.interval = lastFMURL, lastFMInterval
+	default:
+		return nil, fmt.Errorf("unknown source %q (use %s or %s)", source, sourceMusicBrainz, sourceLastFM)
+	}
+
+	return l, nil
+}
+
+// tags returns the tags of the recording of artist and title, most popular first. A recording the
+// source doesn't know has no tags.
+func (l *genreLookup) tags(ctx context.Context, artist, title string) ([]string, error) {
+	if wait := l.interval - time.Since(l.last); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	l.last = time.Now()
+
+	var reqURL string
+
+	if l.source == sourceLastFM {
+		q := url.Values{"method": {"track.gettoptags"}, "artist": {artist}, "track": {title}, "autocorrect": {"1"}, "api_key": {l.apiKey}, "format": {"json"}}
+		reqURL = l.baseURL + "?" + q.Encode()
+	} else {
+		q := url.Values{"query": {"artist:" + luceneQuote(artist) + " AND recording:" + luceneQuote(title)}, "limit": {"1"}, "fmt": {"json"}}
+		reqURL = l.baseURL + "/recording?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", enrichUserAgent) // MusicBrainz blocks anonymous clients
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s lookup failed: %w", l.source, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s lookup failed: %s", l.source, resp.Status)
+	}
+
+	body := io.LimitReader(resp.Body, maxTagResponseSize)
+
+	if l.source == sourceLastFM {
+		return parseLastFMTags(body)
+	}
+
+	return parseMusicBrainzTags(body)
+}
+
+// luceneQuote quotes s as a phrase in a MusicBrainz search query
+func luceneQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// apiTag is a tag with its vote count, as both sources report them
+type apiTag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// tagNames returns the names of tags, most votes first
+func tagNames(tags []apiTag) []string {
+	slices.SortStableFunc(tags, func(a, b apiTag) int { return b.Count - a.Count })
+
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+
+	return names
+}
+
+// parseMusicBrainzTags returns the tags of the best recording of a MusicBrainz search response, or
+// none when it matches the query too poorly to be the same track
+func parseMusicBrainzTags(r io.Reader) ([]string, error) {
+	var resp struct {
+		Recordings []struct {
+			Score int      `json:"score"`
+			Tags  []apiTag `json:"tags"`
+		} `json:"recordings"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to parse MusicBrainz response: %w", err)
+	}
+
+	if len(resp.Recordings) == 0 || resp.Recordings[0].Score < musicBrainzMinScore {
+		return nil, nil
+	}
+
+	return tagNames(resp.Recordings[0].Tags), nil
+}
+
+// parseLastFMTags returns the tags of a Last.fm track.getTopTags response
+func parseLastFMTags(r io.Reader) ([]string, error) {
+	var resp struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+		TopTags struct {
+			Tag json.RawMessage `json:"tag"` // An object instead of a list when there is one tag
+		} `json:"toptags"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to parse Last.fm response: %w", err)
+	}
+
+	switch resp.Error {
+	case 0:
+	case lastFMNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("last.fm error %d: %s", resp.Error, resp.Message)
+	}
+
+	var tags []apiTag
+
+	if len(resp.TopTags.Tag) > 0 && json.Unmarshal(resp.TopTags.Tag, &tags) != nil {
+		var tag apiTag
+		if err := json.Unmarshal(resp.TopTags.Tag, &tag); err != nil {
+			return nil, fmt.Errorf("failed to parse Last.fm tags: %w", err)
+		}
+
+		tags = []apiTag{tag}
+	}
+
+	return tagNames(tags), nil
+}
+
+// genresFromTags keeps the first enrichMaxGenres tags the genre hierarchy knows (distinct once
+// normalized) as a multi-value genre tag, e.g. "Drum and Bass; Liquid Funk". Empty when no tag is a genre.
+func genresFromTags(tags []string) string {
+	var kept, seen []string
+
+	for _, tag := range tags {
+		g, ok := playlist.HierarchyGenre(tag)
+		if !ok || slices.Contains(seen, g) {
+			continue
+		}
+
+		seen = append(seen, g)
+		kept = append(kept, strings.TrimSpace(tag))
+
+		if len(kept) == enrichMaxGenres {
+			break
+		}
+	}
+
+	return strings.Join(kept, "; ")
+}
+
+// genreCache holds looked up tags by genreCacheKey, so a recording is only looked up once.
+// Tags are cached as the source returned them, so a recording without genre tags isn't asked again
+// and later changes to the hierarchy or genre_aliases apply without a new lookup.
+type genreCache map[string][]string
+
+// genreCachePath returns the genre tag cache file, shared by all playlists
+func genreCachePath() string {
+	return filepath.Join(config.GetCacheDir(), "genre-tags.json")
+}
+
+// genreCacheKey identifies a recording in the cache, ignoring case and surrounding spaces
+func genreCacheKey(source, artist, title string) string {
+	return source + "|" + strings.ToLower(strings.TrimSpace(artist)) + "|" + strings.ToLower(strings.TrimSpace(title))
+}
+
+// loadGenreCache reads the cache file; a missing file is an empty cache
+func loadGenreCache(path string) (genreCache, error) {
+	cache := genreCache{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genre cache %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse genre cache %s: %w", path, err)
+	}
+
+	return cache, nil
+}
+
+// save writes the cache file, creating its directory
+func (c genreCache) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode genre cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil { //nolint:gosec // Not sensitive
+		return fmt.Errorf("failed to write genre cache %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// enrichOptions holds what enrichPlaylist works on
+type enrichOptions struct {
+	PlaylistPath  string
+	OverridesPath string // Overrides file read and written (empty = the playlist's <name>.overrides.json)
+	Refresh       bool   // Look up cached recordings again
+	DryRun        bool   // Print the genres found without writing them
+}
+
+// enrichResult counts what enrichPlaylist did
+type enrichResult struct {
+	Untagged int // Tracks without a genre (after overrides) that have an artist to look up
+	Enriched int // Tracks given a genre
+	Cached   int // Lookups answered from the cache
+	Failed   int // Lookups that failed (not cached, so the next run tries again)
+}
+
+// enrichPlaylist looks up a genre for each track of the playlist without one and records it in the
+// overrides file, where sorting picks it up. The audio files are never touched. Lookups go through
+// cache, which gains every new answer. Stops early, with the result so far, when ctx is done.
+func enrichPlaylist(ctx context.Context, opts enrichOptions, lookup *genreLookup, cache genreCache, out io.Writer) (enrichResult, error) {
+	var result enrichResult
+
+	entries, err := playlist.ReadPlaylist(opts.PlaylistPath)
+	if err != nil {
+		return result, err
+	}
+
+	overridesPath := cmp.Or(opts.OverridesPath, playlist.OverridesPath(opts.PlaylistPath))
+
+	overrides, err := playlist.LoadPlaylistOverrides(opts.OverridesPath, opts.PlaylistPath)
+	if err != nil {
+		return result, err
+	}
+
+	playlistDir := filepath.Dir(opts.PlaylistPath)
+
+	for i, entry := range entries {
+		track, err := playlist.GetTrackMetadata(entry.Path, playlistDir)
+		if err != nil {
+			fmt.Fprintf(out, "%3d. skipped %s: %v\n", i+1, entry.Path, err)
+
+			continue
+		}
+
+		// A genre from an earlier run (or a hand correction) counts as tagged
+		tracks := []playlist.Track{*track}
+		overrides.Apply(tracks, playlistDir)
+
+		if strings.TrimSpace(tracks[0].Genre) != "" || strings.TrimSpace(track.Artist) == "" {
+			continue
+		}
+
+		result.Untagged++
+
+		key := genreCacheKey(lookup.source, track.Artist, track.Title)
+
+		tags, cached := cache[key]
+		if cached && !opts.Refresh {
+			result.Cached++
+		} else {
+			tags, err = lookup.tags(ctx, track.Artist, track.Title)
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+
+			if err != nil {
+				result.Failed++
+				fmt.Fprintf(out, "%3d. %s - %s: %v\n", i+1, track.Artist, track.Title, err)
+
+				continue
+			}
+
+			cache[key] = tags
+		}
+
+		genre := genresFromTags(tags)
+		if genre == "" {
+			fmt.Fprintf(out, "%3d. %s - %s: no genre among %d tags\n", i+1, track.Artist, track.Title, len(tags))
+
+			continue
+		}
+
+		fmt.Fprintf(out, "%3d. %s - %s: %s\n", i+1, track.Artist, track.Title, genre)
+
+		result.Enriched++
+
+		if opts.DryRun {
+			continue
+		}
+
+		if err := playlist.SaveTrackOverride(overridesPath, entry.Path, playlistDir, playlist.TrackOverride{Genre: genre}); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// runEnrich implements `playlist-sorter enrich [flags] <playlist>`
+func runEnrich(args []string) int {
+	fs := newFlagSet("enrich", "enrich [flags] <playlist.m3u8>")
+	source := fs.String("source", "", "where to look up genre tags: musicbrainz or lastfm (default: lastfm with an API key, otherwise musicbrainz)")
+	lastFMKey := fs.String("lastfm-key", "", "Last.fm API key (default: $LASTFM_API_KEY)")
+	overrides := fs.String("overrides", "", "overrides file to record the genres in (default: <playlist>.overrides.json)")
+	refresh := fs.Bool("refresh", false, "look up tracks again even when cached")
+	dryRun := fs.Bool("dry-run", false, "print the genres found without recording them")
+	pathMap := pathMapFlag(fs)
+
+	playlistPath, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	if playlistPath == playlist.StdioPath {
+		log.Printf("Enrich error: needs a playlist file to keep the overrides next to")
+
+		return 2
+	}
+
+	cfg, _ := config.LoadConfig(config.GetConfigPath())
+	if err := setPathMap(cfg, *pathMap); err != nil {
+		log.Printf("Enrich error: %v", err)
+
+		return 2
+	}
+
+	// Aliases decide which tags count as genres
+	playlist.SetGenreAliases(cfg.GenreAliases)
+
+	apiKey := cmp.Or(*lastFMKey, os.Getenv("LASTFM_API_KEY"))
+
+	if *source == "" {
+		*source = sourceMusicBrainz
+		if apiKey != "" {
+			*source = sourceLastFM
+		}
+	}
+
+	lookup, err := newGenreLookup(*source, apiKey)
+	if err != nil {
+		log.Printf("Enrich error: %v", err)
+
+		return 2
+	}
+
+	cachePath := genreCachePath()
+
+	cache, err := loadGenreCache(cachePath)
+	if err != nil {
+		log.Printf("Enrich error: %v", err)
+
+		return 1
+	}
+
+	// Ctrl+C stops looking up, keeping what was found so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := enrichPlaylist(ctx, enrichOptions{
+		PlaylistPath:  playlistPath,
+		OverridesPath: *overrides,
+		Refresh:       *refresh,
+		DryRun:        *dryRun,
+	}, lookup, cache, os.Stdout)
+
+	if saveErr := cache.save(cachePath); saveErr != nil {
+		log.Printf("Warning: %v", saveErr)
+	}
+
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("Enrich error: %v", err)
+
+		return 1
+	}
+
+	fmt.Printf("\nFound genres for %d of %d tracks without one (%d cached lookups, %d failed)",
+		result.Enriched, result.Untagged, result.Cached, result.Failed)
+
+	if result.Enriched > 0 && !*dryRun {
+		fmt.Printf("; recorded in %s", cmp.Or(*overrides, playlist.OverridesPath(playlistPath)))
+	}
+
+	fmt.Println()
+
+	if err != nil || result.Failed > 0 {
+		return 1
+	}
+
+	return 0
+}
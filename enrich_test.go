@@ -0,0 +1,131 @@
+// ABOUTME: Tests for the enrich subcommand
+// ABOUTME: Serves canned MusicBrainz and Last.fm responses and checks genres are picked, cached and recorded as overrides
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func TestGenresFromTags(t *testing.T) {
+	if got := genresFromTags([]string{"seen live", "Drum and Bass", "dnb", "Liquid Funk", "jungle"}); got != "Drum and Bass; Liquid Funk" {
+		t.Errorf("Expected the first two distinct genres, got %q", got)
+	}
+
+	if got := genresFromTags([]string{"british", "favourites"}); got != "" {
+		t.Errorf("Expected no genre among free-form tags, got %q", got)
+	}
+}
+
+func TestParseLastFMTags(t *testing.T) {
+	tags, err := parseLastFMTags(strings.NewReader(`{"toptags":{"tag":[{"name":"uk","count":40},{"name":"house","count":100}]}}`))
+	if err != nil || !slices.Equal(tags, []string{"house", "uk"}) {
+		t.Errorf("Expected tags by votes, got %v (%v)", tags, err)
+	}
+
+	tags, err = parseLastFMTags(strings.NewReader(`{"toptags":{"tag":{"name":"techno","count":100}}}`))
+	if err != nil || !slices.Equal(tags, []string{"techno"}) {
+		t.Errorf("Expected a single tag object read as one tag, got %v (%v)", tags, err)
+	}
+
+	if tags, err := parseLastFMTags(strings.NewReader(`{"error":6,"message":"Track not found"}`)); err != nil || len(tags) != 0 {
+		t.Errorf("Expected an unknown track to have no tags, got %v (%v)", tags, err)
+	}
+
+	if _, err := parseLastFMTags(strings.NewReader(`{"error":10,"message":"Invalid API key"}`)); err == nil {
+		t.Error("Expected an error for a rejected request")
+	}
+}
+
+func TestParseMusicBrainzTags(t *testing.T) {
+	tags, err := parseMusicBrainzTags(strings.NewReader(`{"recordings":[{"score":100,"tags":[{"name":"techno","count":1},{"name":"house","count":3}]}]}`))
+	if err != nil || !slices.Equal(tags, []string{"house", "techno"}) {
+		t.Errorf("Expected tags by votes, got %v (%v)", tags, err)
+	}
+
+	if tags, _ := parseMusicBrainzTags(strings.NewReader(`{"recordings":[{"score":40,"tags":[{"name":"house","count":3}]}]}`)); len(tags) != 0 {
+		t.Errorf("Expected a poor match ignored, got %v", tags)
+	}
+}
+
+func TestEnrichPlaylist(t *testing.T) {
+	var queries []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+
+		tags := `[{"name":"british","count":5}]`
+		if strings.Contains(query, `artist:"Artist 1"`) {
+			tags = `[{"name":"seen live","count":9},{"name":"Drum & Bass","count":7}]`
+		}
+
+		_, _ = io.WriteString(w, `{"recordings":[{"score":100,"tags":`+tags+`}]}`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	var lines []string
+	for i, genre := range []string{"House", "", ""} {
+		name := fmt.Sprintf("track%d.mp3", i)
+		writeTagOnlyMP3(t, filepath.Join(dir, name), fmt.Sprintf("Artist %d", i), "8A", 5, 124, genre)
+		lines = append(lines, name)
+	}
+
+	path := filepath.Join(dir, "set.m3u8")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lookup := &genreLookup{source: sourceMusicBrainz, baseURL: srv.URL, client: srv.Client()}
+	cache := genreCache{}
+
+	result, err := enrichPlaylist(context.Background(), enrichOptions{PlaylistPath: path}, lookup, cache, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != (enrichResult{Untagged: 2, Enriched: 1}) || len(queries) != 2 {
+		t.Errorf("Expected both untagged tracks looked up and one enriched, got %+v after %d queries", result, len(queries))
+	}
+
+	overrides, err := playlist.LoadTrackOverrides(playlist.OverridesPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := overrides[filepath.Join(dir, lines[1])].Genre; got != "Drum & Bass" || len(overrides) != 1 {
+		t.Errorf("Expected the found genre recorded for the second track only, got %v", overrides)
+	}
+
+	// Again: the enriched track now has a genre, the other is answered from the cache
+	result, err = enrichPlaylist(context.Background(), enrichOptions{PlaylistPath: path}, lookup, cache, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != (enrichResult{Untagged: 1, Cached: 1}) || len(queries) != 2 {
+		t.Errorf("Expected one cached lookup and no new queries, got %+v after %d queries", result, len(queries))
+	}
+
+	cachePath := filepath.Join(dir, "cache", "genre-tags.json")
+	if err := cache.save(cachePath); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded, err := loadGenreCache(cachePath); err != nil || len(loaded) != 2 {
+		t.Errorf("Expected both lookups cached on disk, got %v (%v)", loaded, err)
+	}
+}
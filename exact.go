@@ -0,0 +1,306 @@
+// ABOUTME: Exact solvers: exhaustive search for tiny playlists, branch and bound for medium ones
+// ABOUTME: Cover all fitness components (including position-dependent ones), so results are optimal
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// exactSolverMaxTracks is the largest playlist ordered by exhaustive search rather than the GA.
+// 8! = 40320 orderings score in milliseconds; each extra track multiplies the work.
+const exactSolverMaxTracks = 8
+
+// Branch and bound limits
+const (
+	exactSearchMaxTracks  = 25   // Largest playlist branch and bound accepts (remaining sets are bitmasks)
+	exactSearchCheckEvery = 4096 // Nodes expanded between cancellation checks
+)
+
+// exactSort returns the lowest-fitness ordering of tracks and its fitness, trying every
+// permutation (Heap's algorithm). Ties keep the first ordering found, starting with tracks as given.
+// Positions pinned by ctx.locked keep their tracks; only the free ones are permuted.
+func exactSort(tracks []playlist.Track, cfg config.GAConfig, ctx *GAContext) ([]playlist.Track, float64) {
+	current := slices.Clone(tracks)
+	best := slices.Clone(tracks)
+	bestFitness := calculateFitness(current, cfg, ctx)
+
+	free := ctx.locked.freePositions(len(current))
+	n := len(free)
+	counters := make([]int, n)
+
+	for i := 1; i < n; {
+		if counters[i] >= i {
+			counters[i] = 0
+			i++
+
+			continue
+		}
+
+		if i%2 == 0 {
+			current[free[0]], current[free[i]] = current[free[i]], current[free[0]]
+		} else {
+			current[free[counters[i]]], current[free[i]] = current[free[i]], current[free[counters[i]]]
+		}
+
+		if fitness := calculateFitness(current, cfg, ctx); fitness < bestFitness {
+			bestFitness = fitness
+			copy(best, current)
+		}
+
+		counters[i]++
+		i = 1
+	}
+
+	return best, bestFitness
+}
+
+// exactSearchResult is the outcome of a branch and bound search
+type exactSearchResult struct {
+	Best       []playlist.Track // Lowest-fitness ordering found
+	Fitness    float64          // Fitness of Best
+	LowerBound float64          // No ordering scores below this (equals Fitness when Complete)
+	Complete   bool             // Search space exhausted: Best is optimal
+	Nodes      int              // Partial orderings expanded
+}
+
+// Gap returns how far fitness may be above the optimum, in percent of fitness (0 when proven optimal)
+func (r exactSearchResult) Gap(fitness float64) float64 {
+	if fitness <= 0 || fitness <= r.LowerBound {
+		return 0
+	}
+
+	return (fitness - r.LowerBound) * 100 / fitness
+}
+
+// branchAndBound searches for the optimal ordering of tracks, starting from upper (e.g. the GA's
+// result, a reordering of tracks; nil starts from tracks as given) as the best known ordering. Partial orderings are extended depth-first, most promising
+// track first, and abandoned once their lower bound reaches the best fitness found so far. The
+// search is anytime: when ctx is cancelled it returns the best ordering found with a lower bound
+// on the optimum. ctx's GAContext must have normalized weights for cfg (see updateNormalizedWeights).
+//
+// The lower bound of a partial ordering is its exact cost plus, for every track still to place,
+// its cheapest incoming transition from a track that could precede it, plus for every position
+// still to fill, its cheapest position-dependent cost (position bias, energy wave).
+func branchAndBound(ctx context.Context, tracks, upper []playlist.Track, cfg config.GAConfig, gaCtx *GAContext) exactSearchResult {
+	n := len(tracks)
+	if n > exactSearchMaxTracks {
+		panic("branchAndBound: too many tracks")
+	}
+
+	s := newBranchAndBound(tracks, cfg, gaCtx)
+
+	if upper == nil {
+		upper = tracks
+	}
+
+	s.best = make([]int, n)
+	for i := range upper {
+		s.best[i] = slices.IndexFunc(tracks, func(t playlist.Track) bool { return t.Index == upper[i].Index })
+	}
+
+	s.bestCost = s.cost(s.best)
+
+	all := uint32(1)<<n - 1
+	rootBound := s.remainingBound(-1, all, 0)
+
+	s.search(ctx, -1, all, 0, 0)
+
+	result := exactSearchResult{
+		Best:     make([]playlist.Track, n),
+		Complete: !s.cancelled,
+		Nodes:    s.nodes,
+	}
+
+	for i, slot := range s.best {
+		result.Best[i] = tracks[slot]
+	}
+
+	result.Fitness = calculateFitness(result.Best, cfg, gaCtx)
+
+	switch {
+	case result.Complete:
+		result.LowerBound = result.Fitness
+	default:
+		result.LowerBound = min(max(rootBound, s.frontier), result.Fitness)
+	}
+
+	return result
+}
+
+// bnbSearch is the state of one branch and bound search. Tracks are referred to by slot (position
+// in the input slice), so sets of remaining tracks fit in a bitmask.
+type bnbSearch struct {
+	n        int
+	edge     [][]float64 // Weighted cost of the transition from slot a to slot b
+	position [][]float64 // Position-dependent cost of slot a at position j
+
+	path      []int
+	best      []int
+	bestCost  float64
+	frontier  float64 // Bound of the first track being explored; unexplored orderings cost at least this
+	nodes     int
+	cancelled bool
+}
+
+// newBranchAndBound precomputes transition and position costs between tracks' slots
+func newBranchAndBound(tracks []playlist.Track, cfg config.GAConfig, gaCtx *GAContext) *bnbSearch {
+	n := len(tracks)
+	s := &bnbSearch{
+		n:        n,
+		edge:     make([][]float64, n),
+		position: make([][]float64, n),
+		path:     make([]int, 0, n),
+	}
+
+	for a := range n {
+		s.edge[a] = make([]float64, n)
+		for b := range n {
+			var breakdown playlist.Breakdown
+
+			addEdgeBreakdown(&breakdown, tracks[a].Index, tracks[b].Index, gaCtx)
+
+			s.edge[a][b] = breakdown.Sum()
+		}
+
+		s.position[a] = make([]float64, n)
+		for j := range n {
+			s.position[a][j] = positionalCost(&tracks[a], j, n, cfg, gaCtx).total()
+		}
+	}
+
+	return s
+}
+
+// cost returns the fitness of an ordering of slots
+func (s *bnbSearch) cost(order []int) float64 {
+	total := 0.0
+
+	for j, slot := range order {
+		if j > 0 {
+			total += s.edge[order[j-1]][slot]
+		}
+
+		total += s.position[slot][j]
+	}
+
+	return total
+}
+
+// remainingBound returns a lower bound on the cost still to come when last (-1 = none) is at
+// position depth-1 and the slots in remaining fill positions depth onwards
+func (s *bnbSearch) remainingBound(last int, remaining uint32, depth int) float64 {
+	bound := 0.0
+
+	for r := range s.n {
+		if remaining&(1<<r) == 0 {
+			continue
+		}
+
+		// The track placed next may follow last; any other follows another remaining track
+		cheapest := math.Inf(1)
+		if last >= 0 {
+			cheapest = s.edge[last][r]
+		}
+
+		for p := range s.n {
+			if p != r && remaining&(1<<p) != 0 {
+				cheapest = min(cheapest, s.edge[p][r])
+			}
+		}
+
+		if !math.IsInf(cheapest, 1) {
+			bound += cheapest
+		}
+	}
+
+	for j := depth; j < s.n; j++ {
+		cheapest := math.Inf(1)
+
+		for r := range s.n {
+			if remaining&(1<<r) != 0 {
+				cheapest = min(cheapest, s.position[r][j])
+			}
+		}
+
+		bound += cheapest
+	}
+
+	return bound
+}
+
+// search extends the partial ordering s.path (ending in last, costing cost) with the slots in remaining
+func (s *bnbSearch) search(ctx context.Context, last int, remaining uint32, depth int, cost float64) {
+	if remaining == 0 {
+		if cost < s.bestCost {
+			s.bestCost = cost
+			copy(s.best, s.path)
+		}
+
+		return
+	}
+
+	s.nodes++
+	if s.nodes%exactSearchCheckEvery == 0 && ctx.Err() != nil {
+		s.cancelled = true
+	}
+
+	if s.cancelled {
+		return
+	}
+
+	type child struct {
+		slot  int
+		cost  float64
+		bound float64
+	}
+
+	children := make([]child, 0, s.n-depth)
+
+	for c := range s.n {
+		if remaining&(1<<c) == 0 {
+			continue
+		}
+
+		childCost := cost + s.position[c][depth]
+		if last >= 0 {
+			childCost += s.edge[last][c]
+		}
+
+		bound := childCost + s.remainingBound(c, remaining&^(1<<c), depth+1)
+		if bound < s.bestCost {
+			children = append(children, child{slot: c, cost: childCost, bound: bound})
+		}
+	}
+
+	slices.SortFunc(children, func(a, b child) int { return cmp.Compare(a.bound, b.bound) })
+
+	for _, c := range children {
+		// Siblings were bounded against an older best; the search may have found a better one since
+		if c.bound >= s.bestCost {
+			break
+		}
+
+		if depth == 0 {
+			s.frontier = c.bound
+		}
+
+		s.path = append(s.path, c.slot)
+		s.search(ctx, c.slot, remaining&^(1<<c.slot), depth+1, c.cost)
+		s.path = s.path[:depth]
+
+		if s.cancelled {
+			return
+		}
+	}
+
+	if depth == 0 {
+		s.frontier = s.bestCost
+	}
+}
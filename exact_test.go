@@ -0,0 +1,147 @@
+// ABOUTME: Tests for the exact solver used on tiny playlists
+// ABOUTME: Checks it against a brute-force search and that geneticSort hands tiny playlists to it
+
+package main
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// permutations returns every ordering of tracks
+func permutations(tracks []playlist.Track) [][]playlist.Track {
+	if len(tracks) <= 1 {
+		return [][]playlist.Track{slices.Clone(tracks)}
+	}
+
+	var result [][]playlist.Track
+
+	for i := range tracks {
+		rest := slices.Delete(slices.Clone(tracks), i, i+1)
+		for _, p := range permutations(rest) {
+			result = append(result, append([]playlist.Track{tracks[i]}, p...))
+		}
+	}
+
+	return result
+}
+
+func TestExactSortFindsMinimum(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.EnergyWaveWeight = 0.5 // Position-dependent component: edge costs alone can't find the optimum
+
+	for n := 1; n <= 6; n++ {
+		tracks := benchmarkTracks(n)
+		gaCtx := buildEdgeFitnessCache(tracks)
+		updateNormalizedWeights(gaCtx, cfg)
+
+		best, fitness := exactSort(tracks, cfg, gaCtx)
+
+		minimum := math.Inf(1)
+		for _, p := range permutations(tracks) {
+			minimum = min(minimum, calculateFitness(p, cfg, gaCtx))
+		}
+
+		if math.Abs(fitness-minimum) > 1e-12 {
+			t.Errorf("%d tracks: exact fitness %.10f, brute-force minimum %.10f", n, fitness, minimum)
+		}
+
+		if got := calculateFitness(best, cfg, gaCtx); got != fitness {
+			t.Errorf("%d tracks: returned order scores %.10f, reported %.10f", n, got, fitness)
+		}
+
+		if len(best) != n {
+			t.Errorf("%d tracks: exact solver returned %d tracks", n, len(best))
+		}
+	}
+}
+
+func TestGeneticSortSolvesTinyPlaylistsExactly(t *testing.T) {
+	tracks := benchmarkTracks(5)
+	gaCtx := buildEdgeFitnessCache(tracks)
+
+	sharedCfg := &config.SharedConfig{}
+	sharedCfg.Update(config.DefaultConfig())
+
+	updates := make(chan GAUpdate, 10)
+
+	// No deadline: a tiny playlist must not wait for one
+	best, err := geneticSort(context.Background(), tracks, sharedCfg, updates, 3, gaCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case update := <-updates:
+		if !update.Optimal || update.Epoch != 3 {
+			t.Errorf("Expected one optimal update for epoch 3, got optimal=%v epoch=%d", update.Optimal, update.Epoch)
+		}
+
+		if update.BestFitness != calculateFitness(best, sharedCfg.Get(), gaCtx) {
+			t.Errorf("Expected the update to score the returned order")
+		}
+	default:
+		t.Fatal("Expected an update for the exact result")
+	}
+}
+
+func TestBranchAndBoundMatchesExhaustive(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.EnergyWaveWeight = 0.5
+
+	for n := 1; n <= 8; n++ {
+		tracks := benchmarkTracks(n)
+		gaCtx := buildEdgeFitnessCache(tracks)
+		updateNormalizedWeights(gaCtx, cfg)
+
+		_, want := exactSort(tracks, cfg, gaCtx)
+
+		// Start from the worst possible upper bound: the input order
+		result := branchAndBound(context.Background(), tracks, nil, cfg, gaCtx)
+
+		if !result.Complete {
+			t.Fatalf("%d tracks: expected the search to finish", n)
+		}
+
+		if math.Abs(result.Fitness-want) > 1e-9 {
+			t.Errorf("%d tracks: branch and bound %.10f, exhaustive %.10f", n, result.Fitness, want)
+		}
+
+		if result.LowerBound != result.Fitness || result.Gap(result.Fitness) != 0 {
+			t.Errorf("%d tracks: expected a finished search to prove its result optimal", n)
+		}
+	}
+}
+
+func TestBranchAndBoundCancelledKeepsBounds(t *testing.T) {
+	tracks := benchmarkTracks(20)
+	cfg := config.DefaultConfig()
+	gaCtx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(gaCtx, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := branchAndBound(ctx, tracks, nil, cfg, gaCtx)
+
+	if result.Complete {
+		t.Fatal("Expected a cancelled search not to claim optimality")
+	}
+
+	if result.Fitness > calculateFitness(tracks, cfg, gaCtx)+1e-12 {
+		t.Errorf("Expected the result to be no worse than the starting order")
+	}
+
+	if result.LowerBound > result.Fitness {
+		t.Errorf("Lower bound %.10f above the best fitness %.10f", result.LowerBound, result.Fitness)
+	}
+
+	if len(result.Best) != len(tracks) {
+		t.Errorf("Expected %d tracks, got %d", len(tracks), len(result.Best))
+	}
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Downloads playlists given as http(s) URLs so they can be optimized locally
+// ABOUTME: Saves the download next to the local output file so relative track paths resolve there
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Download limits for remote playlists
+const (
+	playlistDownloadTimeout = 30 * time.Second
+	maxPlaylistDownloadSize = 10 << 20 // 10 MiB is far beyond any real playlist
+	defaultDownloadName     = "playlist.m3u8"
+)
+
+// isPlaylistURL reports whether the playlist argument is an http(s) URL
+func isPlaylistURL(arg string) bool {
+	u, err := url.Parse(arg)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// urlPlaylistName returns the local file name for a downloaded playlist (the URL's last path element)
+func urlPlaylistName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return defaultDownloadName
+	}
+
+	name := path.Base(u.Path)
+	if name == "." || name == "/" || name == "" {
+		return defaultDownloadName
+	}
+
+	return name
+}
+
+// downloadPlaylist fetches rawURL into a temporary file in dir.
+// Returns the file path and a cleanup function that removes it.
+func downloadPlaylist(ctx context.Context, rawURL, dir string) (string, func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, playlistDownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid playlist URL: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download playlist: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("failed to download playlist: %s", resp.Status)
+	}
+
+	f, err := os.CreateTemp(dir, ".playlist-sorter-*.m3u8")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	cleanup := func() { _ = os.Remove(f.Name()) }
+
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxPlaylistDownloadSize+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		cleanup()
+
+		return "", nil, fmt.Errorf("failed to download playlist: %w", err)
+	}
+
+	if n > maxPlaylistDownloadSize {
+		cleanup()
+
+		return "", nil, fmt.Errorf("playlist download exceeds %d bytes", maxPlaylistDownloadSize)
+	}
+
+	return f.Name(), cleanup, nil
+}
+
+// localPlaylistFromURL downloads a playlist URL for optimization. The result is written to output,
+// or to the URL's file name in the current directory if output is empty.
+// Returns the downloaded file, the local output path, and a cleanup function for the download.
+func localPlaylistFromURL(rawURL, output string) (string, string, func(), error) {
+	if output == "" {
+		output = urlPlaylistName(rawURL)
+	}
+
+	// Download beside the output so relative track paths resolve the same for both
+	downloaded, cleanup, err := downloadPlaylist(context.Background(), rawURL, filepath.Dir(output))
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return downloaded, output, cleanup, nil
+}
@@ -0,0 +1,79 @@
+// ABOUTME: Tests for downloading playlists given as http(s) URLs
+// ABOUTME: Verifies URL detection, local naming, and download error handling
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsPlaylistURL(t *testing.T) {
+	for arg, want := range map[string]bool{
+		"https://nas.local/sets/friday.m3u8": true,
+		"http://music:4533/playlist.m3u":     true,
+		"sets/friday.m3u8":                   false,
+		"/abs/friday.m3u8":                   false,
+		"-":                                  false,
+		"ftp://host/friday.m3u8":             false,
+	} {
+		if got := isPlaylistURL(arg); got != want {
+			t.Errorf("isPlaylistURL(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
+
+func TestURLPlaylistName(t *testing.T) {
+	for rawURL, want := range map[string]string{
+		"https://nas.local/sets/friday.m3u8?token=x": "friday.m3u8",
+		"https://nas.local/":                         defaultDownloadName,
+		"https://nas.local":                          defaultDownloadName,
+	} {
+		if got := urlPlaylistName(rawURL); got != want {
+			t.Errorf("urlPlaylistName(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestDownloadPlaylist(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/set.m3u8" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("a.mp3\nb.mp3\n"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	path, cleanup, err := downloadPlaylist(context.Background(), srv.URL+"/set.m3u8", dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if filepath.Dir(path) != dir {
+		t.Errorf("Expected download in %s, got %s", dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "a.mp3\nb.mp3\n" {
+		t.Errorf("Unexpected download content %q (%v)", data, err)
+	}
+
+	cleanup()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected cleanup to remove the downloaded file")
+	}
+
+	if _, _, err := downloadPlaylist(context.Background(), srv.URL+"/missing.m3u8", dir); err == nil {
+		t.Error("Expected error for 404 response")
+	}
+}
@@ -6,6 +6,8 @@ package main
 import (
 	"cmp"
 	"context"
+	"fmt"
+	"maps"
 	"math"
 	"math/rand/v2"
 	"runtime"
@@ -25,9 +27,9 @@ type workerPool struct {
 	taskWg   sync.WaitGroup // tracks task completion
 }
 
-// newWorkerPool creates worker pool sized to available CPUs
+// newWorkerPool creates worker pool sized to available CPUs (or fewer, see -threads)
 func newWorkerPool(bufferSize int) *workerPool {
-	numWorkers := runtime.NumCPU()
+	numWorkers := throttle.workers()
 	pool := &workerPool{
 		workers:  numWorkers,
 		taskChan: make(chan func(), bufferSize),
@@ -91,6 +93,16 @@ const (
 	twoOptIntervalGens   = 5000
 	floatingPointEpsilon = 1e-10
 
+	// Adaptive 2-opt scheduling: the interval doubles when a run gains nothing or takes more than
+	// twoOptMaxShare of the time since the previous one, and halves when it pays off cheaply
+	twoOptMaxShare        = 0.2
+	twoOptMinIntervalGens = 500
+	twoOptMaxIntervalGens = 80000
+
+	// Fitness sharing: an individual next to an elite has its selection score raised by up to this
+	// share of its magnitude (the full amount for an exact copy)
+	nicheSharingPenalty = 0.5
+
 	updateIntervalGenerations = 50
 
 	camelotWheelPositions = 12
@@ -102,9 +114,25 @@ type Individual struct {
 	Score float64
 }
 
-// Compare returns -1 if better, 0 if equal, 1 if worse
+// Compare returns -1 if better, 0 if equal, 1 if worse. Equal scores are ordered by their orderings
+// (see comparePermutations), so sorting a population never depends on the order it was in.
 func (ind Individual) Compare(other Individual) int {
-	return cmp.Compare(ind.Score, other.Score)
+	if c := cmp.Compare(ind.Score, other.Score); c != 0 {
+		return c
+	}
+
+	return comparePermutations(ind.Genes, other.Genes)
+}
+
+// comparePermutations orders two orderings lexicographically by their tracks' cache indices
+func comparePermutations(a, b []playlist.Track) int {
+	for i := range min(len(a), len(b)) {
+		if c := cmp.Compare(a[i].Index, b[i].Index); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(a), len(b))
 }
 
 // GAUpdate contains GA state information
@@ -115,6 +143,8 @@ type GAUpdate struct {
 	BestPlaylist []playlist.Track
 	GenPerSec    float64
 	Breakdown    playlist.Breakdown
+	Flags        []playlist.TrackFlags // Per position of BestPlaylist
+	Optimal      bool                  // BestPlaylist is provably optimal (exact solver), the run is over
 }
 
 // minBPMDistance finds minimum BPM difference considering half/double time mixing
@@ -145,6 +175,10 @@ type EdgeData struct {
 	EnergyDelta      float64
 	BPMDelta         float64
 	GenreDifference  float64 // 0.0 = same, 1.0 = different
+	PreviousAdjacent bool    // Pair was adjacent (either direction) in the last saved output
+	MixMismatch      float64 // Outro/intro length mismatch, 0.0 = blends evenly (or unknown) to 1.0
+	FromBPM, ToBPM   float32 // Raw tempos for the BPM band component (band width is a weight setting)
+	BothFresh        bool    // Both tracks were recently added
 }
 
 // FitnessNormalizers stores max values for normalizing components to [0,1]
@@ -156,6 +190,18 @@ type FitnessNormalizers struct {
 	MaxBPMDelta     float64
 	MaxPositionBias float64
 	MaxGenreChange  float64
+	MaxShuffle      float64
+	MaxNovelty      float64
+	MaxMixLength    float64
+	MaxEnergyWave   float64
+	MaxBPMBand      float64
+	MaxFavorite     float64
+	MaxFreshness    float64
+	MaxBookends     float64
+
+	// Energy range of the playlist, the start and end of the energy wave's baseline
+	MinEnergy float64
+	MaxEnergy float64
 }
 
 // NormalizedWeights holds pre-normalized weight values to avoid recalculation
@@ -166,21 +212,51 @@ type NormalizedWeights struct {
 	genreAbsWeight     float64
 	genreSign          float64
 	genreEnabled       bool
+	genreBlocks        bool // Block mode: genre changes cost per boundary, more at harmonically rough ones
 	artistPenaltyRatio float64
 	albumPenaltyRatio  float64
 	positionBiasFactor float64
+	shuffleFactor      float64
+	noveltyFactor      float64
+	mixLengthFactor    float64
+	bpmBandFactor      float64
+	bpmBandWidth       float64
+	freshnessFactor    float64 // Back-to-back fresh tracks (0 when front-loading them instead)
 }
 
 // GAContext holds pre-calculated data for fitness evaluation
 type GAContext struct {
 	edgeCache   [][]EdgeData
+	edgeNoise   [][]float64 // Smart-shuffle jitter in [0,1) per transition (nil when shuffle is off)
 	normalizers FitnessNormalizers
 	weights     NormalizedWeights
+
+	// Weighted total per transition in one flat row-major array (from*numTracks + to), so the GA's
+	// hot path is a single load-and-add per edge. Rebuilt by updateNormalizedWeights; nil until then.
+	edgeCost  []float64
+	numTracks int
+
+	trackFlags  map[string]playlist.TrackFlags // Sticky per-track flags (locked, manual) by track Identity, echoed in updates
+	locked      *lockedPositions               // Positions of the FlagLocked tracks, set per run by geneticSort (nil = none)
+	rng         *rand.Rand                     // Random source of a run; set for reproducible runs (nil = random seed)
+	generations int                            // Stop after this many generations (0 = until ctx is done), e.g. to compare seeded runs
+	seeds       [][]playlist.Track             // User-provided orderings joining the first generation (see addSeedOrders)
+	landscape   *landscapeRecorder             // Periodic population snapshots for offline analysis (nil = off)
+
+	// Per-genre weight overrides, rebuilt by updateNormalizedWeights: transitions between two tracks
+	// resolving to the same overridden genre use its weights instead of weights
+	genres       []string            // Track genres by Index
+	genreKeys    []string            // Sorted genre_overrides keys genreNames and genreOfTrack were resolved from
+	genreSources []string            // genre_overrides key behind each entry of genreWeights
+	genreWeights []NormalizedWeights // Weights of each overridden genre
+	genreNames   map[string]int      // Lowercase overridden genre -> index into genreWeights (nil without overrides)
+	genreOfTrack []int               // Index into genreWeights per track Index (-1 = global weights)
 }
 
 // geneticSort optimizes track ordering using GA with fitness-based selection, crossover, mutation,
-// and 2-opt local search. Runs until context cancelled or 5 minute timeout.
-func geneticSort(ctx context.Context, tracks []playlist.Track, sharedConfig *config.SharedConfig, updateChan chan<- GAUpdate, epoch int, gaCtx *GAContext) []playlist.Track {
+// and 2-opt local search. Runs until context cancelled or 5 minute timeout. Fails without sorting if
+// the tracks' indices don't match gaCtx's edge cache.
+func geneticSort(ctx context.Context, tracks []playlist.Track, sharedConfig *config.SharedConfig, updateChan chan<- GAUpdate, epoch int, gaCtx *GAContext) ([]playlist.Track, error) {
 	var (
 		startTime    = time.Now()
 		gen          = 0
@@ -189,11 +265,52 @@ func geneticSort(ctx context.Context, tracks []playlist.Track, sharedConfig *con
 		lastGenCount = 0
 	)
 
+	// Stale indices would silently score every ordering against the wrong transitions
+	if err := playlist.CheckIndices(tracks, len(gaCtx.edgeCache)); err != nil {
+		return nil, fmt.Errorf("tracks don't match the edge cache (reindex and rebuild after edits): %w", err)
+	}
+
 	config := sharedConfig.Get()
 
+	rng := gaCtx.rng
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+
+	// Fresh jitter per run makes every smart-shuffle run land on a different good ordering
+	if config.ShuffleTemperature > 0 {
+		applyShuffleNoise(gaCtx, rng)
+	}
+
 	// Pre-normalize weights to avoid division in fitness hot path
 	updateNormalizedWeights(gaCtx, config)
 
+	// Locked tracks stay where they are: every ordering below is put back in line with them
+	gaCtx.locked = newLockedPositions(tracks, gaCtx.trackFlags, len(gaCtx.edgeCache))
+
+	// Tiny playlists: every ordering can be scored, so return the optimum instead of evolving
+	if genesLen <= exactSolverMaxTracks {
+		best, _ := exactSort(tracks, config, gaCtx)
+
+		if updateChan != nil {
+			breakdown := calculateFitnessWithBreakdown(best, config, gaCtx)
+
+			select {
+			case updateChan <- GAUpdate{
+				Epoch:        epoch,
+				BestFitness:  breakdown.Total,
+				BestPlaylist: slices.Clone(best),
+				Breakdown:    breakdown,
+				Flags:        playlist.PositionFlags(best, gaCtx.trackFlags),
+				Optimal:      true,
+			}:
+			default:
+			}
+		}
+
+		return best, nil
+	}
+
 	workerPool := newWorkerPool(runtime.NumCPU())
 	defer workerPool.close()
 
@@ -202,7 +319,13 @@ func geneticSort(ctx context.Context, tracks []playlist.Track, sharedConfig *con
 		scoredPopulation[i].Genes = make([]playlist.Track, genesLen)
 	}
 
-	presentMap := make(map[string]bool, genesLen)
+	twoOptCount := max(int(float64(populationSize)*elitePercentage), 2)
+	scratch := newGAScratch(populationSize, genesLen, len(gaCtx.edgeCache), twoOptCount)
+	twoOpt := newTwoOptScheduler(startTime)
+
+	// A seeded run must come out the same every time, so 2-opt ignores the clock: no time budget, and
+	// the fixed schedule instead of the adaptive one
+	seeded := gaCtx.rng != nil
 
 	nextGen := make([][]playlist.Track, populationSize)
 	for i := range populationSize {
@@ -213,33 +336,60 @@ func geneticSort(ctx context.Context, tracks []playlist.Track, sharedConfig *con
 
 	currentGen[seedOriginalOrder] = slices.Clone(tracks)
 
+	// Stable, so tracks that tie keep their playlist order
 	currentGen[seedEnergySorted] = slices.Clone(tracks)
-	slices.SortFunc(currentGen[seedEnergySorted], func(a, b playlist.Track) int { return a.Energy - b.Energy })
+	slices.SortStableFunc(currentGen[seedEnergySorted], func(a, b playlist.Track) int { return a.Energy - b.Energy })
 
 	currentGen[seedBPMSorted] = slices.Clone(tracks)
-	slices.SortFunc(currentGen[seedBPMSorted], func(a, b playlist.Track) int { return cmp.Compare(a.BPM, b.BPM) })
+	slices.SortStableFunc(currentGen[seedBPMSorted], func(a, b playlist.Track) int { return cmp.Compare(a.BPM, b.BPM) })
 
 	currentGen[seedKeySorted] = slices.Clone(tracks)
-	slices.SortFunc(currentGen[seedKeySorted], func(a, b playlist.Track) int { return a.ParsedKey.Compare(b.ParsedKey) })
+	slices.SortStableFunc(currentGen[seedKeySorted], func(a, b playlist.Track) int { return a.ParsedKey.Compare(b.ParsedKey) })
 
 	for i := seedRandomStart; i < populationSize; i++ {
 		currentGen[i] = slices.Clone(tracks)
-		rand.Shuffle(len(currentGen[i]), func(a, b int) { currentGen[i][a], currentGen[i][b] = currentGen[i][b], currentGen[i][a] })
+		rng.Shuffle(len(currentGen[i]), func(a, b int) { currentGen[i][a], currentGen[i][b] = currentGen[i][b], currentGen[i][a] })
+	}
+
+	// User-provided orderings take the place of random ones
+	for k, seed := range gaCtx.seeds {
+		if seedRandomStart+k >= populationSize || len(seed) != genesLen {
+			continue
+		}
+
+		currentGen[seedRandomStart+k] = slices.Clone(seed)
+	}
+
+	for i := range currentGen {
+		gaCtx.locked.apply(currentGen[i])
 	}
 
 	var (
-		bestIndividual                []playlist.Track
+		bestIndividual                = slices.Clone(tracks) // Returned as is if ctx is done before the first generation
 		bestFitness                   = math.MaxFloat64
 		generationsWithoutImprovement = 0
 	)
 
+	// Scores of the previous generation by permutation hash: elites and offspring that came out
+	// unchanged are looked up instead of re-evaluated
+	var (
+		scoreCache  = make(map[uint64]float64, populationSize)
+		hashes      = make([]uint64, populationSize)
+		cacheHits   = make([]bool, populationSize)
+		cachedPos   = positionalSettings(config)
+		totalHits   int
+		totalScored int
+	)
+
 loop:
 	for {
+		genStart := time.Now()
+
 		select {
 		case <-ctx.Done():
 			break loop
 		default:
-			if time.Since(startTime) >= maxDuration {
+			if time.Since(startTime) >= maxDuration || (gaCtx.generations > 0 && gen >= gaCtx.generations) {
 				break loop
 			}
 		}
@@ -248,43 +398,87 @@ loop:
 		config = sharedConfig.Get()
 		debugf("[GA] Config retrieved - Genre Weight: %.2f", config.GenreWeight)
 
+		// Positional components are the only ones read from config mid-run; cached scores are stale if they changed
+		if pos := positionalSettings(config); pos != cachedPos {
+			clear(scoreCache)
+			cachedPos = pos
+		}
+
 		debugf("[GA] Starting fitness evaluation for gen %d", gen)
 		for i := range currentGen {
 			workerPool.submit(func() {
-				scoredPopulation[i] = Individual{Genes: currentGen[i], Score: calculateFitness(currentGen[i], config, gaCtx)}
+				hashes[i] = permutationHash(currentGen[i])
+
+				score, ok := scoreCache[hashes[i]]
+				if !ok {
+					score = calculateFitness(currentGen[i], config, gaCtx)
+				}
+
+				cacheHits[i] = ok
+				scoredPopulation[i] = Individual{Genes: currentGen[i], Score: score}
 			})
 		}
 		workerPool.wait()
-		debugf("[GA] Fitness evaluation complete for gen %d", gen)
+
+		// Rebuild from this generation only, so the cache stays population-sized.
+		// Done before 2-opt, which changes the elites' genes in place.
+		clear(scoreCache)
+
+		for i := range currentGen {
+			scoreCache[hashes[i]] = scoredPopulation[i].Score
+
+			if cacheHits[i] {
+				totalHits++
+			}
+		}
+
+		totalScored += len(currentGen)
+		debugf("[GA] Fitness evaluation complete for gen %d (cache hits: %d/%d overall)", gen, totalHits, totalScored)
 
 		slices.SortFunc(scoredPopulation, func(a, b Individual) int { return a.Compare(b) })
+		scratch.spreadElites(scoredPopulation, config.NicheRadius)
+
+		// 2-opt is the slowest phase; skip it when cancelled so restarts don't wait for it
+		if ctx.Err() == nil && twoOpt.due(gen) {
+			debugf("[GA] Starting 2-opt for gen %d (topCount=%d)", gen, twoOptCount)
+
+			twoOptStart := time.Now()
 
-		shouldRunTwoOpt := gen >= twoOptStartGen && (gen == twoOptStartGen || (gen-twoOptStartGen)%twoOptIntervalGens == 0)
-		if shouldRunTwoOpt {
-			topCount := int(float64(populationSize) * elitePercentage)
-			if topCount < 2 {
-				topCount = 2
+			deadline := time.Time{}
+			if config.TwoOptBudgetMs > 0 && !seeded {
+				deadline = twoOptStart.Add(time.Duration(config.TwoOptBudgetMs) * time.Millisecond)
 			}
-			debugf("[GA] Starting 2-opt for gen %d (topCount=%d)", gen, topCount)
-			for i := range topCount {
+
+			before := scoredPopulation[0].Score
+
+			for i := range twoOptCount {
 				workerPool.submit(func() {
-					twoOptImprove(scoredPopulation[i].Genes, config, gaCtx)
+					twoOptImprove(scoredPopulation[i].Genes, scratch.exhausted[i], deadline, config, gaCtx)
+					scoredPopulation[i].Score = calculateFitness(scoredPopulation[i].Genes, config, gaCtx)
 				})
 			}
 			workerPool.wait()
-			debugf("[GA] 2-opt complete for gen %d", gen)
+
+			slices.SortFunc(scoredPopulation[:twoOptCount], func(a, b Individual) int { return a.Compare(b) })
+
+			twoOpt.record(gen, time.Now(), time.Since(twoOptStart), before-scoredPopulation[0].Score, config.TwoOptAdaptive && !seeded)
+			debugf("[GA] 2-opt complete for gen %d (next at gen %d)", gen, twoOpt.next)
 		}
 
 		fitnessImproved := false
 		if scoredPopulation[0].Score < bestFitness {
 			bestFitness = scoredPopulation[0].Score
-			bestIndividual = slices.Clone(scoredPopulation[0].Genes)
+			bestIndividual = append(bestIndividual[:0], scoredPopulation[0].Genes...)
 			generationsWithoutImprovement = 0
 			fitnessImproved = true
 		} else {
 			generationsWithoutImprovement++
 		}
 
+		if gaCtx.landscape != nil && gaCtx.landscape.due(gen) {
+			gaCtx.landscape.record(gen, generationsWithoutImprovement, scoredPopulation)
+		}
+
 		if updateChan != nil && (fitnessImproved || gen%updateIntervalGenerations == 0) {
 			now := time.Now()
 			elapsed := now.Sub(lastGenTime).Seconds()
@@ -304,6 +498,7 @@ loop:
 				BestPlaylist: slices.Clone(bestIndividual),
 				GenPerSec:    genPerSec,
 				Breakdown:    breakdown,
+				Flags:        playlist.PositionFlags(bestIndividual, gaCtx.trackFlags),
 			}:
 			default:
 			}
@@ -322,96 +517,356 @@ loop:
 			worstIdx := len(scoredPopulation) - 1 - i
 			copy(scoredPopulation[worstIdx].Genes, scoredPopulation[0].Genes)
 			for range immigrantSwaps {
-				a := rand.IntN(genesLen)
-				b := rand.IntN(genesLen)
+				a := rng.IntN(genesLen)
+				b := rng.IntN(genesLen)
 				scoredPopulation[worstIdx].Genes[a], scoredPopulation[worstIdx].Genes[b] = scoredPopulation[worstIdx].Genes[b], scoredPopulation[worstIdx].Genes[a]
 			}
+			gaCtx.locked.apply(scoredPopulation[worstIdx].Genes)
 			scoredPopulation[worstIdx].Score = calculateFitness(scoredPopulation[worstIdx].Genes, config, gaCtx)
 		}
 
-		parents := make([][]playlist.Track, populationSize)
+		scratch.shareFitness(scoredPopulation, config.NicheRadius)
+		scratch.breed(scoredPopulation, nextGen, rng)
 
-		parents[0] = scoredPopulation[0].Genes
-		parents[1] = scoredPopulation[1].Genes
+		mutationRate := minMutationRate + (float64(generationsWithoutImprovement)/mutationDecayGen)*(maxMutationRate-minMutationRate)
+		if mutationRate > maxMutationRate {
+			mutationRate = maxMutationRate
+		}
 
-		for i := 2; i < len(scoredPopulation); i++ {
-			bestIdx := rand.IntN(len(scoredPopulation))
-			bestScore := scoredPopulation[bestIdx].Score
-			for j := 1; j < tournamentSize; j++ {
-				idx := rand.IntN(len(scoredPopulation))
-				if scoredPopulation[idx].Score < bestScore {
-					bestIdx = idx
-					bestScore = scoredPopulation[idx].Score
-				}
+		for i := 2; i < populationSize; i++ {
+			if rng.Float64() < mutationRate {
+				mutate(nextGen[i], rng)
 			}
-			parents[i] = scoredPopulation[bestIdx].Genes
+
+			// Crossover and mutation move locked tracks like any other
+			gaCtx.locked.apply(nextGen[i])
 		}
 
-		copy(nextGen[0], scoredPopulation[0].Genes)
-		copy(nextGen[1], scoredPopulation[1].Genes)
+		currentGen, nextGen = nextGen, currentGen
+
+		debugf("[GA] Generation %d complete", gen)
+		gen++
+
+		throttle.pause(ctx, time.Since(genStart))
+	}
+
+	return bestIndividual, nil
+}
+
+// gaScratch holds the buffers a GA run reuses every generation, so breeding doesn't allocate
+type gaScratch struct {
+	parents   [][]playlist.Track // Tournament winners (aliases of population genes, not copies)
+	shared    []float64          // Selection score per population position after fitness sharing
+	niches    []neighbours       // Neighbours in each elite, to measure distances to it
+	present   []bool             // Crossover duplicate check by Track.Index
+	exhausted [][]bool           // 2-opt don't-look bits per elite, since elites are improved in parallel
+}
+
+// newGAScratch allocates the buffers for a population of genesLen-track individuals whose indices
+// are below numIndices (the edge cache size), twoOptCount of which get 2-opt
+func newGAScratch(populationSize, genesLen, numIndices, twoOptCount int) *gaScratch {
+	s := &gaScratch{
+		parents:   make([][]playlist.Track, populationSize),
+		shared:    make([]float64, populationSize),
+		niches:    make([]neighbours, twoOptCount),
+		present:   make([]bool, numIndices),
+		exhausted: make([][]bool, twoOptCount),
+	}
+
+	for i := range s.exhausted {
+		s.exhausted[i] = make([]bool, genesLen)
+	}
+
+	for i := range s.niches {
+		s.niches[i] = neighbours{next: make([]int, numIndices), prev: make([]int, numIndices)}
+	}
+
+	return s
+}
+
+// neighbours records each track's successor and predecessor in an ordering, by Track.Index (-1 at the ends)
+type neighbours struct {
+	next, prev []int
+}
 
-		for i := 2; i < len(parents)-1; i += 2 {
-			orderCrossover(nextGen[i], parents[i], parents[i+1], presentMap)
-			orderCrossover(nextGen[i+1], parents[i+1], parents[i], presentMap)
+// set records the neighbours in order
+func (nb *neighbours) set(order []playlist.Track) {
+	for i := range order {
+		next, prev := -1, -1
+		if i+1 < len(order) {
+			next = order[i+1].Index
 		}
-		if len(parents)%2 == 1 {
-			orderCrossover(nextGen[len(parents)-1], parents[len(parents)-1], parents[0], presentMap)
+
+		if i > 0 {
+			prev = order[i-1].Index
 		}
 
-		mutationRate := minMutationRate + (float64(generationsWithoutImprovement)/mutationDecayGen)*(maxMutationRate-minMutationRate)
-		if mutationRate > maxMutationRate {
-			mutationRate = maxMutationRate
+		nb.next[order[i].Index], nb.prev[order[i].Index] = next, prev
+	}
+}
+
+// distance returns the share of order's transitions missing from the recorded ordering in either
+// direction: 0 for the same ordering (or its reverse), 1 when no transition is shared
+func (nb *neighbours) distance(order []playlist.Track) float64 {
+	if len(order) < 2 {
+		return 0
+	}
+
+	missing := 0
+
+	for i := 1; i < len(order); i++ {
+		a, b := order[i-1].Index, order[i].Index
+		if nb.next[a] != b && nb.prev[a] != b {
+			missing++
 		}
+	}
 
-		for i := 2; i < populationSize; i++ {
-			if rand.Float64() < mutationRate {
-				if rand.Uint32()&1 == 0 {
-					numSwaps := minSwapMutations + rand.IntN(maxSwapMutations-minSwapMutations+1)
-					for range numSwaps {
-						a := rand.IntN(genesLen)
-						b := rand.IntN(genesLen)
-						nextGen[i][a], nextGen[i][b] = nextGen[i][b], nextGen[i][a]
-					}
-				} else {
-					start := rand.IntN(genesLen)
-					end := rand.IntN(genesLen)
-					if start > end {
-						start, end = end, start
-					}
-					reverseSegment(nextGen[i], start, end)
-				}
+	return float64(missing) / float64(len(order)-1)
+}
+
+// nearestNiche returns order's distance to the closest of the first count elites (1 without any)
+func (s *gaScratch) nearestNiche(order []playlist.Track, count int) float64 {
+	nearest := 1.0
+	for k := range count {
+		nearest = min(nearest, s.niches[k].distance(order))
+	}
+
+	return nearest
+}
+
+// spreadElites reorders the score-sorted population so each elite slot holds the best individual at
+// least radius away from the elites before it (crowding), falling back to the best remaining one when
+// no such individual exists. The best individual stays first; radius 0 leaves the order alone.
+func (s *gaScratch) spreadElites(population []Individual, radius float64) {
+	if radius <= 0 {
+		return
+	}
+
+	elites := min(len(s.niches), len(population))
+
+	for k := range elites {
+		for j := k; j < len(population); j++ {
+			if s.nearestNiche(population[j].Genes, k) < radius {
+				continue
+			}
+
+			// Move j into slot k, shifting the ones in between back to keep them in score order
+			if j != k {
+				ind := population[j]
+				copy(population[k+1:j+1], population[k:j])
+				population[k] = ind
 			}
+
+			break
 		}
 
-		currentGen, nextGen = nextGen, currentGen
+		s.niches[k].set(population[k].Genes)
+	}
+}
 
-		debugf("[GA] Generation %d complete", gen)
-		gen++
+// shareFitness sets the selection scores: an individual closer than radius to an elite has its score
+// raised in proportion to how close it is, so tournaments favour the unexplored over near-duplicates
+func (s *gaScratch) shareFitness(population []Individual, radius float64) {
+	elites := min(len(s.niches), len(population))
+	if radius <= 0 {
+		elites = 0
+	}
+
+	for k := range elites {
+		s.niches[k].set(population[k].Genes)
+	}
+
+	for i := range population {
+		s.shared[i] = population[i].Score
+
+		if i < elites {
+			continue
+		}
+
+		if d := s.nearestNiche(population[i].Genes, elites); d < radius {
+			s.shared[i] += math.Abs(population[i].Score) * nicheSharingPenalty * (1 - d/radius)
+		}
+	}
+}
+
+// breed fills nextGen from the sorted population: the two best carry over unchanged, the rest are
+// crossovers of parents selected by tournament on their shared scores (see shareFitness)
+func (s *gaScratch) breed(population []Individual, nextGen [][]playlist.Track, rng *rand.Rand) {
+	parents := s.parents
+
+	parents[0] = population[0].Genes
+	parents[1] = population[1].Genes
+
+	for i := 2; i < len(population); i++ {
+		bestIdx := rng.IntN(len(population))
+		bestScore := s.shared[bestIdx]
+		for j := 1; j < tournamentSize; j++ {
+			idx := rng.IntN(len(population))
+			if s.shared[idx] < bestScore {
+				bestIdx = idx
+				bestScore = s.shared[idx]
+			}
+		}
+		parents[i] = population[bestIdx].Genes
 	}
 
-	return bestIndividual
+	copy(nextGen[0], population[0].Genes)
+	copy(nextGen[1], population[1].Genes)
+
+	for i := 2; i < len(parents)-1; i += 2 {
+		orderCrossover(nextGen[i], parents[i], parents[i+1], s.present, rng)
+		orderCrossover(nextGen[i+1], parents[i+1], parents[i], s.present, rng)
+	}
+	if len(parents)%2 == 1 {
+		orderCrossover(nextGen[len(parents)-1], parents[len(parents)-1], parents[0], s.present, rng)
+	}
+}
+
+// permutationHash returns an FNV-1a hash over the tracks' cache indices, identifying an ordering
+func permutationHash(tracks []playlist.Track) uint64 {
+	const (
+		fnvOffset = 14695981039346656037
+		fnvPrime  = 1099511628211
+	)
+
+	h := uint64(fnvOffset)
+	for i := range tracks {
+		h ^= uint64(tracks[i].Index) //nolint:gosec // Cache indices are non-negative
+		h *= fnvPrime
+	}
+
+	return h
 }
 
 // updateNormalizedWeights pre-calculates normalized weight values to avoid division in hot path
 func updateNormalizedWeights(ctx *GAContext, config config.GAConfig) {
-	norm := &ctx.normalizers
+	ctx.weights = normalizedWeights(&ctx.normalizers, config)
+
+	// Genre names resolve through the playlist package's genre aliases, which another playlist's
+	// load may have replaced since (concurrent server jobs), so they are only resolved again when
+	// the overridden genres change. InitializePlaylist resolves them while its aliases are set.
+	if keys := slices.Sorted(maps.Keys(config.GenreOverrides)); !slices.Equal(keys, ctx.genreKeys) {
+		ctx.genreKeys = keys
+		ctx.genreSources, ctx.genreNames = resolveGenreOverrides(config)
+
+		ctx.genreOfTrack = nil
+		if ctx.genreNames != nil {
+			ctx.genreOfTrack = make([]int, len(ctx.genres))
+			for i, genre := range ctx.genres {
+				ctx.genreOfTrack[i] = genreOverrideIndex(genre, ctx.genreNames)
+			}
+		}
+	}
+
+	ctx.genreWeights = genreOverrideWeights(&ctx.normalizers, config, ctx.genreSources)
 
-	ctx.weights.harmonicFactor = config.HarmonicWeight / norm.MaxHarmonic
-	ctx.weights.energyFactor = config.EnergyDeltaWeight / norm.MaxEnergyDelta
-	ctx.weights.bpmFactor = config.BPMDeltaWeight / norm.MaxBPMDelta
-	ctx.weights.artistPenaltyRatio = config.SameArtistPenalty / norm.MaxSameArtist
-	ctx.weights.albumPenaltyRatio = config.SameAlbumPenalty / norm.MaxSameAlbum
-	ctx.weights.positionBiasFactor = config.LowEnergyBiasWeight / norm.MaxPositionBias
+	buildEdgeCosts(ctx)
+}
 
-	ctx.weights.genreEnabled = config.GenreWeight != 0 && norm.MaxGenreChange > 0
-	if ctx.weights.genreEnabled {
-		ctx.weights.genreAbsWeight = math.Abs(config.GenreWeight) / norm.MaxGenreChange
+// resolveGenreOverrides normalizes the overridden genres of cfg, returning the genre_overrides key
+// behind each distinct genre with a lookup from lowercase genre name to its index (both nil without
+// overrides)
+func resolveGenreOverrides(cfg config.GAConfig) ([]string, map[string]int) {
+	if len(cfg.GenreOverrides) == 0 {
+		return nil, nil
+	}
+
+	var sources []string
+
+	names := make(map[string]int, len(cfg.GenreOverrides))
+
+	for _, genre := range slices.Sorted(maps.Keys(cfg.GenreOverrides)) {
+		name := playlist.NormalizeGenre(genre)
+		if _, dup := names[name]; dup || name == "" {
+			continue
+		}
+
+		names[name] = len(sources)
+		sources = append(sources, genre)
+	}
+
+	return sources, names
+}
+
+// genreOverrideWeights normalizes the weights of the genre overrides named by sources, in order
+func genreOverrideWeights(norm *FitnessNormalizers, cfg config.GAConfig, sources []string) []NormalizedWeights {
+	if len(sources) == 0 {
+		return nil
+	}
+
+	weights := make([]NormalizedWeights, len(sources))
+	for i, genre := range sources {
+		weights[i] = normalizedWeights(norm, cfg.GenreOverrides[genre].Apply(cfg))
+	}
+
+	return weights
+}
+
+// genreOverrideIndex returns the override of genre or its nearest overridden parent genre (-1 if none)
+func genreOverrideIndex(genre string, names map[string]int) int {
+	for _, ancestor := range playlist.GenreAncestors(genre) {
+		if k, ok := names[ancestor]; ok {
+			return k
+		}
+	}
+
+	return -1
+}
+
+// edgeWeights returns the weights for the transition idx1 -> idx2: a genre override's when both
+// tracks resolve to the same overridden genre, the global weights otherwise
+func (ctx *GAContext) edgeWeights(idx1, idx2 int) *NormalizedWeights {
+	if ctx.genreOfTrack != nil {
+		if k := ctx.genreOfTrack[idx1]; k >= 0 && k == ctx.genreOfTrack[idx2] {
+			return &ctx.genreWeights[k]
+		}
+	}
+
+	return &ctx.weights
+}
+
+// normalizedWeights divides each configured weight by its component's normalizer
+func normalizedWeights(norm *FitnessNormalizers, config config.GAConfig) NormalizedWeights {
+	w := NormalizedWeights{
+		harmonicFactor:     weightRatio(config.HarmonicWeight, norm.MaxHarmonic),
+		energyFactor:       weightRatio(config.EnergyDeltaWeight, norm.MaxEnergyDelta),
+		bpmFactor:          weightRatio(config.BPMDeltaWeight, norm.MaxBPMDelta),
+		artistPenaltyRatio: weightRatio(config.SameArtistPenalty, norm.MaxSameArtist),
+		albumPenaltyRatio:  weightRatio(config.SameAlbumPenalty, norm.MaxSameAlbum),
+		positionBiasFactor: weightRatio(config.LowEnergyBiasWeight, norm.MaxPositionBias),
+		shuffleFactor:      weightRatio(config.ShuffleTemperature, norm.MaxShuffle),
+		noveltyFactor:      weightRatio(config.NoveltyWeight, norm.MaxNovelty),
+		mixLengthFactor:    weightRatio(config.MixLengthWeight, norm.MaxMixLength),
+		bpmBandFactor:      weightRatio(config.BPMBandWeight, norm.MaxBPMBand),
+		bpmBandWidth:       config.BandWidth(),
+	}
+
+	if !config.FreshFront {
+		w.freshnessFactor = weightRatio(config.FreshnessWeight, norm.MaxFreshness)
+	}
+
+	w.genreEnabled = config.GenreWeight != 0 && norm.MaxGenreChange > 0
+	if w.genreEnabled {
+		w.genreAbsWeight = math.Abs(config.GenreWeight) / norm.MaxGenreChange
 		if config.GenreWeight > 0 {
-			ctx.weights.genreSign = 1.0
+			w.genreSign = 1.0
+			w.genreBlocks = config.GenreBlocks
 		} else {
-			ctx.weights.genreSign = -1.0
+			w.genreSign = -1.0
 		}
 	}
+
+	return w
+}
+
+// weightRatio divides a weight by its normalizer. A zero normalizer means the component is zero for
+// every transition (e.g. all tracks share one energy), so it contributes nothing instead of NaN.
+func weightRatio(weight, normalizer float64) float64 {
+	if normalizer == 0 {
+		return 0
+	}
+
+	return weight / normalizer
 }
 
 // buildEdgeFitnessCache pre-calculates base values for track pairs (weights applied at eval time)
@@ -419,92 +874,406 @@ func buildEdgeFitnessCache(tracks []playlist.Track) *GAContext {
 	n := len(tracks)
 
 	ctx := &GAContext{
-		edgeCache: make([][]EdgeData, n),
+		edgeCache:   make([][]EdgeData, n),
+		normalizers: computeNormalizers(tracks),
 	}
 
+	ctx.genres = make([]string, n)
+
 	for i := range ctx.edgeCache {
 		ctx.edgeCache[i] = make([]EdgeData, n)
+		ctx.genres[i] = tracks[i].Genre
 	}
 
+	genres, genreIDs := internGenres(ctx.genres)
+
 	for i := range n {
 		for j := range n {
-			if i == j {
-				continue
+			if i != j {
+				ctx.edgeCache[i][j] = computeEdgeWithGenre(&tracks[i], &tracks[j], genres.Distance(genreIDs[i], genreIDs[j]))
 			}
+		}
+	}
 
-			t1, t2 := &tracks[i], &tracks[j]
+	return ctx
+}
 
-			harmonicDist := playlist.HarmonicDistanceParsed(t1.ParsedKey, t2.ParsedKey)
+// edgeCacheStore keeps the edge cache of the last run, so a run after a TUI edit only computes the
+// transitions of added or changed tracks instead of rebuilding all n² of them
+type edgeCacheStore struct {
+	mu     sync.Mutex
+	edges  [][]EdgeData
+	tracks []playlist.Track // Tracks the edges were computed for, by index
+}
 
-			sameArtist := t1.Artist == t2.Artist
-			sameAlbum := t1.Album == t2.Album
+// build returns a GA context for tracks (indexed by position), reusing the stored edges of tracks
+// that are still present and unchanged, and stores the new edges for the next run
+func (s *edgeCacheStore) build(tracks []playlist.Track) *GAContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-			energyDelta := math.Abs(float64(t1.Energy - t2.Energy))
+	ctx := remapEdgeFitnessCache(s.edges, s.tracks, tracks)
+	s.edges, s.tracks = ctx.edgeCache, slices.Clone(tracks)
 
-			bpmDelta := 0.0
-			if t1.BPM > 0 && t2.BPM > 0 {
-				bpmDelta = minBPMDistance(t1.BPM, t2.BPM)
-			}
+	return ctx
+}
+
+// remapEdgeFitnessCache is buildEdgeFitnessCache reusing edges computed for prevTracks: transitions
+// between two tracks found in prevTracks (by Identity, with the same metadata) are copied from their old
+// row and column, so deleting or reordering tracks costs a copy instead of recomputing every edge.
+func remapEdgeFitnessCache(prevEdges [][]EdgeData, prevTracks, tracks []playlist.Track) *GAContext {
+	if len(prevEdges) == 0 {
+		return buildEdgeFitnessCache(tracks)
+	}
+
+	prevIndex := make(map[string]int, len(prevTracks))
+	for i := range prevTracks {
+		prevIndex[prevTracks[i].Identity()] = i
+	}
+
+	// Old index of each track, or -1 for tracks that are new or whose metadata changed (reload)
+	from := make([]int, len(tracks))
+	for i := range tracks {
+		from[i] = -1
+		if k, ok := prevIndex[tracks[i].Identity()]; ok && sameEdgeInputs(&prevTracks[k], &tracks[i]) {
+			from[i] = k
+		}
+	}
+
+	n := len(tracks)
+
+	ctx := &GAContext{
+		edgeCache:   make([][]EdgeData, n),
+		normalizers: computeNormalizers(tracks),
+		genres:      make([]string, n),
+	}
 
-			genreDiff := playlist.GenreSimilarity(t1.Genre, t2.Genre)
+	for i := range tracks {
+		ctx.genres[i] = tracks[i].Genre
+	}
+
+	genres, genreIDs := internGenres(ctx.genres)
+
+	for i := range n {
+		row := make([]EdgeData, n)
 
-			ctx.edgeCache[i][j] = EdgeData{
-				HarmonicDistance: harmonicDist,
-				SameArtist:       sameArtist,
-				SameAlbum:        sameAlbum,
-				EnergyDelta:      energyDelta,
-				BPMDelta:         bpmDelta,
-				GenreDifference:  genreDiff,
+		for j := range n {
+			switch {
+			case i == j:
+			case from[i] >= 0 && from[j] >= 0:
+				row[j] = prevEdges[from[i]][from[j]]
+			default:
+				row[j] = computeEdgeWithGenre(&tracks[i], &tracks[j], genres.Distance(genreIDs[i], genreIDs[j]))
 			}
 		}
+
+		ctx.edgeCache[i] = row
 	}
 
-	ctx.normalizers.MaxHarmonic = camelotWheelPositions * float64(n-1)
+	return ctx
+}
+
+// sameEdgeInputs reports whether two versions of a track agree on every field computeEdge reads
+func sameEdgeInputs(a, b *playlist.Track) bool {
+	sameKey := func(x, y *playlist.CamelotKey) bool {
+		return x == y || (x != nil && y != nil && *x == *y)
+	}
 
-	ctx.normalizers.MaxSameArtist = float64(n - 1)
-	ctx.normalizers.MaxSameAlbum = float64(n - 1)
+	return a.Artist == b.Artist && a.Album == b.Album && a.Genre == b.Genre && a.Energy == b.Energy &&
+		a.BPM == b.BPM && a.Intro == b.Intro && a.Outro == b.Outro && a.Fresh == b.Fresh &&
+		sameKey(a.InKey(), b.InKey()) && sameKey(a.OutKey(), b.OutKey())
+}
+
+// internGenres builds the genre distance matrix of a playlist and returns it with each track's genre ID
+func internGenres(genres []string) (*playlist.GenreMatrix, []int) {
+	m := playlist.NewGenreMatrix(genres)
+
+	ids := make([]int, len(genres))
+	for i, g := range genres {
+		ids[i] = m.ID(g)
+	}
 
-	minEnergy, maxEnergy := float64(tracks[0].Energy), float64(tracks[0].Energy)
+	return m, ids
+}
+
+// computeEdge calculates the unweighted component values of the transition t1 -> t2.
+// Harmonic distance runs from t1's end key to t2's start key, so modulating tracks make edges asymmetric.
+func computeEdge(t1, t2 *playlist.Track) EdgeData {
+	return computeEdgeWithGenre(t1, t2, playlist.GenreSimilarity(t1.Genre, t2.Genre))
+}
+
+// computeEdgeWithGenre is computeEdge with the genre difference already known (see internGenres)
+func computeEdgeWithGenre(t1, t2 *playlist.Track, genreDifference float64) EdgeData {
+	// Missing values (BPM or energy 0, no artist/album) are neutral rather than extreme
+	bpmDelta := 0.0
+	if t1.BPM > 0 && t2.BPM > 0 {
+		bpmDelta = minBPMDistance(t1.BPM, t2.BPM)
+	}
+
+	energyDelta := 0.0
+	if t1.Energy > 0 && t2.Energy > 0 {
+		energyDelta = math.Abs(float64(t1.Energy - t2.Energy))
+	}
+
+	return EdgeData{
+		HarmonicDistance: playlist.HarmonicDistanceParsed(t1.OutKey(), t2.InKey()),
+		SameArtist:       t1.Artist != "" && t1.Artist == t2.Artist,
+		SameAlbum:        t1.Album != "" && t1.Album == t2.Album,
+		EnergyDelta:      energyDelta,
+		BPMDelta:         bpmDelta,
+		GenreDifference:  genreDifference,
+		MixMismatch:      playlist.MixLengthMismatch(t1.Outro, t2.Intro),
+		FromBPM:          float32(t1.BPM),
+		ToBPM:            float32(t2.BPM),
+		BothFresh:        t1.Fresh && t2.Fresh,
+	}
+}
+
+// computeNormalizers calculates the worst-case value of each component over a playlist of tracks.
+// Runs in O(n²) time but constant memory, so it also works for playlists too large to cache.
+//
+// Only tracks that have the data count: missing energy or BPM (0) doesn't stretch the ranges. A
+// component whose data is absent (fewer than two tracks with a key, BPM or energy; every energy
+// equal) gets a zero normalizer, which disables it (see weightRatio).
+func computeNormalizers(tracks []playlist.Track) FitnessNormalizers {
+	n := len(tracks)
+	if n == 0 {
+		return FitnessNormalizers{}
+	}
+
+	var (
+		minEnergy, maxEnergy float64
+		withEnergy, withKey  int
+	)
+
+	for i := range tracks {
+		if tracks[i].InKey() != nil || tracks[i].OutKey() != nil {
+			withKey++
+		}
 
-	for i := 1; i < n; i++ {
 		e := float64(tracks[i].Energy)
-		if e < minEnergy {
-			minEnergy = e
+		if e <= 0 {
+			continue
 		}
 
-		if e > maxEnergy {
-			maxEnergy = e
+		if withEnergy == 0 || e < minEnergy {
+			minEnergy = e
 		}
+
+		maxEnergy = max(maxEnergy, e)
+		withEnergy++
 	}
 
-	ctx.normalizers.MaxEnergyDelta = (maxEnergy - minEnergy) * float64(n-1)
+	favorites, fresh := 0, 0
+
+	for i := range n {
+		if tracks[i].Favorite {
+			favorites++
+		}
+
+		if tracks[i].Fresh {
+			fresh++
+		}
+	}
 
 	maxBPMDist := 0.0
+	withBPM := 0
 
 	for i := range n {
+		if tracks[i].BPM <= 0 {
+			continue
+		}
+
+		withBPM++
+
 		for j := range n {
-			if i != j && tracks[i].BPM > 0 && tracks[j].BPM > 0 {
-				if ctx.edgeCache[i][j].BPMDelta > maxBPMDist {
-					maxBPMDist = ctx.edgeCache[i][j].BPMDelta
+			if i != j && tracks[j].BPM > 0 {
+				maxBPMDist = max(maxBPMDist, minBPMDistance(tracks[i].BPM, tracks[j].BPM))
+			}
+		}
+	}
+
+	transitions := float64(n - 1)
+
+	norm := FitnessNormalizers{
+		MaxHarmonic:     camelotWheelPositions * transitions,
+		MaxSameArtist:   transitions,
+		MaxSameAlbum:    transitions,
+		MaxEnergyDelta:  (maxEnergy - minEnergy) * transitions,
+		MaxBPMDelta:     maxBPMDist * transitions,
+		MaxPositionBias: maxEnergy,
+		MaxGenreChange:  transitions,
+		MaxShuffle:      transitions,
+		MaxNovelty:      transitions,
+		MaxMixLength:    transitions,
+		MaxEnergyWave:   float64(n) * max(maxEnergy-minEnergy, 1),
+		MaxBPMBand:      transitions,
+		MaxFavorite:     float64(favorites),
+		MaxFreshness:    float64(fresh),
+		MaxBookends:     2,
+		MinEnergy:       minEnergy,
+		MaxEnergy:       maxEnergy,
+	}
+
+	// Without two keyed tracks every transition is equally incompatible: nothing to optimize
+	if withKey < 2 {
+		norm.MaxHarmonic = 0
+	}
+
+	if withBPM < 2 {
+		norm.MaxBPMBand = 0
+	}
+
+	if withEnergy == 0 {
+		norm.MaxEnergyWave = 0
+	}
+
+	return norm
+}
+
+// neutralEnergy returns energy, or the middle of the playlist's energy range for a track without
+// one (0), so the position-dependent components neither favor nor punish it at any position
+func (n *FitnessNormalizers) neutralEnergy(energy int) float64 {
+	if energy > 0 {
+		return float64(energy)
+	}
+
+	return (n.MinEnergy + n.MaxEnergy) / 2
+}
+
+// disabledComponents names the weighted components switched off because the playlist lacks their
+// data (see computeNormalizers)
+func (n *FitnessNormalizers) disabledComponents(cfg config.GAConfig) []string {
+	var disabled []string
+
+	for _, c := range []struct {
+		name       string
+		weight     float64
+		normalizer float64
+	}{
+		{"harmonic (no keys)", cfg.HarmonicWeight, n.MaxHarmonic},
+		{"energy delta (no energy range)", cfg.EnergyDeltaWeight, n.MaxEnergyDelta},
+		{"BPM delta (no BPM range)", cfg.BPMDeltaWeight, n.MaxBPMDelta},
+		{"position bias (no energy)", cfg.LowEnergyBiasWeight, n.MaxPositionBias},
+		{"energy wave (no energy)", cfg.EnergyWaveWeight, n.MaxEnergyWave},
+		{"BPM band (no BPM)", cfg.BPMBandWeight, n.MaxBPMBand},
+		{"favorites (none marked)", cfg.FavoriteWeight, n.MaxFavorite},
+		{"freshness (no recent tracks)", cfg.FreshnessWeight, n.MaxFreshness},
+	} {
+		if c.weight != 0 && c.normalizer == 0 {
+			disabled = append(disabled, c.name)
+		}
+	}
+
+	return disabled
+}
+
+// markPreviousAdjacencies flags transitions that were adjacent in a previous ordering (given as track
+// paths), so the novelty component can steer repeated runs away from reproducing the same pairs.
+// Paths not in tracks are ignored; a path listed more than once marks the transitions of each entry.
+func markPreviousAdjacencies(ctx *GAContext, tracks []playlist.Track, previousOrder []string) {
+	indicesByPath := make(map[string][]int, len(tracks))
+	for _, t := range tracks {
+		indicesByPath[t.Path] = append(indicesByPath[t.Path], t.Index)
+	}
+
+	for i := 1; i < len(previousOrder); i++ {
+		for _, a := range indicesByPath[previousOrder[i-1]] {
+			for _, b := range indicesByPath[previousOrder[i]] {
+				if a != b {
+					ctx.edgeCache[a][b].PreviousAdjacent = true
+					ctx.edgeCache[b][a].PreviousAdjacent = true
 				}
 			}
 		}
 	}
+}
 
-	ctx.normalizers.MaxBPMDelta = maxBPMDist * float64(n-1)
+// addSeedOrders adds orderings of tracks (given as track paths, like a hand-curated playlist or the
+// output of another tool) to the GA's first generation. Paths not in tracks are ignored and tracks
+// missing from an ordering follow it in their current order. Orderings sharing no track with tracks
+// are skipped; returns how many were added.
+func addSeedOrders(ctx *GAContext, tracks []playlist.Track, orders [][]string) int {
+	// Entries by path in playlist order, so a file listed twice is placed once per listing
+	byPath := make(map[string][]int, len(tracks))
+	for i, t := range tracks {
+		byPath[t.Path] = append(byPath[t.Path], i)
+	}
 
-	ctx.normalizers.MaxGenreChange = float64(n - 1)
+	added := 0
+	used := make([]bool, len(tracks))
 
-	ctx.normalizers.MaxPositionBias = maxEnergy
+	for _, order := range orders {
+		clear(used)
 
-	return ctx
+		seed := make([]playlist.Track, 0, len(tracks))
+
+		for _, path := range order {
+			if k := slices.IndexFunc(byPath[path], func(i int) bool { return !used[i] }); k >= 0 {
+				i := byPath[path][k]
+				seed = append(seed, tracks[i])
+				used[i] = true
+			}
+		}
+
+		if len(seed) == 0 {
+			continue
+		}
+
+		for i := range tracks {
+			if !used[i] {
+				seed = append(seed, tracks[i])
+			}
+		}
+
+		ctx.seeds = append(ctx.seeds, seed)
+		added++
+	}
+
+	return added
 }
 
-// calculateFitness computes the fitness score for a given playlist ordering
+// applyShuffleNoise draws new random jitter for every transition. Jitter is bounded and scaled by
+// ShuffleTemperature like any other component, so harmonic/energy constraints still dominate at low
+// temperatures while near-equal orderings are broken differently on each run.
+func applyShuffleNoise(ctx *GAContext, rng *rand.Rand) {
+	n := len(ctx.edgeCache)
+
+	ctx.edgeNoise = make([][]float64, n)
+	for i := range ctx.edgeNoise {
+		ctx.edgeNoise[i] = make([]float64, n)
+		for j := range ctx.edgeNoise[i] {
+			if i != j {
+				ctx.edgeNoise[i][j] = rng.Float64()
+			}
+		}
+	}
+}
+
+// calculateFitness computes the fitness score for a given playlist ordering.
+// Sums the flat edgeCost array (one load per transition) instead of re-weighting each component;
+// the result matches calculateFitnessWithBreakdown's Total up to float rounding.
 func calculateFitness(individual []playlist.Track, config config.GAConfig, ctx *GAContext) float64 {
-	breakdown := calculateFitnessWithBreakdown(individual, config, ctx)
+	if ctx.edgeCost == nil || len(individual) == 0 {
+		return calculateFitnessWithBreakdown(individual, config, ctx).Total
+	}
+
+	n := ctx.numTracks
+	cost := ctx.edgeCost
+	total := 0.0
+
+	row := individual[0].Index * n
+	for j := 1; j < len(individual); j++ {
+		idx := individual[j].Index
+		total += cost[row+idx]
+		row = idx * n
+	}
 
-	return breakdown.Total
+	for j := range individual {
+		total += positionalCost(&individual[j], j, len(individual), config, ctx).total()
+	}
+
+	return total
 }
 
 // calculateFitnessWithBreakdown computes fitness and returns detailed breakdown
@@ -513,14 +1282,15 @@ func calculateFitnessWithBreakdown(individual []playlist.Track, config config.GA
 }
 
 // orderCrossover (OX) creates offspring by preserving order from parents.
-// Copies random substring from parent1, fills rest from parent2 in order.
-func orderCrossover(dst, parent1, parent2 []playlist.Track, present map[string]bool) {
+// Copies random substring from parent1, fills rest from parent2 in order. present is a scratch
+// buffer indexed by Track.Index, covering every index in the parents.
+func orderCrossover(dst, parent1, parent2 []playlist.Track, present []bool, rng *rand.Rand) {
 	numTracks := len(parent1)
 
 	clear(present)
 
-	cut1 := rand.IntN(numTracks)
-	cut2 := rand.IntN(numTracks)
+	cut1 := rng.IntN(numTracks)
+	cut2 := rng.IntN(numTracks)
 
 	if cut1 > cut2 {
 		cut1, cut2 = cut2, cut1
@@ -528,33 +1298,77 @@ func orderCrossover(dst, parent1, parent2 []playlist.Track, present map[string]b
 
 	for i := cut1; i <= cut2; i++ {
 		dst[i] = parent1[i]
-		present[parent1[i].Path] = true
+		present[parent1[i].Index] = true
 	}
 
 	dstIdx := (cut2 + 1) % numTracks
 
 	for i := range numTracks {
 		parent2Idx := (cut2 + 1 + i) % numTracks
-		if !present[parent2[parent2Idx].Path] {
+		if !present[parent2[parent2Idx].Index] {
 			dst[dstIdx] = parent2[parent2Idx]
 			dstIdx = (dstIdx + 1) % numTracks
 		}
 	}
 }
 
+// twoOptScheduler decides in which generations 2-opt runs: first at twoOptStartGen, then every
+// interval generations. Adaptive scheduling tunes the interval to what each run cost and gained.
+type twoOptScheduler struct {
+	next     int       // Generation of the next run
+	interval int       // Generations between runs
+	lastEnd  time.Time // When the previous run (or the GA) ended/started, to measure each run's share of time
+}
+
+// newTwoOptScheduler schedules the first run for a GA started at start
+func newTwoOptScheduler(start time.Time) *twoOptScheduler {
+	return &twoOptScheduler{next: twoOptStartGen, interval: twoOptIntervalGens, lastEnd: start}
+}
+
+// due reports whether 2-opt runs in generation gen
+func (s *twoOptScheduler) due(gen int) bool {
+	return gen >= s.next
+}
+
+// record schedules the next run after one in generation gen that ended at now, took cost and
+// improved the best score by gain. Without adaptive the fixed interval is kept.
+func (s *twoOptScheduler) record(gen int, now time.Time, cost time.Duration, gain float64, adaptive bool) {
+	if adaptive {
+		share := 1.0
+		if window := now.Sub(s.lastEnd); window > 0 {
+			share = float64(cost) / float64(window)
+		}
+
+		switch {
+		case gain <= floatingPointEpsilon || share > twoOptMaxShare:
+			s.interval = min(s.interval*2, twoOptMaxIntervalGens)
+		case share < twoOptMaxShare/2:
+			s.interval = max(s.interval/2, twoOptMinIntervalGens)
+		}
+	}
+
+	s.next = gen + s.interval
+	s.lastEnd = now
+}
+
 // twoOptImprove applies 2-opt local search by systematically testing segment reversals.
 // Uses delta evaluation (only recalc changed segment), don't-look-bits optimization,
-// and epsilon threshold to prevent floating point oscillation.
-func twoOptImprove(tracks []playlist.Track, config config.GAConfig, ctx *GAContext) {
+// and epsilon threshold to prevent floating point oscillation. positionsExhausted is a scratch
+// buffer of len(tracks) don't-look bits. The search stops early at deadline unless it is zero.
+// Segments containing a position pinned by ctx.locked are never reversed.
+func twoOptImprove(tracks []playlist.Track, positionsExhausted []bool, deadline time.Time, config config.GAConfig, ctx *GAContext) {
 	n := len(tracks)
 
-	positionsExhausted := make([]bool, n)
+	clear(positionsExhausted)
 
 	currentFitness := calculateFitness(tracks, config, ctx)
 
-	const maxIterations = 1000
+	const (
+		maxIterations = 1000
+		clockEvery    = 64 // Candidate reversals between deadline checks, so one long row can't overrun the budget
+	)
 
-	iteration := 0
+	iteration, candidates := 0, 0
 
 	improved := true
 	for improved && iteration < maxIterations {
@@ -562,13 +1376,21 @@ func twoOptImprove(tracks []playlist.Track, config config.GAConfig, ctx *GAConte
 		iteration++
 
 		for i := range n - 1 {
-			if positionsExhausted[i] {
+			if positionsExhausted[i] || ctx.locked.isPinned(i) {
 				continue
 			}
 
 			positionImproved := false
 
-			for j := i + 1; j < n; j++ {
+			for j := i + 1; j < n && !ctx.locked.isPinned(j); j++ {
+				if !deadline.IsZero() && candidates%clockEvery == 0 && time.Now().After(deadline) {
+					debugf("[2-OPT] Time budget used up after %d iterations", iteration)
+
+					return
+				}
+
+				candidates++
+
 				endPos := j + 1
 				if endPos >= n {
 					endPos = n - 1
@@ -614,55 +1436,348 @@ func segmentFitness(tracks []playlist.Track, start, end int, config config.GACon
 func segmentFitnessWithBreakdown(tracks []playlist.Track, start, end int, config config.GAConfig, ctx *GAContext) playlist.Breakdown {
 	var breakdown playlist.Breakdown
 
-	biasThreshold := int(float64(len(tracks)) * config.LowEnergyBiasPortion)
+	for j := start; j <= end; j++ {
+		if j > 0 {
+			addEdgeBreakdown(&breakdown, tracks[j-1].Index, tracks[j].Index, ctx)
+		}
+
+		positionalCost(&tracks[j], j, len(tracks), config, ctx).addTo(&breakdown)
+	}
 
-	// Use pre-normalized weights from ctx to avoid recalculation
-	w := &ctx.weights
+	breakdown.Total = breakdown.Sum()
 
-	for j := start; j <= end; j++ {
-		if j > 0 { //nolint:nestif
-			idx1 := tracks[j-1].Index
-			idx2 := tracks[j].Index
-			edge := ctx.edgeCache[idx1][idx2]
+	return breakdown
+}
 
-			// Use pre-normalized weights (no division in hot path)
-			breakdown.Harmonic += float64(edge.HarmonicDistance) * w.harmonicFactor
+// addEdgeBreakdown adds the weighted components of the transition idx1 -> idx2 to breakdown (Total untouched)
+func addEdgeBreakdown(breakdown *playlist.Breakdown, idx1, idx2 int, ctx *GAContext) {
+	noise := 0.0
+	if ctx.edgeNoise != nil {
+		noise = ctx.edgeNoise[idx1][idx2]
+	}
 
-			if edge.SameArtist {
-				breakdown.SameArtist += w.artistPenaltyRatio
-			}
+	addWeightedEdge(breakdown, &ctx.edgeCache[idx1][idx2], noise, ctx.edgeWeights(idx1, idx2))
+}
 
-			if edge.SameAlbum {
-				breakdown.SameAlbum += w.albumPenaltyRatio
-			}
+// addWeightedEdge adds the weighted components of one transition, and their raw counterparts, to
+// breakdown (Total untouched). noise is the transition's smart-shuffle jitter (0 when shuffle is off).
+func addWeightedEdge(breakdown *playlist.Breakdown, edge *EdgeData, noise float64, w *NormalizedWeights) {
+	addRawEdge(breakdown, edge, w)
 
-			breakdown.EnergyDelta += edge.EnergyDelta * w.energyFactor
+	breakdown.Harmonic += float64(edge.HarmonicDistance) * w.harmonicFactor
 
-			breakdown.BPMDelta += edge.BPMDelta * w.bpmFactor
+	if edge.SameArtist {
+		breakdown.SameArtist += w.artistPenaltyRatio
+	}
 
-			if w.genreEnabled {
-				rawPenalty := edge.GenreDifference
-				if w.genreSign < 0 {
-					rawPenalty = 1.0 - rawPenalty
-				}
+	if edge.SameAlbum {
+		breakdown.SameAlbum += w.albumPenaltyRatio
+	}
 
-				breakdown.GenreChange += rawPenalty * w.genreAbsWeight
-			}
+	breakdown.EnergyDelta += edge.EnergyDelta * w.energyFactor
+
+	breakdown.BPMDelta += edge.BPMDelta * w.bpmFactor
+
+	if w.genreEnabled {
+		rawPenalty := edge.GenreDifference
+
+		switch {
+		case w.genreBlocks:
+			rawPenalty = genreBoundaryPenalty(edge)
+		case w.genreSign < 0:
+			rawPenalty = 1.0 - rawPenalty
+		}
+
+		breakdown.GenreChange += rawPenalty * w.genreAbsWeight
+	}
+
+	if edge.PreviousAdjacent {
+		breakdown.Novelty += w.noveltyFactor
+	}
+
+	breakdown.MixLength += edge.MixMismatch * w.mixLengthFactor
+
+	if w.bpmBandFactor != 0 && bpmBandChange(edge.FromBPM, edge.ToBPM, w.bpmBandWidth) {
+		breakdown.BPMBand += w.bpmBandFactor
+	}
+
+	if edge.BothFresh {
+		breakdown.Freshness += w.freshnessFactor
+	}
+
+	breakdown.Shuffle += noise * w.shuffleFactor
+}
+
+// addRawEdge counts one transition in breakdown's raw fields
+func addRawEdge(breakdown *playlist.Breakdown, edge *EdgeData, w *NormalizedWeights) {
+	breakdown.Transitions++
+	breakdown.TotalEnergyChange += edge.EnergyDelta
+	breakdown.TotalBPMDrift += edge.BPMDelta
+
+	if playlist.IsHarmonicClash(edge.HarmonicDistance) {
+		breakdown.BadKeyTransitions++
+	}
+
+	if edge.GenreDifference > 0 {
+		breakdown.GenreChanges++
+	}
+
+	if edge.SameArtist {
+		breakdown.SameArtistAdjacencies++
+	}
+
+	if edge.SameAlbum {
+		breakdown.SameAlbumAdjacencies++
+	}
+
+	if edge.PreviousAdjacent {
+		breakdown.RepeatedAdjacencies++
+	}
+
+	if w.bpmBandFactor != 0 && bpmBandChange(edge.FromBPM, edge.ToBPM, w.bpmBandWidth) {
+		breakdown.BPMBandChanges++
+	}
+
+	if edge.BothFresh {
+		breakdown.FreshAdjacencies++
+	}
+}
+
+// bpmBandChange reports whether a transition crosses from one BPM band to another. Bands are width
+// BPM wide starting at multiples of width; tracks without a BPM never change band.
+func bpmBandChange(from, to float32, width float64) bool {
+	if from <= 0 || to <= 0 {
+		return false
+	}
+
+	return math.Floor(float64(from)/width) != math.Floor(float64(to)/width)
+}
+
+// genreBoundaryPenalty scores a transition in genre block mode. Any genre change is a block boundary
+// and costs the same, so the fewest boundaries (one contiguous block per genre) wins over drifting
+// through related genres; a boundary costs up to twice as much when its keys clash, steering the
+// joins between blocks onto harmonically compatible pairs. Transitions within a block are free.
+func genreBoundaryPenalty(edge *EdgeData) float64 {
+	if edge.GenreDifference == 0 {
+		return 0
+	}
+
+	return 1 + min(float64(edge.HarmonicDistance)/camelotWheelPositions, 1)
+}
+
+// placement holds the weighted position-dependent components of one track's cost
+type placement struct {
+	bias, wave, favorite, fresh, bookend float64
+}
+
+// positionalCost returns the position-dependent cost of t at position j of n: every component whose
+// weight is set. All scoring (GA fitness, breakdowns, chunk joins, exact search) goes through it.
+func positionalCost(t *playlist.Track, j, n int, config config.GAConfig, ctx *GAContext) placement {
+	var p placement
+
+	if biasThreshold := int(float64(n) * config.LowEnergyBiasPortion); j < biasThreshold {
+		p.bias = positionBias(t.Energy, j, biasThreshold, config, ctx)
+	}
+
+	if config.EnergyWaveWeight != 0 {
+		p.wave = energyWave(t.Energy, j, n, config, ctx)
+	}
+
+	if config.FavoriteWeight != 0 {
+		p.favorite = favoritePlacement(t.Favorite, j, n, config, ctx)
+	}
+
+	if config.FreshFront && config.FreshnessWeight != 0 {
+		p.fresh = freshPlacement(t.Fresh, j, n, config, ctx)
+	}
+
+	if config.BookendWeight != 0 {
+		p.bookend = bookend(t, j, n, config, ctx)
+	}
+
+	return p
+}
+
+// total returns the sum of the components
+func (p placement) total() float64 {
+	return p.bias + p.wave + p.favorite + p.fresh + p.bookend
+}
+
+// addTo adds the components to breakdown (Total untouched)
+func (p placement) addTo(breakdown *playlist.Breakdown) {
+	breakdown.PositionBias += p.bias
+	breakdown.EnergyWave += p.wave
+	breakdown.Favorites += p.favorite
+	breakdown.Freshness += p.fresh
+	breakdown.Bookends += p.bookend
+}
+
+// positionBias returns the low-energy-opener penalty for a track with the given energy at position j
+// (only positions below biasThreshold are penalized)
+func positionBias(energy, j, biasThreshold int, config config.GAConfig, ctx *GAContext) float64 {
+	positionWeight := 1.0 - float64(j)/float64(biasThreshold)
+	rawPositionBias := ctx.normalizers.neutralEnergy(energy) * positionWeight
+
+	return rawPositionBias * weightRatio(config.LowEnergyBiasWeight, ctx.normalizers.MaxPositionBias)
+}
+
+// energyWave returns the penalty for a track with the given energy at position j of n for straying
+// from the wave: a baseline rising linearly from the playlist's lowest to its highest energy, plus a
+// sine of EnergyWaveAmplitude levels repeating every EnergyWavePeriod tracks
+func energyWave(energy, j, n int, config config.GAConfig, ctx *GAContext) float64 {
+	norm := &ctx.normalizers
+
+	// A track without an energy can't stray from the wave
+	if energy <= 0 {
+		return 0
+	}
+
+	progress := 0.0
+	if n > 1 {
+		progress = float64(j) / float64(n-1)
+	}
+
+	target := norm.MinEnergy + progress*(norm.MaxEnergy-norm.MinEnergy)
+	if config.EnergyWavePeriod > 0 {
+		target += config.EnergyWaveAmplitude * math.Sin(2*math.Pi*float64(j)/float64(config.EnergyWavePeriod))
+	}
+
+	return math.Abs(float64(energy)-target) * weightRatio(config.EnergyWaveWeight, norm.MaxEnergyWave)
+}
+
+// favoritePlacement returns the penalty for a favorite track at position j of n for sitting outside
+// the prime region (PrimeStart to PrimeEnd, fractions of the set): how far outside it is, as a fraction
+// of the set. Other tracks can go anywhere.
+func favoritePlacement(favorite bool, j, n int, config config.GAConfig, ctx *GAContext) float64 {
+	if !favorite {
+		return 0
+	}
+
+	progress := 0.0
+	if n > 1 {
+		progress = float64(j) / float64(n-1)
+	}
+
+	distance := max(config.PrimeStart-progress, progress-config.PrimeEnd, 0)
+
+	return distance * weightRatio(config.FavoriteWeight, ctx.normalizers.MaxFavorite)
+}
+
+// freshPlacement returns the penalty for a recently added track at position j of n when front-loading
+// them (FreshFront): its progress through the set, so the earlier the better
+func freshPlacement(fresh bool, j, n int, config config.GAConfig, ctx *GAContext) float64 {
+	if !fresh || n < 2 {
+		return 0
+	}
+
+	return float64(j) / float64(n-1) * weightRatio(config.FreshnessWeight, ctx.normalizers.MaxFreshness)
+}
+
+// bookend returns the penalty for t at position j of n if it opens or closes the set, from 0 (an ideal
+// bookend) to 1. An opener should have low energy and a mixable intro of at least OpenerIntro seconds;
+// a closer high energy and a high rating (favorites count as five stars). Each part counts only when
+// the track has the data, so a track without any is neutral.
+func bookend(t *playlist.Track, j, n int, config config.GAConfig, ctx *GAContext) float64 {
+	if n < 2 || (j != 0 && j != n-1) {
+		return 0
+	}
+
+	norm := &ctx.normalizers
+
+	// Mean of the parts that apply, kept as a sum and count (this runs on every evaluation)
+	var (
+		sum   float64
+		parts int
+	)
+
+	if t.Energy > 0 && norm.MaxEnergy > norm.MinEnergy {
+		level := (float64(t.Energy) - norm.MinEnergy) / (norm.MaxEnergy - norm.MinEnergy)
+		if j == 0 {
+			sum += level
+		} else {
+			sum += 1 - level
+		}
+
+		parts++
+	}
+
+	switch {
+	case j == 0 && t.Intro > 0 && config.OpenerIntro > 0:
+		sum += max(1-t.Intro.Seconds()/config.OpenerIntro, 0)
+		parts++
+	case j == n-1 && t.Favorite:
+		parts++
+	case j == n-1 && t.Rating > 0:
+		sum += float64(5-t.Rating) / 4
+		parts++
+	}
+
+	if parts == 0 {
+		return 0
+	}
+
+	return sum / float64(parts) * weightRatio(config.BookendWeight, norm.MaxBookends)
+}
+
+// positionalSettings returns the config values the position-dependent components depend on
+func positionalSettings(config config.GAConfig) [12]float64 {
+	front := 0.0
+	if config.FreshFront {
+		front = 1
+	}
+
+	return [12]float64{
+		config.LowEnergyBiasPortion, config.LowEnergyBiasWeight,
+		config.EnergyWaveWeight, config.EnergyWaveAmplitude, float64(config.EnergyWavePeriod),
+		config.FavoriteWeight, config.PrimeStart, config.PrimeEnd,
+		config.FreshnessWeight, front, config.BookendWeight, config.OpenerIntro,
+	}
+}
+
+// buildEdgeCosts flattens the weighted per-transition totals into ctx.edgeCost
+func buildEdgeCosts(ctx *GAContext) {
+	n := len(ctx.edgeCache)
+	if len(ctx.edgeCost) != n*n {
+		ctx.edgeCost = make([]float64, n*n)
+	}
+
+	ctx.numTracks = n
+
+	for i := range n {
+		row := ctx.edgeCost[i*n : (i+1)*n]
+		for j := range n {
+			var b playlist.Breakdown
+
+			addEdgeBreakdown(&b, i, j, ctx)
+
+			row[j] = b.Sum()
 		}
+	}
+}
 
-		if j < biasThreshold {
-			positionWeight := 1.0 - float64(j)/float64(biasThreshold)
-			rawPositionBias := float64(tracks[j].Energy) * positionWeight
-			normalizedPositionBias := rawPositionBias / ctx.normalizers.MaxPositionBias
-			energyPositionPenalty := normalizedPositionBias * config.LowEnergyBiasWeight
-			breakdown.PositionBias += energyPositionPenalty
+// mutate applies one mutation to genes with equal odds: a few random swaps, or the inversion of a
+// random segment
+func mutate(genes []playlist.Track, rng *rand.Rand) {
+	n := len(genes)
+
+	if rng.Uint32()&1 == 0 {
+		numSwaps := minSwapMutations + rng.IntN(maxSwapMutations-minSwapMutations+1)
+		for range numSwaps {
+			a := rng.IntN(n)
+			b := rng.IntN(n)
+			genes[a], genes[b] = genes[b], genes[a]
 		}
+
+		return
 	}
 
-	breakdown.Total = breakdown.Harmonic + breakdown.SameArtist + breakdown.SameAlbum +
-		breakdown.EnergyDelta + breakdown.BPMDelta + breakdown.PositionBias + breakdown.GenreChange
+	start := rng.IntN(n)
+	end := rng.IntN(n)
+	if start > end {
+		start, end = end, start
+	}
 
-	return breakdown
+	reverseSegment(genes, start, end)
 }
 
 // reverseSegment reverses tracks[start:end+1] in place
@@ -674,40 +1789,45 @@ func reverseSegment(tracks []playlist.Track, start, end int) {
 	}
 }
 
-// calculateTheoreticalMinimum calculates theoretical minimum fitness (not achievable due to conflicting constraints)
-func calculateTheoreticalMinimum(tracks []playlist.Track, config config.GAConfig, ctx *GAContext) float64 {
+// calculateTheoreticalMinimum calculates the theoretical minimum of each fitness component (not
+// achievable together due to conflicting constraints). Components without a bound tighter than
+// their best case (harmonic, same artist/album, genre, ...) are 0.
+func calculateTheoreticalMinimum(tracks []playlist.Track, config config.GAConfig, ctx *GAContext) playlist.Breakdown {
+	var b playlist.Breakdown
+
 	n := len(tracks)
 	if n == 0 {
-		return 0.0
+		return b
 	}
 
-	// 1. Harmonic: Best case = all tracks have perfect harmonic compatibility (distance 0)
-	minHarmonic := 0.0
-
-	// 2. Same Artist: Best case = no consecutive tracks from same artist
-	minSameArtist := 0.0
+	// Harmonic, same artist/album and genre: best case = every transition compatible (0)
 
-	// 3. Same Album: Best case = no consecutive tracks from same album
-	minSameAlbum := 0.0
+	// Energy Delta: Best case = tracks sorted by energy (monotonic increase/decrease). Tracks
+	// without an energy have neutral transitions, so only the known energies count.
+	energies := make([]float64, n)
+	known := make([]float64, 0, n)
 
-	// 4. Energy Delta: Best case = tracks sorted by energy (monotonic increase/decrease)
-	energies := make([]int, n)
 	for i, t := range tracks {
-		energies[i] = t.Energy
+		energies[i] = ctx.normalizers.neutralEnergy(t.Energy)
+
+		if t.Energy > 0 {
+			known = append(known, float64(t.Energy))
+		}
 	}
 
 	slices.Sort(energies)
+	slices.Sort(known)
 
 	minEnergyDelta := 0.0
-	for i := 1; i < n; i++ {
-		minEnergyDelta += math.Abs(float64(energies[i] - energies[i-1]))
+	for i := 1; i < len(known); i++ {
+		minEnergyDelta += known[i] - known[i-1]
 	}
 
 	if ctx.normalizers.MaxEnergyDelta > 0 {
-		minEnergyDelta = (minEnergyDelta / ctx.normalizers.MaxEnergyDelta) * config.EnergyDeltaWeight
+		b.EnergyDelta = (minEnergyDelta / ctx.normalizers.MaxEnergyDelta) * config.EnergyDeltaWeight
 	}
 
-	// 5. BPM Delta: Best case = tracks sorted by BPM
+	// BPM Delta: Best case = tracks sorted by BPM
 	bpms := make([]float64, 0, n)
 
 	for _, t := range tracks {
@@ -724,25 +1844,46 @@ func calculateTheoreticalMinimum(tracks []playlist.Track, config config.GAConfig
 	}
 
 	if ctx.normalizers.MaxBPMDelta > 0 && len(bpms) > 1 {
-		minBPMDelta = (minBPMDelta / ctx.normalizers.MaxBPMDelta) * config.BPMDeltaWeight
+		b.BPMDelta = (minBPMDelta / ctx.normalizers.MaxBPMDelta) * config.BPMDeltaWeight
 	}
 
-	// 6. Position Bias: Best case = lowest energy tracks at start
+	// Position Bias: Best case = lowest energy tracks at start
 	biasThreshold := int(float64(n) * config.LowEnergyBiasPortion)
-	minPositionBias := 0.0
 
 	for j := 0; j < biasThreshold && j < n; j++ {
 		positionWeight := 1.0 - float64(j)/float64(biasThreshold)
 
-		rawBias := float64(energies[j]) * positionWeight
+		rawBias := energies[j] * positionWeight
 		if ctx.normalizers.MaxPositionBias > 0 {
-			minPositionBias += (rawBias / ctx.normalizers.MaxPositionBias) * config.LowEnergyBiasWeight
+			b.PositionBias += (rawBias / ctx.normalizers.MaxPositionBias) * config.LowEnergyBiasWeight
+		}
+	}
+
+	// Front-loaded freshness: best case = the fresh tracks open the set
+	if config.FreshFront && config.FreshnessWeight != 0 {
+		fresh := 0
+
+		for _, t := range tracks {
+			if t.Fresh {
+				b.Freshness += freshPlacement(true, fresh, n, config, ctx)
+				fresh++
+			}
 		}
 	}
 
-	// 7. Genre: Best case = 0 (either all same genre or all different, depending on weight direction)
-	minGenre := 0.0
+	// Bookends: best case = the most suitable opener and closer (possibly the same track)
+	if config.BookendWeight != 0 && n > 1 {
+		opener, closer := math.MaxFloat64, math.MaxFloat64
+
+		for i := range tracks {
+			opener = min(opener, bookend(&tracks[i], 0, n, config, ctx))
+			closer = min(closer, bookend(&tracks[i], n-1, n, config, ctx))
+		}
+
+		b.Bookends = opener + closer
+	}
+
+	b.Total = b.Sum()
 
-	return minHarmonic + minSameArtist + minSameAlbum +
-		minEnergyDelta + minBPMDelta + minPositionBias + minGenre
+	return b
 }
@@ -4,9 +4,14 @@
 package main
 
 import (
+	"context"
+	"math"
+	"math/rand/v2"
 	"os"
 	"slices"
+	"strconv"
 	"testing"
+	"time"
 
 	"playlist-sorter/config"
 	"playlist-sorter/playlist"
@@ -103,9 +108,43 @@ func TestIndividualSortingWithSmallDifferences(t *testing.T) {
 		individuals[0].Score, individuals[1].Score, individuals[2].Score, individuals[3].Score)
 }
 
+func TestIndividualCompareBreaksTiesByOrdering(t *testing.T) {
+	tracks := benchmarkTracks(8)
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	population, _ := testPopulation(tracks, 20, rng)
+	for i := range population {
+		population[i].Score = float64(i % 3) // Many equal scores
+	}
+
+	sortCopy := func(seed uint64) []Individual {
+		shuffled := slices.Clone(population)
+		rand.New(rand.NewPCG(seed, seed)).Shuffle(len(shuffled), func(a, b int) { shuffled[a], shuffled[b] = shuffled[b], shuffled[a] })
+		slices.SortFunc(shuffled, func(a, b Individual) int { return a.Compare(b) })
+
+		return shuffled
+	}
+
+	first, second := sortCopy(1), sortCopy(2)
+
+	for i := range first {
+		if first[i].Score != second[i].Score || comparePermutations(first[i].Genes, second[i].Genes) != 0 {
+			t.Fatalf("Expected the same order whatever the starting order, differs at %d", i)
+		}
+
+		if i > 0 && first[i-1].Score == first[i].Score && comparePermutations(first[i-1].Genes, first[i].Genes) > 0 {
+			t.Errorf("Expected equal scores ordered by their orderings at %d", i)
+		}
+	}
+
+	if got := comparePermutations(tracks[:3], tracks[:4]); got != -1 {
+		t.Errorf("Expected a prefix to order first, got %d", got)
+	}
+}
+
 // TestOrderCrossover verifies OX crossover produces valid permutations (no duplicates)
 func TestOrderCrossover(t *testing.T) {
-	// Create test tracks with unique paths (crossover uses Path as key)
+	// Create test tracks with unique indices (crossover uses Index as key)
 	tracks := make([]playlist.Track, 10)
 	for i := range tracks {
 		tracks[i] = playlist.Track{
@@ -126,12 +165,13 @@ func TestOrderCrossover(t *testing.T) {
 		parent2[i] = tracks[9-i]
 	}
 
-	// Create reusable map for crossover (avoid allocations)
-	present := make(map[string]bool, 10)
+	// Create reusable presence buffer for crossover (avoid allocations)
+	present := make([]bool, 10)
+	rng := rand.New(rand.NewPCG(1, 2))
 
 	// Run crossover multiple times (it's randomized)
 	for trial := range 100 {
-		orderCrossover(child, parent1, parent2, present)
+		orderCrossover(child, parent1, parent2, present, rng)
 
 		// Verify child is a valid permutation (no duplicates, all indices present)
 		seen := make(map[int]bool)
@@ -329,6 +369,621 @@ func TestFitnessImprovement(t *testing.T) {
 	}
 }
 
+// TestFitnessBreakdownRawCounts verifies the raw counterparts count transitions in human units
+func TestFitnessBreakdownRawCounts(t *testing.T) {
+	tracks := []playlist.Track{
+		{Index: 0, Path: "A", Key: "1A", ParsedKey: parseKey("1A"), BPM: 120.0, Energy: 5, Artist: "Artist1", Album: "Album1", Genre: "House"},
+		{Index: 1, Path: "B", Key: "2A", ParsedKey: parseKey("2A"), BPM: 124.0, Energy: 7, Artist: "Artist1", Album: "Album1", Genre: "House"},
+		{Index: 2, Path: "C", Key: "7B", ParsedKey: parseKey("7B"), BPM: 130.0, Energy: 6, Artist: "Artist1", Album: "Album2", Genre: "Techno"},
+		{Index: 3, Path: "D", Key: "", BPM: 128.0, Energy: 6, Artist: "Artist2", Album: "Album3", Genre: "Techno"},
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	cfg := config.DefaultConfig()
+
+	updateNormalizedWeights(ctx, cfg)
+
+	b := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+
+	if b.Transitions != 3 {
+		t.Errorf("Expected 3 transitions, got %d", b.Transitions)
+	}
+
+	if b.BadKeyTransitions != 2 {
+		t.Errorf("Expected 2 bad key transitions (2A->7B, 7B->unknown), got %d", b.BadKeyTransitions)
+	}
+
+	if b.TotalEnergyChange != 3 {
+		t.Errorf("Expected 3 energy levels of change, got %.1f", b.TotalEnergyChange)
+	}
+
+	if b.TotalBPMDrift != 12 {
+		t.Errorf("Expected 12 BPM of drift, got %.1f", b.TotalBPMDrift)
+	}
+
+	if b.SameArtistAdjacencies != 2 || b.SameAlbumAdjacencies != 1 || b.GenreChanges != 1 {
+		t.Errorf("Expected 2 same-artist, 1 same-album and 1 genre change, got %d, %d, %d",
+			b.SameArtistAdjacencies, b.SameAlbumAdjacencies, b.GenreChanges)
+	}
+
+	// Raw counts don't depend on weights
+	cfg.HarmonicWeight *= 3
+	updateNormalizedWeights(ctx, cfg)
+
+	if reweighted := calculateFitnessWithBreakdown(tracks, cfg, ctx); reweighted.BadKeyTransitions != b.BadKeyTransitions {
+		t.Errorf("Expected the same bad key transitions after a weight change, got %d", reweighted.BadKeyTransitions)
+	}
+}
+
+// TestAddSeedOrders verifies seed playlists become complete orderings of the playlist's tracks
+func TestAddSeedOrders(t *testing.T) {
+	tracks := benchmarkTracks(4)
+	tracks = append(tracks, tracks[1]) // track1 is listed twice
+	playlist.AssignIDs(tracks, "")
+	playlist.ReindexTracks(tracks)
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	orders := [][]string{
+		{"track3", "track2", "track1", "track0"},
+		{"track2", "missing", "track0"}, // Partial: the rest follow in playlist order
+		{"elsewhere"},                   // Shares nothing
+		{"track1", "track1", "track1"},  // Listed more often than in the playlist
+	}
+
+	if added := addSeedOrders(ctx, tracks, orders); added != 3 {
+		t.Fatalf("Expected 3 seeds, got %d", added)
+	}
+
+	want := [][]string{
+		{"track3", "track2", "track1", "track0", "track1"},
+		{"track2", "track0", "track1", "track3", "track1"},
+		{"track1", "track1", "track0", "track2", "track3"},
+	}
+
+	for k, seed := range ctx.seeds {
+		got := make([]string, len(seed))
+		for i, track := range seed {
+			got[i] = track.Path
+		}
+
+		if !slices.Equal(got, want[k]) {
+			t.Errorf("Seed %d: expected %v, got %v", k, want[k], got)
+		}
+	}
+}
+
+// TestShuffleNoiseBounded verifies smart-shuffle jitter is off by default and bounded by temperature
+func TestShuffleNoiseBounded(t *testing.T) {
+	tracks := make([]playlist.Track, 6)
+	for i := range tracks {
+		tracks[i] = playlist.Track{Index: i, Path: string(rune('A' + i)), Key: "1A", ParsedKey: parseKey("1A"), BPM: 120.0 + float64(i), Energy: i + 1}
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	cfg := config.DefaultConfig()
+
+	updateNormalizedWeights(ctx, cfg)
+
+	if b := calculateFitnessWithBreakdown(tracks, cfg, ctx); b.Shuffle != 0 {
+		t.Errorf("Expected no shuffle component without noise, got %.4f", b.Shuffle)
+	}
+
+	cfg.ShuffleTemperature = 0.5
+	applyShuffleNoise(ctx, rand.New(rand.NewPCG(1, 2)))
+	updateNormalizedWeights(ctx, cfg)
+
+	b := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+	if b.Shuffle <= 0 || b.Shuffle > cfg.ShuffleTemperature {
+		t.Errorf("Expected shuffle component in (0, %.2f], got %.4f", cfg.ShuffleTemperature, b.Shuffle)
+	}
+
+	withoutShuffle := b.Total - b.Shuffle
+	if diff := withoutShuffle - (b.Harmonic + b.SameArtist + b.SameAlbum + b.EnergyDelta + b.BPMDelta + b.PositionBias + b.GenreChange); diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("Shuffle component not included in total exactly once (diff %.12f)", diff)
+	}
+}
+
+// TestNoveltyPenalizesPreviousAdjacencies verifies repeating the last output's pairs is penalized
+func TestNoveltyPenalizesPreviousAdjacencies(t *testing.T) {
+	tracks := make([]playlist.Track, 4)
+	for i := range tracks {
+		tracks[i] = playlist.Track{Index: i, Path: string(rune('A' + i)), Key: "1A", ParsedKey: parseKey("1A"), BPM: 120.0, Energy: i + 1}
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	markPreviousAdjacencies(ctx, tracks, []string{"A", "B", "C", "D", "missing"})
+
+	cfg := config.DefaultConfig()
+	cfg.NoveltyWeight = 1.0
+	updateNormalizedWeights(ctx, cfg)
+
+	// Same order as before: every transition repeats
+	same := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+	if same.Novelty < cfg.NoveltyWeight-1e-9 {
+		t.Errorf("Expected full novelty penalty %.2f for identical order, got %.4f", cfg.NoveltyWeight, same.Novelty)
+	}
+
+	// Reversed order repeats the same pairs in the other direction
+	reversed := []playlist.Track{tracks[3], tracks[2], tracks[1], tracks[0]}
+	if b := calculateFitnessWithBreakdown(reversed, cfg, ctx); b.Novelty < cfg.NoveltyWeight-1e-9 {
+		t.Errorf("Expected reversed pairs to count as repeats, got %.4f", b.Novelty)
+	}
+
+	// A C B D shares only the B-C pair
+	fresh := []playlist.Track{tracks[0], tracks[2], tracks[1], tracks[3]}
+	if b := calculateFitnessWithBreakdown(fresh, cfg, ctx); b.Novelty >= same.Novelty {
+		t.Errorf("Expected novel order to be penalized less (%.4f >= %.4f)", b.Novelty, same.Novelty)
+	}
+}
+
+func TestMixLengthPrefersMatchingOutroIntro(t *testing.T) {
+	// A has a long outro; B a long intro and C a short one
+	tracks := []playlist.Track{
+		{Index: 0, Path: "A", Key: "1A", ParsedKey: parseKey("1A"), BPM: 120, Energy: 5, Outro: 32 * time.Second},
+		{Index: 1, Path: "B", Key: "1A", ParsedKey: parseKey("1A"), BPM: 120, Energy: 5, Intro: 32 * time.Second},
+		{Index: 2, Path: "C", Key: "1A", ParsedKey: parseKey("1A"), BPM: 120, Energy: 5, Intro: 4 * time.Second},
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	cfg := config.DefaultConfig()
+	cfg.MixLengthWeight = 1.0
+	updateNormalizedWeights(ctx, cfg)
+
+	intoLong := calculateFitnessWithBreakdown([]playlist.Track{tracks[2], tracks[0], tracks[1]}, cfg, ctx)
+	intoShort := calculateFitnessWithBreakdown([]playlist.Track{tracks[1], tracks[0], tracks[2]}, cfg, ctx)
+
+	if intoLong.MixLength != 0 {
+		t.Errorf("Expected no mix penalty when the 32s outro meets a 32s intro, got %.4f", intoLong.MixLength)
+	}
+
+	if intoShort.MixLength <= 0 || intoShort.Total <= intoLong.Total {
+		t.Errorf("Expected the 32s outro into a 4s intro to be penalized (mix %.4f, total %.4f vs %.4f)",
+			intoShort.MixLength, intoShort.Total, intoLong.Total)
+	}
+}
+
+func TestModulatingTrackUsesEndAndStartKeys(t *testing.T) {
+	// B starts in 1A but ends in 5A: A -> B is perfect, B -> C (5A) too, but not C -> B
+	tracks := []playlist.Track{
+		{Index: 0, Path: "A", Key: "1A", ParsedKey: parseKey("1A"), BPM: 120, Energy: 5},
+		{Index: 1, Path: "B", Key: "1A", ParsedKey: parseKey("1A"), EndKey: parseKey("5A"), BPM: 120, Energy: 5},
+		{Index: 2, Path: "C", Key: "5A", ParsedKey: parseKey("5A"), BPM: 120, Energy: 5},
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	if d := ctx.edgeCache[0][1].HarmonicDistance; d != 0 {
+		t.Errorf("Expected A -> B to mix into B's start key (distance 0), got %d", d)
+	}
+
+	if d := ctx.edgeCache[1][2].HarmonicDistance; d != 0 {
+		t.Errorf("Expected B -> C to mix out of B's end key (distance 0), got %d", d)
+	}
+
+	if d := ctx.edgeCache[2][1].HarmonicDistance; d == 0 {
+		t.Error("Expected C -> B (5A into 1A) to be penalized")
+	}
+}
+
+func TestEnergyWavePrefersOscillation(t *testing.T) {
+	// Baseline 1 -> 9 over 9 tracks plus a ±2 sine every 4 tracks: targets 1 4 3 2 5 8 7 6 9
+	waveOrder := []int{1, 4, 3, 2, 5, 8, 7, 6, 9}
+
+	tracks := make([]playlist.Track, len(waveOrder))
+	for i, energy := range waveOrder {
+		tracks[i] = playlist.Track{Index: i, Path: strconv.Itoa(i), Key: "1A", ParsedKey: parseKey("1A"), BPM: 120, Energy: energy}
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	cfg := config.DefaultConfig()
+	cfg.EnergyWaveWeight = 1.0
+	cfg.EnergyWaveAmplitude = 2
+	cfg.EnergyWavePeriod = 4
+	updateNormalizedWeights(ctx, cfg)
+
+	wave := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+
+	monotonic := slices.Clone(tracks)
+	slices.SortFunc(monotonic, func(a, b playlist.Track) int { return a.Energy - b.Energy })
+
+	ramp := calculateFitnessWithBreakdown(monotonic, cfg, ctx)
+
+	if wave.EnergyWave > 1e-9 {
+		t.Errorf("Expected no wave penalty for the target order, got %.6f", wave.EnergyWave)
+	}
+
+	if ramp.EnergyWave <= wave.EnergyWave {
+		t.Errorf("Expected a straight ramp to stray from the wave (%.6f <= %.6f)", ramp.EnergyWave, wave.EnergyWave)
+	}
+
+	if fast := calculateFitness(monotonic, cfg, ctx); math.Abs(fast-ramp.Total) > 1e-9 {
+		t.Errorf("Expected flat fitness %.10f to include the wave like the breakdown %.10f", fast, ramp.Total)
+	}
+}
+
+func TestFavoritesPlacedInPrimeRegion(t *testing.T) {
+	tracks := make([]playlist.Track, 9)
+	for i := range tracks {
+		tracks[i] = playlist.Track{Index: i, Path: strconv.Itoa(i), Key: "1A", ParsedKey: parseKey("1A"), BPM: 120, Energy: 5}
+	}
+
+	tracks[0].Favorite = true
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	cfg := config.DefaultConfig()
+	cfg.FavoriteWeight = 1.0
+	updateNormalizedWeights(ctx, cfg)
+
+	opening := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+
+	closing := slices.Clone(tracks)
+	closing[0], closing[8] = closing[8], closing[0]
+
+	prime := calculateFitnessWithBreakdown(closing, cfg, ctx)
+
+	if prime.Favorites != 0 {
+		t.Errorf("Expected no penalty for a favorite in the final third, got %.6f", prime.Favorites)
+	}
+
+	if opening.Favorites <= 0 {
+		t.Errorf("Expected a favorite opening the set to be penalized, got %.6f", opening.Favorites)
+	}
+
+	if fast := calculateFitness(tracks, cfg, ctx); math.Abs(fast-opening.Total) > 1e-9 {
+		t.Errorf("Expected flat fitness %.10f to include favorites like the breakdown %.10f", fast, opening.Total)
+	}
+}
+
+func TestFreshnessSpreadsOrFrontLoads(t *testing.T) {
+	tracks := make([]playlist.Track, 6)
+	for i := range tracks {
+		tracks[i] = playlist.Track{Index: i, Path: strconv.Itoa(i), Key: "1A", ParsedKey: parseKey("1A"), BPM: 120, Energy: 5}
+	}
+
+	tracks[0].Fresh = true
+	tracks[1].Fresh = true
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	cfg := config.DefaultConfig()
+	cfg.FreshnessWeight = 1.0
+	updateNormalizedWeights(ctx, cfg)
+
+	order := func(idx ...int) []playlist.Track {
+		ordered := make([]playlist.Track, len(idx))
+		for i, j := range idx {
+			ordered[i] = tracks[j]
+		}
+
+		return ordered
+	}
+
+	together := calculateFitnessWithBreakdown(order(0, 1, 2, 3, 4, 5), cfg, ctx)
+	spread := calculateFitnessWithBreakdown(order(0, 2, 3, 1, 4, 5), cfg, ctx)
+
+	if together.FreshAdjacencies != 1 || together.Freshness <= 0 {
+		t.Errorf("Expected back-to-back fresh tracks to be penalized, got %d pairs scoring %.6f", together.FreshAdjacencies, together.Freshness)
+	}
+
+	if spread.Freshness != 0 {
+		t.Errorf("Expected no penalty for spread fresh tracks, got %.6f", spread.Freshness)
+	}
+
+	cfg.FreshFront = true
+	updateNormalizedWeights(ctx, cfg)
+
+	early := calculateFitnessWithBreakdown(order(0, 1, 2, 3, 4, 5), cfg, ctx)
+	late := calculateFitnessWithBreakdown(order(2, 3, 4, 5, 0, 1), cfg, ctx)
+
+	if early.Freshness >= late.Freshness {
+		t.Errorf("Expected front-loading to favor fresh tracks early (%.6f >= %.6f)", early.Freshness, late.Freshness)
+	}
+
+	if minimum := calculateTheoreticalMinimum(tracks, cfg, ctx); math.Abs(minimum.Freshness-early.Freshness) > 1e-9 {
+		t.Errorf("Expected the minimum %.6f to match fresh tracks opening the set (%.6f)", minimum.Freshness, early.Freshness)
+	}
+
+	if fast := calculateFitness(order(2, 3, 4, 5, 0, 1), cfg, ctx); math.Abs(fast-late.Total) > 1e-9 {
+		t.Errorf("Expected flat fitness %.10f to include freshness like the breakdown %.10f", fast, late.Total)
+	}
+}
+
+func TestBookendsFavorSuitableOpenerAndCloser(t *testing.T) {
+	tracks := []playlist.Track{
+		{Path: "opener", Energy: 2, Intro: 32 * time.Second},
+		{Path: "middle", Energy: 5},
+		{Path: "closer", Energy: 8, Rating: 5},
+	}
+
+	for i := range tracks {
+		tracks[i].Index = i
+		tracks[i].Key = "1A"
+		tracks[i].ParsedKey = parseKey("1A")
+		tracks[i].BPM = 120
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	cfg := config.DefaultConfig()
+	cfg.BookendWeight = 1.0
+	updateNormalizedWeights(ctx, cfg)
+
+	proper := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+
+	reversed := slices.Clone(tracks)
+	slices.Reverse(reversed)
+
+	backwards := calculateFitnessWithBreakdown(reversed, cfg, ctx)
+
+	if proper.Bookends > 1e-9 {
+		t.Errorf("Expected no penalty for a quiet long-intro opener and a top-rated peak closer, got %.6f", proper.Bookends)
+	}
+
+	if backwards.Bookends <= proper.Bookends {
+		t.Errorf("Expected swapped bookends to be penalized (%.6f <= %.6f)", backwards.Bookends, proper.Bookends)
+	}
+
+	if fast := calculateFitness(reversed, cfg, ctx); math.Abs(fast-backwards.Total) > 1e-9 {
+		t.Errorf("Expected flat fitness %.10f to include bookends like the breakdown %.10f", fast, backwards.Total)
+	}
+}
+
+func TestGenreBlocksMinimizeBoundaries(t *testing.T) {
+	track := func(i int, path, key, genre string) playlist.Track {
+		return playlist.Track{Index: i, Path: path, Key: key, ParsedKey: parseKey(key), Genre: genre, BPM: 120, Energy: 5}
+	}
+
+	// A1/A2 are house, B1/B2 techno; A2 -> B1 is a smooth boundary (1A -> 1A), A1 -> B2 a clash (1A -> 7A)
+	tracks := []playlist.Track{
+		track(0, "A1", "1A", "House"),
+		track(1, "A2", "1A", "House"),
+		track(2, "B1", "1A", "Techno"),
+		track(3, "B2", "7A", "Techno"),
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	cfg := config.DefaultConfig()
+	cfg.GenreWeight = 1.0
+	cfg.GenreBlocks = true
+	updateNormalizedWeights(ctx, cfg)
+
+	order := func(idx ...int) playlist.Breakdown {
+		ordered := make([]playlist.Track, len(idx))
+		for i, j := range idx {
+			ordered[i] = tracks[j]
+		}
+
+		return calculateFitnessWithBreakdown(ordered, cfg, ctx)
+	}
+
+	smooth := order(0, 1, 2, 3)
+	clashing := order(1, 0, 3, 2)
+	interleaved := order(0, 2, 1, 3)
+
+	if smooth.GenreChange >= clashing.GenreChange {
+		t.Errorf("Expected a harmonic boundary to cost less than a clashing one: %.4f >= %.4f",
+			smooth.GenreChange, clashing.GenreChange)
+	}
+
+	if interleaved.GenreChange <= clashing.GenreChange {
+		t.Errorf("Expected three boundaries to cost more than one clashing boundary: %.4f <= %.4f",
+			interleaved.GenreChange, clashing.GenreChange)
+	}
+}
+
+func TestBPMBandsPreferFewBandChanges(t *testing.T) {
+	bpms := []float64{170, 171, 174, 175}
+
+	tracks := make([]playlist.Track, len(bpms))
+	for i, bpm := range bpms {
+		tracks[i] = playlist.Track{Index: i, Path: strconv.Itoa(i), Key: "1A", ParsedKey: parseKey("1A"), BPM: bpm, Energy: 5}
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	cfg := config.DefaultConfig()
+	cfg.BPMBandWeight = 1.0
+	updateNormalizedWeights(ctx, cfg)
+
+	banded := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+	drifting := calculateFitnessWithBreakdown([]playlist.Track{tracks[0], tracks[2], tracks[1], tracks[3]}, cfg, ctx)
+
+	if want := 1.0 / 3; math.Abs(banded.BPMBand-want) > 1e-9 {
+		t.Errorf("Expected one band change out of three transitions (%.4f), got %.4f", want, banded.BPMBand)
+	}
+
+	if drifting.BPMBand <= banded.BPMBand {
+		t.Errorf("Expected hopping between 170-172 and 174-176 to cost more: %.4f <= %.4f", drifting.BPMBand, banded.BPMBand)
+	}
+
+	cfg.BPMBandWidth = 10
+	updateNormalizedWeights(ctx, cfg)
+
+	if wide := calculateFitnessWithBreakdown(tracks, cfg, ctx); wide.BPMBand != 0 {
+		t.Errorf("Expected a single 170-180 band with width 10, got penalty %.4f", wide.BPMBand)
+	}
+}
+
+func TestGenreOverridesApplyWithinGenre(t *testing.T) {
+	track := func(i int, genre string, bpm float64) playlist.Track {
+		return playlist.Track{Index: i, Path: strconv.Itoa(i), Key: "1A", ParsedKey: parseKey("1A"), Genre: genre, BPM: bpm, Energy: 5}
+	}
+
+	// Jungle is a child of drum and bass, so both DnB tracks resolve to the override
+	tracks := []playlist.Track{
+		track(0, "Drum and Bass", 170),
+		track(1, "Jungle", 174),
+		track(2, "House", 124),
+		track(3, "House", 128),
+	}
+
+	bpmCost := func(ctx *GAContext, i, j int) float64 {
+		var b playlist.Breakdown
+
+		addEdgeBreakdown(&b, i, j, ctx)
+
+		return b.BPMDelta
+	}
+
+	cfg := config.DefaultConfig()
+
+	base := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(base, cfg)
+
+	stricter := 10 * cfg.BPMDeltaWeight
+	cfg.GenreOverrides = map[string]config.GenreOverride{"drum and bass": {BPMDeltaWeight: &stricter}}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(ctx, cfg)
+	overridden := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+
+	if got, want := bpmCost(ctx, 0, 1), 10*bpmCost(base, 0, 1); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected the DnB -> Jungle transition to use the override: %.6f, want %.6f", got, want)
+	}
+
+	if bpmCost(ctx, 2, 3) != bpmCost(base, 2, 3) || bpmCost(ctx, 1, 2) != bpmCost(base, 1, 2) {
+		t.Error("Expected house and cross-genre transitions to keep the global weight")
+	}
+
+	if fast := calculateFitness(tracks, cfg, ctx); math.Abs(fast-overridden.Total) > 1e-9 {
+		t.Errorf("Expected flat fitness %.10f to match the breakdown %.10f", fast, overridden.Total)
+	}
+
+	if scored := newPathScorer(tracks, cfg, nil).breakdown(tracks); math.Abs(scored.Total-overridden.Total) > 1e-9 {
+		t.Errorf("Expected the on-demand scorer %.10f to apply overrides like the cache %.10f", scored.Total, overridden.Total)
+	}
+}
+
+func TestGeneticSortRejectsStaleIndices(t *testing.T) {
+	tracks := benchmarkTracks(6)
+
+	// A track deleted without reindexing: the last index no longer fits the rebuilt cache
+	edited := slices.Delete(slices.Clone(tracks), 0, 1)
+	gaCtx := buildEdgeFitnessCache(edited)
+
+	sharedCfg := &config.SharedConfig{}
+	sharedCfg.Update(config.DefaultConfig())
+
+	sorted, err := geneticSort(context.Background(), edited, sharedCfg, nil, 0, gaCtx)
+	if err == nil || sorted != nil {
+		t.Errorf("Expected geneticSort to refuse tracks whose indices don't match the cache, got %d tracks and %v", len(sorted), err)
+	}
+
+	// Reindexed and rebuilt, the same tracks sort
+	playlist.ReindexTracks(edited)
+
+	if _, err := geneticSort(context.Background(), edited, sharedCfg, nil, 0, buildEdgeFitnessCache(edited)); err != nil {
+		t.Errorf("Expected reindexed tracks to sort, got %v", err)
+	}
+}
+
+// TestSeededRunsAreReproducible verifies two runs from the same seed over the same generations,
+// including a 2-opt run with the clock-based settings on, return the same order
+func TestSeededRunsAreReproducible(t *testing.T) {
+	tracks := benchmarkTracks(40)
+
+	cfg := config.DefaultConfig()
+	cfg.TwoOptBudgetMs = 1
+	cfg.TwoOptAdaptive = true
+
+	sharedCfg := &config.SharedConfig{}
+	sharedCfg.Update(cfg)
+
+	run := func() []string {
+		gaCtx := buildEdgeFitnessCache(tracks)
+		gaCtx.rng = rand.New(rand.NewPCG(7, 7))
+		gaCtx.generations = twoOptStartGen + 100
+
+		sorted, err := geneticSort(context.Background(), tracks, sharedCfg, nil, 0, gaCtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return trackPathsOf(sorted)
+	}
+
+	if first, second := run(), run(); !slices.Equal(first, second) {
+		t.Errorf("Expected the same order from the same seed, got\n%v\n%v", first, second)
+	}
+}
+
+func TestGeneticSortCancelledBeforeStartKeepsOrder(t *testing.T) {
+	tracks := benchmarkTracks(30)
+	gaCtx := buildEdgeFitnessCache(tracks)
+
+	sharedCfg := &config.SharedConfig{}
+	sharedCfg.Update(config.DefaultConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err := geneticSort(ctx, tracks, sharedCfg, nil, 0, gaCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.EqualFunc(got, tracks, func(a, b playlist.Track) bool { return a.Path == b.Path }) {
+		t.Errorf("Expected the playlist's order back from a sort cancelled before it started, got %d tracks", len(got))
+	}
+}
+
+func TestEdgeCacheStoreMatchesFullRebuild(t *testing.T) {
+	tracks := benchmarkTracks(12)
+
+	var store edgeCacheStore
+
+	store.build(tracks)
+
+	// Delete two tracks, reverse the rest, change one track's key (a reload) and add a new one
+	edited := slices.Delete(slices.Clone(tracks), 3, 5)
+	slices.Reverse(edited)
+	edited[0].Key, edited[0].ParsedKey = "3B", parseKey("3B")
+	edited = append(edited, benchmarkTracks(13)[12])
+	playlist.ReindexTracks(edited)
+
+	got := store.build(edited)
+	want := buildEdgeFitnessCache(edited)
+
+	for i := range edited {
+		for j := range edited {
+			if got.edgeCache[i][j] != want.edgeCache[i][j] {
+				t.Fatalf("Edge %d -> %d differs from a full rebuild: %+v vs %+v", i, j, got.edgeCache[i][j], want.edgeCache[i][j])
+			}
+		}
+	}
+
+	if got.normalizers != want.normalizers || !slices.Equal(got.genres, want.genres) {
+		t.Error("Expected normalizers and genres to match a full rebuild")
+	}
+}
+
+// TestEdgeCacheStoreDuplicateEntries verifies a file listed twice keeps a real transition between
+// its two entries when edges are reused, instead of both mapping to the same old entry
+func TestEdgeCacheStoreDuplicateEntries(t *testing.T) {
+	tracks := benchmarkTracks(4)
+	tracks = append(tracks, tracks[1])
+	playlist.AssignIDs(tracks, "")
+	playlist.ReindexTracks(tracks)
+
+	var store edgeCacheStore
+
+	store.build(tracks)
+
+	edited := slices.Clone(tracks)
+	slices.Reverse(edited)
+	playlist.ReindexTracks(edited)
+
+	got := store.build(edited)
+	if edge := got.edgeCache[0][3]; edge != computeEdge(&edited[0], &edited[3]) || !edge.SameArtist {
+		t.Errorf("Expected the transition between both entries of a file to be computed, got %+v", edge)
+	}
+}
+
 // TestReverseSegment verifies segment reversal works correctly
 func TestReverseSegment(t *testing.T) {
 	tests := []struct {
@@ -396,6 +1051,126 @@ func TestReverseSegment(t *testing.T) {
 	}
 }
 
+// TestFlatFitnessMatchesBreakdown verifies the flat edge-cost fast path scores like the component breakdown
+func TestFlatFitnessMatchesBreakdown(t *testing.T) {
+	tracks := benchmarkTracks(60)
+	ctx := buildEdgeFitnessCache(tracks)
+	markPreviousAdjacencies(ctx, tracks, []string{tracks[3].Path, tracks[4].Path})
+
+	cfg := config.DefaultConfig()
+	cfg.GenreWeight = -0.4
+	cfg.NoveltyWeight = 0.3
+	cfg.ShuffleTemperature = 0.2
+
+	applyShuffleNoise(ctx, rand.New(rand.NewPCG(1, 2)))
+	updateNormalizedWeights(ctx, cfg)
+
+	for range 20 {
+		rand.Shuffle(len(tracks), func(a, b int) { tracks[a], tracks[b] = tracks[b], tracks[a] })
+
+		flat := calculateFitness(tracks, cfg, ctx)
+		components := calculateFitnessWithBreakdown(tracks, cfg, ctx).Total
+
+		if math.Abs(flat-components) > 1e-9 {
+			t.Fatalf("Flat fitness %.12f != breakdown total %.12f", flat, components)
+		}
+	}
+}
+
+func TestDegeneratePlaylistsScoreFinite(t *testing.T) {
+	withEnergy := func(tracks []playlist.Track, energy int) []playlist.Track {
+		for i := range tracks {
+			tracks[i].Energy = energy
+		}
+
+		return tracks
+	}
+
+	noKeysOrBPM := benchmarkTracks(8)
+	for i := range noKeysOrBPM {
+		noKeysOrBPM[i].Key, noKeysOrBPM[i].ParsedKey, noKeysOrBPM[i].BPM = "", nil, 0
+	}
+
+	bare := make([]playlist.Track, 6)
+	for i := range bare {
+		bare[i] = playlist.Track{Index: i, Path: strconv.Itoa(i)}
+	}
+
+	tests := []struct {
+		name     string
+		tracks   []playlist.Track
+		disabled []string
+	}{
+		{"single track", benchmarkTracks(1), []string{"harmonic (no keys)", "energy delta (no energy range)", "BPM delta (no BPM range)", "BPM band (no BPM)"}},
+		{"equal energy", withEnergy(benchmarkTracks(8), 5), []string{"energy delta (no energy range)"}},
+		{"no energy", withEnergy(benchmarkTracks(8), 0), []string{"energy delta (no energy range)", "position bias (no energy)", "energy wave (no energy)"}},
+		{"no keys or BPM", noKeysOrBPM, []string{"harmonic (no keys)", "BPM delta (no BPM range)", "BPM band (no BPM)"}},
+		{"no metadata", bare, []string{
+			"harmonic (no keys)", "energy delta (no energy range)", "BPM delta (no BPM range)",
+			"position bias (no energy)", "energy wave (no energy)", "BPM band (no BPM)",
+		}},
+	}
+
+	// Every component weighted, so each can be reported as disabled
+	cfg := config.DefaultConfig()
+	cfg.LowEnergyBiasWeight = 0.3
+	cfg.EnergyWaveWeight = 0.2
+	cfg.BPMBandWeight = 0.2
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := buildEdgeFitnessCache(tt.tracks)
+			updateNormalizedWeights(ctx, cfg)
+
+			breakdown := calculateFitnessWithBreakdown(tt.tracks, cfg, ctx)
+			minimum := calculateTheoreticalMinimum(tt.tracks, cfg, ctx)
+
+			for name, v := range map[string]float64{
+				"fitness":   calculateFitness(tt.tracks, cfg, ctx),
+				"breakdown": breakdown.Total,
+				"minimum":   minimum.Total,
+			} {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					t.Errorf("%s is %v", name, v)
+				}
+			}
+
+			if got := ctx.normalizers.disabledComponents(cfg); !slices.Equal(got, tt.disabled) {
+				t.Errorf("Disabled components %q, want %q", got, tt.disabled)
+			}
+		})
+	}
+
+	if norm := computeNormalizers(nil); norm != (FitnessNormalizers{}) {
+		t.Errorf("Expected zero normalizers for an empty playlist, got %+v", norm)
+	}
+}
+
+func TestMissingDataIsNeutral(t *testing.T) {
+	tracks := benchmarkTracks(4)
+	tracks[1].Energy = 0
+	tracks[2].Artist, tracks[3].Artist = "", ""
+	tracks[2].Album, tracks[3].Album = "", ""
+
+	if edge := computeEdge(&tracks[0], &tracks[1]); edge.EnergyDelta != 0 {
+		t.Errorf("Transition to a track without energy should be neutral, got delta %v", edge.EnergyDelta)
+	}
+
+	if edge := computeEdge(&tracks[2], &tracks[3]); edge.SameArtist || edge.SameAlbum {
+		t.Error("Tracks without artist/album tags should not count as the same artist/album")
+	}
+
+	// Missing energy doesn't stretch the energy range: it spans the known energies only
+	norm := computeNormalizers(tracks)
+	if norm.MinEnergy != 1 || norm.MaxEnergy != 4 {
+		t.Errorf("Energy range %v-%v, want 1-4", norm.MinEnergy, norm.MaxEnergy)
+	}
+
+	if got := norm.neutralEnergy(0); got != 2.5 {
+		t.Errorf("Neutral energy %v, want 2.5", got)
+	}
+}
+
 // ========== Benchmarks ==========
 
 // BenchmarkCalculateFitness measures fitness calculation performance (hot path)
@@ -468,7 +1243,8 @@ func BenchmarkOrderCrossover(b *testing.B) {
 	parent1 := make([]playlist.Track, 50)
 	parent2 := make([]playlist.Track, 50)
 	child := make([]playlist.Track, 50)
-	present := make(map[string]bool, 50)
+	present := make([]bool, 50)
+	rng := rand.New(rand.NewPCG(1, 2))
 
 	copy(parent1, tracks)
 	// Reverse for parent2
@@ -479,10 +1255,191 @@ func BenchmarkOrderCrossover(b *testing.B) {
 	b.ResetTimer()
 
 	for range b.N {
-		orderCrossover(child, parent1, parent2, present)
+		orderCrossover(child, parent1, parent2, present, rng)
 	}
 }
 
+// testPopulation returns a sorted population of shuffled orderings of tracks and a matching next generation
+func testPopulation(tracks []playlist.Track, size int, rng *rand.Rand) ([]Individual, [][]playlist.Track) {
+	population := make([]Individual, size)
+	nextGen := make([][]playlist.Track, size)
+
+	for i := range population {
+		population[i] = Individual{Genes: slices.Clone(tracks), Score: float64(i)}
+		rng.Shuffle(len(tracks), func(a, b int) {
+			population[i].Genes[a], population[i].Genes[b] = population[i].Genes[b], population[i].Genes[a]
+		})
+		nextGen[i] = make([]playlist.Track, len(tracks))
+	}
+
+	return population, nextGen
+}
+
+// TestBreedReusesScratch verifies breeding produces valid permutations without allocating
+func TestBreedReusesScratch(t *testing.T) {
+	tracks := benchmarkTracks(40)
+	rng := rand.New(rand.NewPCG(1, 2))
+	population, nextGen := testPopulation(tracks, 21, rng)
+	scratch := newGAScratch(len(population), len(tracks), len(tracks), 2)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		scratch.shareFitness(population, 0.1)
+		scratch.breed(population, nextGen, rng)
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected breeding to reuse its scratch buffers, got %.0f allocations per generation", allocs)
+	}
+
+	for i, child := range nextGen {
+		seen := make([]bool, len(tracks))
+		for _, track := range child {
+			if seen[track.Index] {
+				t.Fatalf("Child %d repeats track %d", i, track.Index)
+			}
+
+			seen[track.Index] = true
+		}
+	}
+
+	if !slices.Equal(trackPathsOf(nextGen[0]), trackPathsOf(population[0].Genes)) {
+		t.Error("Expected the best individual to carry over unchanged")
+	}
+}
+
+// TestTwoOptStopsAtDeadline verifies a 2-opt run past its time budget leaves the order alone
+func TestTwoOptStopsAtDeadline(t *testing.T) {
+	tracks := benchmarkTracks(60)
+	ctx := buildEdgeFitnessCache(tracks)
+	cfg := config.DefaultConfig()
+	updateNormalizedWeights(ctx, cfg)
+
+	order := slices.Clone(tracks)
+	twoOptImprove(order, make([]bool, len(order)), time.Now().Add(-time.Second), cfg, ctx)
+
+	if !slices.Equal(trackPathsOf(order), trackPathsOf(tracks)) {
+		t.Error("Expected an expired budget to stop 2-opt before any reversal")
+	}
+
+	twoOptImprove(order, make([]bool, len(order)), time.Time{}, cfg, ctx)
+
+	if calculateFitness(order, cfg, ctx) >= calculateFitness(tracks, cfg, ctx) {
+		t.Error("Expected 2-opt without a budget to improve the order")
+	}
+}
+
+// TestTwoOptSchedulerAdapts verifies the 2-opt interval follows each run's cost and benefit
+func TestTwoOptSchedulerAdapts(t *testing.T) {
+	start := time.Now()
+	s := newTwoOptScheduler(start)
+
+	if s.due(twoOptStartGen-1) || !s.due(twoOptStartGen) {
+		t.Fatalf("Expected the first run at generation %d", twoOptStartGen)
+	}
+
+	// Cheap and useful: run more often
+	now := start.Add(10 * time.Second)
+	s.record(twoOptStartGen, now, 100*time.Millisecond, 0.01, true)
+
+	if s.interval != twoOptIntervalGens/2 || s.next != twoOptStartGen+twoOptIntervalGens/2 {
+		t.Errorf("Expected the interval to halve after a cheap improvement, got %d (next %d)", s.interval, s.next)
+	}
+
+	// No gain: back off
+	now = now.Add(10 * time.Second)
+	s.record(s.next, now, 100*time.Millisecond, 0, true)
+
+	if s.interval != twoOptIntervalGens {
+		t.Errorf("Expected the interval to double after a run without gain, got %d", s.interval)
+	}
+
+	// Useful but too expensive: back off
+	now = now.Add(10 * time.Second)
+	s.record(s.next, now, 5*time.Second, 0.01, true)
+
+	if s.interval != 2*twoOptIntervalGens {
+		t.Errorf("Expected the interval to double after an expensive run, got %d", s.interval)
+	}
+
+	// Fixed schedule ignores cost and benefit
+	fixed := newTwoOptScheduler(start)
+	fixed.record(twoOptStartGen, start.Add(time.Second), time.Second, 0, false)
+
+	if fixed.next != twoOptStartGen+twoOptIntervalGens {
+		t.Errorf("Expected the fixed interval without adaptive scheduling, got next %d", fixed.next)
+	}
+}
+
+// TestNichingSpreadsElites verifies near-duplicates of the best don't take the elite slots and are
+// penalized in selection
+func TestNichingSpreadsElites(t *testing.T) {
+	tracks := benchmarkTracks(20)
+
+	best := slices.Clone(tracks)
+	nearCopy := slices.Clone(tracks)
+	nearCopy[5], nearCopy[6] = nearCopy[6], nearCopy[5]
+
+	different := slices.Clone(tracks)
+	rand.New(rand.NewPCG(3, 4)).Shuffle(len(different), func(a, b int) { different[a], different[b] = different[b], different[a] })
+
+	population := []Individual{{best, 1.0}, {nearCopy, 1.1}, {different, 1.5}, {slices.Clone(different), 1.5}}
+	scratch := newGAScratch(len(population), len(tracks), len(tracks), 2)
+
+	scratch.spreadElites(population, 0.3)
+
+	if !slices.Equal(trackPathsOf(population[0].Genes), trackPathsOf(best)) || population[1].Score != 1.5 || population[2].Score != 1.1 {
+		t.Fatalf("Expected the best and a different ordering as elites, got scores %.1f, %.1f, %.1f",
+			population[0].Score, population[1].Score, population[2].Score)
+	}
+
+	scratch.shareFitness(population, 0.3)
+
+	if scratch.shared[0] != 1.0 || scratch.shared[1] != 1.5 {
+		t.Errorf("Expected elites to keep their scores, got %.2f, %.2f", scratch.shared[0], scratch.shared[1])
+	}
+
+	if scratch.shared[2] <= 1.1 || scratch.shared[3] <= 1.5 {
+		t.Errorf("Expected near-duplicates of elites to be penalized, got %.2f, %.2f", scratch.shared[2], scratch.shared[3])
+	}
+
+	// Without niching the population keeps its order and scores
+	plain := []Individual{{best, 1.0}, {nearCopy, 1.1}, {different, 1.5}}
+	scratch.spreadElites(plain, 0)
+	scratch.shareFitness(plain, 0)
+
+	if plain[1].Score != 1.1 || scratch.shared[1] != 1.1 {
+		t.Error("Expected no reordering or penalty with niching off")
+	}
+}
+
+// BenchmarkBreed compares breeding with fresh buffers every generation (the old behaviour) against reused scratch
+func BenchmarkBreed(b *testing.B) {
+	tracks := benchmarkTracks(200)
+	rng := rand.New(rand.NewPCG(1, 2))
+	population, nextGen := testPopulation(tracks, populationSize, rng)
+
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for range b.N {
+			scratch := newGAScratch(len(population), len(tracks), len(tracks), 0)
+			scratch.shareFitness(population, 0)
+			scratch.breed(population, nextGen, rng)
+		}
+	})
+
+	b.Run("reused", func(b *testing.B) {
+		b.ReportAllocs()
+
+		scratch := newGAScratch(len(population), len(tracks), len(tracks), 0)
+
+		for range b.N {
+			scratch.shareFitness(population, 0)
+			scratch.breed(population, nextGen, rng)
+		}
+	})
+}
+
 // BenchmarkReverseSegment measures mutation operation performance
 func BenchmarkReverseSegment(b *testing.B) {
 	tracks := make([]playlist.Track, 100)
@@ -496,3 +1453,82 @@ func BenchmarkReverseSegment(b *testing.B) {
 		reverseSegment(tracks, 25, 75)
 	}
 }
+
+// TestPermutationHash verifies the fitness cache key identifies an ordering
+func TestPermutationHash(t *testing.T) {
+	tracks := make([]playlist.Track, 6)
+	for i := range tracks {
+		tracks[i].Index = i
+	}
+
+	reversed := slices.Clone(tracks)
+	slices.Reverse(reversed)
+
+	swapped := slices.Clone(tracks)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+
+	if permutationHash(tracks) != permutationHash(slices.Clone(tracks)) {
+		t.Error("Expected equal orderings to hash equally")
+	}
+
+	if permutationHash(tracks) == permutationHash(reversed) || permutationHash(tracks) == permutationHash(swapped) {
+		t.Error("Expected different orderings to hash differently")
+	}
+}
+
+// benchmarkTracks returns n tracks with varied keys, BPM, energy, artists and genres
+func benchmarkTracks(n int) []playlist.Track {
+	genres := []string{"House", "Techno", "Drum and Bass", "Ambient"}
+
+	tracks := make([]playlist.Track, n)
+	for i := range tracks {
+		key := strconv.Itoa(1+i%12) + "AB"[i%2:i%2+1]
+		tracks[i] = playlist.Track{
+			Index:     i,
+			Path:      "track" + strconv.Itoa(i),
+			Key:       key,
+			ParsedKey: parseKey(key),
+			BPM:       90.0 + float64(i*7%80),
+			Energy:    1 + i%10,
+			Artist:    "Artist" + strconv.Itoa(i%17),
+			Album:     "Album" + strconv.Itoa(i%23),
+			Genre:     genres[i%len(genres)],
+		}
+	}
+
+	return tracks
+}
+
+// BenchmarkCalculateFitnessLarge compares the flat edge-cost path with the component breakdown on 500 tracks
+func BenchmarkCalculateFitnessLarge(b *testing.B) {
+	tracks := benchmarkTracks(500)
+	ctx := buildEdgeFitnessCache(tracks)
+	cfg := config.DefaultConfig()
+	updateNormalizedWeights(ctx, cfg)
+
+	b.Run("flat", func(b *testing.B) {
+		for range b.N {
+			calculateFitness(tracks, cfg, ctx)
+		}
+	})
+
+	b.Run("breakdown", func(b *testing.B) {
+		for range b.N {
+			calculateFitnessWithBreakdown(tracks, cfg, ctx)
+		}
+	})
+}
+
+// BenchmarkBuildEdgeFitnessCache measures building the edge cache of a large library with varied genres
+func BenchmarkBuildEdgeFitnessCache(b *testing.B) {
+	genres := []string{"House", "Deep House", "Techno", "Drum & Bass; Liquid", "Jungle", "Ambient", "Hip-Hop", "Funk / Soul"}
+
+	tracks := benchmarkTracks(1000)
+	for i := range tracks {
+		tracks[i].Genre = genres[i%len(genres)]
+	}
+
+	for range b.N {
+		buildEdgeFitnessCache(tracks)
+	}
+}
@@ -0,0 +1,211 @@
+// ABOUTME: gRPC server mirroring the REST API (Optimize, StreamProgress, Analyze)
+// ABOUTME: Served by `playlist-sorter serve -grpc-addr`; the service is defined in api/playlistsorter.proto
+
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"playlist-sorter/api"
+	"playlist-sorter/playlist"
+)
+
+// grpcServer implements api.PlaylistSorterServer on top of the same sort and analysis as the REST API
+type grpcServer struct {
+	api.UnimplementedPlaylistSorterServer
+
+	maxDuration time.Duration // Upper bound for a sort request's optimizer run time
+}
+
+// newGRPCServer returns a gRPC server with the PlaylistSorter service registered
+func newGRPCServer(maxDuration time.Duration) *grpc.Server {
+	srv := grpc.NewServer()
+	api.RegisterPlaylistSorterServer(srv, &grpcServer{maxDuration: maxDuration})
+
+	return srv
+}
+
+// Optimize sorts a playlist for the requested duration; cancelling the call stops the optimizer early
+func (s *grpcServer) Optimize(ctx context.Context, req *api.OptimizeRequest) (*api.PlaylistResult, error) {
+	sortReq, duration, err := s.sortRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	resp, err := serveSort(ctx, sortReq, nil)
+	if err != nil {
+		return nil, sortStatus(err)
+	}
+
+	return newPlaylistResult(resp), nil
+}
+
+// StreamProgress sorts like Optimize, sending the optimizer's updates and then the result
+func (s *grpcServer) StreamProgress(req *api.OptimizeRequest, stream grpc.ServerStreamingServer[api.Progress]) error {
+	sortReq, duration, err := s.sortRequest(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(stream.Context(), duration)
+	defer cancel()
+
+	type sortResult struct {
+		resp playlistResponse
+		err  error
+	}
+
+	updates := make(chan GAUpdate, 10)
+	done := make(chan sortResult, 1)
+
+	go func() {
+		resp, err := serveSort(ctx, sortReq, updates)
+		done <- sortResult{resp: resp, err: err}
+	}()
+
+	for {
+		select {
+		case update := <-updates:
+			if err := stream.Send(newProgress(update)); err != nil {
+				cancel()
+				<-done
+
+				return err
+			}
+		case result := <-done:
+			if result.err != nil {
+				return sortStatus(result.err)
+			}
+
+			return stream.Send(&api.Progress{Result: newPlaylistResult(result.resp)})
+		}
+	}
+}
+
+// Analyze scores a playlist's current order against its theoretical minimum
+func (s *grpcServer) Analyze(_ context.Context, req *api.AnalyzeRequest) (*api.PlaylistResult, error) {
+	if req.GetPlaylist() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing playlist")
+	}
+
+	resp, err := serveAnalyze(req.GetPlaylist())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return newPlaylistResult(resp), nil
+}
+
+// sortRequest validates req, returning it as a sortRequest with its run time capped at maxDuration
+func (s *grpcServer) sortRequest(req *api.OptimizeRequest) (sortRequest, time.Duration, error) {
+	if req.GetPlaylist() == "" {
+		return sortRequest{}, 0, status.Error(codes.InvalidArgument, "missing playlist")
+	}
+
+	duration := defaultSortDuration
+	if req.GetDuration() != nil {
+		if err := req.GetDuration().CheckValid(); err != nil || req.GetDuration().AsDuration() <= 0 {
+			return sortRequest{}, 0, status.Errorf(codes.InvalidArgument, "invalid duration %v", req.GetDuration().AsDuration())
+		}
+
+		duration = req.GetDuration().AsDuration()
+	}
+
+	return sortRequest{Playlist: req.GetPlaylist(), Output: req.GetOutput()}, min(duration, s.maxDuration), nil
+}
+
+// sortStatus maps a serveSort error to a gRPC status: cancellation is reported as such, write
+// failures are internal, loading failures are the caller's
+func sortStatus(err error) error {
+	var writeErr *sortWriteError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.As(err, &writeErr):
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// newPlaylistResult converts a REST response to its gRPC message
+func newPlaylistResult(resp playlistResponse) *api.PlaylistResult {
+	result := &api.PlaylistResult{
+		Playlist:  resp.Playlist,
+		Tracks:    resp.Tracks,
+		Fitness:   resp.Fitness,
+		Breakdown: newBreakdown(resp.Breakdown),
+		Written:   resp.Written,
+		Skipped:   resp.Skipped,
+	}
+
+	if resp.TheoreticalMinimum != nil {
+		result.TheoreticalMinimum = newBreakdown(*resp.TheoreticalMinimum)
+	}
+
+	if resp.Headroom != nil {
+		result.Headroom = newBreakdown(*resp.Headroom)
+	}
+
+	return result
+}
+
+// newProgress converts an optimizer update to its gRPC message
+func newProgress(update GAUpdate) *api.Progress {
+	paths := make([]string, len(update.BestPlaylist))
+	for i, t := range update.BestPlaylist {
+		paths[i] = t.Path
+	}
+
+	return &api.Progress{
+		Generation:  int64(update.Generation),
+		BestFitness: update.BestFitness,
+		GenPerSec:   update.GenPerSec,
+		Breakdown:   newBreakdown(update.Breakdown),
+		Tracks:      paths,
+		Optimal:     update.Optimal,
+	}
+}
+
+// newBreakdown converts a fitness breakdown to its gRPC message
+func newBreakdown(b playlist.Breakdown) *api.Breakdown {
+	return &api.Breakdown{
+		Total:        b.Total,
+		Harmonic:     b.Harmonic,
+		EnergyDelta:  b.EnergyDelta,
+		BpmDelta:     b.BPMDelta,
+		GenreChange:  b.GenreChange,
+		SameArtist:   b.SameArtist,
+		SameAlbum:    b.SameAlbum,
+		PositionBias: b.PositionBias,
+		Shuffle:      b.Shuffle,
+		Novelty:      b.Novelty,
+		MixLength:    b.MixLength,
+		EnergyWave:   b.EnergyWave,
+		BpmBand:      b.BPMBand,
+		Bookends:     b.Bookends,
+		Freshness:    b.Freshness,
+		Favorites:    b.Favorites,
+
+		Transitions:           int32(b.Transitions),
+		BadKeyTransitions:     int32(b.BadKeyTransitions),
+		TotalEnergyChange:     b.TotalEnergyChange,
+		TotalBpmDrift:         b.TotalBPMDrift,
+		GenreChanges:          int32(b.GenreChanges),
+		SameArtistAdjacencies: int32(b.SameArtistAdjacencies),
+		SameAlbumAdjacencies:  int32(b.SameAlbumAdjacencies),
+		RepeatedAdjacencies:   int32(b.RepeatedAdjacencies),
+		BpmBandChanges:        int32(b.BPMBandChanges),
+		FreshAdjacencies:      int32(b.FreshAdjacencies),
+	}
+}
@@ -0,0 +1,236 @@
+// ABOUTME: Tests for the gRPC API served by `serve -grpc-addr`
+// ABOUTME: Runs the service over an in-memory listener against a playlist of tag-only MP3 files
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"playlist-sorter/api"
+)
+
+// writeTagOnlyMP3 writes an "MP3" consisting of just an ID3v2.3 tag with artist, key/energy comment,
+// BPM and genre (omitted if empty)
+func writeTagOnlyMP3(t *testing.T, path, artist, key string, energy int, bpm float64, genre string) {
+	t.Helper()
+
+	frame := func(id string, body []byte) []byte {
+		f := binary.BigEndian.AppendUint32([]byte(id), uint32(len(body)))
+
+		return append(append(f, 0, 0), body...)
+	}
+
+	var body []byte
+	body = append(body, frame("TPE1", append([]byte{0}, artist...))...)
+	body = append(body, frame("COMM", fmt.Appendf([]byte{0, 'e', 'n', 'g', 0}, "%s - Energy %d", key, energy))...)
+	body = append(body, frame("TBPM", fmt.Appendf([]byte{0}, "%.0f", bpm))...)
+
+	if genre != "" {
+		body = append(body, frame("TCON", append([]byte{0}, genre...))...)
+	}
+
+	body = append(body, make([]byte, 16)...)
+
+	n := len(body)
+	tag := append([]byte{'I', 'D', '3', 3, 0, 0, byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}, body...)
+
+	if err := os.WriteFile(path, tag, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTestPlaylist writes a playlist of n tag-only tracks and returns its path. Up to
+// exactSolverMaxTracks tracks are solved exactly; more run the GA until the deadline.
+func writeTestPlaylist(t *testing.T, n int) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	var lines []string
+	for i := range n {
+		name := fmt.Sprintf("track%d.mp3", i)
+		key := fmt.Sprintf("%d%c", i*7%12+1, "AB"[i%2])
+		writeTagOnlyMP3(t, filepath.Join(dir, name), fmt.Sprintf("Artist %d", i%4), key, 3+i%6, 120+float64(i%5)*2, "")
+		lines = append(lines, name)
+	}
+
+	path := filepath.Join(dir, "set.m3u8")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// newTestGRPCClient serves the gRPC API over an in-memory listener and returns a client for it
+func newTestGRPCClient(t *testing.T) api.PlaylistSorterClient {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir()) // Keep the user's config out of the scores
+
+	lis := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(maxDuration)
+
+	go func() { _ = srv.Serve(lis) }()
+
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return api.NewPlaylistSorterClient(conn)
+}
+
+func TestGRPCOptimizeWritesResult(t *testing.T) {
+	client := newTestGRPCClient(t)
+	path := writeTestPlaylist(t, 5)
+	output := filepath.Join(filepath.Dir(path), "sorted.m3u8")
+
+	result, err := client.Optimize(context.Background(), &api.OptimizeRequest{
+		Playlist: path,
+		Duration: durationpb.New(time.Second),
+		Output:   output,
+	})
+	if err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+
+	if len(result.GetTracks()) != 5 {
+		t.Errorf("Expected 5 tracks, got %d", len(result.GetTracks()))
+	}
+
+	if result.GetWritten() != output {
+		t.Errorf("Expected written %q, got %q", output, result.GetWritten())
+	}
+
+	if result.GetBreakdown().GetTotal() != result.GetFitness() || result.GetBreakdown().GetTransitions() != 4 {
+		t.Errorf("Expected breakdown total %v with 4 transitions, got %v", result.GetFitness(), result.GetBreakdown())
+	}
+
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("Expected output written: %v", err)
+	}
+}
+
+func TestGRPCStreamProgressEndsWithResult(t *testing.T) {
+	client := newTestGRPCClient(t)
+
+	stream, err := client.StreamProgress(context.Background(), &api.OptimizeRequest{
+		Playlist: writeTestPlaylist(t, 5),
+		Duration: durationpb.New(time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []*api.Progress
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	if len(messages) < 2 {
+		t.Fatalf("Expected progress followed by a result, got %d messages", len(messages))
+	}
+
+	if first := messages[0]; first.GetResult() != nil || !first.GetOptimal() || len(first.GetTracks()) != 5 {
+		t.Errorf("Expected an optimal progress update for the 5-track playlist first, got %v", first)
+	}
+
+	last := messages[len(messages)-1].GetResult()
+	if last == nil || len(last.GetTracks()) != 5 || last.GetWritten() != "" {
+		t.Errorf("Expected the final message to carry an unwritten 5-track result, got %v", messages[len(messages)-1])
+	}
+}
+
+func TestGRPCAnalyze(t *testing.T) {
+	client := newTestGRPCClient(t)
+
+	result, err := client.Analyze(context.Background(), &api.AnalyzeRequest{Playlist: writeTestPlaylist(t, 5)})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	if result.GetTheoreticalMinimum() == nil || result.GetHeadroom() == nil {
+		t.Fatalf("Expected theoretical minimum and headroom, got %v", result)
+	}
+
+	if result.GetTheoreticalMinimum().GetTotal() > result.GetFitness() {
+		t.Errorf("Expected minimum %v <= fitness %v", result.GetTheoreticalMinimum().GetTotal(), result.GetFitness())
+	}
+}
+
+func TestGRPCRejectsBadRequests(t *testing.T) {
+	client := newTestGRPCClient(t)
+	ctx := context.Background()
+	path := writeTestPlaylist(t, 5)
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"optimize without playlist", func() error {
+			_, err := client.Optimize(ctx, &api.OptimizeRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"optimize negative duration", func() error {
+			_, err := client.Optimize(ctx, &api.OptimizeRequest{Playlist: path, Duration: durationpb.New(-time.Second)})
+			return err
+		}, codes.InvalidArgument},
+		{"optimize missing file", func() error {
+			_, err := client.Optimize(ctx, &api.OptimizeRequest{Playlist: "/nonexistent/set.m3u8"})
+			return err
+		}, codes.InvalidArgument},
+		{"optimize unwritable output", func() error {
+			_, err := client.Optimize(ctx, &api.OptimizeRequest{
+				Playlist: path,
+				Duration: durationpb.New(time.Second),
+				Output:   filepath.Join(filepath.Dir(path), "missing", "out.m3u8"),
+			})
+			return err
+		}, codes.Internal},
+		{"analyze without playlist", func() error {
+			_, err := client.Analyze(ctx, &api.AnalyzeRequest{})
+			return err
+		}, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// ABOUTME: Job queue for server mode: sort submissions run in the background under a concurrency limit
+// ABOUTME: Jobs can be listed, polled for status and result, and cancelled while queued or running
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Job queue defaults
+const (
+	defaultJobConcurrency = 2   // Sort jobs optimizing at the same time
+	maxFinishedJobs       = 100 // Finished jobs kept for status queries before the oldest are forgotten
+)
+
+// jobStatus is the lifecycle state of a sort job
+type jobStatus string
+
+const (
+	jobQueued    jobStatus = "queued"
+	jobRunning   jobStatus = "running"
+	jobDone      jobStatus = "done"
+	jobFailed    jobStatus = "failed"
+	jobCancelled jobStatus = "cancelled"
+)
+
+// finished reports whether a job in this state will not change any more
+func (s jobStatus) finished() bool {
+	return s == jobDone || s == jobFailed || s == jobCancelled
+}
+
+// jobInfo is the externally visible state of a job, returned by the job endpoints
+type jobInfo struct {
+	ID        string            `json:"id"`
+	Status    jobStatus         `json:"status"`
+	Request   sortRequest       `json:"request"`
+	Submitted time.Time         `json:"submitted"`
+	Started   time.Time         `json:"started,omitzero"`
+	Finished  time.Time         `json:"finished,omitzero"`
+	Result    *playlistResponse `json:"result,omitempty"` // Set when done
+	Error     string            `json:"error,omitempty"`  // Set when failed
+}
+
+// job is a queued or finished sort
+type job struct {
+	info     jobInfo
+	ctx      context.Context
+	cancel   context.CancelFunc
+	duration time.Duration // Longest the sort may run
+}
+
+// jobQueue runs submitted sorts, at most concurrency at a time, in submission order
+type jobQueue struct {
+	mu          sync.Mutex
+	jobs        map[string]*job
+	order       []string // Job IDs in submission order
+	concurrency int      // Most jobs running at once
+	running     int
+	nextID      int
+
+	// run performs a job's sort; replaced in tests
+	run func(ctx context.Context, req sortRequest) (playlistResponse, error)
+}
+
+// newJobQueue creates a queue running up to concurrency jobs at once
+func newJobQueue(concurrency int, run func(context.Context, sortRequest) (playlistResponse, error)) *jobQueue {
+	return &jobQueue{
+		jobs:        make(map[string]*job),
+		concurrency: max(concurrency, 1),
+		run:         run,
+	}
+}
+
+// submit queues a sort and returns its initial state. The job waits for a free slot, then runs
+// for at most duration.
+func (q *jobQueue) submit(req sortRequest, duration time.Duration) jobInfo {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextID++
+	j := &job{
+		info:     jobInfo{ID: strconv.Itoa(q.nextID), Status: jobQueued, Request: req, Submitted: time.Now()},
+		ctx:      ctx,
+		cancel:   cancel,
+		duration: duration,
+	}
+	q.jobs[j.info.ID] = j
+	q.order = append(q.order, j.info.ID)
+	q.forgetFinished()
+	info := j.info
+	q.dispatch()
+
+	return info
+}
+
+// dispatch starts the oldest queued jobs while slots are free (q.mu held)
+func (q *jobQueue) dispatch() {
+	for _, id := range q.order {
+		if q.running >= q.concurrency {
+			return
+		}
+
+		j := q.jobs[id]
+		if j.info.Status != jobQueued {
+			continue
+		}
+
+		j.info.Status = jobRunning
+		j.info.Started = time.Now()
+		q.running++
+
+		go q.process(j)
+	}
+}
+
+// process runs j and records the outcome
+func (q *jobQueue) process(j *job) {
+	defer j.cancel()
+
+	runCtx, cancel := context.WithTimeout(j.ctx, j.duration)
+	defer cancel()
+
+	resp, err := q.run(runCtx, j.info.Request)
+
+	// A cancelled run still returns its best order so far, but the client asked to drop it
+	if j.ctx.Err() != nil {
+		err = j.ctx.Err()
+	}
+
+	q.finish(j, &resp, err)
+}
+
+// finish records the outcome of j and hands its slot to the next queued job
+func (q *jobQueue) finish(j *job, resp *playlistResponse, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	defer q.dispatch()
+
+	q.running--
+	j.info.Finished = time.Now()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		j.info.Status = jobCancelled
+	case err != nil:
+		j.info.Status = jobFailed
+		j.info.Error = err.Error()
+	default:
+		j.info.Status = jobDone
+		j.info.Result = resp
+	}
+}
+
+// get returns the state of the job with the given ID
+func (q *jobQueue) get(id string) (jobInfo, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return jobInfo{}, false
+	}
+
+	return j.info, true
+}
+
+// list returns the state of every known job in submission order, without results
+func (q *jobQueue) list() []jobInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	infos := make([]jobInfo, 0, len(q.order))
+	for _, id := range q.order {
+		info := q.jobs[id].info
+		info.Result = nil
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// cancelJob cancels a queued or running job. Returns false for an unknown ID.
+func (q *jobQueue) cancelJob(id string) (jobInfo, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return jobInfo{}, false
+	}
+
+	j.cancel()
+
+	// A queued job is marked straight away; a running one when its sort returns
+	if j.info.Status == jobQueued {
+		j.info.Status = jobCancelled
+		j.info.Finished = time.Now()
+	}
+
+	return j.info, true
+}
+
+// forgetFinished drops the oldest finished jobs beyond maxFinishedJobs (q.mu held)
+func (q *jobQueue) forgetFinished() {
+	finished := 0
+	for _, id := range q.order {
+		if q.jobs[id].info.Status.finished() {
+			finished++
+		}
+	}
+
+	q.order = slices.DeleteFunc(q.order, func(id string) bool {
+		if finished <= maxFinishedJobs || !q.jobs[id].info.Status.finished() {
+			return false
+		}
+
+		delete(q.jobs, id)
+		finished--
+
+		return true
+	})
+}
+
+// handleSubmitJob queues a sort: POST /api/jobs with a sort request body. Responds 202 with the job.
+func (s *apiServer) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	req, duration, err := decodeSortRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, s.jobs.submit(req, min(duration, s.maxDuration)))
+}
+
+// handleListJobs lists all known jobs: GET /api/jobs
+func (s *apiServer) handleListJobs(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.jobs.list())
+}
+
+// handleGetJob returns a job's status, with the result once done: GET /api/jobs/{id}
+func (s *apiServer) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.jobs.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("no such job"))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleCancelJob cancels a queued or running job: DELETE /api/jobs/{id}
+func (s *apiServer) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.jobs.cancelJob(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("no such job"))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
@@ -0,0 +1,302 @@
+// ABOUTME: Tests for the server mode job queue
+// ABOUTME: Verifies the concurrency limit, cancellation, results and the job endpoints
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingSorts returns a job runner that reports each started job on started and finishes it
+// when its playlist is sent on release (or its context ends)
+func blockingSorts() (run func(context.Context, sortRequest) (playlistResponse, error), started chan string, release chan string) {
+	started, release = make(chan string, 10), make(chan string, 10)
+
+	run = func(ctx context.Context, req sortRequest) (playlistResponse, error) {
+		started <- req.Playlist
+
+		for {
+			select {
+			case name := <-release:
+				if name == req.Playlist {
+					return playlistResponse{Playlist: req.Playlist, Fitness: 0.5}, nil
+				}
+
+				release <- name
+			case <-ctx.Done():
+				return playlistResponse{}, ctx.Err()
+			}
+		}
+	}
+
+	return run, started, release
+}
+
+// waitForStatus polls a job until it reaches want
+func waitForStatus(t *testing.T, q *jobQueue, id string, want jobStatus) jobInfo {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		info, ok := q.get(id)
+		if ok && info.Status == want {
+			return info
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Job %s: expected status %s, got %s", id, want, info.Status)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestJobQueueConcurrencyLimit(t *testing.T) {
+	run, started, release := blockingSorts()
+	q := newJobQueue(1, run)
+
+	first := q.submit(sortRequest{Playlist: "a"}, time.Minute)
+	second := q.submit(sortRequest{Playlist: "b"}, time.Minute)
+
+	if got := <-started; got != "a" {
+		t.Fatalf("Expected the first submission to start first, got %s", got)
+	}
+
+	waitForStatus(t, q, first.ID, jobRunning)
+
+	if info, _ := q.get(second.ID); info.Status != jobQueued {
+		t.Errorf("Expected the second job to wait for a slot, got %s", info.Status)
+	}
+
+	release <- "a"
+
+	done := waitForStatus(t, q, first.ID, jobDone)
+	if done.Result == nil || done.Result.Fitness != 0.5 || done.Finished.IsZero() {
+		t.Errorf("Expected a finished job with its result, got %+v", done)
+	}
+
+	if got := <-started; got != "b" {
+		t.Fatalf("Expected the queued job to start once a slot freed, got %s", got)
+	}
+
+	release <- "b"
+	waitForStatus(t, q, second.ID, jobDone)
+
+	if list := q.list(); len(list) != 2 || list[0].ID != first.ID || list[0].Result != nil {
+		t.Errorf("Expected both jobs listed in submission order without results, got %+v", list)
+	}
+}
+
+func TestJobQueueCancel(t *testing.T) {
+	run, started, _ := blockingSorts()
+	q := newJobQueue(1, run)
+
+	running := q.submit(sortRequest{Playlist: "a"}, time.Minute)
+	queued := q.submit(sortRequest{Playlist: "b"}, time.Minute)
+
+	<-started
+
+	if info, ok := q.cancelJob(queued.ID); !ok || info.Status != jobCancelled {
+		t.Errorf("Expected a queued job to be cancelled straight away, got %+v", info)
+	}
+
+	if _, ok := q.cancelJob(running.ID); !ok {
+		t.Fatal("Expected the running job to be found")
+	}
+
+	waitForStatus(t, q, running.ID, jobCancelled)
+
+	if _, ok := q.cancelJob("missing"); ok {
+		t.Error("Expected an unknown job not to be found")
+	}
+
+	select {
+	case name := <-started:
+		t.Errorf("Expected the cancelled queued job never to run, but %s started", name)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestJobQueueFailure(t *testing.T) {
+	q := newJobQueue(1, func(context.Context, sortRequest) (playlistResponse, error) {
+		return playlistResponse{}, errors.New("no such playlist")
+	})
+
+	info := waitForStatus(t, q, q.submit(sortRequest{Playlist: "a"}, time.Minute).ID, jobFailed)
+	if info.Error != "no such playlist" || info.Result != nil {
+		t.Errorf("Expected the error without a result, got %+v", info)
+	}
+}
+
+func TestJobEndpoints(t *testing.T) {
+	run, started, release := blockingSorts()
+	s := &apiServer{maxDuration: maxDuration, jobs: newJobQueue(1, run)}
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/jobs", `{"playlist":"set.m3u8","duration":"10s"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var submitted jobInfo
+	if err := json.NewDecoder(rec.Body).Decode(&submitted); err != nil || submitted.ID == "" {
+		t.Fatalf("Expected the job in the response, got %q", rec.Body.String())
+	}
+
+	<-started
+	release <- "set.m3u8"
+	waitForStatus(t, s.jobs, submitted.ID, jobDone)
+
+	rec = do(http.MethodGet, "/api/jobs/"+submitted.ID, "")
+
+	var info jobInfo
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil || info.Status != jobDone || info.Result == nil {
+		t.Errorf("Expected the finished job with its result, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	if rec := do(http.MethodGet, "/api/jobs", ""); !strings.Contains(rec.Body.String(), `"status":"done"`) {
+		t.Errorf("Expected the job in the list, got %q", rec.Body.String())
+	}
+
+	for _, tt := range []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodPost, "/api/jobs", `{}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/jobs", `{"playlist":"set.m3u8","duration":"soon"}`, http.StatusBadRequest},
+		{http.MethodGet, "/api/jobs/99", "", http.StatusNotFound},
+		{http.MethodDelete, "/api/jobs/99", "", http.StatusNotFound},
+	} {
+		if rec := do(tt.method, tt.target, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.target, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestCancelledJobLeavesOutputUntouched(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	path := writeTestPlaylist(t, exactSolverMaxTracks+4)
+	output := filepath.Join(filepath.Dir(path), "sorted.m3u8")
+
+	if err := os.WriteFile(output, []byte("previous\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &apiServer{maxDuration: maxDuration, jobs: newJobQueue(1, serveSortJob)}
+
+	rec := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"playlist":%q,"duration":"1m","output":%q}`, path, output)
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(body)))
+
+	var submitted jobInfo
+	if err := json.NewDecoder(rec.Body).Decode(&submitted); err != nil {
+		t.Fatalf("Expected the job in the response, got %q", rec.Body.String())
+	}
+
+	waitForStatus(t, s.jobs, submitted.ID, jobRunning)
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/jobs/"+submitted.ID, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	waitForStatus(t, s.jobs, submitted.ID, jobCancelled)
+
+	if data, err := os.ReadFile(output); err != nil || string(data) != "previous\n" {
+		t.Errorf("Expected the cancelled job to leave the output alone, got %q (%v)", data, err)
+	}
+}
+
+func TestConcurrentJobsUseTheirOwnSidecars(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Each playlist alternates techno with a genre only its own sidecar aliases to techno, and lists
+	// its tracks under /virtual, which only its own sidecar maps to its directory. Enough tracks that
+	// the loads overlap.
+	const playlistCount, trackCount = 8, 60
+
+	var playlists []string
+
+	for i := range playlistCount {
+		dir := t.TempDir()
+		alias := fmt.Sprintf("Set %d", i)
+
+		var lines []string
+
+		for k := range trackCount {
+			genre := "Techno"
+			if k%2 == 1 {
+				genre = alias
+			}
+
+			name := fmt.Sprintf("track%d.mp3", k)
+			writeTagOnlyMP3(t, filepath.Join(dir, name), fmt.Sprintf("Artist %d", k), "8A", 5, 124, genre)
+			lines = append(lines, "/virtual/"+name)
+		}
+
+		path := filepath.Join(dir, "set.m3u8")
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		sidecar := fmt.Sprintf(`{"path_map": ["/virtual=>%s"], "genre_aliases": {%q: "techno"}}`, filepath.ToSlash(dir), alias)
+		if err := os.WriteFile(filepath.Join(dir, "set.sorter.json"), []byte(sidecar), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		playlists = append(playlists, path)
+	}
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(playlistCount))
+
+	q := newJobQueue(playlistCount, serveSortJob)
+
+	var ids []string
+	for _, path := range playlists {
+		ids = append(ids, q.submit(sortRequest{Playlist: path}, 50*time.Millisecond).ID)
+	}
+
+	for i, id := range ids {
+		info := waitForStatus(t, q, id, jobDone)
+		dir := filepath.Dir(playlists[i])
+
+		if len(info.Result.Skipped) > 0 || len(info.Result.Tracks) != trackCount {
+			t.Errorf("Job %s: expected %d tracks and none skipped, got %d (skipped %d)", id, trackCount, len(info.Result.Tracks), len(info.Result.Skipped))
+
+			continue
+		}
+
+		for _, track := range info.Result.Tracks {
+			if filepath.Dir(track) != dir {
+				t.Errorf("Job %s: expected tracks mapped into %s by its sidecar, got %s", id, dir, track)
+
+				break
+			}
+		}
+
+		if changes := info.Result.Breakdown.GenreChanges; changes != 0 {
+			t.Errorf("Job %s: expected its sidecar's alias to make every track techno, got %d genre changes", id, changes)
+		}
+	}
+}
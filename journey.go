@@ -0,0 +1,304 @@
+// ABOUTME: Journey subcommand building a new playlist from a music library, starting at a seed track
+// ABOUTME: Greedily chains harmonically compatible tracks within an energy step, then polishes with 2-opt
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// audioExtensions are the file extensions treated as tracks in a library scan
+var audioExtensions = []string{".mp3", ".flac", ".m4a", ".mp4", ".aac", ".ogg", ".opus", ".wav", ".aiff", ".aif"}
+
+// findAudioFiles returns the absolute paths of all audio files under dir (recursively), sorted.
+// Hidden files and directories are skipped.
+func findAudioFiles(dir string) ([]string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []string
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.IsDir() && slices.Contains(audioExtensions, strings.ToLower(filepath.Ext(path))) {
+			found = append(found, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	slices.Sort(found)
+
+	return found, nil
+}
+
+// journeyRules are the constraints every transition of a journey must meet
+type journeyRules struct {
+	MaxEnergyStep int  // Largest energy change between neighbours (0 = any; unknown energy always passes)
+	AllowClashes  bool // Allow harmonically incompatible transitions
+}
+
+// allows reports whether the transition a -> b meets the rules
+func (r journeyRules) allows(a, b *playlist.Track) bool {
+	if !r.AllowClashes && playlist.IsHarmonicClash(playlist.HarmonicDistanceParsed(a.OutKey(), b.InKey())) {
+		return false
+	}
+
+	if r.MaxEnergyStep > 0 && a.Energy > 0 && b.Energy > 0 && max(a.Energy-b.Energy, b.Energy-a.Energy) > r.MaxEnergyStep {
+		return false
+	}
+
+	return true
+}
+
+// buildJourney chains up to length tracks from library starting at seed: each step appends the
+// cheapest unused track the rules allow. The journey ends early when no track qualifies.
+// The chain is then polished with 2-opt reversals that keep the seed first and every transition allowed.
+func buildJourney(seed playlist.Track, library []playlist.Track, length int, rules journeyRules, scorer *pathScorer) []playlist.Track {
+	journey := []playlist.Track{seed}
+
+	used := make([]bool, len(library))
+
+	for len(journey) < length {
+		last := &journey[len(journey)-1]
+		best := -1
+		bestCost := 0.0
+
+		for i := range library {
+			if used[i] || library[i].Path == seed.Path || !rules.allows(last, &library[i]) {
+				continue
+			}
+
+			if cost := scorer.edgeCost(last, &library[i]); best < 0 || cost < bestCost {
+				best, bestCost = i, cost
+			}
+		}
+
+		if best < 0 {
+			break
+		}
+
+		used[best] = true
+		journey = append(journey, library[best])
+	}
+
+	polishJourney(journey, rules, scorer)
+
+	return journey
+}
+
+// polishJourney improves a journey with 2-opt segment reversals, never moving the seed (position 0)
+// and only accepting reversals whose transitions all still meet the rules
+func polishJourney(tracks []playlist.Track, rules journeyRules, scorer *pathScorer) {
+	for improved := true; improved; {
+		improved = false
+
+		for i := 1; i < len(tracks)-1; i++ {
+			for j := i + 1; j < len(tracks); j++ {
+				before := scorer.segmentCost(tracks, i, j)
+
+				reverseSegment(tracks, i, j)
+
+				if journeyAllowed(tracks[i-1:min(j+2, len(tracks))], rules) &&
+					hasFitnessImproved(scorer.segmentCost(tracks, i, j), before, floatingPointEpsilon) {
+					improved = true
+				} else {
+					reverseSegment(tracks, i, j)
+				}
+			}
+		}
+	}
+}
+
+// journeyAllowed reports whether every transition in tracks meets the rules
+func journeyAllowed(tracks []playlist.Track, rules journeyRules) bool {
+	for i := 1; i < len(tracks); i++ {
+		if !rules.allows(&tracks[i-1], &tracks[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// librarySeed returns the library's copy of seed when the seed file is part of the library, so it
+// carries the same imputed and corrected values as the tracks it is chained with
+func librarySeed(seed *playlist.Track, library []playlist.Track) (*playlist.Track, bool) {
+	if i := slices.IndexFunc(library, func(t playlist.Track) bool { return t.Path == seed.Path }); i >= 0 {
+		return &library[i], true
+	}
+
+	return seed, false
+}
+
+// loadLibrary reads the metadata of every audio file under dir, skipping unreadable files
+func loadLibrary(dir string, cfg config.GAConfig, imputeEnergy bool, out cliPrinter) ([]playlist.Track, int, error) {
+	paths, err := findAudioFiles(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tracks := make([]playlist.Track, 0, len(paths))
+	skipped := 0
+
+	for i, path := range paths {
+		if (i+1)%100 == 0 {
+			out.Printf("[+] Scanned %d/%d files...\n", i+1, len(paths))
+		}
+
+		track, err := playlist.GetTrackMetadata(path, "")
+		if err != nil {
+			skipped++
+
+			continue
+		}
+
+		tracks = append(tracks, *track)
+	}
+
+	playlist.MarkFavorites(tracks, cfg.FavoriteMinRating, cfg.Favorites)
+	playlist.MarkFresh(tracks, time.Now(), cfg.FreshDays)
+	playlist.CorrectBPM(tracks, cfg.BPMRanges)
+
+	if imputeEnergy || cfg.ImputeEnergy {
+		playlist.ImputeEnergy(tracks, cfg.EnergyByGenre)
+	}
+
+	return tracks, skipped, nil
+}
+
+// runJourney implements `playlist-sorter journey [flags] <seed-track>`
+func runJourney(args []string) int {
+	fs := newFlagSet("journey", "journey [flags] <seed-track>")
+	libraryDir := fs.String("library", "", "folder of audio files to pick tracks from (default: the seed track's folder)")
+	length := fs.Int("length", 20, "number of tracks in the journey, including the seed")
+	maxEnergyStep := fs.Int("max-energy-step", 2, "largest energy change between neighbouring tracks (0 = any)")
+	allowClashes := fs.Bool("allow-clashes", false, "allow harmonically incompatible transitions")
+	output := fs.String("output", "journey.m3u8", "write the journey to this playlist file, or - for stdout")
+	imputeEnergy := fs.Bool("impute-energy", false, "estimate missing energy values from genre/BPM")
+	preset := fs.String("preset", "", "score transitions with a built-in weight preset: "+presetNames())
+
+	seedPath, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	if *length < 2 {
+		log.Printf("Journey error: -length must be at least 2")
+
+		return 2
+	}
+
+	seedPath, err := filepath.Abs(seedPath)
+	if err != nil {
+		log.Printf("Journey error: %v", err)
+
+		return 1
+	}
+
+	if *libraryDir == "" {
+		*libraryDir = filepath.Dir(seedPath)
+	}
+
+	if !isDir(*libraryDir) {
+		log.Printf("Journey error: %s is not a directory", *libraryDir)
+
+		return 2
+	}
+
+	cfg, _ := config.LoadConfig(config.GetConfigPath())
+
+	if *preset != "" {
+		if cfg, err = config.ApplyPreset(cfg, *preset); err != nil {
+			log.Printf("%v", err)
+
+			return 2
+		}
+	}
+
+	playlist.SetMixTags(cfg.IntroTag, cfg.OutroTag)
+	playlist.SetGenreAliases(cfg.GenreAliases)
+
+	seed, err := playlist.GetTrackMetadata(seedPath, "")
+	if err != nil {
+		log.Printf("Journey error: seed track: %v", err)
+
+		return 1
+	}
+
+	// Playlist on stdout: keep stdout clean for the playlist
+	out := cliPrinter{w: os.Stdout, plain: noColor(false)}
+	if *output == playlist.StdioPath {
+		out.w = io.Discard
+	}
+
+	out.Printf("Scanning %s...\n", *libraryDir)
+
+	library, skipped, err := loadLibrary(*libraryDir, cfg, *imputeEnergy, out)
+	if err != nil {
+		log.Printf("Journey error: %v", err)
+
+		return 1
+	}
+
+	out.Printf("Library: %d tracks (%d unreadable files skipped)\n", len(library), skipped)
+
+	seed, inLibrary := librarySeed(seed, library)
+
+	pool := library
+	if !inLibrary {
+		pool = append(slices.Clone(library), *seed)
+	}
+
+	scorer := newPathScorer(pool, cfg, nil)
+	rules := journeyRules{MaxEnergyStep: *maxEnergyStep, AllowClashes: *allowClashes}
+
+	journey := buildJourney(*seed, library, *length, rules, scorer)
+
+	if len(journey) < *length {
+		out.Printf("Only %d tracks could be chained within the rules (try -max-energy-step or -allow-clashes)\n", len(journey))
+	}
+
+	for i, t := range journey {
+		out.Printf("%3d. %s - %s  [%s, energy %s, %.0f BPM]\n", i+1, t.Artist, t.Title, keyName(t.ParsedKey, t.Key), t.EnergyLabel(), t.BPM)
+	}
+
+	out.Printf("Fitness: %.6f\n", scorer.breakdown(journey).Total)
+
+	if err := playlist.WritePlaylist(*output, journey); err != nil {
+		log.Printf("Journey error: %v", err)
+
+		return 1
+	}
+
+	out.Printf("Wrote %d tracks to %s\n", len(journey), *output)
+
+	return 0
+}
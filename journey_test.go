@@ -0,0 +1,133 @@
+// ABOUTME: Tests for the journey subcommand
+// ABOUTME: Verifies the library scan, seed selection, the transition rules and the greedy chain
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+func TestFindAudioFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"b.mp3", "A/c.FLAC", "A/notes.txt", "set.m3u8", ".hidden.mp3", ".cache/d.mp3"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := findAudioFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join(dir, "A", "c.FLAC"), filepath.Join(dir, "b.mp3")}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := findAudioFiles(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing folder")
+	}
+}
+
+func TestLibrarySeed(t *testing.T) {
+	library := []playlist.Track{
+		{Path: "/music/a.mp3", Energy: 5, EnergyEstimated: true},
+		{Path: "/music/b.mp3", Energy: 6},
+	}
+
+	seed, inLibrary := librarySeed(&playlist.Track{Path: "/music/a.mp3"}, library)
+	if !inLibrary || seed != &library[0] || !seed.EnergyEstimated {
+		t.Errorf("Expected the library's copy of the seed, got %+v (in library: %v)", seed, inLibrary)
+	}
+
+	outside := &playlist.Track{Path: "/elsewhere/c.mp3"}
+	if seed, inLibrary := librarySeed(outside, library); inLibrary || seed != outside {
+		t.Errorf("Expected a seed outside the library to be used as is, got %+v (in library: %v)", seed, inLibrary)
+	}
+}
+
+func TestJourneyRules(t *testing.T) {
+	track := func(key string, energy int) *playlist.Track {
+		return &playlist.Track{Key: key, ParsedKey: parseKey(key), Energy: energy}
+	}
+
+	tests := []struct {
+		name  string
+		a, b  *playlist.Track
+		rules journeyRules
+		want  bool
+	}{
+		{"compatible keys", track("8A", 5), track("9A", 6), journeyRules{MaxEnergyStep: 2}, true},
+		{"key clash", track("8A", 5), track("2B", 5), journeyRules{MaxEnergyStep: 2}, false},
+		{"key clash allowed", track("8A", 5), track("2B", 5), journeyRules{MaxEnergyStep: 2, AllowClashes: true}, true},
+		{"energy jump", track("8A", 3), track("8A", 7), journeyRules{MaxEnergyStep: 2}, false},
+		{"energy drop", track("8A", 7), track("8A", 3), journeyRules{MaxEnergyStep: 2}, false},
+		{"any energy step", track("8A", 3), track("8A", 7), journeyRules{}, true},
+		{"unknown energy", track("8A", 0), track("8A", 9), journeyRules{MaxEnergyStep: 2}, true},
+		{"unknown key", track("", 5), track("8A", 5), journeyRules{MaxEnergyStep: 2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.allows(tt.a, tt.b); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildJourney(t *testing.T) {
+	track := func(name, key string, energy int, bpm float64) playlist.Track {
+		return playlist.Track{Path: name, Artist: name, Key: key, ParsedKey: parseKey(key), Energy: energy, BPM: bpm, Genre: "House"}
+	}
+
+	seed := track("seed", "8A", 4, 124)
+	library := []playlist.Track{
+		seed,
+		track("clash", "3B", 4, 124),
+		track("jump", "8A", 9, 124),
+		track("next", "8A", 5, 124),
+		track("then", "9A", 6, 125),
+		track("far", "9A", 6, 170),
+	}
+
+	cfg := config.DefaultConfig()
+	rules := journeyRules{MaxEnergyStep: 2}
+	scorer := newPathScorer(library, cfg, nil)
+
+	journey := buildJourney(seed, library, 10, rules, scorer)
+
+	paths := trackPathsOf(journey)
+	if paths[0] != "seed" {
+		t.Fatalf("Expected the journey to start at the seed, got %v", paths)
+	}
+
+	if len(journey) != 4 || slices.Contains(paths, "clash") || slices.Contains(paths, "jump") {
+		t.Errorf("Expected the seed plus the three tracks the rules allow, got %v", paths)
+	}
+
+	if !journeyAllowed(journey, rules) {
+		t.Errorf("Expected every transition to meet the rules, got %v", paths)
+	}
+
+	if paths[len(paths)-1] != "far" {
+		t.Errorf("Expected the big tempo change to be left for last, got %v", paths)
+	}
+
+	if short := buildJourney(seed, library, 2, rules, scorer); len(short) != 2 || short[1].Path != "next" {
+		t.Errorf("Expected the cheapest allowed step after the seed, got %v", trackPathsOf(short))
+	}
+}
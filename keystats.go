@@ -0,0 +1,133 @@
+// ABOUTME: Key statistics for analyze: tracks per Camelot key and the mix of harmonic transition kinds
+// ABOUTME: Compares the original order with an optimized one when analyze is asked to optimize
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"playlist-sorter/playlist"
+)
+
+// Harmonic transition categories, by harmonic distance (see playlist.HarmonicDistanceParsed)
+const (
+	harmonicPerfect      = iota // Same key
+	harmonicExcellent           // Neighbour or relative major/minor
+	harmonicDramatic            // Parallel major/minor
+	harmonicIncompatible        // Anything else, including transitions from or to a track without a key
+	numHarmonicCategories
+)
+
+var harmonicCategoryNames = [numHarmonicCategories]string{"Perfect", "Excellent", "Dramatic", "Incompatible"}
+
+// namedOrder is one ordering of the playlist's tracks, labelled for a statistics column
+type namedOrder struct {
+	name   string
+	tracks []playlist.Track
+}
+
+// keyCount is the number of tracks in one key
+type keyCount struct {
+	key    playlist.CamelotKey
+	tracks int
+}
+
+// keyCounts counts tracks per key in Camelot wheel order (1A, 1B, 2A, ...), plus the tracks
+// without a parseable key
+func keyCounts(tracks []playlist.Track) (counts []keyCount, missing int) {
+	byKey := make(map[playlist.CamelotKey]int)
+
+	for i := range tracks {
+		if tracks[i].ParsedKey == nil {
+			missing++
+
+			continue
+		}
+
+		byKey[*tracks[i].ParsedKey]++
+	}
+
+	for key, n := range byKey {
+		counts = append(counts, keyCount{key: key, tracks: n})
+	}
+
+	slices.SortFunc(counts, func(a, b keyCount) int {
+		if a.key.Number != b.key.Number {
+			return a.key.Number - b.key.Number
+		}
+
+		return int(a.key.Letter) - int(b.key.Letter)
+	})
+
+	return counts, missing
+}
+
+// harmonicCategories counts the transitions of tracks in their current order per category
+func harmonicCategories(tracks []playlist.Track) [numHarmonicCategories]int {
+	var counts [numHarmonicCategories]int
+
+	for i := 1; i < len(tracks); i++ {
+		switch playlist.HarmonicDistanceParsed(tracks[i-1].OutKey(), tracks[i].InKey()) {
+		case 0:
+			counts[harmonicPerfect]++
+		case 1:
+			counts[harmonicExcellent]++
+		case 2:
+			counts[harmonicDramatic]++
+		default:
+			counts[harmonicIncompatible]++
+		}
+	}
+
+	return counts
+}
+
+// printKeyStats prints the tracks per key and the share of transitions in each harmonic category,
+// with a column for each order (e.g. original and optimized)
+func printKeyStats(tracks []playlist.Track, orders []namedOrder) {
+	counts, missing := keyCounts(tracks)
+
+	fmt.Printf("\nKeys: %d distinct, %d tracks without a parseable key\n", len(counts), missing)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	lines := []string{"Key\tTracks\tShare"}
+
+	for _, c := range counts {
+		lines = append(lines, fmt.Sprintf("%s\t%d\t%.0f%%", c.key.String(), c.tracks, percent(c.tracks, len(tracks))))
+	}
+
+	header := "\nTransition"
+
+	categories := make([][numHarmonicCategories]int, len(orders))
+	for i, order := range orders {
+		header += "\t" + order.name
+		categories[i] = harmonicCategories(order.tracks)
+	}
+
+	lines = append(lines, header)
+
+	for c, name := range harmonicCategoryNames {
+		line := name
+
+		for i, order := range orders {
+			n := categories[i][c]
+			line += fmt.Sprintf("\t%d (%.0f%%)", n, percent(n, len(order.tracks)-1))
+		}
+
+		lines = append(lines, line)
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			log.Printf("Warning: failed to write key statistics: %v", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Printf("Warning: failed to flush output: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Tests for analyze's key statistics
+// ABOUTME: Verifies per-key counts in wheel order and the harmonic transition categories
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func TestKeyCounts(t *testing.T) {
+	tracks := []playlist.Track{
+		{Key: "8B", ParsedKey: parseKey("8B")},
+		{Key: "10A", ParsedKey: parseKey("10A")},
+		{Key: "8A", ParsedKey: parseKey("8A")},
+		{Key: "8A", ParsedKey: parseKey("8A")},
+		{Key: ""},
+	}
+
+	counts, missing := keyCounts(tracks)
+	if missing != 1 {
+		t.Errorf("Expected 1 track without a key, got %d", missing)
+	}
+
+	want := []string{"8A:2", "8B:1", "10A:1"}
+	if len(counts) != len(want) {
+		t.Fatalf("Expected %d keys, got %d", len(want), len(counts))
+	}
+
+	for i, c := range counts {
+		if got := fmt.Sprintf("%s:%d", c.key.String(), c.tracks); got != want[i] {
+			t.Errorf("Position %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+}
+
+func TestHarmonicCategories(t *testing.T) {
+	order := []string{"8A", "8A", "9A", "9B", "6A", "1B", ""}
+
+	tracks := make([]playlist.Track, len(order))
+	for i, key := range order {
+		tracks[i] = playlist.Track{Key: key, ParsedKey: parseKey(key)}
+	}
+
+	// 8A>8A perfect, 8A>9A and 9A>9B excellent, 9B>6A dramatic (parallel), 6A>1B and 1B>none incompatible
+	got := harmonicCategories(tracks)
+	if want := [numHarmonicCategories]int{1, 2, 1, 2}; got != want {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,160 @@
+// ABOUTME: Fitness landscape snapshots: periodic dumps of a GA population's scores and diversity
+// ABOUTME: Written as JSON lines for offline analysis of premature convergence and hyperparameters
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"time"
+)
+
+// defaultLandscapeEvery is how many generations apart landscape snapshots are taken by default
+const defaultLandscapeEvery = 500
+
+// landscapeSnapshot is the state of a GA population at one generation
+type landscapeSnapshot struct {
+	Generation int     `json:"generation"`
+	ElapsedMs  int64   `json:"elapsed_ms"`
+	Stagnant   int     `json:"generations_without_improvement"`
+	Best       float64 `json:"best"`
+	Mean       float64 `json:"mean"`
+	Median     float64 `json:"median"`
+	Worst      float64 `json:"worst"`
+	StdDev     float64 `json:"stddev"`
+
+	// Diversity: distinct orderings, and the share of transitions an individual doesn't share with
+	// the best one (0 = a copy of the best, 1 = nothing in common), averaged and at most
+	Unique       int     `json:"unique"`
+	MeanDistance float64 `json:"mean_distance"`
+	MaxDistance  float64 `json:"max_distance"`
+
+	Scores []float64 `json:"scores"` // Every individual's score, best first
+}
+
+// landscapeRecorder writes a snapshot of the population every few generations of a GA run.
+// Write errors stop the recording (reported by Err) without stopping the run.
+type landscapeRecorder struct {
+	enc   *json.Encoder
+	every int
+	start time.Time
+	best  neighbours // Transitions of the best individual, reused between snapshots
+	err   error
+}
+
+// newLandscapeRecorder creates a recorder writing a JSON line to w every `every` generations
+func newLandscapeRecorder(w io.Writer, every int) *landscapeRecorder {
+	if every <= 0 {
+		every = defaultLandscapeEvery
+	}
+
+	return &landscapeRecorder{enc: json.NewEncoder(w), every: every, start: time.Now()}
+}
+
+// due reports whether generation gen should be recorded
+func (r *landscapeRecorder) due(gen int) bool {
+	return r.err == nil && gen%r.every == 0
+}
+
+// record writes a snapshot of the score-sorted population at generation gen
+func (r *landscapeRecorder) record(gen, stagnant int, population []Individual) {
+	if len(population) == 0 || r.err != nil {
+		return
+	}
+
+	if r.best.next == nil {
+		size := 0
+		for _, t := range population[0].Genes {
+			size = max(size, t.Index+1)
+		}
+
+		r.best = neighbours{next: make([]int, size), prev: make([]int, size)}
+	}
+
+	if err := r.enc.Encode(newLandscapeSnapshot(gen, stagnant, time.Since(r.start), population, &r.best)); err != nil {
+		r.err = fmt.Errorf("failed to write landscape snapshot: %w", err)
+	}
+}
+
+// Err returns the error that stopped the recording, if any
+func (r *landscapeRecorder) Err() error {
+	return r.err
+}
+
+// newLandscapeSnapshot summarizes a score-sorted population; best is scratch space sized for its track indices
+func newLandscapeSnapshot(gen, stagnant int, elapsed time.Duration, population []Individual, best *neighbours) landscapeSnapshot {
+	scores := make([]float64, len(population))
+	hashes := make(map[uint64]bool, len(population))
+	sum := 0.0
+
+	for i := range population {
+		scores[i] = population[i].Score
+		sum += scores[i]
+		hashes[permutationHash(population[i].Genes)] = true
+	}
+
+	// 2-opt and crowding can leave the population slightly out of score order
+	slices.Sort(scores)
+
+	n := float64(len(scores))
+	mean := sum / n
+
+	variance := 0.0
+	for _, s := range scores {
+		variance += (s - mean) * (s - mean)
+	}
+
+	best.set(population[0].Genes)
+
+	totalDistance, maxDistance := 0.0, 0.0
+	for i := 1; i < len(population); i++ {
+		d := best.distance(population[i].Genes)
+		totalDistance += d
+		maxDistance = max(maxDistance, d)
+	}
+
+	snap := landscapeSnapshot{
+		Generation:  gen,
+		ElapsedMs:   elapsed.Milliseconds(),
+		Stagnant:    stagnant,
+		Best:        scores[0],
+		Mean:        mean,
+		Median:      scores[len(scores)/2],
+		Worst:       scores[len(scores)-1],
+		StdDev:      math.Sqrt(variance / n),
+		Unique:      len(hashes),
+		MaxDistance: maxDistance,
+		Scores:      scores,
+	}
+
+	if len(population) > 1 {
+		snap.MeanDistance = totalDistance / float64(len(population)-1)
+	}
+
+	return snap
+}
+
+// openLandscape creates the snapshot file at path and returns a recorder writing to it with a
+// function that closes the file, reporting any write error
+func openLandscape(path string, every int) (*landscapeRecorder, func() error, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create landscape file: %w", err)
+	}
+
+	r := newLandscapeRecorder(f, every)
+
+	closeFile := func() error {
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to close landscape file: %w", err)
+		}
+
+		return r.Err()
+	}
+
+	return r, closeFile, nil
+}
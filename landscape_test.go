@@ -0,0 +1,119 @@
+// ABOUTME: Tests for fitness landscape snapshots
+// ABOUTME: Verifies the population statistics and the JSON lines written during a GA run
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"playlist-sorter/config"
+)
+
+func TestNewLandscapeSnapshot(t *testing.T) {
+	tracks := benchmarkTracks(10)
+
+	reversed := slices.Clone(tracks)
+	slices.Reverse(reversed)
+
+	swapped := slices.Clone(tracks)
+	swapped[0], swapped[9] = swapped[9], swapped[0]
+
+	population := []Individual{
+		{Genes: tracks, Score: 1},
+		{Genes: slices.Clone(tracks), Score: 1},
+		{Genes: reversed, Score: 4},
+		{Genes: swapped, Score: 2},
+	}
+
+	best := neighbours{next: make([]int, 10), prev: make([]int, 10)}
+	snap := newLandscapeSnapshot(7, 3, 1500*time.Millisecond, population, &best)
+
+	if snap.Generation != 7 || snap.Stagnant != 3 || snap.ElapsedMs != 1500 {
+		t.Errorf("Expected generation, stagnation and elapsed time to be recorded, got %+v", snap)
+	}
+
+	if !slices.Equal(snap.Scores, []float64{1, 1, 2, 4}) {
+		t.Errorf("Expected the scores best first, got %v", snap.Scores)
+	}
+
+	if snap.Best != 1 || snap.Worst != 4 || snap.Mean != 2 || snap.Median != 2 {
+		t.Errorf("Expected best 1, worst 4, mean 2, median 2, got %+v", snap)
+	}
+
+	if math.Abs(snap.StdDev-math.Sqrt(1.5)) > 1e-9 {
+		t.Errorf("Expected stddev sqrt(1.5), got %f", snap.StdDev)
+	}
+
+	// The copy and the reverse share every transition with the best; swapping the ends loses 2 of 9
+	if snap.Unique != 3 {
+		t.Errorf("Expected 3 distinct orderings, got %d", snap.Unique)
+	}
+
+	if math.Abs(snap.MaxDistance-2.0/9) > 1e-9 || math.Abs(snap.MeanDistance-2.0/27) > 1e-9 {
+		t.Errorf("Expected max distance 2/9 and mean 2/27, got %f and %f", snap.MaxDistance, snap.MeanDistance)
+	}
+}
+
+func TestGeneticSortRecordsLandscape(t *testing.T) {
+	tracks := benchmarkTracks(30)
+	gaCtx := buildEdgeFitnessCache(tracks)
+	gaCtx.rng = rand.New(rand.NewPCG(1, 2))
+
+	var buf bytes.Buffer
+
+	gaCtx.landscape = newLandscapeRecorder(&buf, 10)
+
+	sharedCfg := &config.SharedConfig{}
+	sharedCfg.Update(config.DefaultConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if _, err := geneticSort(ctx, tracks, sharedCfg, nil, 0, gaCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gaCtx.landscape.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(nil, 1<<20)
+
+	lines := 0
+	previousBest := math.MaxFloat64
+
+	for scanner.Scan() {
+		var snap landscapeSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			t.Fatalf("Expected JSON lines, got %q: %v", scanner.Text(), err)
+		}
+
+		if snap.Generation != lines*10 {
+			t.Errorf("Expected snapshot %d at generation %d, got %d", lines, lines*10, snap.Generation)
+		}
+
+		if len(snap.Scores) != populationSize || snap.Unique < 1 || snap.Unique > populationSize {
+			t.Errorf("Expected the whole population in snapshot %d, got %d scores, %d unique", lines, len(snap.Scores), snap.Unique)
+		}
+
+		if snap.Best > previousBest+floatingPointEpsilon {
+			t.Errorf("Expected the best score never to get worse, got %f after %f", snap.Best, previousBest)
+		}
+
+		previousBest = snap.Best
+		lines++
+	}
+
+	if lines < 2 {
+		t.Errorf("Expected several snapshots, got %d", lines)
+	}
+}
@@ -0,0 +1,165 @@
+// ABOUTME: Library subcommand ranking a folder of playlists by how much re-sorting could improve them
+// ABOUTME: Scores each playlist's current order against its theoretical minimum without optimizing
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// libraryEntry is the current score of one playlist in a library scan
+type libraryEntry struct {
+	Path    string
+	Tracks  int
+	Fitness float64 // Fitness of the current order
+	Minimum float64 // Theoretical minimum fitness
+	Biggest string  // Component with the most headroom ("" when none has any)
+	Err     error
+}
+
+// Headroom returns how far the current order is above the theoretical minimum
+func (e libraryEntry) Headroom() float64 {
+	return max(e.Fitness-e.Minimum, 0)
+}
+
+// HeadroomPercent returns the headroom as a share of the current fitness
+func (e libraryEntry) HeadroomPercent() float64 {
+	if e.Fitness <= 0 {
+		return 0
+	}
+
+	return e.Headroom() * 100 / e.Fitness
+}
+
+// scoreLibraryPlaylist loads a playlist with its sidecar config and scores its current order
+func scoreLibraryPlaylist(opts PlaylistOptions) libraryEntry {
+	data, err := InitializePlaylist(opts)
+	if err != nil {
+		return libraryEntry{Path: opts.Path, Err: err}
+	}
+
+	updateNormalizedWeights(data.GACtx, data.Config)
+
+	current := calculateFitnessWithBreakdown(data.Tracks, data.Config, data.GACtx)
+	minimum := calculateTheoreticalMinimum(data.Tracks, data.Config, data.GACtx)
+
+	entry := libraryEntry{Path: opts.Path, Tracks: len(data.Tracks), Fitness: current.Total, Minimum: minimum.Total}
+
+	if top := mostHeadroom(breakdownComponents(breakdownHeadroom(current, minimum))); top.value > 0 {
+		entry.Biggest = strings.ToLower(top.name)
+	}
+
+	return entry
+}
+
+// rankLibrary orders entries most improvable first (largest headroom); playlists that failed to load go last
+func rankLibrary(entries []libraryEntry) {
+	slices.SortStableFunc(entries, func(a, b libraryEntry) int {
+		if (a.Err == nil) != (b.Err == nil) {
+			if a.Err == nil {
+				return -1
+			}
+
+			return 1
+		}
+
+		return cmp.Compare(b.Headroom(), a.Headroom())
+	})
+}
+
+// printLibraryRanking prints one row per playlist in ranked order
+func printLibraryRanking(out io.Writer, dir string, entries []libraryEntry) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "#\tPlaylist\tTracks\tFitness\tMinimum\tHeadroom\tMost headroom"); err != nil {
+		log.Printf("Warning: failed to write header: %v", err)
+	}
+
+	for i, e := range entries {
+		name, err := filepath.Rel(dir, e.Path)
+		if err != nil {
+			name = e.Path
+		}
+
+		row := fmt.Sprintf("-\t%s\t-\t-\t-\t-\t%s\n", name, e.Err)
+
+		if e.Err == nil {
+			row = fmt.Sprintf("%d\t%s\t%d\t%.6f\t%.6f\t%.6f (%.0f%%)\t%s\n",
+				i+1, name, e.Tracks, e.Fitness, e.Minimum, e.Headroom(), e.HeadroomPercent(), cmp.Or(e.Biggest, "-"))
+		}
+
+		if _, err := fmt.Fprint(w, row); err != nil {
+			log.Printf("Warning: failed to write ranking row: %v", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Printf("Warning: failed to flush output: %v", err)
+	}
+}
+
+// runLibrary implements `playlist-sorter library [flags] <directory>`
+func runLibrary(args []string) int {
+	fs := newFlagSet("library", "library [flags] <directory>")
+	top := fs.Int("top", 0, "only list this many of the most improvable playlists (0 = all)")
+	imputeEnergy := fs.Bool("impute-energy", false, "estimate missing energy values from genre/BPM")
+	preset := fs.String("preset", "", "score with a built-in weight preset: "+presetNames())
+	pathMap := pathMapFlag(fs)
+
+	dir, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	if !isDir(dir) {
+		log.Printf("Library error: %s is not a directory", dir)
+
+		return 2
+	}
+
+	paths, err := findPlaylists(dir)
+	if err != nil {
+		log.Printf("Library error: %v", err)
+
+		return 1
+	}
+
+	if len(paths) == 0 {
+		log.Printf("Library error: no playlists (.m3u8, .m3u) found in %s", dir)
+
+		return 1
+	}
+
+	fmt.Printf("Scoring %d playlists in %s\n", len(paths), dir)
+
+	entries := make([]libraryEntry, 0, len(paths))
+
+	for i, path := range paths {
+		fmt.Printf("[%d/%d] %s\n", i+1, len(paths), path)
+
+		entries = append(entries, scoreLibraryPlaylist(PlaylistOptions{
+			Path:         path,
+			ImputeEnergy: *imputeEnergy,
+			Preset:       *preset,
+			PathMap:      *pathMap,
+		}))
+	}
+
+	rankLibrary(entries)
+
+	if *top > 0 && *top < len(entries) {
+		entries = entries[:*top]
+	}
+
+	fmt.Println()
+	printLibraryRanking(os.Stdout, dir, entries)
+
+	return 0
+}
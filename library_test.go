@@ -0,0 +1,65 @@
+// ABOUTME: Tests for the library subcommand
+// ABOUTME: Verifies headroom, ranking order and the ranking table
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLibraryEntryHeadroom(t *testing.T) {
+	e := libraryEntry{Fitness: 0.4, Minimum: 0.1}
+
+	if got := e.Headroom(); got < 0.2999 || got > 0.3001 {
+		t.Errorf("Expected headroom 0.3, got %f", got)
+	}
+
+	if got := e.HeadroomPercent(); got < 74.99 || got > 75.01 {
+		t.Errorf("Expected 75%% headroom, got %f", got)
+	}
+
+	if got := (libraryEntry{}).HeadroomPercent(); got != 0 {
+		t.Errorf("Expected no headroom for a zero fitness, got %f", got)
+	}
+}
+
+func TestRankLibrary(t *testing.T) {
+	entries := []libraryEntry{
+		{Path: "tidy", Fitness: 0.2, Minimum: 0.19},
+		{Path: "broken", Err: errors.New("playlist is empty")},
+		{Path: "messy", Fitness: 0.6, Minimum: 0.1},
+		{Path: "middling", Fitness: 0.3, Minimum: 0.1},
+	}
+
+	rankLibrary(entries)
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Path)
+	}
+
+	if want := []string{"messy", "middling", "tidy", "broken"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestPrintLibraryRanking(t *testing.T) {
+	var buf bytes.Buffer
+
+	printLibraryRanking(&buf, "/crates", []libraryEntry{
+		{Path: "/crates/friday.m3u8", Tracks: 40, Fitness: 0.5, Minimum: 0.1, Biggest: "harmonic"},
+		{Path: "/crates/broken.m3u8", Err: errors.New("playlist is empty")},
+	})
+
+	out := buf.String()
+
+	for _, want := range []string{"friday.m3u8", "0.400000 (80%)", "harmonic", "broken.m3u8", "playlist is empty"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Ranking missing %q:\n%s", want, out)
+		}
+	}
+}
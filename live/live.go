@@ -0,0 +1,224 @@
+// ABOUTME: Streams a running optimization's best order to view mode over a local Unix socket
+// ABOUTME: The sort run publishes newline-delimited JSON updates; view mode subscribes by playlist path
+
+// Package live connects a running `sort` to `view` without writing the playlist on every
+// improvement. The socket lives in a directory only the current user can access ($XDG_RUNTIME_DIR,
+// else a per-user directory in the temp directory) under a name derived from the playlist's
+// absolute path, so both sides find it from the playlist path alone.
+package live
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"playlist-sorter/playlist"
+)
+
+// Connection limits
+const (
+	writeTimeout  = time.Second // A subscriber that doesn't read for this long is dropped
+	maxUpdateSize = 64 << 20    // Largest update a subscriber accepts (huge playlists with full metadata)
+)
+
+// Update is one published state of the run: the best order so far and its score
+type Update struct {
+	Tracks    []playlist.Track   `json:"tracks"`
+	Breakdown playlist.Breakdown `json:"breakdown"`
+}
+
+// SocketPath returns the socket a run on playlistPath publishes to
+func SocketPath(playlistPath string) string {
+	if abs, err := filepath.Abs(playlistPath); err == nil {
+		playlistPath = abs
+	}
+
+	sum := sha256.Sum256([]byte(playlistPath))
+
+	return filepath.Join(socketDir(), fmt.Sprintf("%x.sock", sum[:8]))
+}
+
+// socketDir returns the per-user directory sockets live in: playlist-sorter under $XDG_RUNTIME_DIR,
+// or playlist-sorter-<uid> in the temp directory
+func socketDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "playlist-sorter")
+	}
+
+	return filepath.Join(os.TempDir(), fmt.Sprintf("playlist-sorter-%d", os.Getuid()))
+}
+
+// checkSocketDir makes sure dir is a directory that is owned by, and only accessible to, the current
+// user, creating it first if create is set. In a shared temp directory another user could create it
+// first to feed view mode fake updates or take over the socket.
+func checkSocketDir(dir string, create bool) error {
+	if create {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create socket directory: %w", err)
+		}
+	}
+
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() || info.Mode().Perm()&0o077 != 0 || !ownedByCurrentUser(info) {
+		return fmt.Errorf("socket directory %s must be a directory only you can access (mode 0700)", dir)
+	}
+
+	return nil
+}
+
+// Publisher sends updates to every connected subscriber. New subscribers get the latest update straight away.
+type Publisher struct {
+	ln net.Listener
+
+	mu     sync.Mutex
+	conns  map[net.Conn]bool
+	latest []byte // Latest encoded update (nil until the first Publish)
+}
+
+// Listen starts publishing for playlistPath. A socket left behind by a crashed run is replaced;
+// one still served by another run is an error.
+func Listen(playlistPath string) (*Publisher, error) {
+	path := SocketPath(playlistPath)
+
+	if err := checkSocketDir(filepath.Dir(path), true); err != nil {
+		return nil, err
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("another run is already publishing on %s", path)
+	}
+
+	_ = os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	p := &Publisher{ln: ln, conns: make(map[net.Conn]bool)}
+
+	go p.accept()
+
+	return p, nil
+}
+
+// accept registers subscribers until the listener closes
+func (p *Publisher) accept() {
+	for {
+		conn, err := p.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		p.mu.Lock()
+		p.conns[conn] = true
+
+		if p.latest != nil {
+			p.send(conn, p.latest)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// Publish sends u to all subscribers, dropping those that can't keep up
+func (p *Publisher) Publish(u Update) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.latest = data
+
+	for conn := range p.conns {
+		p.send(conn, data)
+	}
+
+	return nil
+}
+
+// send writes data to conn, dropping the subscriber on failure. Callers hold p.mu.
+func (p *Publisher) send(conn net.Conn, data []byte) {
+	_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	if _, err := conn.Write(data); err != nil {
+		_ = conn.Close()
+		delete(p.conns, conn)
+	}
+}
+
+// Close stops publishing, disconnecting subscribers and removing the socket
+func (p *Publisher) Close() error {
+	err := p.ln.Close()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for conn := range p.conns {
+		_ = conn.Close()
+		delete(p.conns, conn)
+	}
+
+	return err
+}
+
+// Subscribe connects to the run publishing for playlistPath and sends its updates to updates until
+// the run ends (returns nil) or ctx is cancelled. Returns an error straight away when no run is publishing.
+func Subscribe(ctx context.Context, playlistPath string, updates chan<- Update) error {
+	path := SocketPath(playlistPath)
+
+	if err := checkSocketDir(filepath.Dir(path), false); err != nil {
+		return err
+	}
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer func() { _ = conn.Close() }()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(nil, maxUpdateSize)
+
+	for scanner.Scan() {
+		var u Update
+		if err := json.Unmarshal(scanner.Bytes(), &u); err != nil {
+			return fmt.Errorf("invalid update: %w", err)
+		}
+
+		select {
+		case updates <- u:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,119 @@
+// ABOUTME: Tests for the live update socket between sort runs and view mode
+// ABOUTME: Round-trips updates from a publisher to a subscriber over a real Unix socket in a private directory
+
+package live
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"playlist-sorter/playlist"
+)
+
+// receive waits for the next update
+func receive(t *testing.T, updates <-chan Update) Update {
+	t.Helper()
+
+	select {
+	case u := <-updates:
+		return u
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an update")
+	}
+
+	return Update{}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "set.m3u8")
+
+	if err := Subscribe(context.Background(), path, make(chan Update)); err == nil {
+		t.Fatal("Expected subscribing without a publishing run to fail")
+	}
+
+	p, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Listen(path); err == nil {
+		t.Error("Expected a second publisher for the same playlist to be refused")
+	}
+
+	first := Update{Tracks: []playlist.Track{{Path: "a.mp3", Key: "8A"}}, Breakdown: playlist.Breakdown{Total: 0.5}}
+	if err := p.Publish(first); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := make(chan Update, 4)
+	done := make(chan error, 1)
+
+	go func() { done <- Subscribe(context.Background(), path, updates) }()
+
+	// A new subscriber gets the latest update straight away
+	if u := receive(t, updates); len(u.Tracks) != 1 || u.Tracks[0].Path != "a.mp3" || u.Breakdown.Total != 0.5 {
+		t.Errorf("Unexpected first update %+v", u)
+	}
+
+	second := Update{Tracks: []playlist.Track{{Path: "b.mp3"}, {Path: "a.mp3"}}, Breakdown: playlist.Breakdown{Total: 0.4}}
+	if err := p.Publish(second); err != nil {
+		t.Fatal(err)
+	}
+
+	if u := receive(t, updates); len(u.Tracks) != 2 || u.Breakdown.Total != 0.4 {
+		t.Errorf("Unexpected second update %+v", u)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the subscription to end cleanly with the run, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Subscription didn't end when the run closed")
+	}
+
+	// The socket is gone, so a later run can publish again
+	p, err = Listen(path)
+	if err != nil {
+		t.Fatalf("Expected a new run to publish after the last one closed: %v", err)
+	}
+
+	_ = p.Close()
+}
+
+func TestSocketDirMustBePrivate(t *testing.T) {
+	runtime := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtime)
+
+	path := filepath.Join(t.TempDir(), "set.m3u8")
+	if dir := filepath.Dir(SocketPath(path)); dir != filepath.Join(runtime, "playlist-sorter") {
+		t.Errorf("Expected the socket under $XDG_RUNTIME_DIR, got %s", dir)
+	}
+
+	// A directory other users can get into (e.g. created by someone else first) is refused
+	if err := os.Mkdir(filepath.Join(runtime, "playlist-sorter"), 0o777); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chmod(filepath.Join(runtime, "playlist-sorter"), 0o777); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Listen(path); err == nil {
+		t.Error("Expected publishing from a shared socket directory to be refused")
+	}
+
+	if err := Subscribe(context.Background(), path, make(chan Update)); err == nil {
+		t.Error("Expected subscribing through a shared socket directory to be refused")
+	}
+}
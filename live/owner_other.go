@@ -0,0 +1,14 @@
+// ABOUTME: Socket directory ownership check where file owners aren't Unix user IDs
+// ABOUTME: Relies on the directory's permissions alone
+
+//go:build !unix
+
+package live
+
+import "os"
+
+// ownedByCurrentUser reports whether info belongs to the user running the process. Without Unix
+// owners the permission check has to do.
+func ownedByCurrentUser(os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,18 @@
+// ABOUTME: Socket directory ownership check on Unix systems
+// ABOUTME: Compares the directory's owner with the user running the process
+
+//go:build unix
+
+package live
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByCurrentUser reports whether info belongs to the user running the process
+func ownedByCurrentUser(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	return ok && int(stat.Uid) == os.Getuid()
+}
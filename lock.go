@@ -0,0 +1,98 @@
+// ABOUTME: Advisory lock files marking a playlist as being written by an optimization run
+// ABOUTME: Keeps concurrent CLI runs, TUI sessions and the daemon from clobbering each other; stale locks expire
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+)
+
+// lockStaleAfter is the age after which a lock file is assumed left behind by a crashed run.
+// Runs are capped at maxDuration, so a live run never holds a lock this long.
+const lockStaleAfter = maxDuration + 10*time.Minute
+
+// errPlaylistLocked is returned when another run holds the playlist's lock
+var errPlaylistLocked = errors.New("playlist is being optimized by another process")
+
+// playlistLockPath returns the lock file for a playlist, e.g. set.m3u8.lock
+func playlistLockPath(path string) string {
+	return path + ".lock"
+}
+
+// acquirePlaylistLock creates path's lock file and returns the function removing it.
+// Returns an error wrapping errPlaylistLocked if another run holds it. The lock is advisory:
+// when the lock file can't be created for another reason, the run goes ahead unlocked.
+func acquirePlaylistLock(path string) (func(), error) {
+	lockPath := playlistLockPath(path)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			log.Printf("Warning: running without a lock: %v", err)
+
+			return func() {}, nil
+		}
+
+		info, err := os.Stat(lockPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // Released in the meantime
+		}
+
+		if err != nil || time.Since(info.ModTime()) < lockStaleAfter {
+			return nil, fmt.Errorf("%w (remove %s if it isn't)", errPlaylistLocked, lockPath)
+		}
+
+		log.Printf("Warning: removing stale lock %s (from %s)", lockPath, info.ModTime().Format(time.DateTime))
+
+		if err := os.Remove(lockPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lock: %w", err)
+		}
+	}
+}
+
+// lockRefreshEvery is how often holdPlaylistLock touches its lock file
+const lockRefreshEvery = lockStaleAfter / 3
+
+// holdPlaylistLock is acquirePlaylistLock for a lock held longer than any run, such as for a whole
+// TUI session: the lock file is touched every refreshEvery until released, so other runs never take
+// it for stale
+func holdPlaylistLock(path string, refreshEvery time.Duration) (func(), error) {
+	release, err := acquirePlaylistLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lockPath := playlistLockPath(path)
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(refreshEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				_ = os.Chtimes(lockPath, now, now) // Fails harmlessly when running unlocked
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		release()
+	}, nil
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Locked positions: tracks flagged FlagLocked keep their place in every ordering the GA tries
+// ABOUTME: Lets the TUI freeze most of a set and optimize only the rough patch around the cursor
+
+package main
+
+import "playlist-sorter/playlist"
+
+// lockedPositions pins each locked track to its position in the order a run started from. The GA's
+// operators move tracks freely; apply then puts every ordering back in line with the pins.
+type lockedPositions struct {
+	base    []playlist.Track // Order the run started from: the track each pinned position holds
+	pinned  []bool           // Per position: holds a locked track
+	byIndex []bool           // Per Track.Index: track is locked
+	free    []playlist.Track // Scratch for apply
+}
+
+// newLockedPositions returns the pins of the tracks flagged FlagLocked in flags (by Identity), or nil
+// when none is. Track indices must be below numIndices.
+func newLockedPositions(tracks []playlist.Track, flags map[string]playlist.TrackFlags, numIndices int) *lockedPositions {
+	var l *lockedPositions
+
+	for pos := range tracks {
+		if !flags[tracks[pos].Identity()].Has(playlist.FlagLocked) {
+			continue
+		}
+
+		if l == nil {
+			l = &lockedPositions{
+				base:    tracks,
+				pinned:  make([]bool, len(tracks)),
+				byIndex: make([]bool, numIndices),
+				free:    make([]playlist.Track, 0, len(tracks)),
+			}
+		}
+
+		l.pinned[pos] = true
+		l.byIndex[tracks[pos].Index] = true
+	}
+
+	return l
+}
+
+// isPinned reports whether position pos holds a locked track (never, on nil pins)
+func (l *lockedPositions) isPinned(pos int) bool {
+	return l != nil && l.pinned[pos]
+}
+
+// freePositions returns the positions of an n-track ordering that aren't pinned, in order
+func (l *lockedPositions) freePositions(n int) []int {
+	positions := make([]int, 0, n)
+
+	for pos := range n {
+		if !l.isPinned(pos) {
+			positions = append(positions, pos)
+		}
+	}
+
+	return positions
+}
+
+// apply restores the locked tracks of order to their pinned positions, filling the free positions
+// with the other tracks in the order they appear. A no-op on nil pins; not safe for concurrent use.
+func (l *lockedPositions) apply(order []playlist.Track) {
+	if l == nil {
+		return
+	}
+
+	l.free = l.free[:0]
+
+	for _, t := range order {
+		if !l.byIndex[t.Index] {
+			l.free = append(l.free, t)
+		}
+	}
+
+	next := 0
+
+	for pos := range order {
+		if l.pinned[pos] {
+			order[pos] = l.base[pos]
+		} else {
+			order[pos] = l.free[next]
+			next++
+		}
+	}
+}
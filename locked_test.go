@@ -0,0 +1,112 @@
+// ABOUTME: Tests for locked positions: the GA and exact solver keep FlagLocked tracks in place
+// ABOUTME: Covers the repair of orderings and a focus-style run that may only reorder a window
+
+package main
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// lockOutside returns flags locking every track of tracks outside positions lo to hi
+func lockOutside(tracks []playlist.Track, lo, hi int) map[string]playlist.TrackFlags {
+	flags := make(map[string]playlist.TrackFlags)
+
+	for i := range tracks {
+		if i < lo || i > hi {
+			flags[tracks[i].Identity()] = playlist.FlagLocked
+		}
+	}
+
+	return flags
+}
+
+func TestLockedPositionsApply(t *testing.T) {
+	tracks := benchmarkTracks(6)
+
+	if l := newLockedPositions(tracks, map[string]playlist.TrackFlags{tracks[0].Path: playlist.FlagManual}, len(tracks)); l != nil {
+		t.Fatal("Expected no pins without locked tracks")
+	}
+
+	l := newLockedPositions(tracks, lockOutside(tracks, 1, 3), len(tracks))
+
+	// Locked tracks 0, 4 and 5 moved around; the free ones keep their relative order
+	order := []playlist.Track{tracks[5], tracks[3], tracks[0], tracks[1], tracks[4], tracks[2]}
+	l.apply(order)
+
+	want := []string{tracks[0].Path, tracks[3].Path, tracks[1].Path, tracks[2].Path, tracks[4].Path, tracks[5].Path}
+	if got := trackPathsOf(order); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if got := l.freePositions(len(tracks)); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Expected free positions [1 2 3], got %v", got)
+	}
+}
+
+func TestGeneticSortKeepsLockedTracks(t *testing.T) {
+	tracks := benchmarkTracks(30)
+	gaCtx := buildEdgeFitnessCache(tracks)
+	gaCtx.trackFlags = lockOutside(tracks, 10, 16)
+
+	sharedCfg := &config.SharedConfig{}
+	sharedCfg.Update(config.DefaultConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	sorted, err := geneticSort(ctx, tracks, sharedCfg, nil, 0, gaCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range tracks {
+		if (i < 10 || i > 16) && sorted[i].Path != tracks[i].Path {
+			t.Errorf("Expected locked track %s to stay at position %d, got %s", tracks[i].Path, i, sorted[i].Path)
+		}
+	}
+
+	window, original := trackPathsOf(sorted[10:17]), trackPathsOf(tracks[10:17])
+	slices.Sort(window)
+	slices.Sort(original)
+
+	if !slices.Equal(window, original) {
+		t.Errorf("Expected the window to hold its own tracks, got %v", window)
+	}
+
+	cfg := sharedCfg.Get()
+	if calculateFitness(sorted, cfg, gaCtx) > calculateFitness(tracks, cfg, gaCtx) {
+		t.Error("Expected the window reordering to be no worse than the original order")
+	}
+}
+
+func TestExactSortKeepsLockedTracks(t *testing.T) {
+	cfg := config.DefaultConfig()
+	tracks := benchmarkTracks(6)
+	gaCtx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(gaCtx, cfg)
+	gaCtx.locked = newLockedPositions(tracks, lockOutside(tracks, 1, 4), len(tracks))
+
+	best, fitness := exactSort(tracks, cfg, gaCtx)
+
+	if best[0].Path != tracks[0].Path || best[5].Path != tracks[5].Path {
+		t.Errorf("Expected locked tracks at both ends, got %v", trackPathsOf(best))
+	}
+
+	minimum := math.Inf(1)
+	for _, p := range permutations(tracks) {
+		if p[0].Path == tracks[0].Path && p[5].Path == tracks[5].Path {
+			minimum = min(minimum, calculateFitness(p, cfg, gaCtx))
+		}
+	}
+
+	if math.Abs(fitness-minimum) > 1e-12 {
+		t.Errorf("Expected the best order with the locks kept (%.10f), got %.10f", minimum, fitness)
+	}
+}
@@ -1,11 +1,12 @@
 // ABOUTME: Entry point for playlist-sorter application
-// ABOUTME: Handles command-line parsing, profiling, and routing to CLI or TUI modes
+// ABOUTME: Dispatches subcommands (sort, view, analyze, ...) with per-command flags and profiling
 
 // Package main provides the entry point for playlist-sorter, a genetic algorithm-based playlist optimizer.
 package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -13,6 +14,8 @@ import (
 	"runtime"
 	"runtime/debug"
 	"runtime/pprof"
+	"slices"
+	"strings"
 
 	"playlist-sorter/config"
 	"playlist-sorter/playlist"
@@ -23,37 +26,329 @@ func main() {
 	os.Exit(run())
 }
 
+// command is a subcommand with its own flag set
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// commands returns the subcommands in the order they are listed in help
+func commands() []command {
+	return []command{
+		{"sort", "optimize a playlist (default when no command is given)", runSort},
+		{"view", "live read-only view of a playlist being optimized elsewhere", runView},
+		{"analyze", "score the current order and list the worst transitions", runAnalyze},
+		{"validate", "report missing metadata and coverage", runValidate},
+		{"enrich", "look up genres online for tracks without one", runEnrich},
+		{"config", "show, locate or reset the configuration, or list presets", runConfig},
+		{"bench", "benchmark the optimizer on a playlist without writing", runBench},
+		{"ab", "compare two configs on the same playlist and seed", runAB},
+		{"serve", "serve a REST API for sorting and analyzing playlists", runServe},
+		{"daemon", "re-optimize playlists automatically whenever they change", runDaemon},
+		{"library", "rank a folder of playlists by how much re-sorting could improve them", runLibrary},
+		{"journey", "build a playlist from a music folder starting at a seed track", runJourney},
+		{"selftest", "check this build scores the bundled fixture playlists like the last release", runSelftest},
+	}
+}
+
 func run() int {
-	cpuprofile := flag.String("cpuprofile", "", "write cpu profile to file")
-	memprofile := flag.String("memprofile", "", "write memory profile to file")
-	visual := flag.Bool("visual", false, "run in visual/interactive mode with live parameter tuning")
-	debug := flag.Bool("debug", false, "enable debug logging to playlist-sorter-debug.log")
-	dryRun := flag.Bool("dry-run", false, "preview optimization without writing changes")
-	output := flag.String("output", "", "write sorted playlist to this file (default: overwrite input)")
-	flag.Parse()
-
-	args := flag.Args()
-	if len(args) != 1 {
-		fmt.Println("Usage: playlist-sorter [flags] <playlist.m3u8>")
-		fmt.Println("Example: playlist-sorter /path/to/playlist.m3u8")
-		fmt.Println("\nFlags:")
-		flag.PrintDefaults()
+	args := os.Args[1:]
 
-		return 1
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			printUsage()
+
+			return 0
+		}
+
+		for _, cmd := range commands() {
+			if cmd.name == args[0] {
+				return cmd.run(args[1:])
+			}
+		}
+	}
+
+	// Bare invocation (flags and a playlist path) is an alias for sort
+	return runSort(args)
+}
+
+// printUsage lists the available subcommands
+func printUsage() {
+	fmt.Println("Usage: playlist-sorter <command> [flags] <playlist.m3u8>")
+	fmt.Println("       playlist-sorter [sort flags] <playlist.m3u8>")
+	fmt.Println("\nCommands:")
+
+	for _, cmd := range commands() {
+		fmt.Printf("  %-9s %s\n", cmd.name, cmd.summary)
+	}
+
+	fmt.Println("\nRun 'playlist-sorter <command> -h' for command flags.")
+}
+
+// newFlagSet creates a flag set for a subcommand with a usage line shown on -h or bad flags
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	fs.Usage = func() {
+		out := fs.Output()
+		_, _ = fmt.Fprintf(out, "Usage: playlist-sorter %s\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseFlags parses args and checks for exactly one positional playlist argument.
+// Returns the playlist path, or ok=false with the exit code to return.
+func parseFlags(fs *flag.FlagSet, args []string) (path string, code int, ok bool) {
+	if err := fs.Parse(args); err != nil {
+		return "", usageExitCode(err), false
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return "", 2, false
+	}
+
+	return fs.Arg(0), 0, true
+}
+
+// usageExitCode returns the exit code for a flag parse error: 0 for -h, 2 for bad usage
+func usageExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
+	return 2
+}
+
+// pathMapFlag registers the repeatable -path-map flag
+func pathMapFlag(fs *flag.FlagSet) *[]string {
+	var mappings []string
+
+	fs.Func("path-map", `rewrite track paths from another machine, e.g. "D:\Music=>/mnt/music" (repeatable)`, func(s string) error {
+		if _, err := playlist.ParsePathMapping(s); err != nil {
+			return err
+		}
+
+		mappings = append(mappings, s)
+
+		return nil
+	})
+
+	return &mappings
+}
+
+// seedPlaylistFlag registers the repeatable -seed-playlist flag
+func seedPlaylistFlag(fs *flag.FlagSet) *[]string {
+	var paths []string
+
+	fs.Func("seed-playlist", "start the optimizer from this ordering of the same tracks too, e.g. a hand-curated order (repeatable)", func(s string) error {
+		paths = append(paths, s)
+
+		return nil
+	})
+
+	return &paths
+}
+
+// presetNames lists the built-in weight presets for flag help
+func presetNames() string {
+	var names []string
+	for _, p := range config.Presets() {
+		names = append(names, p.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// profileFlags holds the CPU/memory profiling flags shared by commands that run the optimizer
+type profileFlags struct {
+	cpu *string
+	mem *string
+}
+
+// addProfileFlags registers -cpuprofile and -memprofile on fs
+func addProfileFlags(fs *flag.FlagSet) profileFlags {
+	return profileFlags{
+		cpu: fs.String("cpuprofile", "", "write cpu profile to file"),
+		mem: fs.String("memprofile", "", "write memory profile to file"),
+	}
+}
+
+// start begins CPU profiling if requested and returns a function that stops it
+// and writes the memory profile
+func (p profileFlags) start() func() {
+	stopCPUProfile := func() {}
+	if *p.cpu != "" {
+		stopCPUProfile = setupCPUProfile(*p.cpu)
+	}
+
+	return func() {
+		stopCPUProfile()
+
+		if *p.mem != "" {
+			writeMemoryProfile(*p.mem)
+		}
+	}
+}
+
+// runSort implements `playlist-sorter sort [flags] <playlist>`
+func runSort(args []string) int {
+	fs := newFlagSet("sort", "sort [flags] <playlist.m3u8|directory|-|http(s)://...>")
+	profile := addProfileFlags(fs)
+	visual := fs.Bool("visual", false, "run in visual/interactive mode with live parameter tuning")
+	dual := fs.String("dual", "", "with -visual, open this second playlist side by side (e.g. a backup crate) and move tracks between them with m")
+	simpleUI := fs.Bool("simple-ui", false, "print a plain text dashboard every few seconds (no alt screen or raw mode), for terminals where -visual misbehaves")
+	debug := fs.Bool("debug", false, "enable debug logging to playlist-sorter-debug.log")
+	dryRun := fs.Bool("dry-run", false, "preview optimization without writing changes")
+	quiet := fs.Bool("quiet", false, "print only a one-line summary instead of progress and the track table (for cron)")
+	duration := fs.Duration("duration", 0, "stop optimizing after this long (default and max 5m)")
+	output := fs.String("output", "", "write sorted playlist to this file, or - for stdout (default: overwrite input; stdout when reading stdin)")
+	outputFormat := fs.String("output-format", "m3u8", "output playlist format: m3u8, m3u, pls, xspf, json, txt (tracklist with start times), cue (non-m3u8 requires -output)")
+	shuffle := fs.Float64("shuffle", 0, "smart-shuffle temperature (0-1): different but still well-mixed order each run")
+	novelty := fs.Float64("novelty", 0, "novelty weight (0-1): penalize repeating adjacencies from the last saved output")
+	imputeEnergy := fs.Bool("impute-energy", false, "estimate missing energy values from genre/BPM (shown as ~N)")
+	writeTagsFlag := fs.Bool("write-tags", false, "write each track's final position into its tags via tag_write_command (config)")
+	tagNotes := fs.Bool("tag-notes", false, "with -write-tags, also pass a transition note as {note}")
+	threads := fs.Int("threads", 0, "limit the optimizer to this many worker threads (default: all CPUs)")
+	nice := fs.Bool("nice", false, "pause between generations to use about half the CPU (toggle with n in -visual)")
+	noColorFlag := fs.Bool("no-color", false, "plain output without colors, spinner or cursor tricks (also set by NO_COLOR)")
+	chunkSize := fs.Int("chunk-size", 0, fmt.Sprintf("for huge playlists: optimize clusters of about this many similar tracks (at least %d) in parallel, then join them (0 = off)", minChunkSize))
+	beetsAttr := fs.String("beets-attr", "", "store each track's final position in this beets flexible attribute via `beet modify`")
+	keyOnly := fs.Bool("key-only", false, "fast harmonic ordering: read only key and BPM tags and score only harmonic and BPM components")
+	preset := fs.String("preset", "", "start from a built-in weight preset: "+presetNames())
+	autoProfile := fs.Bool("auto-profile", false, "without -preset or a sidecar, use the preset suiting the playlist (genre family, BPM spread, size) and say which")
+	pathMap := pathMapFlag(fs)
+	seedPlaylists := seedPlaylistFlag(fs)
+	overrides := fs.String("overrides", "", "JSON file of per-track key/bpm/energy/genre corrections applied after reading tags (default: <playlist>.overrides.json if present)")
+	liveFlag := fs.String("live", liveWritesSocket, "how view mode follows the run: socket (nothing written until the end), working-copy (<playlist>.optimizing, removed at the end), in-place, off")
+	skippedFlag := fs.String("skipped", "keep", "where tracks with unreadable metadata go in the output: keep (original position), end, drop")
+	landscape := fs.String("landscape", "", "write a snapshot of the whole population's scores and diversity to this file (JSON lines) every -landscape-every generations")
+	landscapeEvery := fs.Int("landscape-every", defaultLandscapeEvery, "generations between -landscape snapshots")
+	exportTransitions := fs.String("export-transitions", "", "write every transition of the final order with its edge scores to this JSON file")
+	reportFlag := fs.String("report", "", "write a report of the result next to the output: md (<playlist>.report.md with tracklist, transition notes and fitness)")
+	notifyCommand := fs.String("notify-command", "", "run this command when the run finishes or reaches -notify-below ({event}, {playlist}, {fitness}, {message}; default: notify_command in config)")
+	notifyURL := fs.String("notify-url", "", "POST a JSON notification here when the run finishes or reaches -notify-below (default: notify_url in config)")
+	notifyBelow := fs.Float64("notify-below", 0, "also notify once when the best fitness drops to this (0 = notify_below in config, or off)")
+	failIfAbove := fs.Float64("fail-if-above", 0, "exit with code 3 if the final fitness is above this (0 = off)")
+	failIfImprovementBelow := fs.Float64("fail-if-improvement-below", 0, "exit with code 4 if fitness improved by less than this percentage (0 = off)")
+
+	playlistPath, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	skipped, err := playlist.ParseSkippedPlacement(*skippedFlag)
+	if err != nil {
+		log.Printf("%v", err)
+
+		return 2
+	}
+
+	liveWrites, err := parseLiveWrites(*liveFlag)
+	if err != nil {
+		log.Printf("%v", err)
+
+		return 2
+	}
+
+	report, err := parseReportFormat(*reportFlag)
+	if err != nil {
+		log.Printf("%v", err)
+
+		return 2
 	}
 
-	playlistPath := args[0]
+	throttle.set(*threads, *nice)
+
+	// Remote playlists are downloaded and the result written locally
+	if isPlaylistURL(playlistPath) {
+		downloaded, localOutput, cleanup, err := localPlaylistFromURL(playlistPath, *output)
+		if err != nil {
+			log.Printf("CLI error: %v", err)
+
+			return 1
+		}
+		defer cleanup()
 
-	if *cpuprofile != "" {
-		stopCPUProfile := setupCPUProfile(*cpuprofile)
-		defer stopCPUProfile()
+		playlistPath, *output = downloaded, localOutput
 	}
 
-	if *memprofile != "" {
-		defer writeMemoryProfile(*memprofile)
+	if *chunkSize < 0 || (*chunkSize > 0 && *chunkSize < minChunkSize) {
+		log.Printf("-chunk-size must be at least %d (or 0 for off)", minChunkSize)
+
+		return 2
 	}
 
+	if *dual != "" && !*visual {
+		log.Printf("-dual needs -visual")
+
+		return 2
+	}
+
+	if *simpleUI && (*visual || *quiet || *chunkSize > 0) {
+		log.Printf("-simple-ui can't be combined with -visual, -quiet or -chunk-size")
+
+		return 2
+	}
+
+	stopProfiling := profile.start()
+	defer stopProfiling()
+
 	if *visual {
+		if playlistPath == playlist.StdioPath || isDir(playlistPath) || *chunkSize > 0 || *keyOnly || *exportTransitions != "" || report != "" || *landscape != "" {
+			log.Printf("-visual needs a single playlist file and can't be combined with -chunk-size, -key-only, -export-transitions, -report or -landscape")
+
+			return 2
+		}
+
+		// The TUI starts from the configured weights (or -preset); presets are picked there with c
+		if *autoProfile {
+			log.Printf("-auto-profile can't be combined with -visual; use -preset, or press c in the TUI to pick one")
+
+			return 2
+		}
+
+		if *dual != "" && (*output != "" || isDir(*dual) || isPlaylistURL(*dual) || *dual == playlistPath) {
+			log.Printf("-dual needs a second, local playlist file and can't be combined with -output")
+
+			return 2
+		}
+
+		// The TUI auto-saves as it goes; catch an unwritable target before the first save, and keep
+		// other runs and the daemon off the playlists for as long as the session is open
+		if !*dryRun {
+			targets := []string{playlistPath}
+			if *output != "" {
+				targets[0] = *output
+			}
+
+			if *dual != "" {
+				targets = append(targets, *dual)
+			}
+
+			for _, target := range targets {
+				if err := checkWritable(target); err != nil {
+					log.Printf("%v", err)
+
+					return 1
+				}
+
+				release, err := holdPlaylistLock(target, lockRefreshEvery)
+				if err != nil {
+					log.Printf("%v", err)
+
+					return 1
+				}
+
+				defer release()
+			}
+		}
+
 		if *debug {
 			if err := SetupDebugLog("playlist-sorter-debug.log"); err != nil {
 				log.Printf("Failed to setup debug log: %v", err)
@@ -64,22 +359,88 @@ func run() int {
 
 		opts := tui.Options{
 			PlaylistPath: playlistPath,
+			OutputPath:   *output,
 			DryRun:       *dryRun,
 			DebugLog:     *debug,
+			NoColor:      noColor(*noColorFlag),
+			Nice:         *nice,
+			ToggleNice:   throttle.toggleNice,
+
+			SkippedPlacement: skipped,
+			OverridesPath:    *overrides,
 		}
 
 		sharedCfg := &config.SharedConfig{}
 		configPath := config.GetConfigPath()
 		cfg, _ := config.LoadConfig(configPath)
+
+		if *preset != "" {
+			presetCfg, err := config.ApplyPreset(cfg, *preset)
+			if err != nil {
+				log.Printf("%v", err)
+
+				return 2
+			}
+
+			cfg = presetCfg
+		}
+
+		if err := setPathMap(cfg, *pathMap); err != nil {
+			log.Printf("%v", err)
+
+			return 2
+		}
+
 		sharedCfg.Update(cfg)
+		playlist.SetMixTags(cfg.IntroTag, cfg.OutroTag)
+		playlist.SetGenreAliases(cfg.GenreAliases)
+
+		// Read before the TUI starts auto-saving over it
+		previousOrder := loadPreviousOrder(playlistPath)
 
-		runGA := func(ctx context.Context, tracks []playlist.Track, updates chan<- tui.Update, epoch int) {
-			runGAForTUI(ctx, tracks, sharedCfg, updates, epoch)
+		edgeCache := &edgeCacheStore{}
+
+		runGA := func(ctx context.Context, tracks []playlist.Track, flags map[string]playlist.TrackFlags, updates chan<- tui.Update, epoch int) {
+			runGAForTUI(ctx, tracks, flags, sharedCfg, updates, epoch, previousOrder, edgeCache)
+		}
+
+		// The second playlist of -dual is optimized on its own, with its own history and edge cache
+		if *dual != "" {
+			opts.DualPath = *dual
+			dualPreviousOrder := loadPreviousOrder(*dual)
+			dualEdgeCache := &edgeCacheStore{}
+
+			opts.DualRunGA = func(ctx context.Context, tracks []playlist.Track, flags map[string]playlist.TrackFlags, updates chan<- tui.Update, epoch int) {
+				runGAForTUI(ctx, tracks, flags, sharedCfg, updates, epoch, dualPreviousOrder, dualEdgeCache)
+			}
 		}
+
+		// Built on every load, so weights and settings changed in the TUI apply to reloads
+		playlistOptions := func(path string) PlaylistOptions {
+			cfg := sharedCfg.Get()
+
+			return PlaylistOptions{
+				Path:          path,
+				ImputeEnergy:  *imputeEnergy || cfg.ImputeEnergy,
+				EnergyByGenre: cfg.EnergyByGenre,
+				BPMRanges:     cfg.BPMRanges,
+				MinRating:     cfg.FavoriteMinRating,
+				Favorites:     cfg.Favorites,
+				FreshDays:     cfg.FreshDays,
+				Overrides:     *overrides,
+				TrackTimeout:  cfg.TrackTimeout(),
+			}
+		}
+
 		loadPlaylist := func(path string, requireMultiple bool) ([]playlist.Track, error) {
-			allowSingle := !requireMultiple
+			return LoadPlaylistForMode(playlistOptions(path), !requireMultiple)
+		}
+
+		opts.LoadWithProgress = func(ctx context.Context, path string, progress func(playlist.LoadProgress)) ([]playlist.Track, []playlist.SkippedTrack, error) {
+			loadOpts := playlistOptions(path)
+			loadOpts.Progress = progress
 
-			return LoadPlaylistForMode(PlaylistOptions{Path: path, Verbose: false}, allowSingle)
+			return LoadPlaylistForModeContext(ctx, loadOpts, false)
 		}
 
 		if err := tui.Run(opts, sharedCfg, runGA, loadPlaylist, playlist.WritePlaylist, debugf, configPath); err != nil {
@@ -91,12 +452,60 @@ func run() int {
 		return 0
 	}
 
-	if err := RunCLI(RunOptions{
+	runOpts := RunOptions{
 		PlaylistPath: playlistPath,
 		DryRun:       *dryRun,
 		OutputPath:   *output,
+		OutputFormat: *outputFormat,
+		Quiet:        *quiet,
+		Duration:     *duration,
 		DebugLog:     *debug,
-	}); err != nil {
+		NoColor:      noColor(*noColorFlag),
+		SimpleUI:     *simpleUI,
+		Shuffle:      *shuffle,
+		Novelty:      *novelty,
+		ImputeEnergy: *imputeEnergy,
+		WriteTags:    *writeTagsFlag,
+		TagNotes:     *tagNotes,
+		BeetsAttr:    *beetsAttr,
+		ChunkSize:    *chunkSize,
+		KeyOnly:      *keyOnly,
+		Preset:       *preset,
+		AutoProfile:  *autoProfile,
+		PathMap:      *pathMap,
+		Overrides:    *overrides,
+		Skipped:      skipped,
+		LiveWrites:   liveWrites,
+
+		SeedPlaylists: *seedPlaylists,
+
+		Landscape:      *landscape,
+		LandscapeEvery: *landscapeEvery,
+
+		ExportTransitions: *exportTransitions,
+		Report:            report,
+
+		NotifyCommand: *notifyCommand,
+		NotifyURL:     *notifyURL,
+		NotifyBelow:   *notifyBelow,
+
+		FailIfAbove:            *failIfAbove,
+		FailIfImprovementBelow: *failIfImprovementBelow,
+	}
+
+	run := RunCLI
+	if isDir(playlistPath) {
+		run = func(opts RunOptions) error { return RunDirectory(playlistPath, opts) }
+	}
+
+	if err := run(runOpts); err != nil {
+		var thresholdErr *thresholdError
+		if errors.As(err, &thresholdErr) {
+			log.Printf("Threshold failed: %v", err)
+
+			return thresholdErr.code
+		}
+
 		log.Printf("CLI error: %v", err)
 
 		return 1
@@ -148,12 +557,18 @@ func writeMemoryProfile(filename string) {
 	}
 }
 
-// runGAForTUI runs GA and converts updates to TUI format
-func runGAForTUI(ctx context.Context, tracks []playlist.Track, sharedCfg *config.SharedConfig, updates chan<- tui.Update, epoch int) {
+// runGAForTUI runs GA and converts updates to TUI format.
+// flags holds the TUI's sticky per-track flags (locked, manual) by track Identity, echoed back per position.
+// Returns only after the GA and its converter goroutine have exited, so the TUI can sequence restarts.
+// edgeCache carries the transitions computed by previous runs over to this one.
+func runGAForTUI(ctx context.Context, tracks []playlist.Track, flags map[string]playlist.TrackFlags, sharedCfg *config.SharedConfig, updates chan<- tui.Update, epoch int, previousOrder []string, edgeCache *edgeCacheStore) {
 	// Buffer smooths GA update rate (updates sent every 50 gens or on improvement)
 	gaUpdateChan := make(chan GAUpdate, 10)
+	converterDone := make(chan struct{})
 
 	go func() {
+		defer close(converterDone)
+
 		defer func() {
 			if r := recover(); r != nil {
 				debugf("[PANIC] Converter goroutine panic: %v\n%s", r, string(debug.Stack()))
@@ -172,16 +587,8 @@ func runGAForTUI(ctx context.Context, tracks []playlist.Track, sharedCfg *config
 							return
 						}
 
-						tuiUpdate := tui.Update{
-							BestPlaylist: update.BestPlaylist,
-							BestFitness:  update.BestFitness,
-							Breakdown:    update.Breakdown,
-							Generation:   update.Generation,
-							GenPerSec:    update.GenPerSec,
-							Epoch:        update.Epoch,
-						}
 						select {
-						case updates <- tuiUpdate:
+						case updates <- toTUIUpdate(update):
 						default:
 						}
 					default:
@@ -193,26 +600,43 @@ func runGAForTUI(ctx context.Context, tracks []playlist.Track, sharedCfg *config
 					return
 				}
 
-				tuiUpdate := tui.Update{
-					BestPlaylist: update.BestPlaylist,
-					BestFitness:  update.BestFitness,
-					Breakdown:    update.Breakdown,
-					Generation:   update.Generation,
-					GenPerSec:    update.GenPerSec,
-					Epoch:        update.Epoch,
-				}
-
 				select {
-				case updates <- tuiUpdate:
+				case updates <- toTUIUpdate(update):
 				default:
 				}
 			}
 		}
 	}()
 
-	gaCtx := buildEdgeFitnessCache(tracks)
+	// TUI edits (insert/delete) change the track set, so indices must be reassigned to match
+	// the cache built below. Clone first - the TUI still owns the slice it passed in.
+	tracks = slices.Clone(tracks)
+	playlist.ReindexTracks(tracks)
 
-	defer close(gaUpdateChan)
+	// Edits usually change only a few tracks, so most edges carry over from the previous run
+	gaCtx := edgeCache.build(tracks)
+	markPreviousAdjacencies(gaCtx, tracks, previousOrder)
+	gaCtx.trackFlags = flags
 
-	geneticSort(ctx, tracks, sharedCfg, gaUpdateChan, epoch, gaCtx)
+	// Tracks are reindexed above, so this only fails on a bug; the TUI keeps its current order
+	if _, err := geneticSort(ctx, tracks, sharedCfg, gaUpdateChan, epoch, gaCtx); err != nil {
+		debugf("[GA] %v", err)
+	}
+
+	close(gaUpdateChan)
+	<-converterDone
+}
+
+// toTUIUpdate converts a GA progress update to the TUI's update type
+func toTUIUpdate(update GAUpdate) tui.Update {
+	return tui.Update{
+		BestPlaylist: update.BestPlaylist,
+		BestFitness:  update.BestFitness,
+		Breakdown:    update.Breakdown,
+		Flags:        update.Flags,
+		Generation:   update.Generation,
+		GenPerSec:    update.GenPerSec,
+		Epoch:        update.Epoch,
+		Optimal:      update.Optimal,
+	}
 }
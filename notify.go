@@ -0,0 +1,214 @@
+// ABOUTME: Notification hooks announcing long optimization runs: a command and/or a webhook
+// ABOUTME: Fired when a run finishes and when the best fitness first drops to a threshold
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"playlist-sorter/config"
+)
+
+// Notification events
+const (
+	notifyFinished  = "finished"  // Run ended (time limit, Ctrl+C or exact solution) and the result was written
+	notifyThreshold = "threshold" // Best fitness dropped to the -notify-below threshold
+)
+
+// Placeholders substituted in the notify command template
+const (
+	notifyEventPlaceholder    = "{event}"    // finished or threshold
+	notifyPlaylistPlaceholder = "{playlist}" // Playlist path
+	notifyFitnessPlaceholder  = "{fitness}"  // Best fitness so far
+	notifyMessagePlaceholder  = "{message}"  // Human-readable summary
+)
+
+// notifyTimeout bounds each command and webhook call, so a hanging hook can't stall the run
+const notifyTimeout = 10 * time.Second
+
+// notification is what a hook is told; the webhook receives it as JSON
+type notification struct {
+	Event    string  `json:"event"`
+	Playlist string  `json:"playlist"`
+	Fitness  float64 `json:"fitness"`
+	Message  string  `json:"message"`
+}
+
+// notifier fires the configured hooks. Failures are logged as warnings: a broken hook never fails a run.
+type notifier struct {
+	command  string  // Command template (empty = none)
+	url      string  // Webhook URL (empty = none)
+	below    float64 // Fitness threshold (0 = no threshold notification)
+	playlist string
+
+	crossed bool           // Threshold notification sent
+	pending sync.WaitGroup // Threshold notification still being sent
+}
+
+// newNotifier returns a notifier for the hooks in opts, falling back to mainCfg's, or nil when none
+// is set. The hooks run commands and reach URLs, so they only come from flags or the main config,
+// never from a playlist's sidecar; the threshold falls back to cfg, the playlist's merged config.
+func newNotifier(opts RunOptions, mainCfg, cfg config.GAConfig) *notifier {
+	n := &notifier{
+		command:  cmp.Or(opts.NotifyCommand, mainCfg.NotifyCommand),
+		url:      cmp.Or(opts.NotifyURL, mainCfg.NotifyURL),
+		below:    cmp.Or(opts.NotifyBelow, cfg.NotifyBelow),
+		playlist: opts.PlaylistPath,
+	}
+
+	if n.command == "" && n.url == "" {
+		return nil
+	}
+
+	return n
+}
+
+// improved fires the threshold notification the first time fitness is at or below the threshold.
+// It is sent in the background, so a slow hook doesn't hold up the search reporting improvements.
+func (n *notifier) improved(fitness float64) {
+	if n == nil || n.below <= 0 || n.crossed || fitness > n.below {
+		return
+	}
+
+	n.crossed = true
+
+	note := notification{
+		Event:    notifyThreshold,
+		Playlist: n.playlist,
+		Fitness:  fitness,
+		Message:  fmt.Sprintf("%s: fitness %.6f reached the %g threshold", n.playlist, fitness, n.below),
+	}
+
+	n.pending.Add(1)
+
+	go func() {
+		defer n.pending.Done()
+
+		n.send(note)
+	}()
+}
+
+// finished fires the finished notification for a completed run, after any threshold notification
+// still being sent
+func (n *notifier) finished(result sortResult) {
+	if n == nil {
+		return
+	}
+
+	n.pending.Wait()
+
+	n.send(notification{
+		Event:    notifyFinished,
+		Playlist: n.playlist,
+		Fitness:  result.FinalFitness,
+		Message:  result.Summary(),
+	})
+}
+
+// send runs the command and posts to the webhook
+func (n *notifier) send(note notification) {
+	if n.command != "" {
+		if err := runNotifyCommand(n.command, note); err != nil {
+			log.Printf("Warning: notify command failed: %v", err)
+		}
+	}
+
+	if n.url != "" {
+		if err := postNotification(n.url, note); err != nil {
+			log.Printf("Warning: notify webhook failed: %v", err)
+		}
+	}
+}
+
+// buildNotifyCommand splits the command template on whitespace and substitutes placeholders per
+// argument, so values containing spaces (paths, messages) stay a single argument. The message is
+// appended as the last argument if the template has no placeholders (e.g. "notify-send Playlist").
+func buildNotifyCommand(template string, note notification) ([]string, error) {
+	fields := strings.Fields(template)
+	if len(fields) == 0 {
+		return nil, errors.New("notify command is empty")
+	}
+
+	replacer := strings.NewReplacer(
+		notifyEventPlaceholder, note.Event,
+		notifyPlaylistPlaceholder, note.Playlist,
+		notifyFitnessPlaceholder, strconv.FormatFloat(note.Fitness, 'f', 6, 64),
+		notifyMessagePlaceholder, note.Message,
+	)
+
+	substituted := false
+
+	for i, field := range fields {
+		if replaced := replacer.Replace(field); replaced != field {
+			fields[i] = replaced
+			substituted = true
+		}
+	}
+
+	if !substituted {
+		fields = append(fields, note.Message)
+	}
+
+	return fields, nil
+}
+
+// runNotifyCommand runs the notify command for note
+func runNotifyCommand(template string, note notification) error {
+	args, err := buildNotifyCommand(template, note)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // Command is user-configured by design
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// postNotification posts note as JSON to url
+func postNotification(url string, note notification) error {
+	body, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	return nil
+}
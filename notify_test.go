@@ -0,0 +1,116 @@
+// ABOUTME: Tests for notification hooks
+// ABOUTME: Covers command templating, the webhook payload and when the threshold notification fires
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"playlist-sorter/config"
+)
+
+func TestBuildNotifyCommand(t *testing.T) {
+	note := notification{Event: notifyFinished, Playlist: "/music/my set.m3u8", Fitness: 0.25, Message: "all done"}
+
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{"placeholders", "notify-send {event} {message}", []string{"notify-send", "finished", "all done"}},
+		{"embedded", "echo fitness={fitness} {playlist}", []string{"echo", "fitness=0.250000", "/music/my set.m3u8"}},
+		{"message appended", "notify-send playlist-sorter", []string{"notify-send", "playlist-sorter", "all done"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildNotifyCommand(tt.template, note)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := buildNotifyCommand("  ", note); err == nil {
+		t.Error("Expected an error for an empty command")
+	}
+}
+
+func TestNotifierWebhookAndThreshold(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []notification
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var note notification
+		if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+
+		mu.Lock()
+		received = append(received, note)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.NotifyURL = srv.URL
+	cfg.NotifyBelow = 0.5
+
+	n := newNotifier(RunOptions{PlaylistPath: "set.m3u8", NotifyBelow: 0.3}, cfg, cfg)
+	if n == nil || n.below != 0.3 {
+		t.Fatalf("Expected the flag to override the config threshold, got %+v", n)
+	}
+
+	n.improved(0.4) // Above the threshold
+	n.improved(0.3) // Reaches it
+	n.improved(0.2) // Already notified
+
+	// Sent after the threshold notification, which goes out in the background
+	n.finished(sortResult{Tracks: 10, FinalFitness: 0.2, OutputPath: "set.m3u8"})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received) != 2 {
+		t.Fatalf("Expected threshold and finished notifications, got %+v", received)
+	}
+
+	if received[0].Event != notifyThreshold || received[0].Fitness != 0.3 || received[0].Playlist != "set.m3u8" {
+		t.Errorf("Unexpected threshold notification %+v", received[0])
+	}
+
+	if received[1].Event != notifyFinished || received[1].Fitness != 0.2 {
+		t.Errorf("Unexpected finished notification %+v", received[1])
+	}
+}
+
+func TestNotifierDisabled(t *testing.T) {
+	n := newNotifier(RunOptions{NotifyBelow: 0.3}, config.DefaultConfig(), config.DefaultConfig())
+	if n != nil {
+		t.Fatal("Expected no notifier without a command or URL")
+	}
+
+	// Hooks from a playlist's sidecar (merged into the playlist's config) are never run
+	sidecar := config.DefaultConfig()
+	sidecar.NotifyCommand = "rm -rf {playlist}"
+	sidecar.NotifyURL = "http://example.com/hook"
+
+	if n := newNotifier(RunOptions{}, config.DefaultConfig(), sidecar); n != nil {
+		t.Fatalf("Expected sidecar hooks to be ignored, got %+v", n)
+	}
+
+	// A nil notifier is safe to use
+	n.improved(0.1)
+	n.finished(sortResult{})
+}
@@ -0,0 +1,155 @@
+// ABOUTME: Normalizes genre tags so spelling variants like "Drum & Bass", "DnB" and "drum'n'bass" match
+// ABOUTME: Strips punctuation, resolves built-in synonyms and user-configured aliases
+
+package playlist
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// genreSynonyms maps canonical spellings (see canonicalGenre) of common variants to the genre they mean
+var genreSynonyms = map[string]string{
+	"dnb":              "drum and bass",
+	"d and b":          "drum and bass",
+	"d n b":            "drum and bass",
+	"drum n bass":      "drum and bass",
+	"drumnbass":        "drum and bass",
+	"drum bass":        "drum and bass",
+	"liquid dnb":       "dj drum and bass liquid",
+	"liquid funk":      "dj drum and bass liquid",
+	"hiphop":           "hip hop",
+	"rnb":              "r and b",
+	"rhythm and blues": "r and b",
+	"uk garage":        "garage",
+	"ukg":              "garage",
+	"synth pop":        "synthpop",
+	"synth wave":       "synthwave",
+	"punk rock":        "punkrock",
+}
+
+// genreSeparators split multi-value genre tags; ID3v2.4 separates values with NUL
+const genreSeparators = ";,|\x00"
+
+var (
+	genreAliasMu   sync.RWMutex
+	genreAliases   map[string]string       // Configured aliases by canonical spelling (nil = none)
+	genreCache     = map[string]string{}   // NormalizeGenre results by tag
+	genreListCache = map[string][]string{} // normalizedGenres results by tag
+)
+
+// canonicalHierarchy is genreHierarchy with keys and parents in canonical spelling
+var canonicalHierarchy = func() map[string]string {
+	h := make(map[string]string, len(genreHierarchy))
+
+	for _, genre := range slices.Sorted(maps.Keys(genreHierarchy)) {
+		g, parent := canonicalGenre(genre), canonicalGenre(genreHierarchy[genre])
+		if _, seen := h[g]; seen || g == parent {
+			continue // Spelling variants of the same genre ("hip-hop" -> "hip hop")
+		}
+
+		h[g] = parent
+	}
+
+	return h
+}()
+
+// SetGenreAliases sets user-defined aliases from tag to the genre it means, e.g. {"Liquid": "drum and bass"}.
+// Both sides are matched like tags are, so spelling and punctuation don't matter. nil clears them.
+func SetGenreAliases(aliases map[string]string) {
+	canonical := make(map[string]string, len(aliases))
+	for from, to := range aliases {
+		if from = canonicalGenre(from); from != "" {
+			canonical[from] = canonicalGenre(to)
+		}
+	}
+
+	genreAliasMu.Lock()
+	defer genreAliasMu.Unlock()
+
+	genreAliases = canonical
+	genreCache = map[string]string{}
+	genreListCache = map[string][]string{}
+}
+
+// SplitGenres splits a multi-value genre tag ("Electronic; Drum & Bass, Liquid") into its values,
+// trimmed and without empty ones. A single-value tag yields one value; an empty tag none.
+func SplitGenres(genre string) []string {
+	var values []string
+
+	for _, v := range strings.FieldsFunc(genre, func(r rune) bool { return strings.ContainsRune(genreSeparators, r) }) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// normalizedGenres returns the distinct normalized values of a genre tag (cached; callers must not modify it)
+func normalizedGenres(genre string) []string {
+	genreAliasMu.RLock()
+	values, ok := genreListCache[genre]
+	genreAliasMu.RUnlock()
+
+	if ok {
+		return values
+	}
+
+	values = []string{}
+
+	for _, v := range SplitGenres(genre) {
+		if g := NormalizeGenre(v); g != "" && !slices.Contains(values, g) {
+			values = append(values, g)
+		}
+	}
+
+	genreAliasMu.Lock()
+	genreListCache[genre] = values
+	genreAliasMu.Unlock()
+
+	return values
+}
+
+// NormalizeGenre returns the form genres are compared in: lowercase without punctuation ("&" and
+// "'n'" read as "and"), with configured aliases and then built-in synonyms resolved.
+// "Drum & Bass", "DnB" and "drum'n'bass" all become "drum and bass".
+func NormalizeGenre(genre string) string {
+	genreAliasMu.RLock()
+	normalized, ok := genreCache[genre]
+	genreAliasMu.RUnlock()
+
+	if ok {
+		return normalized
+	}
+
+	genreAliasMu.Lock()
+	defer genreAliasMu.Unlock()
+
+	normalized = canonicalGenre(genre)
+	if alias, ok := genreAliases[normalized]; ok {
+		normalized = alias
+	}
+
+	if synonym, ok := genreSynonyms[normalized]; ok {
+		normalized = synonym
+	}
+
+	genreCache[genre] = normalized
+
+	return normalized
+}
+
+// canonicalGenre lowercases genre, spells "&" and "'n'" as "and", replaces other punctuation with
+// spaces and collapses runs of spaces
+func canonicalGenre(genre string) string {
+	g := strings.ToLower(genre)
+	g = strings.NewReplacer("&", " and ", "'n'", " and ", "’n’", " and ").Replace(g)
+
+	return strings.Join(strings.FieldsFunc(g, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Tests for genre normalization and aliases
+// ABOUTME: Verifies spelling variants resolve to one genre and configured aliases take part in similarity
+
+package playlist
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeGenre(t *testing.T) {
+	tests := []struct {
+		genre string
+		want  string
+	}{
+		{"Drum & Bass", "drum and bass"},
+		{"DnB", "drum and bass"},
+		{"drum'n'bass", "drum and bass"},
+		{"Drum n Bass", "drum and bass"},
+		{"D&B", "drum and bass"},
+		{"Hip-Hop", "hip hop"},
+		{"R&B", "r and b"},
+		{"DJ Drum and Bass - Liquid", "dj drum and bass liquid"},
+		{"  Funk / Soul ", "funk soul"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeGenre(tt.genre); got != tt.want {
+			t.Errorf("NormalizeGenre(%q) = %q, want %q", tt.genre, got, tt.want)
+		}
+	}
+
+	// Variants now share the hierarchy
+	if got := GenreSimilarity("DnB", "drum'n'bass"); got != genreIdentical {
+		t.Errorf("Expected DnB and drum'n'bass to be identical, got %.2f", got)
+	}
+
+	if got := GenreSimilarity("Drum & Bass", "Jungle"); got != genreParentChild {
+		t.Errorf("Expected Drum & Bass to be Jungle's parent, got %.2f", got)
+	}
+
+	if chain := GenreAncestors("DJ Drum and Bass - Liquid"); !slices.Equal(chain, []string{"dj drum and bass liquid", "dj drum and bass", "drum and bass", "electronic"}) {
+		t.Errorf("Unexpected ancestors %v", chain)
+	}
+}
+
+func TestSetGenreAliases(t *testing.T) {
+	t.Cleanup(func() { SetGenreAliases(nil) })
+
+	if got := GenreSimilarity("Neurofunk", "DnB"); got != genreUnrelated {
+		t.Fatalf("Expected an unknown genre to be unrelated before aliasing, got %.2f", got)
+	}
+
+	SetGenreAliases(map[string]string{"Neuro-Funk": "Drum & Bass", "Minimal": "techno"})
+
+	if got := NormalizeGenre("neurofunk"); got == "drum and bass" {
+		t.Errorf("Expected aliases to match spelling exactly apart from case and punctuation, got %q", got)
+	}
+
+	if got := GenreSimilarity("Neuro Funk", "DnB"); got != genreIdentical {
+		t.Errorf("Expected the alias to resolve to drum and bass, got %.2f", got)
+	}
+
+	if got := GenreSimilarity("minimal", "trance"); got != genreSiblings {
+		t.Errorf("Expected an aliased genre to join the hierarchy, got %.2f", got)
+	}
+
+	SetGenreAliases(nil)
+
+	if got := NormalizeGenre("Neuro Funk"); got != "neuro funk" {
+		t.Errorf("Expected cleared aliases to stop applying, got %q", got)
+	}
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Corrects half- and double-time BPM tags, e.g. 87 stored for a 174 BPM drum and bass track
+// ABOUTME: Doubles or halves BPMs outside the configured range of the track's genre when that fits it
+
+package playlist
+
+// BPMCorrection records a BPM changed by CorrectBPM
+type BPMCorrection struct {
+	Path string
+	From float64 // BPM read from the tags
+	To   float64 // Corrected BPM
+}
+
+// CorrectBPM fixes BPMs tagged at half or double time. ranges maps a genre to the [min, max] BPM its
+// tracks are expected in (matched like tags, parent genres too, so "drum and bass" covers jungle). A
+// BPM outside its genre's range is doubled or halved when that lands inside the range; other BPMs,
+// and tracks without a BPM or a ranged genre, are left alone. Returns the corrections in track order.
+func CorrectBPM(tracks []Track, ranges map[string][2]float64) []BPMCorrection {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	mapping := make(map[string][2]float64, len(ranges))
+	for genre, r := range ranges {
+		mapping[NormalizeGenre(genre)] = r
+	}
+
+	var corrections []BPMCorrection
+
+	for i := range tracks {
+		t := &tracks[i]
+		if t.BPM <= 0 {
+			continue
+		}
+
+		r, ok := bpmRange(t.Genre, mapping)
+		if !ok || (t.BPM >= r[0] && t.BPM <= r[1]) {
+			continue
+		}
+
+		for _, corrected := range []float64{t.BPM * 2, t.BPM / 2} {
+			if corrected >= r[0] && corrected <= r[1] {
+				corrections = append(corrections, BPMCorrection{Path: t.Path, From: t.BPM, To: corrected})
+				t.BPM = corrected
+				t.BPMCorrected = true
+
+				break
+			}
+		}
+	}
+
+	return corrections
+}
+
+// CountBPMCorrected returns how many tracks CorrectBPM changed the BPM of
+func CountBPMCorrected(tracks []Track) int {
+	n := 0
+
+	for i := range tracks {
+		if tracks[i].BPMCorrected {
+			n++
+		}
+	}
+
+	return n
+}
+
+// bpmRange looks up a genre (or its nearest ranged ancestor) in the normalized range mapping
+func bpmRange(genre string, mapping map[string][2]float64) ([2]float64, bool) {
+	for _, ancestor := range GenreAncestors(genre) {
+		if r, ok := mapping[ancestor]; ok && r[0] > 0 && r[1] >= r[0] {
+			return r, true
+		}
+	}
+
+	return [2]float64{}, false
+}
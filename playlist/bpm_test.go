@@ -0,0 +1,44 @@
+// ABOUTME: Tests for half/double-time BPM correction
+// ABOUTME: Verifies genre range lookup, doubling and halving, and tracks left alone
+
+package playlist
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCorrectBPM(t *testing.T) {
+	tracks := []Track{
+		{Path: "half", Genre: "Jungle", BPM: 87},           // Ancestor "drum and bass" is ranged
+		{Path: "double", Genre: "Electro House", BPM: 248}, // Ancestor "house"
+		{Path: "fine", Genre: "Drum & Bass", BPM: 172},     // Already in range
+		{Path: "odd", Genre: "Drum and Bass", BPM: 140},    // Neither half nor double fits
+		{Path: "unranged", Genre: "Ambient", BPM: 60},      // No range for the genre
+		{Path: "missing", Genre: "Drum and Bass", BPM: 0},  // No BPM to correct
+	}
+
+	corrections := CorrectBPM(tracks, map[string][2]float64{"Drum and Bass": {160, 180}, "house": {118, 130}})
+
+	want := []BPMCorrection{{Path: "half", From: 87, To: 174}, {Path: "double", From: 248, To: 124}}
+	if !slices.Equal(corrections, want) {
+		t.Errorf("Expected corrections %+v, got %+v", want, corrections)
+	}
+
+	bpms := make([]float64, len(tracks))
+	for i := range tracks {
+		bpms[i] = tracks[i].BPM
+	}
+
+	if wantBPMs := []float64{174, 124, 172, 140, 60, 0}; !slices.Equal(bpms, wantBPMs) {
+		t.Errorf("Expected BPMs %v, got %v", wantBPMs, bpms)
+	}
+
+	if n := CountBPMCorrected(tracks); n != 2 || !tracks[0].BPMCorrected || tracks[2].BPMCorrected {
+		t.Errorf("Expected the two corrected tracks flagged, got %d", n)
+	}
+
+	if CorrectBPM(tracks, nil) != nil {
+		t.Error("Expected no corrections without ranges")
+	}
+}
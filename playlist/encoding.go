@@ -0,0 +1,113 @@
+// ABOUTME: Text encoding detection for playlist files exported by other tools
+// ABOUTME: Strips UTF-8 BOMs and decodes UTF-16 (with or without BOM) to UTF-8 before parsing
+
+package playlist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// utf16Sniff is how many leading bytes are checked for the NUL pattern of BOM-less UTF-16
+const utf16Sniff = 64
+
+// decodePlaylistText returns a UTF-8 reader for a playlist: a UTF-8 BOM is stripped, and UTF-16
+// (little or big endian, detected by BOM or by NULs in every other byte of ASCII text) is decoded.
+// Anything else is passed through unchanged.
+func decodePlaylistText(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	head, err := br.Peek(utf16Sniff)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	var order binary.ByteOrder
+
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		_, _ = br.Discard(len(bomUTF8))
+
+		return br, nil
+	case bytes.HasPrefix(head, bomUTF16LE):
+		_, _ = br.Discard(len(bomUTF16LE))
+		order = binary.LittleEndian
+	case bytes.HasPrefix(head, bomUTF16BE):
+		_, _ = br.Discard(len(bomUTF16BE))
+		order = binary.BigEndian
+	default:
+		order = sniffUTF16(head)
+		if order == nil {
+			return br, nil
+		}
+	}
+
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := decodeUTF16(data, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(text), nil
+}
+
+// sniffUTF16 guesses the byte order of BOM-less UTF-16 from its first bytes: playlists start with
+// ASCII ("#EXTM3U", a path), which encodes with a NUL in every high byte. Returns nil otherwise.
+func sniffUTF16(head []byte) binary.ByteOrder {
+	n := len(head) &^ 1
+	if n < 4 {
+		return nil
+	}
+
+	evenNUL, oddNUL := true, true
+
+	for i := 0; i < n; i += 2 {
+		evenNUL = evenNUL && head[i] == 0 && head[i+1] != 0
+		oddNUL = oddNUL && head[i+1] == 0 && head[i] != 0
+	}
+
+	switch {
+	case oddNUL:
+		return binary.LittleEndian
+	case evenNUL:
+		return binary.BigEndian
+	default:
+		return nil
+	}
+}
+
+// decodeUTF16 converts UTF-16 text in the given byte order to UTF-8
+func decodeUTF16(data []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("invalid UTF-16 playlist: odd length %d", len(data))
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+
+	runes := utf16.Decode(units)
+
+	text := make([]byte, 0, len(runes))
+	for _, r := range runes {
+		text = utf8.AppendRune(text, r)
+	}
+
+	return text, nil
+}
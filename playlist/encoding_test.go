@@ -0,0 +1,74 @@
+// ABOUTME: Tests for playlist text encoding detection
+// ABOUTME: Verifies BOM stripping and UTF-16 decoding before playlist parsing
+
+package playlist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF16 encodes s as UTF-16 in the given byte order, optionally with a BOM
+func encodeUTF16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var buf []byte
+	if bom {
+		buf = order.AppendUint16(buf, 0xFEFF)
+	}
+
+	for _, u := range utf16.Encode([]rune(s)) {
+		buf = order.AppendUint16(buf, u)
+	}
+
+	return buf
+}
+
+func TestReadPlaylistEncodings(t *testing.T) {
+	const text = "#EXTM3U\r\n#EXTINF:-1,Björk\r\nBjörk/Jóga.mp3\r\n/music/02 Track.flac\r\n"
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"UTF-8", []byte(text)},
+		{"UTF-8 BOM", append([]byte{0xEF, 0xBB, 0xBF}, text...)},
+		{"UTF-16LE BOM", encodeUTF16(text, binary.LittleEndian, true)},
+		{"UTF-16BE BOM", encodeUTF16(text, binary.BigEndian, true)},
+		{"UTF-16LE", encodeUTF16(text, binary.LittleEndian, false)},
+		{"UTF-16BE", encodeUTF16(text, binary.BigEndian, false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracks, err := readPlaylistFrom(bytes.NewReader(tt.data), nil)
+			if err != nil {
+				t.Fatalf("Failed to read playlist: %v", err)
+			}
+
+			if len(tracks) != 2 || tracks[0].Path != "Björk/Jóga.mp3" || tracks[1].Path != "/music/02 Track.flac" {
+				t.Errorf("Unexpected tracks: %+v", tracks)
+			}
+		})
+	}
+}
+
+func TestReadPlaylistBOMWithoutHeader(t *testing.T) {
+	// Without stripping, the BOM would become part of the first path
+	tracks, err := readPlaylistFrom(bytes.NewReader(append([]byte{0xEF, 0xBB, 0xBF}, "a.mp3\nb.mp3\n"...)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracks) != 2 || tracks[0].Path != "a.mp3" {
+		t.Errorf("Unexpected tracks: %+v", tracks)
+	}
+}
+
+func TestReadPlaylistOddUTF16(t *testing.T) {
+	data := append(encodeUTF16("a.mp3\n", binary.LittleEndian, true), 'x')
+
+	if _, err := readPlaylistFrom(bytes.NewReader(data), nil); err == nil {
+		t.Error("Expected an error for truncated UTF-16")
+	}
+}
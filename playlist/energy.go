@@ -0,0 +1,112 @@
+// ABOUTME: Energy imputation for tracks whose tags lack an energy level
+// ABOUTME: Estimates energy from a genre mapping or from tracks with similar BPM and genre
+
+package playlist
+
+import (
+	"math"
+)
+
+// Neighborhood limits for energy imputation
+const (
+	imputeBPMTolerance   = 8.0           // Max BPM difference (half/double time aware) for a neighbor
+	imputeGenreTolerance = genreSiblings // Max genre distance for a neighbor
+)
+
+// ImputeEnergy fills in Energy for tracks where it is missing (0) and flags them as estimated.
+// Estimates come from, in order of preference:
+//  1. byGenre: configured genre -> energy mapping (matched on the genre or its parent genres)
+//  2. the average energy of tracks with similar BPM and a closely related genre
+//  3. the average energy of all tracks with known energy
+//
+// Returns the number of tracks that were imputed. Tracks are left at 0 if no track has known energy.
+func ImputeEnergy(tracks []Track, byGenre map[string]int) int {
+	known := make([]int, 0, len(tracks))
+
+	for i := range tracks {
+		if tracks[i].Energy > 0 && !tracks[i].EnergyEstimated {
+			known = append(known, i)
+		}
+	}
+
+	mapping := make(map[string]int, len(byGenre))
+	for genre, energy := range byGenre {
+		mapping[NormalizeGenre(genre)] = energy
+	}
+
+	imputed := 0
+
+	for i := range tracks {
+		t := &tracks[i]
+		if t.Energy > 0 {
+			continue
+		}
+
+		energy, ok := energyFromGenre(t.Genre, mapping)
+		if !ok {
+			energy, ok = energyFromNeighbors(t, tracks, known)
+		}
+
+		if !ok {
+			continue
+		}
+
+		t.Energy = energy
+		t.EnergyEstimated = true
+		imputed++
+	}
+
+	return imputed
+}
+
+// energyFromGenre looks up a genre (or its nearest mapped ancestor) in the energy mapping
+func energyFromGenre(genre string, mapping map[string]int) (int, bool) {
+	if len(mapping) == 0 {
+		return 0, false
+	}
+
+	for _, ancestor := range GenreAncestors(genre) {
+		if energy, ok := mapping[ancestor]; ok && energy > 0 {
+			return energy, true
+		}
+	}
+
+	return 0, false
+}
+
+// energyFromNeighbors averages energy over known tracks with similar BPM and genre,
+// falling back to all known tracks when there are no close neighbors
+func energyFromNeighbors(t *Track, tracks []Track, known []int) (int, bool) {
+	if len(known) == 0 {
+		return 0, false
+	}
+
+	var neighborSum, neighborCount, allSum int
+
+	for _, k := range known {
+		other := &tracks[k]
+		allSum += other.Energy
+
+		if t.BPM <= 0 || other.BPM <= 0 || bpmDistance(t.BPM, other.BPM) > imputeBPMTolerance {
+			continue
+		}
+
+		if GenreSimilarity(t.Genre, other.Genre) > imputeGenreTolerance {
+			continue
+		}
+
+		neighborSum += other.Energy
+		neighborCount++
+	}
+
+	if neighborCount > 0 {
+		return int(math.Round(float64(neighborSum) / float64(neighborCount))), true
+	}
+
+	return int(math.Round(float64(allSum) / float64(len(known)))), true
+}
+
+// bpmDistance returns the BPM difference allowing for half/double time
+func bpmDistance(a, b float64) float64 {
+	return min(math.Abs(a-b), math.Abs(a*2-b), math.Abs(a-b*2))
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Tests for energy imputation of tracks missing energy tags
+// ABOUTME: Verifies genre mapping, BPM/genre neighborhood averaging, and global fallback
+
+package playlist
+
+import "testing"
+
+func TestImputeEnergyFromGenreMapping(t *testing.T) {
+	tracks := []Track{
+		{Path: "a", Genre: "Liquid", Energy: 0},
+		{Path: "b", Genre: "DJ Drum and Bass - Liquid", Energy: 0},
+		{Path: "c", Genre: "Techno", Energy: 7},
+	}
+
+	// "dj drum and bass - liquid" isn't mapped directly but its ancestor "drum and bass" is
+	n := ImputeEnergy(tracks, map[string]int{"Drum and Bass": 5})
+
+	if n != 2 {
+		t.Fatalf("Expected 2 imputed tracks, got %d", n)
+	}
+
+	if tracks[1].Energy != 5 || !tracks[1].EnergyEstimated {
+		t.Errorf("Expected ancestor mapping energy 5 (estimated), got %d (estimated=%v)", tracks[1].Energy, tracks[1].EnergyEstimated)
+	}
+
+	// "liquid" has no mapping and no neighbors, so it gets the global average of known tracks
+	if tracks[0].Energy != 7 {
+		t.Errorf("Expected global average 7, got %d", tracks[0].Energy)
+	}
+
+	if tracks[2].EnergyEstimated {
+		t.Error("Track with tagged energy should not be flagged as estimated")
+	}
+}
+
+func TestImputeEnergyFromNeighbors(t *testing.T) {
+	tracks := []Track{
+		{Path: "a", Genre: "House", BPM: 124, Energy: 0},
+		{Path: "b", Genre: "House", BPM: 126, Energy: 6},
+		{Path: "c", Genre: "Progressive House", BPM: 62, Energy: 8}, // Half-time neighbor
+		{Path: "d", Genre: "Jazz", BPM: 124, Energy: 1},             // Unrelated genre
+		{Path: "e", Genre: "House", BPM: 90, Energy: 2},             // BPM too far
+	}
+
+	ImputeEnergy(tracks, nil)
+
+	if tracks[0].Energy != 7 {
+		t.Errorf("Expected neighbor average 7, got %d", tracks[0].Energy)
+	}
+}
+
+func TestImputeEnergyNoKnownValues(t *testing.T) {
+	tracks := []Track{{Path: "a"}, {Path: "b"}}
+
+	if n := ImputeEnergy(tracks, nil); n != 0 {
+		t.Errorf("Expected nothing imputed without known energies, got %d", n)
+	}
+
+	if tracks[0].EnergyEstimated {
+		t.Error("Track should not be flagged when left at 0")
+	}
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Star ratings read from tags and marking of favorite tracks for prime placement
+// ABOUTME: Favorites are tracks rated at or above a threshold or listed by path or "Artist - Title"
+
+package playlist
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rating tag names holding text ratings: 0-1 (FMPS), 1-5 stars or 0-100
+var ratingTags = []string{"FMPS_RATING", "RATING", "rate"}
+
+// extractRating reads a track's star rating (1-5; 0 when unrated) from an ID3 popularimeter frame
+// or a text rating tag
+func extractRating(raw map[string]interface{}) int {
+	for _, name := range []string{"POPM", "POP"} {
+		if frame, ok := raw[name].([]byte); ok {
+			return popularimeterStars(frame)
+		}
+	}
+
+	for _, name := range ratingTags {
+		if text := strings.TrimSpace(rawTagText(raw, name)); text != "" {
+			return textRatingStars(text)
+		}
+	}
+
+	return 0
+}
+
+// popularimeterStars converts an ID3 POPM frame (email, NUL, rating byte 0-255, play counter) to stars
+// with the mapping common players use (1, 64, 128, 196 and 255 for one to five stars)
+func popularimeterStars(frame []byte) int {
+	i := bytes.IndexByte(frame, 0)
+	if i < 0 || i+1 >= len(frame) {
+		return 0
+	}
+
+	switch rating := frame[i+1]; {
+	case rating == 0:
+		return 0
+	case rating < 32:
+		return 1
+	case rating < 96:
+		return 2
+	case rating < 160:
+		return 3
+	case rating < 224:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// textRatingStars converts a text rating to stars: fractions up to 1 are FMPS ratings, values up to
+// 5 are stars and larger values are percentages
+func textRatingStars(text string) int {
+	r, err := strconv.ParseFloat(text, 64)
+	if err != nil || r <= 0 || math.IsNaN(r) || math.IsInf(r, 0) {
+		return 0
+	}
+
+	switch {
+	case r <= 1:
+		r *= 5
+	case r > 5:
+		r /= 20
+	}
+
+	return min(max(int(math.Round(r)), 1), 5)
+}
+
+// MarkFavorites sets Favorite on tracks rated at least minRating stars (0 = ratings don't count) or
+// listed in names by playlist path or "Artist - Title" (case-insensitive). Returns how many are favorites.
+func MarkFavorites(tracks []Track, minRating int, names []string) int {
+	listed := make(map[string]bool, len(names))
+	for _, name := range names {
+		listed[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	favorites := 0
+
+	for i := range tracks {
+		t := &tracks[i]
+		t.Favorite = (minRating > 0 && t.Rating >= minRating) ||
+			listed[strings.ToLower(t.Path)] || listed[strings.ToLower(t.Artist+" - "+t.Title)]
+
+		if t.Favorite {
+			favorites++
+		}
+	}
+
+	return favorites
+}
@@ -0,0 +1,56 @@
+// ABOUTME: Tests for star ratings and favorite marking
+// ABOUTME: Verifies popularimeter and text rating conversion and matching by rating, path and name
+
+package playlist
+
+import "testing"
+
+func TestExtractRating(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want int
+	}{
+		{"unrated", map[string]interface{}{}, 0},
+		{"popularimeter five stars", map[string]interface{}{"POPM": []byte("a@b\x00\xff\x00\x00\x00\x01")}, 5},
+		{"popularimeter three stars", map[string]interface{}{"POPM": []byte("\x00\x80")}, 3},
+		{"popularimeter zero", map[string]interface{}{"POPM": []byte("\x00\x00")}, 0},
+		{"FMPS fraction", map[string]interface{}{"FMPS_RATING": "0.8"}, 4},
+		{"stars", map[string]interface{}{"RATING": "2"}, 2},
+		{"percentage", map[string]interface{}{"rate": "100"}, 5},
+		{"garbage", map[string]interface{}{"RATING": "great"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractRating(tt.raw); got != tt.want {
+				t.Errorf("Expected %d stars, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMarkFavorites(t *testing.T) {
+	tracks := []Track{
+		{Path: "rated.mp3", Rating: 5},
+		{Path: "four.mp3", Rating: 4},
+		{Path: "Listed.mp3"},
+		{Path: "named.mp3", Artist: "Artist", Title: "Anthem"},
+		{Path: "other.mp3", Favorite: true}, // Stale mark is cleared
+	}
+
+	if n := MarkFavorites(tracks, 5, []string{"listed.mp3", "artist - anthem"}); n != 3 {
+		t.Errorf("Expected 3 favorites, got %d", n)
+	}
+
+	want := []bool{true, false, true, true, false}
+	for i, w := range want {
+		if tracks[i].Favorite != w {
+			t.Errorf("Expected %s favorite=%v, got %v", tracks[i].Path, w, tracks[i].Favorite)
+		}
+	}
+
+	if n := MarkFavorites(tracks, 0, nil); n != 0 {
+		t.Errorf("Expected no favorites without a threshold or list, got %d", n)
+	}
+}
@@ -0,0 +1,365 @@
+// ABOUTME: Playlist output formats (m3u8, m3u, pls, xspf, json, txt, cue) chosen independently of file extension
+// ABOUTME: Encodes an ordered track list to a writer in the selected format
+
+package playlist
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format identifies a playlist file format for writing
+type Format string
+
+// Supported output formats
+const (
+	FormatM3U8 Format = "m3u8" // One path per line (the default, matches the input format)
+	FormatM3U  Format = "m3u"  // Extended M3U with #EXTINF artist/title lines
+	FormatPLS  Format = "pls"  // PLS (INI-style) playlist
+	FormatXSPF Format = "xspf" // XML Shareable Playlist Format
+	FormatJSON Format = "json" // JSON array with track metadata
+	FormatTXT  Format = "txt"  // Tracklist with cumulative start times, for mix descriptions
+	FormatCUE  Format = "cue"  // Cue sheet indexing the tracks in a recording of the set
+)
+
+// Formats lists the supported output formats
+var Formats = []Format{FormatM3U8, FormatM3U, FormatPLS, FormatXSPF, FormatJSON, FormatTXT, FormatCUE}
+
+// ParseFormat parses a format name (case-insensitive). An empty name means m3u8.
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return FormatM3U8, nil
+	}
+
+	f := Format(strings.ToLower(strings.TrimPrefix(name, ".")))
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown output format %q (supported: m3u8, m3u, pls, xspf, json, txt, cue)", name)
+}
+
+// WritePlaylistFormat writes tracks to path in the given format. A path of "-" writes to stdout.
+func WritePlaylistFormat(path string, tracks []Track, format Format) (err error) {
+	if path == StdioPath {
+		writer := bufio.NewWriter(os.Stdout)
+		if err := EncodePlaylist(writer, tracks, format); err != nil {
+			return err
+		}
+
+		return writer.Flush()
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create playlist: %w", err)
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close playlist file: %w", closeErr)
+		}
+	}()
+
+	writer := bufio.NewWriter(file)
+
+	if err := EncodePlaylist(writer, tracks, format); err != nil {
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush writer: %w", err)
+	}
+
+	return nil
+}
+
+// EncodePlaylist writes tracks to w in the given format
+func EncodePlaylist(w io.Writer, tracks []Track, format Format) error {
+	var err error
+
+	switch format {
+	case FormatM3U8:
+		err = encodeM3U8(w, tracks)
+	case FormatM3U:
+		err = encodeM3U(w, tracks)
+	case FormatPLS:
+		err = encodePLS(w, tracks)
+	case FormatXSPF:
+		err = encodeXSPF(w, tracks)
+	case FormatJSON:
+		err = encodeJSON(w, tracks)
+	case FormatTXT:
+		err = encodeTracklist(w, tracks)
+	case FormatCUE:
+		err = encodeCUE(w, tracks)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write %s playlist: %w", format, err)
+	}
+
+	return nil
+}
+
+// displayTitle returns "Artist - Title", falling back to the file name without metadata
+func displayTitle(t *Track) string {
+	switch {
+	case t.Artist != "" && t.Title != "":
+		return t.Artist + " - " + t.Title
+	case t.Title != "":
+		return t.Title
+	default:
+		return strings.TrimSuffix(filepath.Base(t.Path), filepath.Ext(t.Path))
+	}
+}
+
+// encodeM3U8 writes one path per line
+func encodeM3U8(w io.Writer, tracks []Track) error {
+	for _, track := range tracks {
+		if _, err := io.WriteString(w, track.Path+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeM3U writes extended M3U with an #EXTINF line per track (duration in seconds, -1 when unknown)
+func encodeM3U(w io.Writer, tracks []Track) error {
+	if _, err := io.WriteString(w, "#EXTM3U\n"); err != nil {
+		return err
+	}
+
+	for i := range tracks {
+		if _, err := fmt.Fprintf(w, "#EXTINF:%d,%s\n%s\n", durationSeconds(&tracks[i]), displayTitle(&tracks[i]), tracks[i].Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// durationSeconds returns the track length in whole seconds for M3U/PLS (-1 when unknown)
+func durationSeconds(t *Track) int {
+	if t.Duration <= 0 {
+		return -1
+	}
+
+	return int(t.Duration.Round(time.Second).Seconds())
+}
+
+// encodePLS writes a version 2 PLS playlist
+func encodePLS(w io.Writer, tracks []Track) error {
+	if _, err := io.WriteString(w, "[playlist]\n"); err != nil {
+		return err
+	}
+
+	for i := range tracks {
+		n := i + 1
+		if _, err := fmt.Fprintf(w, "File%d=%s\nTitle%d=%s\nLength%d=%d\n", n, tracks[i].Path, n, displayTitle(&tracks[i]), n, durationSeconds(&tracks[i])); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "NumberOfEntries=%d\nVersion=2\n", len(tracks))
+
+	return err
+}
+
+// xspfPlaylist is the XSPF document root
+type xspfPlaylist struct {
+	XMLName   xml.Name    `xml:"playlist"`
+	Version   string      `xml:"version,attr"`
+	Namespace string      `xml:"xmlns,attr"`
+	Tracks    []xspfTrack `xml:"trackList>track"`
+}
+
+// xspfTrack is one XSPF track entry
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Album    string `xml:"album,omitempty"`
+}
+
+// fileURI converts a track path to the URI form XSPF locations require
+func fileURI(path string) string {
+	slashed := filepath.ToSlash(path)
+
+	if filepath.IsAbs(path) {
+		if !strings.HasPrefix(slashed, "/") {
+			slashed = "/" + slashed // Windows drive paths: file:///C:/...
+		}
+
+		return (&url.URL{Scheme: "file", Path: slashed}).String()
+	}
+
+	return (&url.URL{Path: slashed}).String()
+}
+
+// encodeXSPF writes an XSPF (XML) playlist
+func encodeXSPF(w io.Writer, tracks []Track) error {
+	doc := xspfPlaylist{Version: "1", Namespace: "http://xspf.org/ns/0/"}
+
+	for _, t := range tracks {
+		doc.Tracks = append(doc.Tracks, xspfTrack{
+			Location: fileURI(t.Path),
+			Title:    t.Title,
+			Creator:  t.Artist,
+			Album:    t.Album,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// jsonTrack is one entry of the JSON output format
+type jsonTrack struct {
+	Path            string  `json:"path"`
+	Artist          string  `json:"artist,omitempty"`
+	Title           string  `json:"title,omitempty"`
+	Album           string  `json:"album,omitempty"`
+	Genre           string  `json:"genre,omitempty"`
+	Key             string  `json:"key,omitempty"`
+	BPM             float64 `json:"bpm,omitempty"`
+	Energy          int     `json:"energy,omitempty"`
+	EnergyEstimated bool    `json:"energy_estimated,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+}
+
+// encodeJSON writes an indented JSON array of tracks with their metadata
+func encodeJSON(w io.Writer, tracks []Track) error {
+	entries := make([]jsonTrack, len(tracks))
+	for i, t := range tracks {
+		entries[i] = jsonTrack{
+			Path:            t.Path,
+			Artist:          t.Artist,
+			Title:           t.Title,
+			Album:           t.Album,
+			Genre:           t.Genre,
+			Key:             t.Key,
+			BPM:             t.BPM,
+			Energy:          t.Energy,
+			EnergyEstimated: t.EnergyEstimated,
+			DurationSeconds: t.Duration.Seconds(),
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(entries)
+}
+
+// startTimes returns when each track starts in a recording of the set, counting only known lengths
+// (a track without one adds nothing, so later start times run early by its length)
+func startTimes(tracks []Track) []time.Duration {
+	starts := make([]time.Duration, len(tracks))
+
+	var at time.Duration
+
+	for i := range tracks {
+		starts[i] = at
+		at += max(tracks[i].Duration, 0)
+	}
+
+	return starts
+}
+
+// tracklistTimestamp formats a start time as mm:ss, or h:mm:ss from an hour on
+func tracklistTimestamp(d time.Duration) string {
+	secs := int(d.Round(time.Second).Seconds())
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
+
+// encodeTracklist writes one "mm:ss Artist – Title" line per track with its start time
+func encodeTracklist(w io.Writer, tracks []Track) error {
+	starts := startTimes(tracks)
+
+	for i := range tracks {
+		title := displayTitle(&tracks[i])
+		if tracks[i].Artist != "" && tracks[i].Title != "" {
+			title = tracks[i].Artist + " – " + tracks[i].Title
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %s\n", tracklistTimestamp(starts[i]), title); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cueFramesPerSecond is the resolution of cue sheet INDEX times (CD frames)
+const cueFramesPerSecond = 75
+
+// cueString quotes a value for a cue sheet, which has no escape for double quotes
+func cueString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
+
+// encodeCUE writes a cue sheet indexing each track at its start time in a recording of the set.
+// The recording isn't known here, so FILE names a placeholder to point at the actual file.
+func encodeCUE(w io.Writer, tracks []Track) error {
+	if _, err := io.WriteString(w, "FILE \"recording.wav\" WAVE\n"); err != nil {
+		return err
+	}
+
+	for i, start := range startTimes(tracks) {
+		t := &tracks[i]
+
+		frames := start.Milliseconds() * cueFramesPerSecond / 1000
+		index := fmt.Sprintf("%02d:%02d:%02d", frames/cueFramesPerSecond/60, frames/cueFramesPerSecond%60, frames%cueFramesPerSecond)
+
+		title := t.Title
+		if title == "" {
+			title = displayTitle(t)
+		}
+
+		if _, err := fmt.Fprintf(w, "  TRACK %02d AUDIO\n    TITLE %s\n", i+1, cueString(title)); err != nil {
+			return err
+		}
+
+		if t.Artist != "" {
+			if _, err := fmt.Fprintf(w, "    PERFORMER %s\n", cueString(t.Artist)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "    INDEX 01 %s\n", index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+// ABOUTME: Tests for playlist output formats
+// ABOUTME: Verifies m3u, pls, xspf, json, tracklist and cue encodings and format name parsing
+
+package playlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var formatTestTracks = []Track{
+	{Path: "/music/Artist A/01 First & Last.mp3", Artist: "Artist A", Title: "First & Last", Album: "LP", Key: "8A", BPM: 124, Energy: 5, Duration: 365400 * time.Millisecond},
+	{Path: "Relative/02 No Tags.flac"},
+}
+
+func encodeToString(t *testing.T, format Format) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := EncodePlaylist(&buf, formatTestTracks, format); err != nil {
+		t.Fatalf("EncodePlaylist(%s): %v", format, err)
+	}
+
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"": FormatM3U8, "XSPF": FormatXSPF, ".pls": FormatPLS, "json": FormatJSON} {
+		got, err := ParseFormat(name)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	if _, err := ParseFormat("wpl"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestEncodeM3U(t *testing.T) {
+	want := "#EXTM3U\n" +
+		"#EXTINF:365,Artist A - First & Last\n/music/Artist A/01 First & Last.mp3\n" +
+		"#EXTINF:-1,02 No Tags\nRelative/02 No Tags.flac\n"
+
+	if got := encodeToString(t, FormatM3U); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEncodePLS(t *testing.T) {
+	got := encodeToString(t, FormatPLS)
+
+	for _, line := range []string{"[playlist]", "File1=/music/Artist A/01 First & Last.mp3", "Title2=02 No Tags", "Length1=365", "Length2=-1", "NumberOfEntries=2", "Version=2"} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("PLS output missing %q:\n%s", line, got)
+		}
+	}
+}
+
+func TestEncodeXSPF(t *testing.T) {
+	var doc xspfPlaylist
+	if err := xml.Unmarshal([]byte(encodeToString(t, FormatXSPF)), &doc); err != nil {
+		t.Fatalf("XSPF output is not valid XML: %v", err)
+	}
+
+	if len(doc.Tracks) != 2 {
+		t.Fatalf("Expected 2 tracks, got %d", len(doc.Tracks))
+	}
+
+	if got := doc.Tracks[0].Location; got != "file:///music/Artist%20A/01%20First%20&%20Last.mp3" {
+		t.Errorf("Unexpected absolute location: %s", got)
+	}
+
+	if got := doc.Tracks[1].Location; got != "Relative/02%20No%20Tags.flac" {
+		t.Errorf("Unexpected relative location: %s", got)
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	var entries []jsonTrack
+	if err := json.Unmarshal([]byte(encodeToString(t, FormatJSON)), &entries); err != nil {
+		t.Fatalf("JSON output is not valid: %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Key != "8A" || entries[0].BPM != 124 || entries[1].Path != "Relative/02 No Tags.flac" {
+		t.Errorf("Unexpected JSON entries: %+v", entries)
+	}
+}
+
+func TestEncodeTracklist(t *testing.T) {
+	want := "00:00 Artist A – First & Last\n06:05 02 No Tags\n"
+
+	if got := encodeToString(t, FormatTXT); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := tracklistTimestamp(time.Hour + 5*time.Minute + 32*time.Second); got != "1:05:32" {
+		t.Errorf("Expected hours from an hour on, got %q", got)
+	}
+}
+
+func TestEncodeCUE(t *testing.T) {
+	want := "FILE \"recording.wav\" WAVE\n" +
+		"  TRACK 01 AUDIO\n    TITLE \"First & Last\"\n    PERFORMER \"Artist A\"\n    INDEX 01 00:00:00\n" +
+		"  TRACK 02 AUDIO\n    TITLE \"02 No Tags\"\n    INDEX 01 06:05:30\n"
+
+	if got := encodeToString(t, FormatCUE); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
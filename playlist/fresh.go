@@ -0,0 +1,50 @@
+// ABOUTME: Date-added reading and marking of recently added tracks for the freshness component
+// ABOUTME: Tracks are fresh when added to the library within a number of days of the load
+
+package playlist
+
+import (
+	"strings"
+	"time"
+)
+
+// AddedTag is the tag holding the date a track was added to the library (falls back to the file's
+// modification time)
+const AddedTag = "DATE_ADDED"
+
+// addedLayouts are the accepted date-added formats
+var addedLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// extractAdded reads the date-added tag; zero when missing or unparseable
+func extractAdded(raw map[string]interface{}) time.Time {
+	text := strings.TrimSpace(rawTagText(raw, AddedTag))
+	if text == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range addedLayouts {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// MarkFresh sets Fresh on tracks added within days of now (0 days = none are fresh; tracks without
+// a date never are). Returns how many are fresh.
+func MarkFresh(tracks []Track, now time.Time, days int) int {
+	cutoff := now.AddDate(0, 0, -days)
+	fresh := 0
+
+	for i := range tracks {
+		t := &tracks[i]
+		t.Fresh = days > 0 && !t.Added.IsZero() && t.Added.After(cutoff)
+
+		if t.Fresh {
+			fresh++
+		}
+	}
+
+	return fresh
+}
@@ -0,0 +1,53 @@
+// ABOUTME: Tests for date-added reading and fresh track marking
+// ABOUTME: Verifies accepted date formats and the days-since-added cutoff
+
+package playlist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractAdded(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want time.Time
+	}{
+		{"missing", map[string]interface{}{}, time.Time{}},
+		{"date", map[string]interface{}{"DATE_ADDED": "2024-03-01"}, time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)},
+		{"date and time", map[string]interface{}{"date_added": "2024-03-01 12:30:00"}, time.Date(2024, 3, 1, 12, 30, 0, 0, time.Local)},
+		{"RFC 3339", map[string]interface{}{"DATE_ADDED": "2024-03-01T12:30:00Z"}, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"garbage", map[string]interface{}{"DATE_ADDED": "last week"}, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractAdded(tt.raw); !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMarkFresh(t *testing.T) {
+	now := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tracks := []Track{
+		{Path: "new", Added: now.AddDate(0, 0, -2)},
+		{Path: "old", Added: now.AddDate(0, -2, 0)},
+		{Path: "undated"},
+	}
+
+	if n := MarkFresh(tracks, now, 30); n != 1 {
+		t.Errorf("Expected 1 fresh track, got %d", n)
+	}
+
+	if !tracks[0].Fresh || tracks[1].Fresh || tracks[2].Fresh {
+		t.Errorf("Expected only the track added 2 days ago to be fresh, got %v %v %v", tracks[0].Fresh, tracks[1].Fresh, tracks[2].Fresh)
+	}
+
+	if n := MarkFresh(tracks, now, 0); n != 0 || tracks[0].Fresh {
+		t.Errorf("Expected no fresh tracks with 0 days, got %d", n)
+	}
+}
@@ -5,7 +5,6 @@ package playlist
 
 import (
 	"slices"
-	"strings"
 )
 
 // Genre hierarchy: maps genre -> parent genre
@@ -116,20 +115,84 @@ const (
 // GenreSimilarity calculates similarity between two genres
 // Returns 0.0 for identical genres, 1.0 for completely different
 // Uses hierarchical matching: sub-genres are closer than unrelated genres
+// Multi-value tags ("Electronic; Drum & Bass; Liquid") score as their closest pair of values.
 func GenreSimilarity(genre1, genre2 string) float64 {
-	// Normalize genres (lowercase, trim)
-	g1 := strings.ToLower(strings.TrimSpace(genre1))
-	g2 := strings.ToLower(strings.TrimSpace(genre2))
+	// Normalize genres (values, case, punctuation, aliases)
+	values1 := normalizedGenres(genre1)
+	values2 := normalizedGenres(genre2)
 
 	// Handle empty genres (treat as completely different)
-	if g1 == "" || g2 == "" {
-		if g1 == g2 {
+	if len(values1) == 0 || len(values2) == 0 {
+		if len(values1) == len(values2) {
 			return genreIdentical // Both empty = same
 		}
 
 		return genreUnrelated // One empty = different
 	}
 
+	closest := genreUnrelated
+
+	for _, g1 := range values1 {
+		for _, g2 := range values2 {
+			closest = min(closest, genreDistance(g1, g2))
+		}
+	}
+
+	return closest
+}
+
+// GenreMatrix holds GenreSimilarity between every pair of distinct genre tags of a playlist, so
+// comparing tracks is a lookup by interned genre ID instead of string processing per pair
+type GenreMatrix struct {
+	ids      map[string]int // Genre tag -> ID
+	size     int
+	distance []float64 // GenreSimilarity by ID pair, row-major
+}
+
+// NewGenreMatrix interns genres (one tag per track, duplicates allowed) and computes the distance
+// between each pair of distinct tags once
+func NewGenreMatrix(genres []string) *GenreMatrix {
+	m := &GenreMatrix{ids: make(map[string]int)}
+
+	var tags []string
+
+	for _, g := range genres {
+		if _, ok := m.ids[g]; !ok {
+			m.ids[g] = len(tags)
+			tags = append(tags, g)
+		}
+	}
+
+	m.size = len(tags)
+	m.distance = make([]float64, m.size*m.size)
+
+	for i := range tags {
+		for j := i + 1; j < m.size; j++ {
+			d := GenreSimilarity(tags[i], tags[j])
+			m.distance[i*m.size+j] = d
+			m.distance[j*m.size+i] = d
+		}
+	}
+
+	return m
+}
+
+// ID returns the interned ID of genre, or -1 when the matrix wasn't built with it
+func (m *GenreMatrix) ID(genre string) int {
+	if id, ok := m.ids[genre]; ok {
+		return id
+	}
+
+	return -1
+}
+
+// Distance returns GenreSimilarity between the genres with IDs id1 and id2
+func (m *GenreMatrix) Distance(id1, id2 int) float64 {
+	return m.distance[id1*m.size+id2]
+}
+
+// genreDistance calculates the similarity of two normalized single genres (see GenreSimilarity)
+func genreDistance(g1, g2 string) float64 {
 	// Exact match
 	if g1 == g2 {
 		return genreIdentical
@@ -158,6 +221,30 @@ func GenreSimilarity(genre1, genre2 string) float64 {
 	return genreUnrelated
 }
 
+// GenreAncestors returns a genre normalized with NormalizeGenre followed by its parent genres, most
+// specific first (empty for an empty genre). Of a multi-value tag, the value deepest in the hierarchy
+// is used: "Electronic; Drum & Bass" -> [drum and bass, electronic].
+func GenreAncestors(genre string) []string {
+	var deepest []string
+
+	for _, g := range normalizedGenres(genre) {
+		if chain := getAncestorChain(g); len(chain) > len(deepest) {
+			deepest = chain
+		}
+	}
+
+	return deepest
+}
+
+// HierarchyGenre returns tag normalized with NormalizeGenre and whether the genre hierarchy knows
+// it, which tells genres apart from free-form tags such as "seen live" or "british"
+func HierarchyGenre(tag string) (string, bool) {
+	g := NormalizeGenre(tag)
+	_, ok := canonicalHierarchy[g]
+
+	return g, ok && g != ""
+}
+
 // getAncestorChain returns the full ancestry chain for a genre
 // Example: "liquid dnb" -> ["liquid dnb", "drum & bass", "electronic"]
 func getAncestorChain(genre string) []string {
@@ -165,7 +252,7 @@ func getAncestorChain(genre string) []string {
 	current := genre
 
 	for {
-		parent, exists := genreHierarchy[current]
+		parent, exists := canonicalHierarchy[current]
 		if !exists || parent == "" {
 			break
 		}
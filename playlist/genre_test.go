@@ -5,6 +5,7 @@ package playlist
 
 import (
 	"math"
+	"slices"
 	"testing"
 )
 
@@ -184,8 +185,8 @@ func TestGetAncestorChain(t *testing.T) {
 	}{
 		{
 			name:          "liquid dnb full chain",
-			genre:         "dj drum and bass - liquid",
-			expectedChain: []string{"dj drum and bass - liquid", "dj drum and bass", "drum and bass", "electronic"},
+			genre:         "dj drum and bass liquid", // Canonical spelling of "DJ Drum and Bass - Liquid"
+			expectedChain: []string{"dj drum and bass liquid", "dj drum and bass", "drum and bass", "electronic"},
 		},
 		{
 			name:          "house chain",
@@ -275,3 +276,78 @@ func TestGenreSimilarityRange(t *testing.T) {
 		}
 	}
 }
+
+// TestMultiValueGenres verifies multi-value genre tags score as their closest pair of values
+func TestMultiValueGenres(t *testing.T) {
+	if got := SplitGenres(" Electronic; Drum & Bass,Liquid |"); !slices.Equal(got, []string{"Electronic", "Drum & Bass", "Liquid"}) {
+		t.Errorf("Unexpected values %q", got)
+	}
+
+	if got := SplitGenres("Funk / Soul"); len(got) != 1 {
+		t.Errorf("Expected a slash to stay part of the genre, got %q", got)
+	}
+
+	tests := []struct {
+		name   string
+		g1, g2 string
+		want   float64
+	}{
+		{"shared value", "Electronic; Drum & Bass; Liquid", "Drum and Bass", genreIdentical},
+		{"closest pair", "Rock; Jungle", "Drum & Bass", genreParentChild},
+		{"ID3v2.4 NUL separator", "House\x00Techno", "techno", genreIdentical},
+		{"no related values", "Rock; Jazz", "Techno", genreUnrelated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenreSimilarity(tt.g1, tt.g2); got != tt.want {
+				t.Errorf("GenreSimilarity(%q, %q) = %.2f, want %.2f", tt.g1, tt.g2, got, tt.want)
+			}
+		})
+	}
+
+	if chain := GenreAncestors("Electronic; Drum & Bass"); !slices.Equal(chain, []string{"drum and bass", "electronic"}) {
+		t.Errorf("Expected the deepest value's ancestors, got %v", chain)
+	}
+}
+
+// TestGenreMatrix verifies the precomputed matrix agrees with GenreSimilarity for every pair
+func TestGenreMatrix(t *testing.T) {
+	genres := []string{"House", "Techno", "house", "", "Drum & Bass; Liquid", "Jungle", "Techno"}
+	m := NewGenreMatrix(genres)
+
+	if m.ID("Techno") != m.ID(genres[6]) {
+		t.Error("Expected duplicate tags to share an ID")
+	}
+
+	if m.ID("Jazz") != -1 {
+		t.Errorf("Expected -1 for an unknown genre, got %d", m.ID("Jazz"))
+	}
+
+	for _, g1 := range genres {
+		for _, g2 := range genres {
+			if got, want := m.Distance(m.ID(g1), m.ID(g2)), GenreSimilarity(g1, g2); got != want {
+				t.Errorf("Distance(%q, %q) = %.2f, want %.2f", g1, g2, got, want)
+			}
+		}
+	}
+}
+
+// TestHierarchyGenre verifies tags are told apart from genres the hierarchy knows
+func TestHierarchyGenre(t *testing.T) {
+	for tag, want := range map[string]string{
+		"Drum & Bass": "drum and bass",
+		"liquid funk": "dj drum and bass liquid",
+		"Techno":      "techno",
+	} {
+		if got, ok := HierarchyGenre(tag); !ok || got != want {
+			t.Errorf("HierarchyGenre(%q) = %q, %v; want %q", tag, got, ok, want)
+		}
+	}
+
+	for _, tag := range []string{"seen live", "british", ""} {
+		if _, ok := HierarchyGenre(tag); ok {
+			t.Errorf("Expected %q not to be a genre", tag)
+		}
+	}
+}
@@ -89,6 +89,11 @@ func HarmonicDistanceParsed(k1, k2 *CamelotKey) int {
 	return harmonicIncompatible
 }
 
+// IsHarmonicClash reports whether a harmonic distance is an incompatible transition
+func IsHarmonicClash(distance int) bool {
+	return distance >= harmonicIncompatible
+}
+
 // String returns the string representation of a CamelotKey
 func (k *CamelotKey) String() string {
 	return fmt.Sprintf("%d%c", k.Number, k.Letter)
@@ -0,0 +1,203 @@
+// ABOUTME: Fast path reading only the Camelot key, energy and BPM of a track
+// ABOUTME: Scans ID3v2.3/2.4 frames for COMM and TBPM without decoding the rest, falling back to a full tag parse
+
+package playlist
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// id3v2HeaderSize is the size of the ID3v2 tag header and of each v2.3/v2.4 frame header
+const id3v2HeaderSize = 10
+
+// GetTrackKeyBPM reads only what harmonic ordering needs: key and energy from the comment and the
+// BPM. MP3s with a plain ID3v2.3/2.4 tag are scanned for those two frames without decoding the rest
+// (cover art, lyrics, ...); other files fall back to GetTrackMetadata. The title is the file name.
+func GetTrackKeyBPM(trackPath string, baseDir string) (*Track, error) {
+	return getTrackKeyBPM(trackPath, baseDir, currentLoadSettings())
+}
+
+// getTrackKeyBPM is GetTrackKeyBPM falling back to getTrackMetadata with the settings
+func getTrackKeyBPM(trackPath, baseDir string, settings LoadSettings) (*Track, error) {
+	fullPath := resolveTrackPath(trackPath, baseDir)
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	comment, bpmText, ok := readID3v2KeyBPM(file)
+	if !ok {
+		return getTrackMetadata(trackPath, baseDir, settings)
+	}
+
+	key := extractKey(comment)
+	parsedKey, _ := ParseCamelotKey(key)
+	bpm, _ := strconv.ParseFloat(strings.TrimSpace(bpmText), 64)
+
+	return &Track{
+		Path:      trackPath,
+		Key:       key,
+		ParsedKey: parsedKey,
+		Title:     filepath.Base(trackPath),
+		Energy:    extractEnergy(comment),
+		BPM:       bpm,
+	}, nil
+}
+
+// readID3v2KeyBPM scans an ID3v2.3/2.4 tag at the start of r for the comment holding the key
+// (the first one matching the "8A - Energy 6" format, else the first comment) and the TBPM text.
+// ok is false when r has no such tag or uses features this scanner skips (unsynchronisation,
+// extended headers, compressed or encrypted frames), so the caller can fall back to a full parse.
+func readID3v2KeyBPM(r io.Reader) (comment, bpm string, ok bool) {
+	header := make([]byte, id3v2HeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:3]) != "ID3" {
+		return "", "", false
+	}
+
+	major, flags := header[3], header[5]
+	if (major != 3 && major != 4) || flags&0xC0 != 0 {
+		return "", "", false
+	}
+
+	data := make([]byte, syncsafe(header[6:10]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", "", false
+	}
+
+	var firstComment string
+
+	for pos := 0; pos+id3v2HeaderSize <= len(data) && data[pos] != 0; {
+		id, formatFlags := string(data[pos:pos+4]), data[pos+9]
+
+		size := int(binary.BigEndian.Uint32(data[pos+4 : pos+8]))
+		if major == 4 {
+			size = int(syncsafe(data[pos+4 : pos+8]))
+		}
+
+		start := pos + id3v2HeaderSize
+		if size < 0 || start+size > len(data) {
+			return "", "", false
+		}
+
+		body := data[start : start+size]
+		pos = start + size
+
+		if id != "COMM" && id != "TBPM" {
+			continue
+		}
+
+		if encodedFrame(major, formatFlags) || len(body) == 0 {
+			return "", "", false
+		}
+
+		switch id {
+		case "TBPM":
+			bpm = decodeID3Text(body[0], body[1:])
+		case "COMM":
+			text, found := commentText(body)
+			if !found {
+				return "", "", false
+			}
+
+			if firstComment == "" {
+				firstComment = text
+			}
+
+			if comment == "" && keyRegex.MatchString(text) {
+				comment = text
+			}
+		}
+	}
+
+	if comment == "" {
+		comment = firstComment
+	}
+
+	return comment, bpm, true
+}
+
+// encodedFrame reports whether a frame's format flags (second flag byte) mark it as compressed,
+// encrypted or otherwise transformed
+func encodedFrame(major, formatFlags byte) bool {
+	if major == 4 {
+		return formatFlags&0x0F != 0 // Compression, encryption, unsynchronisation, data length indicator
+	}
+
+	return formatFlags&0xC0 != 0 // Compression, encryption
+}
+
+// commentText returns the text of a COMM frame body: encoding, language, description, text
+func commentText(body []byte) (string, bool) {
+	if len(body) < 4 {
+		return "", false
+	}
+
+	enc, rest := body[0], body[4:]
+
+	// Skip the null-terminated description (two-byte aligned terminator for UTF-16)
+	if enc == 1 || enc == 2 {
+		for i := 0; i+1 < len(rest); i += 2 {
+			if rest[i] == 0 && rest[i+1] == 0 {
+				return decodeID3Text(enc, rest[i+2:]), true
+			}
+		}
+
+		return "", false
+	}
+
+	_, text, found := strings.Cut(string(rest), "\x00")
+
+	return decodeID3Text(enc, []byte(text)), found
+}
+
+// decodeID3Text decodes ID3v2 text in the given encoding: 0 ISO-8859-1, 1 UTF-16 with BOM,
+// 2 UTF-16BE, 3 UTF-8. Trailing null terminators are dropped.
+func decodeID3Text(enc byte, b []byte) string {
+	switch enc {
+	case 0:
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+
+		return strings.TrimRight(string(runes), "\x00")
+	case 1, 2:
+		bigEndian := enc == 2
+
+		if len(b) >= 2 && enc == 1 {
+			switch {
+			case b[0] == 0xFE && b[1] == 0xFF:
+				bigEndian, b = true, b[2:]
+			case b[0] == 0xFF && b[1] == 0xFE:
+				b = b[2:]
+			}
+		}
+
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			if bigEndian {
+				units[i] = binary.BigEndian.Uint16(b[2*i:])
+			} else {
+				units[i] = binary.LittleEndian.Uint16(b[2*i:])
+			}
+		}
+
+		return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+	default:
+		return strings.TrimRight(string(b), "\x00")
+	}
+}
+
+// syncsafe decodes a 4-byte ID3v2 syncsafe integer (7 bits per byte)
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
@@ -0,0 +1,142 @@
+// ABOUTME: Tests for the key/BPM-only fast path
+// ABOUTME: Builds ID3v2 tags in memory and checks the frame scanner against the full tag parser
+
+package playlist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// id3Frame encodes one ID3v2 frame; v2.4 frame sizes are syncsafe
+func id3Frame(major byte, id string, body []byte) []byte {
+	frame := []byte(id)
+	if major == 4 {
+		frame = append(frame, syncsafeBytes(len(body))...)
+	} else {
+		frame = binary.BigEndian.AppendUint32(frame, uint32(len(body)))
+	}
+
+	frame = append(frame, 0, 0)
+
+	return append(frame, body...)
+}
+
+// id3Tag wraps frames in an ID3v2 tag header, followed by some padding
+func id3Tag(major byte, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	body = append(body, make([]byte, 16)...)
+
+	tag := []byte{'I', 'D', '3', major, 0, 0}
+
+	return append(append(tag, syncsafeBytes(len(body))...), body...)
+}
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+// latin1Comment encodes a COMM body with an empty description
+func latin1Comment(text string) []byte {
+	return append([]byte{0, 'e', 'n', 'g', 0}, text...)
+}
+
+func TestReadID3v2KeyBPM(t *testing.T) {
+	utf16Text := func(s string) []byte {
+		b := []byte{0xFF, 0xFE}
+		for _, u := range utf16.Encode([]rune(s)) {
+			b = binary.LittleEndian.AppendUint16(b, u)
+		}
+
+		return b
+	}
+
+	utf16Comment := append([]byte{1, 'e', 'n', 'g'}, utf16Text("")...)
+	utf16Comment = append(utf16Comment, 0, 0)
+	utf16Comment = append(utf16Comment, utf16Text("11B - Energy 8")...)
+
+	tests := []struct {
+		name        string
+		tag         []byte
+		wantComment string
+		wantBPM     string
+	}{
+		{
+			name: "v2.3 latin1 with cover art",
+			tag: id3Tag(3,
+				id3Frame(3, "APIC", bytes.Repeat([]byte{0xAB}, 512)),
+				id3Frame(3, "COMM", latin1Comment("8A - Energy 6")),
+				id3Frame(3, "TBPM", []byte("\x00124")),
+			),
+			wantComment: "8A - Energy 6",
+			wantBPM:     "124",
+		},
+		{
+			name: "v2.4 UTF-16 key comment after another comment",
+			tag: id3Tag(4,
+				id3Frame(4, "COMM", latin1Comment("ripped by me")),
+				id3Frame(4, "COMM", utf16Comment),
+				id3Frame(4, "TBPM", []byte("\x03174")),
+			),
+			wantComment: "11B - Energy 8",
+			wantBPM:     "174",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comment, bpm, ok := readID3v2KeyBPM(bytes.NewReader(tt.tag))
+			if !ok || comment != tt.wantComment || bpm != tt.wantBPM {
+				t.Errorf("Expected (%q, %q, true), got (%q, %q, %v)", tt.wantComment, tt.wantBPM, comment, bpm, ok)
+			}
+		})
+	}
+}
+
+func TestReadID3v2KeyBPMFallsBack(t *testing.T) {
+	unsynchronised := id3Tag(3, id3Frame(3, "COMM", latin1Comment("8A - Energy 6")))
+	unsynchronised[5] = 0x80
+
+	for name, data := range map[string][]byte{
+		"no ID3 tag":         []byte("fLaC\x00\x00\x00\x22"),
+		"ID3v2.2":            id3Tag(2),
+		"unsynchronised tag": unsynchronised,
+		"truncated frame":    id3Tag(3, id3Frame(3, "COMM", latin1Comment("8A - Energy 6")))[:20],
+	} {
+		if _, _, ok := readID3v2KeyBPM(bytes.NewReader(data)); ok {
+			t.Errorf("%s: expected the scanner to defer to the full parser", name)
+		}
+	}
+}
+
+func TestGetTrackKeyBPMMatchesFullParse(t *testing.T) {
+	dir := t.TempDir()
+
+	tag := id3Tag(3,
+		id3Frame(3, "TPE1", []byte("\x00Artist")),
+		id3Frame(3, "COMM", latin1Comment("5A - Energy 7")),
+		id3Frame(3, "TBPM", []byte("\x00128")),
+	)
+	if err := os.WriteFile(filepath.Join(dir, "track.mp3"), tag, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fast, err := GetTrackKeyBPM("track.mp3", dir)
+	if err != nil {
+		t.Fatalf("GetTrackKeyBPM: %v", err)
+	}
+
+	full, err := GetTrackMetadata("track.mp3", dir)
+	if err != nil {
+		t.Fatalf("GetTrackMetadata: %v", err)
+	}
+
+	if fast.Key != full.Key || fast.Energy != full.Energy || fast.BPM != full.BPM || fast.Key != "5A" {
+		t.Errorf("Expected key/energy/BPM %s/%d/%.0f like the full parse, got %s/%d/%.0f",
+			full.Key, full.Energy, full.BPM, fast.Key, fast.Energy, fast.BPM)
+	}
+}
@@ -0,0 +1,40 @@
+// ABOUTME: Markdown rendering of an ordered tracklist for exported notes and reports
+// ABOUTME: One table row per track with key, tempo, energy and genre
+
+package playlist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarkdownTracklist renders tracks in order as a markdown table
+func MarkdownTracklist(tracks []Track) string {
+	var b strings.Builder
+
+	b.WriteString("| # | Artist | Title | Key | BPM | Energy | Genre |\n")
+	b.WriteString("|--:|--------|-------|-----|----:|-------:|-------|\n")
+
+	for i, t := range tracks {
+		bpm, energy := "", ""
+		if t.BPM > 0 {
+			bpm = fmt.Sprintf("%.1f", t.BPM)
+		}
+
+		if t.Energy > 0 {
+			energy = fmt.Sprint(t.Energy)
+		}
+
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s | %s | %s |\n", i+1,
+			MarkdownCell(t.Artist), MarkdownCell(t.Title), MarkdownCell(t.Key), bpm, energy, MarkdownCell(t.Genre))
+	}
+
+	return b.String()
+}
+
+// MarkdownCell escapes text for a markdown table cell: pipes would end the cell and newlines the row
+func MarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,29 @@
+// ABOUTME: Tests for markdown tracklist rendering
+// ABOUTME: Verifies table rows, missing values and cell escaping
+
+package playlist
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownTracklist(t *testing.T) {
+	out := MarkdownTracklist([]Track{
+		{Artist: "A|B", Title: "Line\nBreak", Key: "8A", BPM: 174, Energy: 6, Genre: "Drum & Bass"},
+		{Artist: "Unknown", Title: "No Data"},
+	})
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a header, separator and 2 rows, got:\n%s", out)
+	}
+
+	if want := `| 1 | A\|B | Line Break | 8A | 174.0 | 6 | Drum & Bass |`; lines[2] != want {
+		t.Errorf("Expected %q, got %q", want, lines[2])
+	}
+
+	if want := "| 2 | Unknown | No Data |  |  |  |  |"; lines[3] != want {
+		t.Errorf("Expected %q, got %q", want, lines[3])
+	}
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Reads intro/outro lengths from configurable tags and scores how well they blend
+// ABOUTME: Looks up plain frames, Vorbis comments and ID3 TXXX user frames by name
+
+package playlist
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dhowden/tag"
+)
+
+// Default tag names holding a track's intro and outro length (seconds or m:ss)
+const (
+	DefaultIntroTag = "INTRO"
+	DefaultOutroTag = "OUTRO"
+)
+
+var (
+	mixTagsMu sync.RWMutex
+	introTag  string // Set with SetMixTags ("" = DefaultIntroTag)
+	outroTag  string // Set with SetMixTags ("" = DefaultOutroTag)
+)
+
+// SetMixTags sets the tag names intro and outro lengths are read from (empty keeps the default).
+// Serato and Rekordbox keep cue points in binary frames or their own databases, so export the
+// lengths to a text tag (e.g. with a tagger script) to use them here.
+func SetMixTags(intro, outro string) {
+	mixTagsMu.Lock()
+	defer mixTagsMu.Unlock()
+
+	introTag, outroTag = intro, outro
+}
+
+// extractMixLengths reads the intro and outro lengths from the settings' tags (0 when absent)
+func extractMixLengths(raw map[string]interface{}, s LoadSettings) (intro, outro time.Duration) {
+	introName, outroName := DefaultIntroTag, DefaultOutroTag
+	if s.IntroTag != "" {
+		introName = s.IntroTag
+	}
+
+	if s.OutroTag != "" {
+		outroName = s.OutroTag
+	}
+
+	return parseLength(rawTagText(raw, introName)), parseLength(rawTagText(raw, outroName))
+}
+
+// rawTagText returns the text of the tag called name: a frame or atom of that name,
+// a Vorbis comment (stored lowercase), or an ID3 TXXX frame with that description
+func rawTagText(raw map[string]interface{}, name string) string {
+	for _, key := range []string{name, strings.ToLower(name)} {
+		if val, ok := raw[key].(string); ok {
+			return val
+		}
+	}
+
+	for key, val := range raw {
+		if !strings.HasPrefix(key, "TXXX") && !strings.HasPrefix(key, "TXX") {
+			continue
+		}
+
+		if comm, ok := val.(*tag.Comm); ok && strings.EqualFold(comm.Description, name) {
+			return comm.Text
+		}
+	}
+
+	return ""
+}
+
+// parseLength parses a length given in seconds ("32", "31.5") or as m:ss ("0:32"); 0 if invalid
+func parseLength(s string) time.Duration {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+
+	minutes := 0.0
+
+	if m, rest, found := strings.Cut(s, ":"); found {
+		parsed, err := strconv.Atoi(m)
+		if err != nil || parsed < 0 {
+			return 0
+		}
+
+		minutes, s = float64(parsed), rest
+	}
+
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil || secs < 0 || math.IsNaN(secs) || math.IsInf(secs, 0) {
+		return 0
+	}
+
+	return time.Duration((minutes*60 + secs) * float64(time.Second))
+}
+
+// MixLengthMismatch scores how badly an outgoing track's outro and the next track's intro fit for
+// a blend: the length difference relative to the longer one, so 0 for equal lengths and close to 1
+// when one is much shorter. Unknown lengths (0) are neutral.
+func MixLengthMismatch(outro, intro time.Duration) float64 {
+	if outro <= 0 || intro <= 0 {
+		return 0
+	}
+
+	return math.Abs(float64(outro-intro)) / float64(max(outro, intro))
+}
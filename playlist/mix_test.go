@@ -0,0 +1,90 @@
+// ABOUTME: Tests for intro/outro length tags and blend compatibility scoring
+// ABOUTME: Validates tag lookup across formats, length parsing and the mismatch score
+
+package playlist
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dhowden/tag"
+)
+
+func TestRawTagText(t *testing.T) {
+	raw := map[string]interface{}{
+		"TXXX":   &tag.Comm{Description: "other", Text: "x"},
+		"TXXX_0": &tag.Comm{Description: "Intro", Text: "16"},
+		"outro":  "0:32",
+		"TBPM":   "174",
+	}
+
+	if got := rawTagText(raw, "INTRO"); got != "16" {
+		t.Errorf("Expected TXXX description match 16, got %q", got)
+	}
+
+	if got := rawTagText(raw, "OUTRO"); got != "0:32" {
+		t.Errorf("Expected Vorbis-style lowercase match 0:32, got %q", got)
+	}
+
+	if got := rawTagText(raw, "TBPM"); got != "174" {
+		t.Errorf("Expected exact frame match 174, got %q", got)
+	}
+
+	if got := rawTagText(raw, "MISSING"); got != "" {
+		t.Errorf("Expected empty text for a missing tag, got %q", got)
+	}
+}
+
+func TestParseLength(t *testing.T) {
+	tests := map[string]time.Duration{
+		"32":     32 * time.Second,
+		"31.5":   31500 * time.Millisecond,
+		" 1:04 ": 64 * time.Second,
+		"":       0,
+		"-3":     0,
+		"intro":  0,
+		"a:10":   0,
+	}
+
+	for in, want := range tests {
+		if got := parseLength(in); got != want {
+			t.Errorf("parseLength(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestExtractMixLengthsUsesConfiguredTags(t *testing.T) {
+	defer SetMixTags("", "")
+
+	raw := map[string]interface{}{"MIXIN": "16", "MIXOUT": "32", "INTRO": "8"}
+
+	if intro, outro := extractMixLengths(raw, currentLoadSettings()); intro != 8*time.Second || outro != 0 {
+		t.Errorf("Expected default tags to give 8s/0, got %v/%v", intro, outro)
+	}
+
+	SetMixTags("MIXIN", "MIXOUT")
+
+	if intro, outro := extractMixLengths(raw, currentLoadSettings()); intro != 16*time.Second || outro != 32*time.Second {
+		t.Errorf("Expected configured tags to give 16s/32s, got %v/%v", intro, outro)
+	}
+
+	// A load's own settings win over the process-wide ones
+	if intro, outro := extractMixLengths(raw, LoadSettings{OutroTag: "MIXIN"}); intro != 8*time.Second || outro != 16*time.Second {
+		t.Errorf("Expected the load's tags to give 8s/16s, got %v/%v", intro, outro)
+	}
+}
+
+func TestMixLengthMismatch(t *testing.T) {
+	if got := MixLengthMismatch(32*time.Second, 32*time.Second); got != 0 {
+		t.Errorf("Expected equal lengths to match perfectly, got %v", got)
+	}
+
+	if got := MixLengthMismatch(32*time.Second, 8*time.Second); math.Abs(got-0.75) > 1e-9 {
+		t.Errorf("Expected 32s outro into 8s intro to score 0.75, got %v", got)
+	}
+
+	if got := MixLengthMismatch(0, 8*time.Second); got != 0 {
+		t.Errorf("Expected unknown outro to be neutral, got %v", got)
+	}
+}
@@ -0,0 +1,196 @@
+// ABOUTME: Per-track metadata corrections read from an overrides file instead of the audio files
+// ABOUTME: Replaces the key, BPM, energy or genre of listed tracks after their tags are read
+
+package playlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TrackOverride corrects a track's metadata for sorting; empty fields keep the tagged value
+type TrackOverride struct {
+	Key    string  `json:"key,omitempty"`    // Camelot key, e.g. "8A"
+	BPM    float64 `json:"bpm,omitempty"`    // Beats per minute
+	Energy int     `json:"energy,omitempty"` // Energy level 1-10
+	Genre  string  `json:"genre,omitempty"`  // Genre tag (multi-value tags allowed)
+}
+
+// TrackOverrides holds corrections by absolute, cleaned track path (see LoadTrackOverrides)
+type TrackOverrides map[string]TrackOverride
+
+// OverridesPath returns the per-playlist overrides file: "set.m3u8" -> "set.overrides.json"
+func OverridesPath(playlistPath string) string {
+	return strings.TrimSuffix(playlistPath, filepath.Ext(playlistPath)) + ".overrides.json"
+}
+
+// LoadTrackOverrides reads an overrides file: a JSON object from track path to the fields to correct,
+// e.g. {"Artist/track.mp3": {"key": "8A", "bpm": 174}}. Relative paths are resolved against the
+// file's directory, so paths written like the playlist's entries match when both files sit together.
+func LoadTrackOverrides(path string) (TrackOverrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides %s: %w", path, err)
+	}
+
+	var raw map[string]TrackOverride
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides %s: %w", path, err)
+	}
+
+	overrides := make(TrackOverrides, len(raw))
+
+	var errs []error
+
+	for trackPath, o := range raw {
+		if o.Key != "" {
+			if _, err := ParseCamelotKey(o.Key); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", trackPath, err))
+			}
+		}
+
+		if o.Energy < 0 || o.Energy > 10 || o.BPM < 0 {
+			errs = append(errs, fmt.Errorf("%s: energy must be 1-10 and BPM positive", trackPath))
+		}
+
+		overrides[absEntryPath(trackPath, filepath.Dir(path))] = o
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid overrides in %s: %w", path, errors.Join(errs...))
+	}
+
+	return overrides, nil
+}
+
+// LoadPlaylistOverrides reads the overrides for a playlist: from path when set, otherwise from the
+// playlist's OverridesPath if it exists. Returns nil when there are none.
+func LoadPlaylistOverrides(path, playlistPath string) (TrackOverrides, error) {
+	if path != "" {
+		return LoadTrackOverrides(path)
+	}
+
+	if playlistPath == StdioPath {
+		return nil, nil
+	}
+
+	overrides, err := LoadTrackOverrides(OverridesPath(playlistPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	return overrides, err
+}
+
+// Apply replaces the metadata of tracks listed in the overrides. Track paths are resolved against
+// baseDir, the playlist's directory. Returns the number of tracks changed.
+func (o TrackOverrides) Apply(tracks []Track, baseDir string) int {
+	if len(o) == 0 {
+		return 0
+	}
+
+	applied := 0
+
+	for i := range tracks {
+		if override, ok := o[absEntryPath(tracks[i].Path, baseDir)]; ok {
+			override.ApplyTo(&tracks[i])
+			applied++
+		}
+	}
+
+	return applied
+}
+
+// ApplyTo replaces t's metadata with the override's non-empty fields
+func (o TrackOverride) ApplyTo(t *Track) {
+	if o.Key != "" {
+		t.Key = o.Key
+		t.ParsedKey, _ = ParseCamelotKey(o.Key)
+		t.StartKey, t.EndKey = nil, nil // The corrected key is the whole track's
+	}
+
+	if o.BPM > 0 {
+		t.BPM = o.BPM
+	}
+
+	if o.Energy > 0 {
+		t.Energy = o.Energy
+		t.EnergyEstimated = false
+	}
+
+	if o.Genre != "" {
+		t.Genre = o.Genre
+	}
+}
+
+// merge returns base with the override's non-empty fields replacing its own
+func (o TrackOverride) merge(base TrackOverride) TrackOverride {
+	if o.Key != "" {
+		base.Key = o.Key
+	}
+
+	if o.BPM > 0 {
+		base.BPM = o.BPM
+	}
+
+	if o.Energy > 0 {
+		base.Energy = o.Energy
+	}
+
+	if o.Genre != "" {
+		base.Genre = o.Genre
+	}
+
+	return base
+}
+
+// SaveTrackOverride records o for the playlist entry trackPath (resolved against baseDir) in the
+// overrides file at path, merged over the entry's existing corrections. Other entries are kept; a
+// missing file is created. New entries are written relative to the file's directory where possible.
+func SaveTrackOverride(path, trackPath, baseDir string, o TrackOverride) error {
+	raw := make(map[string]TrackOverride)
+
+	data, err := os.ReadFile(path)
+
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse overrides %s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to read overrides %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	target := absEntryPath(trackPath, baseDir)
+
+	entry := target
+	if rel, err := filepath.Rel(absEntryPath(dir, "."), target); err == nil && !strings.HasPrefix(rel, "..") {
+		entry = filepath.ToSlash(rel)
+	}
+
+	for k := range raw {
+		if absEntryPath(k, dir) == target {
+			entry = k // Keep the spelling of the existing entry
+
+			break
+		}
+	}
+
+	raw[entry] = o.merge(raw[entry])
+
+	data, err = json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode overrides: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil { //nolint:gosec // Not sensitive
+		return fmt.Errorf("failed to write overrides %s: %w", path, err)
+	}
+
+	return nil
+}
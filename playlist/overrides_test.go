@@ -0,0 +1,76 @@
+// ABOUTME: Tests for per-track metadata overrides files
+// ABOUTME: Verifies path matching, applied fields, validation and the per-playlist default file
+
+package playlist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrackOverrides(t *testing.T) {
+	dir := t.TempDir()
+	playlistPath := filepath.Join(dir, "set.m3u8")
+
+	data := `{
+		"Artist/one.mp3": {"key": "8A", "bpm": 174, "energy": 7, "genre": "Drum & Bass; Liquid"},
+		"` + filepath.Join(dir, "two.mp3") + `": {"bpm": 126}
+	}`
+	if err := os.WriteFile(OverridesPath(playlistPath), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	overrides, err := LoadPlaylistOverrides("", playlistPath)
+	if err != nil {
+		t.Fatalf("LoadPlaylistOverrides failed: %v", err)
+	}
+
+	tracks := []Track{
+		{Path: "Artist/one.mp3", Key: "3B", StartKey: &CamelotKey{Number: 3, Letter: 'B'}, BPM: 87, Energy: 4, EnergyEstimated: true, Genre: "Electronic"},
+		{Path: "./two.mp3", Key: "5A", BPM: 63, Genre: "House"},
+		{Path: "three.mp3", Key: "1A", BPM: 120},
+	}
+
+	if applied := overrides.Apply(tracks, dir); applied != 2 {
+		t.Fatalf("Expected 2 tracks changed, got %d", applied)
+	}
+
+	one := tracks[0]
+	if one.Key != "8A" || one.ParsedKey == nil || one.ParsedKey.Number != 8 || one.StartKey != nil {
+		t.Errorf("Expected key 8A replacing the tagged keys, got %q (parsed %v, start %v)", one.Key, one.ParsedKey, one.StartKey)
+	}
+
+	if one.BPM != 174 || one.Energy != 7 || one.EnergyEstimated || one.Genre != "Drum & Bass; Liquid" {
+		t.Errorf("Expected BPM 174, energy 7 and genre Drum & Bass; Liquid, got %+v", one)
+	}
+
+	if two := tracks[1]; two.BPM != 126 || two.Key != "5A" || two.Genre != "House" {
+		t.Errorf("Expected only the BPM of two.mp3 to change, got %+v", two)
+	}
+
+	if tracks[2].BPM != 120 {
+		t.Errorf("Expected three.mp3 untouched, got BPM %.0f", tracks[2].BPM)
+	}
+}
+
+func TestLoadTrackOverridesErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if overrides, err := LoadPlaylistOverrides("", filepath.Join(dir, "none.m3u8")); err != nil || overrides != nil {
+		t.Errorf("Expected no overrides and no error without a file, got %v, %v", overrides, err)
+	}
+
+	if _, err := LoadPlaylistOverrides(filepath.Join(dir, "missing.json"), ""); err == nil {
+		t.Error("Expected an error for an explicitly given missing file")
+	}
+
+	path := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(path, []byte(`{"a.mp3": {"key": "13C"}, "b.mp3": {"energy": 11}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadTrackOverrides(path); err == nil {
+		t.Error("Expected an error for an invalid key and energy")
+	}
+}
@@ -0,0 +1,112 @@
+// ABOUTME: Track path normalization for playlists exported on another OS
+// ABOUTME: Converts Windows separators and rewrites library prefixes (e.g. D:\Music => /mnt/music)
+
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// pathMapSeparator separates the two sides of a path mapping
+const pathMapSeparator = "=>"
+
+// PathMapping rewrites track paths starting with From to start with To instead
+type PathMapping struct {
+	From string
+	To   string
+}
+
+var (
+	pathMapMu sync.RWMutex
+	pathMap   []PathMapping
+)
+
+// ParsePathMapping parses a "from=>to" mapping, e.g. `D:\Music=>/mnt/music`
+func ParsePathMapping(s string) (PathMapping, error) {
+	from, to, ok := strings.Cut(s, pathMapSeparator)
+
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !ok || from == "" || to == "" {
+		return PathMapping{}, fmt.Errorf("invalid path mapping %q (expected from%sto, e.g. D:\\Music%s/mnt/music)", s, pathMapSeparator, pathMapSeparator)
+	}
+
+	return PathMapping{
+		From: strings.TrimRight(toSlash(from), "/"),
+		To:   strings.TrimRight(toSlash(to), "/"),
+	}, nil
+}
+
+// SetPathMap sets the mappings ReadPlaylist applies to every track path (first match wins)
+func SetPathMap(mappings []PathMapping) {
+	pathMapMu.Lock()
+	defer pathMapMu.Unlock()
+
+	pathMap = mappings
+}
+
+// ParsePathMap parses several "from=>to" mappings
+func ParsePathMap(specs []string) ([]PathMapping, error) {
+	mappings := make([]PathMapping, 0, len(specs))
+
+	var errs []error
+
+	for _, spec := range specs {
+		m, err := ParsePathMapping(spec)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		mappings = append(mappings, m)
+	}
+
+	return mappings, errors.Join(errs...)
+}
+
+// NormalizeTrackPath rewrites a playlist entry for this OS: backslashes become separators and the
+// first path mapping whose prefix matches is applied. Prefixes match case-insensitively (Windows
+// paths are) and only at a path boundary.
+func NormalizeTrackPath(p string) string {
+	pathMapMu.RLock()
+	mappings := pathMap
+	pathMapMu.RUnlock()
+
+	return mapTrackPath(p, mappings)
+}
+
+// mapTrackPath is NormalizeTrackPath with the given path mappings
+func mapTrackPath(p string, mappings []PathMapping) string {
+	p = toSlash(p)
+
+	for _, m := range mappings {
+		if len(p) >= len(m.From) && strings.EqualFold(p[:len(m.From)], m.From) &&
+			(len(p) == len(m.From) || p[len(m.From)] == '/') {
+			p = m.To + p[len(m.From):]
+
+			break
+		}
+	}
+
+	return filepath.FromSlash(p)
+}
+
+// resolveTrackPath returns the file path of a playlist entry: separators normalized, and relative
+// paths resolved against baseDir (typically the playlist's directory)
+func resolveTrackPath(trackPath, baseDir string) string {
+	fullPath := filepath.FromSlash(toSlash(trackPath))
+	if !filepath.IsAbs(fullPath) && baseDir != "" {
+		fullPath = filepath.Join(baseDir, fullPath)
+	}
+
+	return fullPath
+}
+
+// toSlash converts both Windows and native separators to forward slashes
+func toSlash(p string) string {
+	return filepath.ToSlash(strings.ReplaceAll(p, `\`, "/"))
+}
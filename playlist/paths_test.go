@@ -0,0 +1,63 @@
+// ABOUTME: Tests for track path normalization of playlists exported on another OS
+// ABOUTME: Verifies separator conversion, prefix mappings and mapping parsing
+
+package playlist
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTrackPath(t *testing.T) {
+	mappings, err := ParsePathMap([]string{`D:\Music=>/mnt/music`, `\\nas\share\DJ => /Volumes/DJ/`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetPathMap(mappings)
+	t.Cleanup(func() { SetPathMap(nil) })
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`D:\Music\House\track.mp3`, "/mnt/music/House/track.mp3"},
+		{`d:\music\House\track.mp3`, "/mnt/music/House/track.mp3"}, // Windows paths are case-insensitive
+		{`D:\Musical\track.mp3`, "D:/Musical/track.mp3"},           // Prefix only matches whole directories
+		{`\\nas\share\DJ\set\a.flac`, "/Volumes/DJ/set/a.flac"},
+		{`House\track.mp3`, "House/track.mp3"},
+		{"/already/unix.mp3", "/already/unix.mp3"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTrackPath(tt.in); got != filepath.FromSlash(tt.want) {
+			t.Errorf("NormalizeTrackPath(%q) = %q, want %q", tt.in, got, filepath.FromSlash(tt.want))
+		}
+	}
+}
+
+func TestReadPlaylistNormalizesPaths(t *testing.T) {
+	mappings := []PathMapping{{From: "D:/Music", To: "/mnt/music"}}
+
+	tracks, err := readPlaylistFrom(strings.NewReader("#EXTM3U\r\nD:\\Music\\a.mp3\r\nsub\\b.mp3\r\n"), mappings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracks) != 2 || tracks[0].Path != filepath.FromSlash("/mnt/music/a.mp3") || tracks[1].Path != filepath.FromSlash("sub/b.mp3") {
+		t.Errorf("Unexpected tracks: %+v", tracks)
+	}
+}
+
+func TestParsePathMapping(t *testing.T) {
+	for _, bad := range []string{"", "D:\\Music", "=>/mnt", "D:\\Music=>", "D:\\Music=/mnt"} {
+		if _, err := ParsePathMapping(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+
+	if _, err := ParsePathMap([]string{"a=>b", "broken"}); err == nil {
+		t.Error("Expected ParsePathMap to report the broken mapping")
+	}
+}
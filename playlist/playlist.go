@@ -8,15 +8,40 @@ package playlist
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// StdioPath is the playlist path meaning stdin (when reading) or stdout (when writing)
+const StdioPath = "-"
+
+// WorkingCopySuffix is appended to a playlist's path for the working copy a sort run writes its
+// improvements to, so the playlist itself is only replaced once, at the end
+const WorkingCopySuffix = ".optimizing"
+
+// WorkingCopyPath returns the working copy path for a playlist, e.g. set.m3u8.optimizing
+func WorkingCopyPath(path string) string {
+	return path + WorkingCopySuffix
+}
+
 // ReadPlaylist reads an M3U8 playlist file and fetches metadata for all tracks
 // Returns a slice of Track structs with full metadata
+// A path of "-" reads the playlist from stdin
 func ReadPlaylist(path string) ([]Track, error) {
+	return readPlaylist(path, currentLoadSettings().PathMap)
+}
+
+// readPlaylist is ReadPlaylist rewriting paths with the given path mappings
+func readPlaylist(path string, mappings []PathMapping) ([]Track, error) {
+	if path == StdioPath {
+		return readPlaylistFrom(os.Stdin, mappings)
+	}
+
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open playlist: %w", err)
@@ -26,9 +51,21 @@ func ReadPlaylist(path string) ([]Track, error) {
 		_ = file.Close() // Explicitly ignore error for read-only file
 	}()
 
+	return readPlaylistFrom(file, mappings)
+}
+
+// readPlaylistFrom reads playlist entries (one path per line, # comments skipped) from r.
+// BOMs are stripped and UTF-16 is decoded (see decodePlaylistText), and paths are normalized for
+// this OS with mappings applied (see NormalizeTrackPath).
+func readPlaylistFrom(r io.Reader, mappings []PathMapping) ([]Track, error) {
 	var tracks []Track
 
-	scanner := bufio.NewScanner(file)
+	text, err := decodePlaylistText(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading playlist: %w", err)
+	}
+
+	scanner := bufio.NewScanner(text)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -38,7 +75,7 @@ func ReadPlaylist(path string) ([]Track, error) {
 			continue
 		}
 
-		tracks = append(tracks, Track{Path: line})
+		tracks = append(tracks, Track{Path: mapTrackPath(line, mappings)})
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -53,69 +90,130 @@ func ReadPlaylist(path string) ([]Track, error) {
 // Displays progress as it fetches metadata for each track if verbose is true
 // Relative track paths are resolved against the playlist's directory
 func LoadPlaylistWithMetadata(path string, verbose bool) ([]Track, error) {
-	tracks, err := ReadPlaylist(path)
+	tracks, _, err := LoadPlaylistContext(context.Background(), path, LoadOptions{Verbose: verbose})
+
+	return tracks, err
+}
+
+// LoadPlaylistKeyBPM is LoadPlaylistWithMetadata reading only each track's key, energy and BPM
+// (see GetTrackKeyBPM), for quick harmonic ordering of large folders
+func LoadPlaylistKeyBPM(path string, verbose bool) ([]Track, error) {
+	tracks, _, err := LoadPlaylistContext(context.Background(), path, LoadOptions{KeyOnly: true, Verbose: verbose})
+
+	return tracks, err
+}
+
+// LoadProgress reports how far loading a playlist's metadata has got
+type LoadProgress struct {
+	Done    int    // Tracks processed so far, loaded or skipped
+	Total   int    // Tracks in the playlist
+	Skipped int    // Tracks whose metadata could not be read
+	Path    string // Track processed last
+}
+
+// LoadOptions controls how LoadPlaylistContext reads track metadata
+type LoadOptions struct {
+	KeyOnly      bool               // Read only key, energy and BPM (see GetTrackKeyBPM)
+	Verbose      bool               // Print progress and skipped tracks
+	Progress     func(LoadProgress) // Called after each track (nil: not reported)
+	TrackTimeout time.Duration      // Skip tracks whose metadata takes longer to read (0 = wait as long as it takes)
+	Settings     *LoadSettings      // Mix tags and path map to load with (nil = the process-wide ones)
+}
+
+// LoadSettings are the per-playlist settings reading a playlist depends on. A load given its own
+// doesn't depend on the process-wide ones (SetMixTags, SetPathMap), which concurrent loads of
+// playlists with different configs would otherwise have to take turns setting.
+type LoadSettings struct {
+	IntroTag string        // Tag intro lengths are read from ("" = DefaultIntroTag)
+	OutroTag string        // Tag outro lengths are read from ("" = DefaultOutroTag)
+	PathMap  []PathMapping // Track path rewrites (see NormalizeTrackPath)
+}
+
+// currentLoadSettings returns the process-wide settings set with SetMixTags and SetPathMap
+func currentLoadSettings() LoadSettings {
+	mixTagsMu.RLock()
+	intro, outro := introTag, outroTag
+	mixTagsMu.RUnlock()
+
+	pathMapMu.RLock()
+	defer pathMapMu.RUnlock()
+
+	return LoadSettings{IntroTag: intro, OutroTag: outro, PathMap: pathMap}
+}
+
+// LoadPlaylistContext is LoadPlaylistWithMetadata that also returns the entries whose metadata could
+// not be read (or took longer than opts.TrackTimeout, see TrackReader), so they can be put back into
+// the written playlist. Loading stops with ctx's error when ctx is cancelled.
+func LoadPlaylistContext(ctx context.Context, path string, opts LoadOptions) ([]Track, []SkippedTrack, error) {
+	settings := currentLoadSettings()
+	if opts.Settings != nil {
+		settings = *opts.Settings
+	}
+
+	entries, err := readPlaylist(path, settings.PathMap)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	reader := &TrackReader{Timeout: opts.TrackTimeout, Load: func(trackPath, baseDir string) (*Track, error) {
+		return getTrackMetadata(trackPath, baseDir, settings)
+	}}
+
+	if opts.KeyOnly {
+		reader.Load = func(trackPath, baseDir string) (*Track, error) {
+			return getTrackKeyBPM(trackPath, baseDir, settings)
+		}
 	}
 
-	if verbose {
-		fmt.Printf("Loading metadata for %d tracks...\n", len(tracks))
+	if opts.Verbose {
+		fmt.Printf("Loading metadata for %d tracks...\n", len(entries))
 	}
 
 	// Get the directory containing the playlist for resolving relative paths
 	playlistDir := filepath.Dir(path)
 
-	// Fetch metadata for each track, filtering out failures
-	validTracks := make([]Track, 0, len(tracks))
-	skippedCount := 0
+	// Fetch metadata for each track, setting failures aside
+	validTracks := make([]Track, 0, len(entries))
+
+	var skipped []SkippedTrack
+
+	for i := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
 
-	for i := range tracks {
-		if verbose && (i+1)%10 == 0 {
-			fmt.Printf("[+] Processed %d/%d tracks...\n", i+1, len(tracks))
+		if opts.Verbose && (i+1)%10 == 0 {
+			fmt.Printf("[+] Processed %d/%d tracks...\n", i+1, len(entries))
 		}
 
-		metadata, err := GetTrackMetadata(tracks[i].Path, playlistDir)
+		metadata, err := reader.Read(ctx, entries[i].Path, playlistDir)
 		if err != nil {
-			if verbose {
-				fmt.Printf("[!] Skipping track (could not load metadata): %s: %v\n", tracks[i].Path, err)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, nil, ctxErr
 			}
 
-			skippedCount++
+			if opts.Verbose {
+				fmt.Printf("[!] Skipping track (could not load metadata): %s: %v\n", entries[i].Path, err)
+			}
 
-			continue
+			skipped = append(skipped, SkippedTrack{Path: entries[i].Path, Position: i, Err: err})
+		} else {
+			// Add successfully loaded track
+			validTracks = append(validTracks, *metadata)
 		}
 
-		// Add successfully loaded track
-		validTracks = append(validTracks, *metadata)
+		if opts.Progress != nil {
+			opts.Progress(LoadProgress{Done: i + 1, Total: len(entries), Skipped: len(skipped), Path: entries[i].Path})
+		}
 	}
 
-	return validTracks, nil
+	AssignIDs(validTracks, playlistDir)
+
+	return validTracks, skipped, nil
 }
 
 // WritePlaylist writes a slice of tracks to an M3U8 playlist file
 // Only writes the Path field of each track (not metadata)
 func WritePlaylist(path string, tracks []Track) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create playlist: %w", err)
-	}
-
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close playlist file: %w", closeErr)
-		}
-	}()
-
-	writer := bufio.NewWriter(file)
-	for _, track := range tracks {
-		if _, err := writer.WriteString(track.Path + "\n"); err != nil {
-			return fmt.Errorf("failed to write track: %w", err)
-		}
-	}
-
-	if err := writer.Flush(); err != nil {
-		return fmt.Errorf("failed to flush writer: %w", err)
-	}
-
-	return nil
+	return WritePlaylistFormat(path, tracks, FormatM3U8)
 }
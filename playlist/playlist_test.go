@@ -1,11 +1,14 @@
 // ABOUTME: Tests for M3U8 playlist reading and writing
-// ABOUTME: Verifies file I/O, comment handling, and empty file edge cases
+// ABOUTME: Verifies file I/O, comment handling, empty file edge cases, load progress and per-load settings
 
 package playlist
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -215,3 +218,72 @@ func TestRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+// TestReadPlaylistFrom verifies parsing from a reader (used for stdin input)
+func TestReadPlaylistFrom(t *testing.T) {
+	input := "#EXTM3U\n\nArtist/01 Track.mp3\n  /music/02 Track.flac  \n#EXTINF:-1,comment\n"
+
+	tracks, err := readPlaylistFrom(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("Failed to read playlist: %v", err)
+	}
+
+	if len(tracks) != 2 || tracks[0].Path != "Artist/01 Track.mp3" || tracks[1].Path != "/music/02 Track.flac" {
+		t.Errorf("Unexpected tracks: %+v", tracks)
+	}
+}
+
+func TestLoadPlaylistContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.m3u8")
+	if err := os.WriteFile(path, []byte("a.mp3\nb.mp3\nc.mp3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var reports []LoadProgress
+
+	tracks, skipped, err := LoadPlaylistContext(context.Background(), path, LoadOptions{Progress: func(p LoadProgress) {
+		reports = append(reports, p)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// None of the files exist, so every track is skipped
+	want := LoadProgress{Done: 3, Total: 3, Skipped: 3, Path: "c.mp3"}
+	if len(tracks) != 0 || len(reports) != 3 || reports[2] != want {
+		t.Errorf("Unexpected progress %+v (tracks %d)", reports, len(tracks))
+	}
+
+	if len(skipped) != 3 || skipped[1].Path != "b.mp3" || skipped[1].Position != 1 || skipped[1].Err == nil {
+		t.Errorf("Unexpected skipped tracks: %+v", skipped)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := LoadPlaylistContext(ctx, path, LoadOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLoadPlaylistContextSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "set.m3u8")
+	if err := os.WriteFile(path, []byte("D:\\Music\\a.mp3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	SetPathMap([]PathMapping{{From: "D:/Music", To: "/process/wide"}})
+	t.Cleanup(func() { SetPathMap(nil) })
+
+	// The load's own path map is used instead of the process-wide one
+	settings := &LoadSettings{PathMap: []PathMapping{{From: "D:/Music", To: "/mnt/music"}}}
+
+	_, skipped, err := LoadPlaylistContext(context.Background(), path, LoadOptions{Settings: settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(skipped) != 1 || skipped[0].Path != filepath.FromSlash("/mnt/music/a.mp3") {
+		t.Errorf("Expected the entry mapped to /mnt/music, got %+v", skipped)
+	}
+}
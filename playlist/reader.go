@@ -0,0 +1,106 @@
+// ABOUTME: Reads track metadata with a per-track timeout for playlists on slow or unresponsive mounts
+// ABOUTME: Skips the rest of a directory after a timeout there, and bounds the reads left running in the background
+
+package playlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxStalledReads is how many timed-out reads a TrackReader leaves running before it skips every
+// further track, so a dead mount can't pile up blocked reads
+const maxStalledReads = 4
+
+// ErrReadSkipped marks tracks a TrackReader skipped without reading because earlier reads timed out
+var ErrReadSkipped = errors.New("skipped after earlier metadata reads timed out")
+
+// TrackReader reads tracks' metadata, giving up on a track after Timeout (0 = wait as long as it
+// takes). File reads can't be interrupted: a timed-out read finishes in the background and its result
+// is discarded. A timeout usually means an unresponsive network mount, so after one the rest of that
+// track's directory is skipped, and while maxStalledReads timed-out reads are still running every
+// track is. That way a dead mount costs a few timeouts rather than one per track.
+// Use one TrackReader per playlist load; its reads are meant to be made one at a time.
+type TrackReader struct {
+	Load    func(trackPath, baseDir string) (*Track, error) // Reads one track (nil = GetTrackMetadata)
+	Timeout time.Duration                                   // Per-track limit (0 = none)
+
+	mu       sync.Mutex
+	timedOut map[string]bool // Directories a read timed out in
+	running  int             // Reads whose goroutine hasn't returned, including timed-out ones
+}
+
+// Read reads a track's metadata, resolving a relative trackPath against baseDir.
+// Returns ctx's error if ctx is done first, and an error wrapping ErrReadSkipped for skipped tracks.
+func (r *TrackReader) Read(ctx context.Context, trackPath, baseDir string) (*Track, error) {
+	load := r.Load
+	if load == nil {
+		load = GetTrackMetadata
+	}
+
+	if r.Timeout <= 0 {
+		return load(trackPath, baseDir)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(resolveTrackPath(trackPath, baseDir))
+
+	r.mu.Lock()
+	switch {
+	case r.timedOut[dir]:
+		r.mu.Unlock()
+
+		return nil, fmt.Errorf("%w in %s", ErrReadSkipped, dir)
+	case r.running >= maxStalledReads:
+		r.mu.Unlock()
+
+		return nil, fmt.Errorf("%w (%d reads still stalled)", ErrReadSkipped, maxStalledReads)
+	}
+
+	r.running++
+	r.mu.Unlock()
+
+	type result struct {
+		track *Track
+		err   error
+	}
+
+	done := make(chan result, 1) // Buffered: an abandoned read must not block forever
+
+	go func() {
+		track, err := load(trackPath, baseDir)
+
+		r.mu.Lock()
+		r.running--
+		r.mu.Unlock()
+
+		done <- result{track: track, err: err}
+	}()
+
+	timer := time.NewTimer(r.Timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		return res.track, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		r.mu.Lock()
+		if r.timedOut == nil {
+			r.timedOut = make(map[string]bool)
+		}
+
+		r.timedOut[dir] = true
+		r.mu.Unlock()
+
+		return nil, fmt.Errorf("metadata read timed out after %s", r.Timeout)
+	}
+}
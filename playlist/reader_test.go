@@ -0,0 +1,84 @@
+// ABOUTME: Tests for reading track metadata with a per-track timeout
+// ABOUTME: Verifies timeouts, skipping a directory after a timeout and the bound on stalled reads
+
+package playlist
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTrackReaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	hang := func(string, string) (*Track, error) {
+		<-release // A read stuck on an unresponsive mount
+
+		return nil, errors.New("released")
+	}
+
+	reader := &TrackReader{Load: hang, Timeout: 10 * time.Millisecond}
+	if _, err := reader.Read(context.Background(), "stuck.mp3", "/music/a"); err == nil || !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+
+	fast := &TrackReader{Load: func(path, _ string) (*Track, error) { return &Track{Path: path}, nil }, Timeout: time.Minute}
+	if track, err := fast.Read(context.Background(), "a.mp3", ""); err != nil || track.Path != "a.mp3" {
+		t.Errorf("Expected a.mp3 loaded, got %v, %v", track, err)
+	}
+
+	// Cancelling the whole load is not a timeout of the track
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	slow := &TrackReader{Load: hang, Timeout: time.Minute}
+	if _, err := slow.Read(ctx, "stuck.mp3", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the load's deadline, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := (&TrackReader{Timeout: time.Minute}).Read(cancelled, "a.mp3", ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestTrackReaderSkipsAfterTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var reads atomic.Int32
+
+	reader := &TrackReader{Timeout: 10 * time.Millisecond, Load: func(string, string) (*Track, error) {
+		reads.Add(1)
+		<-release
+
+		return nil, errors.New("released")
+	}}
+
+	if _, err := reader.Read(context.Background(), "a/1.mp3", "/mnt"); errors.Is(err, ErrReadSkipped) {
+		t.Fatalf("Expected the first read to time out, got %v", err)
+	}
+
+	// The rest of the directory is skipped without another read
+	if _, err := reader.Read(context.Background(), "a/2.mp3", "/mnt"); !errors.Is(err, ErrReadSkipped) || reads.Load() != 1 {
+		t.Errorf("Expected a/2.mp3 skipped unread, got %v after %d reads", err, reads.Load())
+	}
+
+	// Other directories are tried until maxStalledReads reads are stuck, then everything is skipped
+	for i := 1; i < maxStalledReads; i++ {
+		if _, err := reader.Read(context.Background(), string(rune('a'+i))+"/1.mp3", "/mnt"); errors.Is(err, ErrReadSkipped) {
+			t.Fatalf("Expected read %d to time out, got %v", i+1, err)
+		}
+	}
+
+	if _, err := reader.Read(context.Background(), "z/1.mp3", "/mnt"); !errors.Is(err, ErrReadSkipped) || reads.Load() != maxStalledReads {
+		t.Errorf("Expected z/1.mp3 skipped with %d reads stalled, got %v after %d reads", maxStalledReads, err, reads.Load())
+	}
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Playlist entries left out of optimization because their metadata could not be read
+// ABOUTME: Puts them back into the written playlist, in place or at the end, so no entry is lost
+
+package playlist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SkippedTrack is a playlist entry whose metadata could not be read
+type SkippedTrack struct {
+	Path     string // Entry as written in the playlist
+	Position int    // Position in the playlist (0-based)
+	Err      error  // Why its metadata could not be read
+}
+
+// SkippedPlacement says where skipped entries go in the written playlist
+type SkippedPlacement string
+
+// Skipped entry placements
+const (
+	SkippedInPlace SkippedPlacement = "keep" // At their original positions, optimized tracks around them
+	SkippedAtEnd   SkippedPlacement = "end"  // After the optimized tracks, in playlist order
+	SkippedDrop    SkippedPlacement = "drop" // Left out of the written playlist
+)
+
+// SkippedPlacements lists the valid placements
+var SkippedPlacements = []SkippedPlacement{SkippedInPlace, SkippedAtEnd, SkippedDrop}
+
+// Description says where the placement puts skipped entries, e.g. for warnings
+func (p SkippedPlacement) Description() string {
+	switch p {
+	case SkippedAtEnd:
+		return "appended to the end of the saved playlist"
+	case SkippedDrop:
+		return "left out of the saved playlist"
+	default:
+		return "kept at their positions in the saved playlist"
+	}
+}
+
+// ParseSkippedPlacement parses a placement name (empty means SkippedInPlace)
+func ParseSkippedPlacement(name string) (SkippedPlacement, error) {
+	if name == "" {
+		return SkippedInPlace, nil
+	}
+
+	names := make([]string, len(SkippedPlacements))
+
+	for i, p := range SkippedPlacements {
+		if strings.EqualFold(name, string(p)) {
+			return p, nil
+		}
+
+		names[i] = string(p)
+	}
+
+	return "", fmt.Errorf("unknown skipped track placement %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// WithSkipped returns tracks with the skipped entries put back per placement. Skipped entries
+// carry only their path. tracks is returned unchanged when nothing was skipped or placement is
+// SkippedDrop; entries already in tracks (e.g. readable after a reload) are not added twice.
+func WithSkipped(tracks []Track, skipped []SkippedTrack, placement SkippedPlacement) []Track {
+	if len(skipped) == 0 || placement == SkippedDrop {
+		return tracks
+	}
+
+	present := make(map[string]bool, len(tracks))
+	for i := range tracks {
+		present[tracks[i].Path] = true
+	}
+
+	missing := make([]SkippedTrack, 0, len(skipped))
+
+	for _, s := range skipped {
+		if !present[s.Path] {
+			missing = append(missing, s)
+		}
+	}
+
+	if len(missing) == 0 {
+		return tracks
+	}
+
+	result := make([]Track, 0, len(tracks)+len(missing))
+
+	if placement == SkippedAtEnd {
+		result = append(result, tracks...)
+
+		for _, s := range missing {
+			result = append(result, Track{Path: s.Path})
+		}
+
+		return result
+	}
+
+	// In place: fill positions in order, a skipped entry wherever one stood. Positions past the
+	// end (the playlist shrank since loading) fall through to the end.
+	next := 0
+
+	for _, s := range missing {
+		for len(result) < s.Position && next < len(tracks) {
+			result = append(result, tracks[next])
+			next++
+		}
+
+		result = append(result, Track{Path: s.Path})
+	}
+
+	return append(result, tracks[next:]...)
+}
@@ -0,0 +1,66 @@
+// ABOUTME: Tests for putting skipped playlist entries back into the written playlist
+// ABOUTME: Verifies in-place, end and drop placements and placement parsing
+
+package playlist
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWithSkipped(t *testing.T) {
+	// Original playlist: a x b c y, where x and y could not be read
+	sorted := []Track{{Path: "c"}, {Path: "a"}, {Path: "b"}}
+	skipped := []SkippedTrack{
+		{Path: "x", Position: 1, Err: errors.New("no tags")},
+		{Path: "y", Position: 4, Err: errors.New("no tags")},
+	}
+
+	tests := []struct {
+		placement SkippedPlacement
+		want      []string
+	}{
+		{SkippedInPlace, []string{"c", "x", "a", "b", "y"}},
+		{SkippedAtEnd, []string{"c", "a", "b", "x", "y"}},
+		{SkippedDrop, []string{"c", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, track := range WithSkipped(sorted, skipped, tt.placement) {
+			got = append(got, track.Path)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.placement, got, tt.want)
+		}
+	}
+}
+
+func TestWithSkippedEdgeCases(t *testing.T) {
+	sorted := []Track{{Path: "a"}, {Path: "x"}}
+
+	// x became readable (e.g. after a reload): not added twice
+	if got := WithSkipped(sorted, []SkippedTrack{{Path: "x", Position: 0}}, SkippedInPlace); len(got) != 2 {
+		t.Errorf("Expected no duplicate, got %+v", got)
+	}
+
+	// Position beyond the (shrunk) playlist goes to the end
+	got := WithSkipped(sorted, []SkippedTrack{{Path: "y", Position: 9}}, SkippedInPlace)
+	if len(got) != 3 || got[2].Path != "y" {
+		t.Errorf("Expected y at the end, got %+v", got)
+	}
+}
+
+func TestParseSkippedPlacement(t *testing.T) {
+	for name, want := range map[string]SkippedPlacement{"": SkippedInPlace, "keep": SkippedInPlace, "END": SkippedAtEnd, "drop": SkippedDrop} {
+		if got, err := ParseSkippedPlacement(name); err != nil || got != want {
+			t.Errorf("ParseSkippedPlacement(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	if _, err := ParseSkippedPlacement("middle"); err == nil {
+		t.Error("Expected an error for an unknown placement")
+	}
+}
@@ -9,37 +9,249 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dhowden/tag"
 )
 
 // Track represents a music track with metadata needed for sorting
 type Track struct {
-	Path      string      // Relative path in playlist (e.g., "Aperio/Dreams/00 Dreams.mp3")
-	Key       string      // Camelot key (e.g., "8A") - for display
-	ParsedKey *CamelotKey // Pre-parsed key for fast harmonic distance calculations
-	Artist    string      // Artist name
-	Album     string      // Album name
-	Title     string      // Track title
-	Genre     string      // Genre from ID3 tags (empty if not available)
-	Energy    int         // Energy level 1-10 (0 if not available)
-	BPM       float64     // Beats per minute (0 if not available)
-	Index     int         // Index in original tracks slice (for fast cache lookups)
+	Path      string        // Relative path in playlist (e.g., "Aperio/Dreams/00 Dreams.mp3")
+	ID        string        // Identity of this playlist entry that survives reordering (see AssignIDs)
+	Key       string        // Camelot key (e.g., "8A") - for display
+	ParsedKey *CamelotKey   // Pre-parsed key for fast harmonic distance calculations
+	StartKey  *CamelotKey   // Key the track opens in, for tracks that modulate (nil = ParsedKey)
+	EndKey    *CamelotKey   // Key the track ends in, for tracks that modulate (nil = ParsedKey)
+	Artist    string        // Artist name
+	Album     string        // Album name
+	Title     string        // Track title
+	Genre     string        // Genre from ID3 tags (empty if not available)
+	Energy    int           // Energy level 1-10 (0 if not available)
+	BPM       float64       // Beats per minute (0 if not available)
+	Index     int           // Index in original tracks slice (for fast cache lookups)
+	Duration  time.Duration // Track length from tags (0 if not available)
+	Intro     time.Duration // Mixable intro length from the intro tag (0 if not available)
+	Outro     time.Duration // Mixable outro length from the outro tag (0 if not available)
+	Rating    int           // Star rating 1-5 from the tags (0 if not rated)
+	Added     time.Time     // When the track was added: the date-added tag, else the file's modification time
+
+	EnergyEstimated bool // Energy was imputed rather than read from tags
+	BPMCorrected    bool // BPM was doubled or halved into its genre's range (see CorrectBPM)
+	Favorite        bool // Belongs in the set's prime region (see MarkFavorites)
+	Fresh           bool // Recently added (see MarkFresh)
+}
+
+// TrackFlags marks per-position state of a track in an ordering, shared by the GA and TUI
+type TrackFlags uint8
+
+const (
+	FlagLocked    TrackFlags = 1 << iota // Position is fixed by the user
+	FlagManual                           // Track was placed by hand (inserted in the TUI)
+	FlagEstimated                        // Some metadata was estimated rather than read from tags
+	FlagFavorite                         // Favorite track, placed in the prime region when weighted
+)
+
+// Has reports whether all of the given flags are set
+func (f TrackFlags) Has(flags TrackFlags) bool {
+	return f&flags == flags
+}
+
+// Marker returns a one-character marker for the most significant flag (space when none is set)
+func (f TrackFlags) Marker() string {
+	switch {
+	case f.Has(FlagLocked):
+		return "L"
+	case f.Has(FlagManual):
+		return "+"
+	case f.Has(FlagFavorite):
+		return "*"
+	case f.Has(FlagEstimated):
+		return "~"
+	default:
+		return " "
+	}
+}
+
+// PositionFlags returns the flags of each track in order: sticky flags (locked, manual) looked up by
+// Identity, plus flags derived from the track itself
+func PositionFlags(tracks []Track, sticky map[string]TrackFlags) []TrackFlags {
+	flags := make([]TrackFlags, len(tracks))
+	for i := range tracks {
+		flags[i] = sticky[tracks[i].Identity()]
+		if tracks[i].EnergyEstimated {
+			flags[i] |= FlagEstimated
+		}
+
+		if tracks[i].Favorite {
+			flags[i] |= FlagFavorite
+		}
+	}
+
+	return flags
+}
+
+// Identity identifies a playlist entry: its ID, or its path when it has none (tracks built outside a loader)
+func (t *Track) Identity() string {
+	if t.ID != "" {
+		return t.ID
+	}
+
+	return t.Path
+}
+
+// AssignIDs gives each track without an ID, or with an ID already used earlier in tracks, an identity
+// that survives reordering: its absolute, cleaned path (resolved against baseDir; left relative when
+// baseDir is empty) with "#2", "#3", ... appended for further occurrences of the same file. A file
+// listed twice, or once as "a/../x.mp3" and once as "x.mp3", thus yields two distinct entries.
+// Tracks that already have a unique ID keep it.
+func AssignIDs(tracks []Track, baseDir string) {
+	used := make(map[string]bool, len(tracks))
+
+	var pending []int
+
+	for i := range tracks {
+		if id := tracks[i].ID; id != "" && !used[id] {
+			used[id] = true
+		} else {
+			pending = append(pending, i)
+		}
+	}
+
+	for _, i := range pending {
+		path := EntryID(tracks[i].Path, baseDir)
+
+		id := path
+		for n := 2; used[id]; n++ {
+			id = path + "#" + strconv.Itoa(n)
+		}
+
+		tracks[i].ID = id
+		used[id] = true
+	}
+}
+
+// EntryID returns the identity AssignIDs gives the first entry for path in a playlist in baseDir
+func EntryID(path, baseDir string) string {
+	return absEntryPath(path, baseDir)
+}
+
+// RebasePath returns path, an entry of a playlist in fromDir, as an entry of a playlist in toDir:
+// relative to toDir when it was relative, so a track moved between playlists still resolves
+func RebasePath(path, fromDir, toDir string) string {
+	if filepath.IsAbs(path) || fromDir == toDir {
+		return path
+	}
+
+	abs := absEntryPath(path, fromDir)
+
+	target, err := filepath.Abs(toDir)
+	if err != nil {
+		return abs
+	}
+
+	rel, err := filepath.Rel(target, abs)
+	if err != nil {
+		return abs
+	}
+
+	return rel
+}
+
+// absEntryPath returns a playlist entry's path resolved against baseDir (the playlist's directory)
+// and cleaned; with an empty baseDir a relative path is only cleaned
+func absEntryPath(path, baseDir string) string {
+	if baseDir == "" {
+		return filepath.Clean(path)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs // Relative baseDir (e.g. "."): resolve against the working directory
+	}
+
+	return filepath.Clean(path)
+}
+
+// ReindexTracks sets each track's Index to its position. Index addresses the edge cache an ordering
+// is scored with, so it is only valid for the track set that cache was built from: after any change
+// to the set (delete, insert, reload) the tracks must be reindexed and the cache rebuilt.
+func ReindexTracks(tracks []Track) {
+	for i := range tracks {
+		tracks[i].Index = i
+	}
+}
+
+// CheckIndices verifies that tracks index a cache of n tracks: every Index is in [0, n) and used
+// only once. A failure means the tracks were edited without ReindexTracks and a cache rebuild.
+func CheckIndices(tracks []Track, n int) error {
+	seen := make([]bool, n)
+
+	for i := range tracks {
+		idx := tracks[i].Index
+		if idx < 0 || idx >= n {
+			return fmt.Errorf("track %d (%s) has index %d outside the cache of %d tracks", i, tracks[i].Path, idx, n)
+		}
+
+		if seen[idx] {
+			return fmt.Errorf("track %d (%s) reuses index %d", i, tracks[i].Path, idx)
+		}
+
+		seen[idx] = true
+	}
+
+	return nil
 }
 
 // Breakdown shows the individual fitness components for playlist optimization.
 // Single source of truth - used by both GA and TUI (no duplication).
 type Breakdown struct {
-	Total        float64 // Sum of all weighted components
-	Harmonic     float64 // Harmonic distance penalties
-	EnergyDelta  float64 // Energy change penalties
-	BPMDelta     float64 // BPM difference penalties
-	GenreChange  float64 // Genre change/clustering (can be negative for clustering)
-	SameArtist   float64 // Same artist penalties
-	SameAlbum    float64 // Same album penalties
-	PositionBias float64 // Low energy position bias reward
+	Total        float64 `json:"total"`         // Sum of all weighted components
+	Harmonic     float64 `json:"harmonic"`      // Harmonic distance penalties
+	EnergyDelta  float64 `json:"energy_delta"`  // Energy change penalties
+	BPMDelta     float64 `json:"bpm_delta"`     // BPM difference penalties
+	GenreChange  float64 `json:"genre_change"`  // Genre change/clustering (can be negative for clustering)
+	SameArtist   float64 `json:"same_artist"`   // Same artist penalties
+	SameAlbum    float64 `json:"same_album"`    // Same album penalties
+	PositionBias float64 `json:"position_bias"` // Low energy position bias reward
+	Shuffle      float64 `json:"shuffle"`       // Random transition jitter from smart-shuffle mode (0 when disabled)
+	Novelty      float64 `json:"novelty"`       // Penalty for repeating adjacencies from the last saved output
+	MixLength    float64 `json:"mix_length"`    // Outro/intro length mismatch penalties (0 when disabled)
+	EnergyWave   float64 `json:"energy_wave"`   // Deviation from the rising energy wave (0 when disabled)
+	BPMBand      float64 `json:"bpm_band"`      // BPM band change penalties (0 when disabled)
+	Bookends     float64 `json:"bookends"`      // Unsuitable opener and closer (0 when disabled)
+	Freshness    float64 `json:"freshness"`     // Recently added tracks played back to back, or late when front-loading (0 when disabled)
+	Favorites    float64 `json:"favorites"`     // Favorite tracks placed outside the prime region (0 when disabled)
+
+	// Raw counterparts of the transition components in human units, unaffected by weights
+	Transitions           int     `json:"transitions"`             // Number of transitions scored
+	BadKeyTransitions     int     `json:"bad_key_transitions"`     // Transitions between incompatible (or unknown) keys
+	TotalEnergyChange     float64 `json:"total_energy_change"`     // Sum of energy level changes
+	TotalBPMDrift         float64 `json:"total_bpm_drift"`         // Sum of tempo changes in BPM (half/double time aware)
+	GenreChanges          int     `json:"genre_changes"`           // Transitions between different genres
+	SameArtistAdjacencies int     `json:"same_artist_adjacencies"` // Back-to-back tracks by the same artist
+	SameAlbumAdjacencies  int     `json:"same_album_adjacencies"`  // Back-to-back tracks from the same album
+	RepeatedAdjacencies   int     `json:"repeated_adjacencies"`    // Pairs already adjacent in the last saved output
+	BPMBandChanges        int     `json:"bpm_band_changes"`        // BPM band crossings (0 when bands are disabled)
+	FreshAdjacencies      int     `json:"fresh_adjacencies"`       // Back-to-back recently added tracks
+}
+
+// Sum adds up the weighted components (the value Total is set to)
+func (b *Breakdown) Sum() float64 {
+	return b.Harmonic + b.EnergyDelta + b.BPMDelta + b.GenreChange + b.SameArtist + b.SameAlbum +
+		b.PositionBias + b.Shuffle + b.Novelty + b.MixLength + b.EnergyWave + b.BPMBand + b.Favorites +
+		b.Freshness + b.Bookends
 }
 
+// Tag names of the start and end keys of tracks that modulate (Camelot notation, e.g. "8A")
+const (
+	StartKeyTag = "START_KEY"
+	EndKeyTag   = "END_KEY"
+)
+
 // Compile regexes once at package initialization
 var (
 	keyRegex    = regexp.MustCompile(`(\d+[AB])\s*-\s*Energy`)
@@ -50,11 +262,13 @@ var (
 // The trackPath can be absolute or relative. Relative paths are resolved against
 // the provided baseDir (typically the playlist's directory).
 func GetTrackMetadata(trackPath string, baseDir string) (*Track, error) {
+	return getTrackMetadata(trackPath, baseDir, currentLoadSettings())
+}
+
+// getTrackMetadata is GetTrackMetadata reading mix lengths from the settings' tags
+func getTrackMetadata(trackPath, baseDir string, settings LoadSettings) (*Track, error) {
 	// If path is already absolute, use it as-is; otherwise resolve against base directory
-	fullPath := trackPath
-	if !filepath.IsAbs(trackPath) && baseDir != "" {
-		fullPath = filepath.Join(baseDir, trackPath)
-	}
+	fullPath := resolveTrackPath(trackPath, baseDir)
 
 	// Open the audio file
 	file, err := os.Open(fullPath)
@@ -112,19 +326,53 @@ func GetTrackMetadata(trackPath string, baseDir string) (*Track, error) {
 	// Parse key once and store it for fast lookups
 	parsedKey, _ := ParseCamelotKey(key)
 
+	intro, outro := extractMixLengths(metadata.Raw(), settings)
+
+	// Optional start/end keys for tracks that modulate; invalid values fall back to the single key
+	startKey, _ := ParseCamelotKey(strings.TrimSpace(rawTagText(metadata.Raw(), StartKeyTag)))
+	endKey, _ := ParseCamelotKey(strings.TrimSpace(rawTagText(metadata.Raw(), EndKeyTag)))
+
+	added := extractAdded(metadata.Raw())
+	if info, err := file.Stat(); err == nil && added.IsZero() {
+		added = info.ModTime()
+	}
+
 	return &Track{
 		Path:      trackPath,
+		Duration:  extractDuration(metadata.Raw()),
+		Intro:     intro,
+		Outro:     outro,
 		Key:       key,
 		ParsedKey: parsedKey,
+		StartKey:  startKey,
+		EndKey:    endKey,
 		Artist:    artist,
 		Album:     album,
 		Title:     title,
 		Genre:     genre,
 		Energy:    energy,
 		BPM:       bpm,
+		Rating:    extractRating(metadata.Raw()),
+		Added:     added,
 	}, nil
 }
 
+// extractDuration reads the track length from the ID3v2 TLEN frame (milliseconds).
+// Other tag formats have no standard length field, so their duration stays unknown.
+func extractDuration(raw map[string]interface{}) time.Duration {
+	val, ok := raw["TLEN"].(string)
+	if !ok {
+		return 0
+	}
+
+	ms, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+	if err != nil || ms <= 0 {
+		return 0
+	}
+
+	return time.Duration(ms) * time.Millisecond
+}
+
 // extractKey extracts Camelot key from comments string
 // Example: "8A - Energy 6" -> "8A"
 func extractKey(comments string) string {
@@ -150,6 +398,77 @@ func extractEnergy(comments string) int {
 	return 0
 }
 
+// InKey returns the key the track is mixed into: its start key, falling back to its single key
+func (t *Track) InKey() *CamelotKey {
+	if t.StartKey != nil {
+		return t.StartKey
+	}
+
+	return t.ParsedKey
+}
+
+// OutKey returns the key the track is mixed out of: its end key, falling back to its single key
+func (t *Track) OutKey() *CamelotKey {
+	if t.EndKey != nil {
+		return t.EndKey
+	}
+
+	return t.ParsedKey
+}
+
+// EnergyLabel formats energy for display, marking imputed values with "~"
+func (t *Track) EnergyLabel() string {
+	if t.EnergyEstimated {
+		return fmt.Sprintf("~%d", t.Energy)
+	}
+
+	return strconv.Itoa(t.Energy)
+}
+
+// DurationLabel formats the track length as m:ss ("-" when unknown)
+func (t *Track) DurationLabel() string {
+	if t.Duration <= 0 {
+		return "-"
+	}
+
+	return FormatDuration(t.Duration)
+}
+
+// FormatDuration formats d as m:ss, or h:mm:ss from an hour up (rounded to the second)
+func FormatDuration(d time.Duration) string {
+	secs := int(d.Round(time.Second).Seconds())
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// TotalDuration sums the lengths of tracks, also returning how many have no known length
+func TotalDuration(tracks []Track) (total time.Duration, unknown int) {
+	for i := range tracks {
+		if tracks[i].Duration <= 0 {
+			unknown++
+
+			continue
+		}
+
+		total += tracks[i].Duration
+	}
+
+	return total, unknown
+}
+
+// TotalDurationLabel formats the total length of tracks, noting tracks of unknown length (e.g. "1:02:03 (2 unknown)")
+func TotalDurationLabel(tracks []Track) string {
+	total, unknown := TotalDuration(tracks)
+	if unknown > 0 {
+		return fmt.Sprintf("%s (%d unknown)", FormatDuration(total), unknown)
+	}
+
+	return FormatDuration(total)
+}
+
 // String returns a formatted string representation of the track
 func (t *Track) String() string {
 	return fmt.Sprintf("%-30s - Key: %-3s Energy: %d BPM: %.0f", t.Artist, t.Key, t.Energy, t.BPM)
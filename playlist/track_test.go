@@ -0,0 +1,147 @@
+// ABOUTME: Tests for per-position track flags
+// ABOUTME: Validates sticky and derived flags and their display markers
+
+package playlist
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPositionFlags(t *testing.T) {
+	tracks := []Track{
+		{Path: "a.mp3"},
+		{Path: "b.mp3", EnergyEstimated: true},
+		{Path: "c.mp3", EnergyEstimated: true},
+	}
+
+	flags := PositionFlags(tracks, map[string]TrackFlags{"c.mp3": FlagLocked})
+
+	if flags[0] != 0 {
+		t.Errorf("Expected no flags for a.mp3, got %v", flags[0])
+	}
+
+	if !flags[1].Has(FlagEstimated) || flags[1].Has(FlagLocked) {
+		t.Errorf("Expected only FlagEstimated for b.mp3, got %v", flags[1])
+	}
+
+	if !flags[2].Has(FlagLocked | FlagEstimated) {
+		t.Errorf("Expected FlagLocked and FlagEstimated for c.mp3, got %v", flags[2])
+	}
+
+	markers := flags[0].Marker() + flags[1].Marker() + flags[2].Marker()
+	if markers != " ~L" {
+		t.Errorf("Expected markers %q, got %q", " ~L", markers)
+	}
+}
+
+func TestExtractDuration(t *testing.T) {
+	tests := []struct {
+		raw  map[string]interface{}
+		want time.Duration
+	}{
+		{map[string]interface{}{"TLEN": "245000"}, 245 * time.Second},
+		{map[string]interface{}{"TLEN": " 1500 "}, 1500 * time.Millisecond},
+		{map[string]interface{}{"TLEN": "n/a"}, 0},
+		{map[string]interface{}{"TLEN": 245000}, 0},
+		{nil, 0},
+	}
+
+	for _, tt := range tests {
+		if got := extractDuration(tt.raw); got != tt.want {
+			t.Errorf("extractDuration(%v) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestDurationLabels(t *testing.T) {
+	tracks := []Track{
+		{Duration: 3*time.Minute + 5*time.Second},
+		{Duration: 59*time.Minute + 30*time.Second},
+		{},
+	}
+
+	if got := tracks[0].DurationLabel(); got != "3:05" {
+		t.Errorf("Expected 3:05, got %q", got)
+	}
+
+	if got := tracks[2].DurationLabel(); got != "-" {
+		t.Errorf("Expected - for unknown length, got %q", got)
+	}
+
+	if got := TotalDurationLabel(tracks); got != "1:02:35 (1 unknown)" {
+		t.Errorf("Expected total 1:02:35 (1 unknown), got %q", got)
+	}
+
+	if got := TotalDurationLabel(tracks[:2]); got != "1:02:35" {
+		t.Errorf("Expected total 1:02:35, got %q", got)
+	}
+}
+
+func TestReindexAndCheckIndices(t *testing.T) {
+	tracks := []Track{{Path: "a", Index: 0}, {Path: "b", Index: 1}, {Path: "c", Index: 2}}
+
+	// Deleting "a" leaves indices 1 and 2, and 2 is out of range for a rebuilt 2-track cache
+	edited := append([]Track{}, tracks[1:]...)
+	if err := CheckIndices(edited, len(edited)); err == nil {
+		t.Error("Expected stale indices after a delete to be rejected")
+	}
+
+	// Inserting a copy of an index-0 track duplicates index 0
+	if err := CheckIndices(append(tracks, Track{Path: "d"}), 4); err == nil {
+		t.Error("Expected a duplicate index to be rejected")
+	}
+
+	ReindexTracks(edited)
+
+	if err := CheckIndices(edited, len(edited)); err != nil {
+		t.Errorf("Expected reindexed tracks to pass, got %v", err)
+	}
+
+	if edited[0].Path != "b" || edited[0].Index != 0 || edited[1].Index != 1 {
+		t.Errorf("Expected reindexing to number tracks by position, got %+v", edited)
+	}
+}
+
+// TestAssignIDs verifies each entry gets a distinct ID, repeated files are counted and existing IDs kept
+func TestAssignIDs(t *testing.T) {
+	tracks := []Track{
+		{Path: "a.mp3"},
+		{Path: "sub/../a.mp3"}, // Same file through a different relative path
+		{Path: "b.mp3", ID: "kept"},
+		{Path: "/music/c.mp3"},
+		{Path: "b.mp3", ID: "kept"}, // Copied entry (e.g. resolved twice from undo history)
+	}
+
+	AssignIDs(tracks, "/music")
+
+	want := []string{"/music/a.mp3", "/music/a.mp3#2", "kept", "/music/c.mp3", "/music/b.mp3"}
+	for i := range tracks {
+		if tracks[i].ID != want[i] {
+			t.Errorf("Track %d: expected ID %q, got %q", i, want[i], tracks[i].ID)
+		}
+	}
+
+	if id := (&Track{Path: "x.mp3"}).Identity(); id != "x.mp3" {
+		t.Errorf("Expected Identity to fall back to the path, got %q", id)
+	}
+}
+
+func TestRebasePath(t *testing.T) {
+	tests := []struct {
+		path, from, to string
+		want           string
+	}{
+		{"house/a.mp3", "/music/sets", "/music/sets", "house/a.mp3"},
+		{"house/a.mp3", "/music/sets", "/music/crates", "../sets/house/a.mp3"},
+		{"../a.mp3", "/music/sets", "/music", "a.mp3"},
+		{"/music/a.mp3", "/music/sets", "/elsewhere", "/music/a.mp3"}, // Absolute entries stay absolute
+	}
+
+	for _, tt := range tests {
+		if got := RebasePath(tt.path, tt.from, tt.to); got != filepath.FromSlash(tt.want) {
+			t.Errorf("RebasePath(%q, %q, %q) = %q, want %q", tt.path, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Picks a built-in weight preset from playlist characteristics when none was chosen
+// ABOUTME: Looks at the dominant genre family, BPM spread and size, and explains the choice
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"playlist-sorter/playlist"
+)
+
+// Auto-profile thresholds
+const (
+	autoProfileMinTracks    = 10   // Fewer tracks than this say too little about the playlist
+	autoProfileFamilyShare  = 0.6  // Share of genre-tagged tracks a family needs to dominate
+	autoProfileClubBPMRange = 12.0 // Widest BPM range (10th to 90th percentile) of a club set
+	autoProfileEclecticMin  = 5    // Distinct genre families of an eclectic listening mix
+	autoProfileEclecticMax  = 0.4  // Largest family share of an eclectic mix
+)
+
+// playlistProfile summarizes the characteristics auto-profile selection looks at
+type playlistProfile struct {
+	Tracks      int
+	Family      string  // Most common genre family (e.g. "drum and bass"; "" without genres)
+	FamilyShare float64 // Share of genre-tagged tracks in Family
+	Families    int     // Distinct genre families
+	Electronic  bool    // Family belongs to electronic music
+	BPMLow      float64 // 10th percentile BPM (0 without BPMs)
+	BPMHigh     float64 // 90th percentile BPM
+}
+
+// genreFamily returns the family of a genre: the ancestor just below its root ("liquid" -> "drum and bass"),
+// or the genre itself when it has no parent. The second result reports whether the root is electronic.
+func genreFamily(genre string) (string, bool) {
+	chain := playlist.GenreAncestors(genre)
+	if len(chain) == 0 {
+		return "", false
+	}
+
+	root := chain[len(chain)-1]
+	if len(chain) == 1 {
+		return root, root == "electronic"
+	}
+
+	return chain[len(chain)-2], root == "electronic"
+}
+
+// profilePlaylist measures tracks' characteristics
+func profilePlaylist(tracks []playlist.Track) playlistProfile {
+	p := playlistProfile{Tracks: len(tracks)}
+
+	counts := make(map[string]int)
+	electronic := make(map[string]bool)
+	tagged := 0
+
+	var bpms []float64
+
+	for _, t := range tracks {
+		if family, isElectronic := genreFamily(t.Genre); family != "" {
+			counts[family]++
+			electronic[family] = isElectronic
+			tagged++
+		}
+
+		if t.BPM > 0 {
+			bpms = append(bpms, t.BPM)
+		}
+	}
+
+	p.Families = len(counts)
+
+	for family, n := range counts {
+		// Ties go to the alphabetically first family, so the choice doesn't depend on map order
+		if n > counts[p.Family] || (n == counts[p.Family] && family < p.Family) {
+			p.Family = family
+		}
+	}
+
+	if tagged > 0 {
+		p.FamilyShare = float64(counts[p.Family]) / float64(tagged)
+		p.Electronic = electronic[p.Family]
+	}
+
+	if len(bpms) > 0 {
+		slices.SortFunc(bpms, cmp.Compare[float64])
+		p.BPMLow = bpms[len(bpms)/10]
+		p.BPMHigh = bpms[len(bpms)*9/10]
+	}
+
+	return p
+}
+
+// preset returns the built-in preset suiting the profile and why, or "" when none clearly does
+func (p playlistProfile) preset() (name, reason string) {
+	share := fmt.Sprintf("%.0f%% %s", p.FamilyShare*100, p.Family)
+
+	switch {
+	case p.Tracks < autoProfileMinTracks:
+		return "", ""
+	case p.Family == "drum and bass" && p.FamilyShare >= autoProfileFamilyShare:
+		return "drum-and-bass", share
+	case p.Electronic && p.FamilyShare >= autoProfileFamilyShare && p.BPMHigh > 0 && p.BPMHigh-p.BPMLow <= autoProfileClubBPMRange:
+		return "club-peak", fmt.Sprintf("%s, BPM %.0f-%.0f", share, p.BPMLow, p.BPMHigh)
+	case p.Families >= autoProfileEclecticMin && p.FamilyShare <= autoProfileEclecticMax:
+		return "artist-spread", fmt.Sprintf("%d genre families, none above %.0f%%", p.Families, p.FamilyShare*100)
+	}
+
+	return "", ""
+}
@@ -0,0 +1,107 @@
+// ABOUTME: Tests for auto-profile selection
+// ABOUTME: Checks which preset playlists of typical shapes get and that small ones get none
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// profileTracks returns n tracks cycling through genres, with BPMs from bpm upwards in steps of step
+func profileTracks(n int, genres []string, bpm, step float64) []playlist.Track {
+	tracks := make([]playlist.Track, n)
+	for i := range tracks {
+		tracks[i] = playlist.Track{Genre: genres[i%len(genres)], BPM: bpm + float64(i)*step}
+	}
+
+	return tracks
+}
+
+func TestAutoProfilePreset(t *testing.T) {
+	tests := []struct {
+		name   string
+		tracks []playlist.Track
+		want   string
+	}{
+		{"drum and bass", profileTracks(20, []string{"DJ Drum and Bass - Liquid", "Jungle", "Drum and Bass"}, 170, 0.3), "drum-and-bass"},
+		{"house set", profileTracks(20, []string{"House", "Progressive House", "DJ House"}, 122, 0.3), "club-peak"},
+		{"house with wide tempo", profileTracks(20, []string{"House"}, 90, 3), ""},
+		{"eclectic mix", profileTracks(20, []string{"Rock", "Jazz", "Pop", "Hip Hop", "Techno"}, 80, 4), "artist-spread"},
+		{"too small", profileTracks(5, []string{"Drum and Bass"}, 174, 0), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, reason := profilePlaylist(tt.tracks).preset()
+			if name != tt.want {
+				t.Fatalf("Expected preset %q, got %q (%s)", tt.want, name, reason)
+			}
+
+			if name != "" && reason == "" {
+				t.Error("Expected the choice to be explained")
+			}
+		})
+	}
+}
+
+func TestInitializePlaylistAutoProfileIsOptIn(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+
+	var lines []string
+
+	for i := range 12 {
+		name := fmt.Sprintf("track%d.mp3", i)
+		writeTagOnlyMP3(t, filepath.Join(dir, name), fmt.Sprintf("Artist %d", i), "8A", 6, 172, "Drum & Bass")
+		lines = append(lines, name)
+	}
+
+	path := filepath.Join(dir, "set.m3u8")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts PlaylistOptions
+		want config.GAConfig
+	}{
+		{"off by default", PlaylistOptions{Path: path}, config.DefaultConfig()},
+		{"on", PlaylistOptions{Path: path, AutoProfile: true}, mustPreset(t, "drum-and-bass")},
+		{"explicit preset wins", PlaylistOptions{Path: path, AutoProfile: true, Preset: "energy-flow"}, mustPreset(t, "energy-flow")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := InitializePlaylist(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(data.Config, tt.want) {
+				t.Errorf("Expected weights %+v, got %+v", tt.want, data.Config)
+			}
+		})
+	}
+}
+
+// mustPreset returns the default config with a built-in preset applied
+func mustPreset(t *testing.T, name string) config.GAConfig {
+	t.Helper()
+
+	cfg, err := config.ApplyPreset(config.DefaultConfig(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg
+}
@@ -0,0 +1,209 @@
+// ABOUTME: Property checks for the GA operators on randomly generated playlists and weights
+// ABOUTME: Shared by the property and fuzz tests; each check returns the first invariant violation it finds
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// propertyTolerance is the relative difference allowed between two ways of computing one fitness
+const propertyTolerance = 1e-9
+
+// propertyGenres are the genres random tracks are drawn from (related and unrelated ones, and none)
+var propertyGenres = []string{"House", "Deep House", "Techno", "Drum and Bass", "Ambient", ""}
+
+// randomTracks returns n tracks indexed 0..n-1 with random keys (some unknown or modulating), tempos,
+// energies (some unknown), artists, albums, genres, mix lengths and flags
+func randomTracks(rng *rand.Rand, n int) []playlist.Track {
+	tracks := make([]playlist.Track, n)
+
+	for i := range tracks {
+		t := playlist.Track{
+			Index:    i,
+			Path:     "track" + strconv.Itoa(i),
+			BPM:      60 + rng.Float64()*120,
+			Energy:   rng.IntN(11), // 0 = unknown
+			Artist:   "Artist" + strconv.Itoa(rng.IntN(max(n/3, 1))),
+			Album:    "Album" + strconv.Itoa(rng.IntN(max(n/2, 1))),
+			Genre:    propertyGenres[rng.IntN(len(propertyGenres))],
+			Intro:    time.Duration(rng.IntN(64)) * time.Second,
+			Outro:    time.Duration(rng.IntN(64)) * time.Second,
+			Rating:   rng.IntN(6),
+			Favorite: rng.IntN(5) == 0,
+			Fresh:    rng.IntN(4) == 0,
+		}
+
+		if rng.IntN(8) > 0 {
+			t.Key = strconv.Itoa(1+rng.IntN(12)) + "AB"[rng.IntN(2):][:1]
+			t.ParsedKey, _ = playlist.ParseCamelotKey(t.Key)
+		}
+
+		if t.ParsedKey != nil && rng.IntN(6) == 0 {
+			t.EndKey = &playlist.CamelotKey{Number: 1 + (t.ParsedKey.Number % 12), Letter: t.ParsedKey.Letter}
+		}
+
+		tracks[i] = t
+	}
+
+	return tracks
+}
+
+// randomConfig returns the default config with every fitness weight, and the settings they depend
+// on, drawn at random (some components switched off)
+func randomConfig(rng *rand.Rand) config.GAConfig {
+	cfg := config.DefaultConfig()
+
+	weight := func(scale float64) float64 {
+		if rng.IntN(4) == 0 {
+			return 0
+		}
+
+		return rng.Float64() * scale
+	}
+
+	cfg.HarmonicWeight = weight(2)
+	cfg.SameArtistPenalty = weight(2)
+	cfg.SameAlbumPenalty = weight(2)
+	cfg.EnergyDeltaWeight = weight(2)
+	cfg.BPMDeltaWeight = weight(2)
+	cfg.GenreWeight = weight(2) - 1
+	cfg.GenreBlocks = rng.IntN(2) == 0
+	cfg.LowEnergyBiasPortion = rng.Float64() * 0.5
+	cfg.LowEnergyBiasWeight = weight(1)
+	cfg.NoveltyWeight = weight(1)
+	cfg.EnergyWaveWeight = weight(1)
+	cfg.EnergyWaveAmplitude = rng.Float64() * 3
+	cfg.EnergyWavePeriod = rng.IntN(12)
+	cfg.BPMBandWeight = weight(1)
+	cfg.BPMBandWidth = 1 + rng.Float64()*8
+	cfg.MixLengthWeight = weight(1)
+	cfg.FavoriteWeight = weight(1)
+	cfg.FreshnessWeight = weight(1)
+	cfg.FreshFront = rng.IntN(2) == 0
+	cfg.BookendWeight = weight(1)
+
+	return cfg
+}
+
+// checkPermutation returns an error unless order holds exactly the tracks of tracks (by Index)
+func checkPermutation(order, tracks []playlist.Track) error {
+	if len(order) != len(tracks) {
+		return fmt.Errorf("got %d tracks, want %d", len(order), len(tracks))
+	}
+
+	seen := make(map[int]bool, len(order))
+
+	for i, t := range order {
+		if t.Index < 0 || t.Index >= len(tracks) || tracks[t.Index].Path != t.Path {
+			return fmt.Errorf("position %d holds an unknown track %q (index %d)", i, t.Path, t.Index)
+		}
+
+		if seen[t.Index] {
+			return fmt.Errorf("position %d repeats track %q", i, t.Path)
+		}
+
+		seen[t.Index] = true
+	}
+
+	return nil
+}
+
+// closeEnough reports whether two fitness values agree up to propertyTolerance (relative)
+func closeEnough(a, b float64) bool {
+	return math.Abs(a-b) <= propertyTolerance*max(1, math.Abs(a), math.Abs(b))
+}
+
+// checkGAProperties builds a random playlist of n tracks under a random config and checks the GA
+// operators' invariants on random orderings of it:
+//   - order crossover and both mutations always yield permutations of the playlist
+//   - 2-opt never makes an ordering worse, and keeps it a permutation
+//   - segmentFitness over the whole ordering equals calculateFitness, and the breakdown total
+//
+// Returns the first violation, describing the case so it can be reproduced from rng's seed.
+func checkGAProperties(rng *rand.Rand, n int) error {
+	if n < 2 {
+		return nil
+	}
+
+	tracks := randomTracks(rng, n)
+	cfg := randomConfig(rng)
+
+	ctx := buildEdgeFitnessCache(tracks)
+
+	// Some runs compare against a previous output for the novelty component
+	if rng.IntN(2) == 0 {
+		previous := shuffled(rng, tracks)
+		markPreviousAdjacencies(ctx, tracks, trackPaths(previous))
+	}
+
+	updateNormalizedWeights(ctx, cfg)
+
+	parent1, parent2 := shuffled(rng, tracks), shuffled(rng, tracks)
+	present := make([]bool, n)
+
+	for range 4 {
+		child := make([]playlist.Track, n)
+		orderCrossover(child, parent1, parent2, present, rng)
+
+		if err := checkPermutation(child, tracks); err != nil {
+			return fmt.Errorf("order crossover (%d tracks): %w", n, err)
+		}
+
+		mutate(child, rng)
+
+		if err := checkPermutation(child, tracks); err != nil {
+			return fmt.Errorf("mutation (%d tracks): %w", n, err)
+		}
+
+		whole := segmentFitness(child, 0, n-1, cfg, ctx)
+		fitness := calculateFitness(child, cfg, ctx)
+		breakdown := calculateFitnessWithBreakdown(child, cfg, ctx)
+
+		if !closeEnough(whole, fitness) || !closeEnough(breakdown.Total, fitness) {
+			return fmt.Errorf("fitness (%d tracks): segmentFitness(0, n-1) = %.12f, breakdown total %.12f, calculateFitness = %.12f",
+				n, whole, breakdown.Total, fitness)
+		}
+
+		twoOptImprove(child, make([]bool, n), time.Time{}, cfg, ctx)
+
+		if err := checkPermutation(child, tracks); err != nil {
+			return fmt.Errorf("2-opt (%d tracks): %w", n, err)
+		}
+
+		if improved := calculateFitness(child, cfg, ctx); improved > fitness && !closeEnough(improved, fitness) {
+			return fmt.Errorf("2-opt (%d tracks) made the ordering worse: %.12f -> %.12f", n, fitness, improved)
+		}
+
+		parent1, parent2 = parent2, child
+	}
+
+	return nil
+}
+
+// shuffled returns a random ordering of tracks
+func shuffled(rng *rand.Rand, tracks []playlist.Track) []playlist.Track {
+	order := make([]playlist.Track, len(tracks))
+	for i, j := range rng.Perm(len(tracks)) {
+		order[i] = tracks[j]
+	}
+
+	return order
+}
+
+// trackPaths lists the tracks' paths in order
+func trackPaths(tracks []playlist.Track) []string {
+	paths := make([]string, len(tracks))
+	for i := range tracks {
+		paths[i] = tracks[i].Path
+	}
+
+	return paths
+}
@@ -0,0 +1,56 @@
+// ABOUTME: Property and fuzz tests for the GA operators
+// ABOUTME: Runs checkGAProperties over many random playlists; `go test -fuzz FuzzGAProperties` explores further
+
+package main
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func TestGAProperties(t *testing.T) {
+	for seed := range uint64(100) {
+		rng := rand.New(rand.NewPCG(seed, seed))
+		n := 2 + rng.IntN(30)
+
+		if err := checkGAProperties(rng, n); err != nil {
+			t.Fatalf("Seed %d: %v", seed, err)
+		}
+	}
+}
+
+func TestCheckPermutation(t *testing.T) {
+	tracks := randomTracks(rand.New(rand.NewPCG(1, 1)), 5)
+
+	if err := checkPermutation(shuffled(rand.New(rand.NewPCG(2, 2)), tracks), tracks); err != nil {
+		t.Errorf("Expected a shuffle to be a permutation, got %v", err)
+	}
+
+	repeated := []int{0, 1, 2, 2, 4}
+	short := []int{0, 1, 2, 3}
+
+	for name, indices := range map[string][]int{"repeated track": repeated, "missing track": short} {
+		var got []playlist.Track
+		for _, i := range indices {
+			got = append(got, tracks[i])
+		}
+
+		if err := checkPermutation(got, tracks); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func FuzzGAProperties(f *testing.F) {
+	f.Add(uint64(1), uint8(2))
+	f.Add(uint64(7), uint8(9))
+	f.Add(uint64(42), uint8(64))
+
+	f.Fuzz(func(t *testing.T, seed uint64, n uint8) {
+		if err := checkGAProperties(rand.New(rand.NewPCG(seed, seed)), int(n)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
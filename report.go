@@ -0,0 +1,119 @@
+// ABOUTME: Markdown report of a sort run for notes and gig preparation
+// ABOUTME: Tracklist table, per-transition notes and a fitness summary written next to the output
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// reportFormatMarkdown is the only -report format
+const reportFormatMarkdown = "md"
+
+// parseReportFormat validates a -report value ("" = no report)
+func parseReportFormat(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "":
+		return "", nil
+	case reportFormatMarkdown, "markdown":
+		return reportFormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q (supported: md)", name)
+	}
+}
+
+// reportPath returns where the report of a run writing outputPath goes: next to it, as
+// <name>.report.md
+func reportPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".report.md"
+}
+
+// markdownReport renders the sorted order of a run as markdown: a summary of the result, the
+// tracklist, every transition with a short note and its weighted cost, and the fitness breakdown
+func markdownReport(name string, result sortResult, tracks []playlist.Track, breakdown playlist.Breakdown, cfg config.GAConfig, previousOrder []string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", name)
+
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "- Tracks: %d\n", len(tracks))
+	fmt.Fprintf(&b, "- Total time: %s\n", playlist.TotalDurationLabel(tracks))
+	fmt.Fprintf(&b, "- Fitness: %.6f (initial %.6f, %.1f%% better)\n", result.FinalFitness, result.InitialFitness, result.Improvement())
+	fmt.Fprintf(&b, "- Optimized for: %s\n", result.Elapsed.Round(time.Second))
+
+	if result.Skipped > 0 {
+		fmt.Fprintf(&b, "- Skipped: %d tracks with unreadable metadata\n", result.Skipped)
+	}
+
+	b.WriteString("\n## Tracklist\n\n")
+	b.WriteString(playlist.MarkdownTracklist(tracks))
+
+	b.WriteString("\n## Transitions\n\n")
+	b.WriteString("| # | From | To | Note | Cost | Flags |\n")
+	b.WriteString("|--:|------|----|------|-----:|-------|\n")
+
+	for _, t := range transitionExport(tracks, cfg, previousOrder) {
+		from, to := &tracks[t.FromPosition-1], &tracks[t.ToPosition-1]
+
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %.6f | %s |\n", t.FromPosition,
+			playlist.MarkdownCell(from.Artist+" - "+from.Title), playlist.MarkdownCell(to.Artist+" - "+to.Title),
+			transitionNote(from, to), t.Cost.Total, strings.Join(transitionFlags(t), ", "))
+	}
+
+	b.WriteString("\n## Fitness breakdown\n\n")
+	b.WriteString("| Component | Score | Raw |\n")
+	b.WriteString("|-----------|------:|-----|\n")
+
+	for _, c := range breakdownComponents(breakdown) {
+		fmt.Fprintf(&b, "| %s | %.6f | %s |\n", c.name, c.value, c.raw)
+	}
+
+	fmt.Fprintf(&b, "| **Total** | **%.6f** | |\n", breakdown.Total)
+
+	return b.String()
+}
+
+// transitionFlags names what stands out about a transition, for the report's notes
+func transitionFlags(t exportedTransition) []string {
+	var flags []string
+
+	if playlist.IsHarmonicClash(t.HarmonicDistance) {
+		flags = append(flags, "key clash")
+	}
+
+	if t.SameArtist {
+		flags = append(flags, "same artist")
+	}
+
+	if t.SameAlbum {
+		flags = append(flags, "same album")
+	}
+
+	if t.GenreDifference > 0 {
+		flags = append(flags, "genre change")
+	}
+
+	if t.PreviousAdjacent {
+		flags = append(flags, "repeated")
+	}
+
+	return flags
+}
+
+// writeMarkdownReport writes the markdown report of a run to path
+func writeMarkdownReport(path, name string, result sortResult, tracks []playlist.Track, breakdown playlist.Breakdown, cfg config.GAConfig, previousOrder []string) error {
+	report := markdownReport(name, result, tracks, breakdown, cfg, previousOrder)
+
+	if err := os.WriteFile(path, []byte(report), 0o644); err != nil { //nolint:gosec // Not sensitive
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
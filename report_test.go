@@ -0,0 +1,78 @@
+// ABOUTME: Tests for the markdown report of a sort run
+// ABOUTME: Verifies format parsing, the report path and the report's sections
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+func TestParseReportFormat(t *testing.T) {
+	for input, want := range map[string]string{"": "", "md": "md", "Markdown": "md"} {
+		if got, err := parseReportFormat(input); err != nil || got != want {
+			t.Errorf("parseReportFormat(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+
+	if _, err := parseReportFormat("html"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+
+	if got, want := reportPath("sets/friday.m3u8"), "sets/friday.report.md"; got != want {
+		t.Errorf("Expected report path %q, got %q", want, got)
+	}
+}
+
+func TestMarkdownReport(t *testing.T) {
+	cfg := config.DefaultConfig()
+	tracks := benchmarkTracks(4)
+	tracks[1].Artist = tracks[0].Artist
+
+	for i := range tracks {
+		tracks[i].Title = "Title " + tracks[i].Path
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(ctx, cfg)
+
+	breakdown := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+	result := sortResult{Tracks: 4, InitialFitness: 2 * breakdown.Total, FinalFitness: breakdown.Total, Elapsed: 3 * time.Second}
+
+	out := markdownReport("set.m3u8", result, tracks, breakdown, cfg, nil)
+
+	for _, want := range []string{
+		"# set.m3u8",
+		"- Tracks: 4",
+		"50.0% better",
+		"## Tracklist",
+		"| 4 | " + tracks[3].Artist + " | " + tracks[3].Title + " |",
+		"## Transitions",
+		"| 1 | " + tracks[0].Artist + " - " + tracks[0].Title + " | ",
+		"same artist",
+		"| 3 | ",
+		"## Fitness breakdown",
+		"| Harmonic | ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in report:\n%s", want, out)
+		}
+	}
+
+	if got := strings.Count(out, transitionNote(&tracks[1], &tracks[2])); got == 0 {
+		t.Errorf("Expected the transition note %q in the report:\n%s", transitionNote(&tracks[1], &tracks[2]), out)
+	}
+
+	if strings.Contains(out, "Skipped") {
+		t.Errorf("Expected no skipped line without skipped tracks:\n%s", out)
+	}
+
+	empty := markdownReport("empty", sortResult{}, []playlist.Track{}, playlist.Breakdown{}, cfg, nil)
+	if !strings.Contains(empty, "## Transitions") {
+		t.Errorf("Expected an empty playlist to still render:\n%s", empty)
+	}
+}
@@ -0,0 +1,247 @@
+// ABOUTME: Selftest subcommand scoring bundled fixture playlists against golden fitness breakdowns
+// ABOUTME: Lets users check a new build scores exactly like the last one before trusting it with their playlists
+
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"math/rand/v2"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+const defaultSelftestProperties = 100 // Default number of random playlists checked with checkGAProperties
+
+//go:embed selftest/*.json
+var selftestFiles embed.FS
+
+// selftestFixture is a playlist with metadata inline, scored under the default config (optionally with a
+// preset applied), and the breakdown its current order scored when the fixture was last updated
+type selftestFixture struct {
+	Description string             `json:"description"`
+	Preset      string             `json:"preset,omitempty"`
+	Tracks      []selftestTrack    `json:"tracks"`
+	Breakdown   playlist.Breakdown `json:"breakdown"`
+}
+
+// selftestTrack holds the metadata of one fixture track (durations in seconds)
+type selftestTrack struct {
+	Path     string  `json:"path"`
+	Artist   string  `json:"artist,omitempty"`
+	Album    string  `json:"album,omitempty"`
+	Key      string  `json:"key,omitempty"`
+	EndKey   string  `json:"end_key,omitempty"`
+	Genre    string  `json:"genre,omitempty"`
+	Energy   int     `json:"energy,omitempty"`
+	BPM      float64 `json:"bpm,omitempty"`
+	Intro    float64 `json:"intro,omitempty"`
+	Outro    float64 `json:"outro,omitempty"`
+	Favorite bool    `json:"favorite,omitempty"`
+	Fresh    bool    `json:"fresh,omitempty"`
+}
+
+// track converts the fixture metadata to a track at index i
+func (t selftestTrack) track(i int) (playlist.Track, error) {
+	track := playlist.Track{
+		Index:    i,
+		Path:     t.Path,
+		Key:      t.Key,
+		Artist:   t.Artist,
+		Album:    t.Album,
+		Genre:    t.Genre,
+		Energy:   t.Energy,
+		BPM:      t.BPM,
+		Intro:    time.Duration(t.Intro * float64(time.Second)),
+		Outro:    time.Duration(t.Outro * float64(time.Second)),
+		Favorite: t.Favorite,
+		Fresh:    t.Fresh,
+	}
+
+	if t.Key != "" {
+		key, err := playlist.ParseCamelotKey(t.Key)
+		if err != nil {
+			return track, fmt.Errorf("track %s: %w", t.Path, err)
+		}
+
+		track.ParsedKey = key
+	}
+
+	if t.EndKey != "" {
+		key, err := playlist.ParseCamelotKey(t.EndKey)
+		if err != nil {
+			return track, fmt.Errorf("track %s: %w", t.Path, err)
+		}
+
+		track.EndKey = key
+	}
+
+	return track, nil
+}
+
+// selftestFixtures returns the bundled fixtures by name
+func selftestFixtures() (map[string]selftestFixture, error) {
+	entries, err := fs.ReadDir(selftestFiles, "selftest")
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make(map[string]selftestFixture, len(entries))
+
+	for _, e := range entries {
+		data, err := selftestFiles.ReadFile(path.Join("selftest", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var fixture selftestFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", e.Name(), err)
+		}
+
+		fixtures[strings.TrimSuffix(e.Name(), ".json")] = fixture
+	}
+
+	return fixtures, nil
+}
+
+// score returns the breakdown of the fixture's order, checking that the edge-cached fitness agrees with it
+func (f selftestFixture) score() (playlist.Breakdown, error) {
+	cfg := config.DefaultConfig()
+
+	if f.Preset != "" {
+		var err error
+		if cfg, err = config.ApplyPreset(cfg, f.Preset); err != nil {
+			return playlist.Breakdown{}, err
+		}
+	}
+
+	tracks := make([]playlist.Track, len(f.Tracks))
+
+	for i, t := range f.Tracks {
+		var err error
+		if tracks[i], err = t.track(i); err != nil {
+			return playlist.Breakdown{}, err
+		}
+	}
+
+	ctx := buildEdgeFitnessCache(tracks)
+	updateNormalizedWeights(ctx, cfg)
+
+	breakdown := calculateFitnessWithBreakdown(tracks, cfg, ctx)
+
+	if fitness := calculateFitness(tracks, cfg, ctx); !closeEnough(fitness, breakdown.Total) {
+		return breakdown, fmt.Errorf("fitness %.12f disagrees with the breakdown total %.12f", fitness, breakdown.Total)
+	}
+
+	return breakdown, nil
+}
+
+// breakdownDiff lists the breakdown fields (by JSON name) where got differs from want beyond
+// propertyTolerance, as "field: got X, want Y"
+func breakdownDiff(got, want playlist.Breakdown) []string {
+	var diffs []string
+
+	gv, wv := reflect.ValueOf(got), reflect.ValueOf(want)
+
+	for i := range gv.NumField() {
+		name, _, _ := strings.Cut(gv.Type().Field(i).Tag.Get("json"), ",")
+		g, w := gv.Field(i), wv.Field(i)
+
+		switch g.Kind() {
+		case reflect.Float64:
+			if !closeEnough(g.Float(), w.Float()) {
+				diffs = append(diffs, fmt.Sprintf("%s: got %.12f, want %.12f", name, g.Float(), w.Float()))
+			}
+		case reflect.Int:
+			if g.Int() != w.Int() {
+				diffs = append(diffs, fmt.Sprintf("%s: got %d, want %d", name, g.Int(), w.Int()))
+			}
+		}
+	}
+
+	return diffs
+}
+
+// runSelftest implements `playlist-sorter selftest [flags]`
+func runSelftest(args []string) int {
+	fs := newFlagSet("selftest", "selftest [flags]")
+	properties := fs.Int("properties", defaultSelftestProperties, "random playlists to check the GA operators on (0 = fixtures only)")
+	seed := fs.Uint64("seed", 1, "seed of the first random playlist")
+
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+
+	fixtures, err := selftestFixtures()
+	if err != nil {
+		log.Printf("Selftest error: %v", err)
+
+		return 1
+	}
+
+	failed := 0
+
+	for _, name := range slices.Sorted(maps.Keys(fixtures)) {
+		fixture := fixtures[name]
+
+		got, err := fixture.score()
+		if err == nil {
+			if diffs := breakdownDiff(got, fixture.Breakdown); len(diffs) > 0 {
+				err = fmt.Errorf("breakdown differs from golden values:\n    %s", strings.Join(diffs, "\n    "))
+			}
+		}
+
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", name, err)
+
+			failed++
+
+			continue
+		}
+
+		fmt.Printf("ok   %s (%d tracks, fitness %.6f)\n", name, len(fixture.Tracks), got.Total)
+	}
+
+	if *properties > 0 {
+		if err := selftestProperties(*seed, *properties); err != nil {
+			fmt.Printf("FAIL properties: %v\n", err)
+
+			failed++
+		} else {
+			fmt.Printf("ok   properties (%d random playlists from seed %d)\n", *properties, *seed)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d check(s) failed: do not trust this build with your playlists\n", failed)
+
+		return 1
+	}
+
+	return 0
+}
+
+// selftestProperties runs checkGAProperties on n random playlists seeded from seed on, returning the
+// first violation (later seeds usually fail the same way)
+func selftestProperties(seed uint64, n int) error {
+	for s := seed; s < seed+uint64(n); s++ {
+		rng := rand.New(rand.NewPCG(s, s))
+
+		if err := checkGAProperties(rng, 2+rng.IntN(30)); err != nil {
+			return fmt.Errorf("seed %d: %w", s, err)
+		}
+	}
+
+	return nil
+}
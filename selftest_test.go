@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the selftest subcommand and its bundled fixtures
+// ABOUTME: `go test -run TestSelftestFixtures -update` rewrites the golden breakdowns after an intended scoring change
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the selftest fixtures' golden breakdowns")
+
+func TestSelftestFixtures(t *testing.T) {
+	fixtures, err := selftestFixtures()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fixtures) == 0 {
+		t.Fatal("Expected bundled fixtures")
+	}
+
+	for name, fixture := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			got, err := fixture.score()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *updateGolden {
+				fixture.Breakdown = got
+
+				data, err := json.MarshalIndent(fixture, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := os.WriteFile(filepath.Join("selftest", name+".json"), append(data, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			if diffs := breakdownDiff(got, fixture.Breakdown); len(diffs) > 0 {
+				t.Errorf("Breakdown differs from golden values (rerun with -update if intended):\n%s", strings.Join(diffs, "\n"))
+			}
+		})
+	}
+}
+
+func TestBreakdownDiff(t *testing.T) {
+	want := mustSelftestScore(t, "house-set")
+
+	if diffs := breakdownDiff(want, want); len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %v", diffs)
+	}
+
+	got := want
+	got.Harmonic += 1e-3
+	got.SameArtistAdjacencies++
+
+	diffs := breakdownDiff(got, want)
+	if len(diffs) != 2 || !strings.HasPrefix(diffs[0], "harmonic: ") || !strings.HasPrefix(diffs[1], "same_artist_adjacencies: ") {
+		t.Errorf("Expected harmonic and same_artist_adjacencies reported, got %v", diffs)
+	}
+}
+
+func TestRunSelftest(t *testing.T) {
+	if code := runSelftest([]string{"-properties", "5"}); code != 0 {
+		t.Errorf("Expected exit code 0, got %d", code)
+	}
+
+	if code := runSelftest([]string{"-unknown"}); code != 2 {
+		t.Errorf("Expected exit code 2 for a bad flag, got %d", code)
+	}
+}
+
+// mustSelftestScore returns the breakdown of the named bundled fixture
+func mustSelftestScore(t *testing.T, name string) playlist.Breakdown {
+	t.Helper()
+
+	fixtures, err := selftestFixtures()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := fixtures[name].score()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
@@ -0,0 +1,302 @@
+// ABOUTME: Serve subcommand exposing sorting and analysis over a small JSON REST API
+// ABOUTME: Playlists are referenced by path on the server's filesystem; binds to localhost by default
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"playlist-sorter/playlist"
+)
+
+// Server defaults
+const (
+	defaultServeAddr     = "127.0.0.1:8080"
+	defaultSortDuration  = 30 * time.Second // Optimizer run time when a sort request doesn't specify one
+	serverReadHeaderTime = 10 * time.Second
+)
+
+// sortRequest is the body of POST /api/sort
+type sortRequest struct {
+	Playlist string `json:"playlist"`           // Path to the playlist on the server
+	Duration string `json:"duration,omitempty"` // Optimizer run time, e.g. "30s" (max 5m)
+	Output   string `json:"output,omitempty"`   // Write the result here (empty = don't write)
+}
+
+// playlistResponse is returned by the sort and analyze endpoints
+type playlistResponse struct {
+	Playlist  string             `json:"playlist"`
+	Tracks    []string           `json:"tracks"`
+	Fitness   float64            `json:"fitness"`
+	Breakdown playlist.Breakdown `json:"breakdown"`
+	Written   string             `json:"written,omitempty"` // Output path if the result was written
+	Skipped   []string           `json:"skipped,omitempty"` // Tracks with unreadable metadata (not in tracks, kept in place when written)
+
+	// Analyze only: per-component theoretical minima and how far the breakdown is above them
+	TheoreticalMinimum *playlist.Breakdown `json:"theoretical_minimum,omitempty"`
+	Headroom           *playlist.Breakdown `json:"headroom,omitempty"`
+}
+
+// errorResponse is returned with non-2xx statuses
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// apiServer serves the REST API
+type apiServer struct {
+	maxDuration time.Duration // Upper bound for a sort request's optimizer run time
+	jobs        *jobQueue     // Background sorts (created by handler with the default concurrency if nil)
+}
+
+// handler returns the API routes
+func (s *apiServer) handler() http.Handler {
+	if s.jobs == nil {
+		s.jobs = newJobQueue(defaultJobConcurrency, serveSortJob)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/health", s.handleHealth)
+	mux.HandleFunc("GET /api/analyze", s.handleAnalyze)
+	mux.HandleFunc("POST /api/sort", s.handleSort)
+	mux.HandleFunc("POST /api/jobs", s.handleSubmitJob)
+	mux.HandleFunc("GET /api/jobs", s.handleListJobs)
+	mux.HandleFunc("GET /api/jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("DELETE /api/jobs/{id}", s.handleCancelJob)
+
+	return mux
+}
+
+// handleHealth reports that the server is up
+func (s *apiServer) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleAnalyze scores a playlist's current order: GET /api/analyze?playlist=<path>
+func (s *apiServer) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("playlist")
+	if path == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing playlist query parameter"))
+
+		return
+	}
+
+	resp, err := serveAnalyze(path)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleSort optimizes a playlist and returns the new order, optionally writing it to disk.
+// Blocks for the requested duration; cancelled if the client disconnects.
+func (s *apiServer) handleSort(w http.ResponseWriter, r *http.Request) {
+	req, duration, err := decodeSortRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), min(duration, s.maxDuration))
+	defer cancel()
+
+	resp, err := serveSort(ctx, req, nil)
+
+	var writeErr *sortWriteError
+
+	switch {
+	case errors.As(err, &writeErr):
+		writeError(w, http.StatusInternalServerError, err)
+	case err != nil:
+		writeError(w, http.StatusUnprocessableEntity, err)
+	default:
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// serveAnalyze scores a playlist's current order against its theoretical minimum
+func serveAnalyze(path string) (playlistResponse, error) {
+	data, err := InitializePlaylist(PlaylistOptions{Path: path})
+	if err != nil {
+		return playlistResponse{}, err
+	}
+
+	updateNormalizedWeights(data.GACtx, data.Config)
+
+	breakdown := calculateFitnessWithBreakdown(data.Tracks, data.Config, data.GACtx)
+	minimum := calculateTheoreticalMinimum(data.Tracks, data.Config, data.GACtx)
+	headroom := breakdownHeadroom(breakdown, minimum)
+
+	resp := newPlaylistResponse(path, data.Tracks, breakdown)
+	resp.TheoreticalMinimum, resp.Headroom = &minimum, &headroom
+	resp.Skipped = skippedPaths(data.Skipped)
+
+	return resp, nil
+}
+
+// decodeSortRequest reads and validates a sort request body, returning it with its run time
+func decodeSortRequest(r *http.Request) (sortRequest, time.Duration, error) {
+	var req sortRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, 0, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	if req.Playlist == "" {
+		return req, 0, errors.New("missing playlist")
+	}
+
+	duration := defaultSortDuration
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			return req, 0, fmt.Errorf("invalid duration %q", req.Duration)
+		}
+
+		duration = d
+	}
+
+	return req, duration, nil
+}
+
+// sortWriteError reports a sort whose result could not be written to the requested output
+type sortWriteError struct {
+	err error
+}
+
+func (e *sortWriteError) Error() string { return e.err.Error() }
+
+func (e *sortWriteError) Unwrap() error { return e.err }
+
+// serveSort optimizes req's playlist until ctx is done, sending progress to updates if non-nil,
+// and writes the result to req.Output if set. Loading errors and cancellation are returned as is,
+// write errors as a *sortWriteError; a cancelled sort writes nothing.
+func serveSort(ctx context.Context, req sortRequest, updates chan<- GAUpdate) (playlistResponse, error) {
+	data, err := InitializePlaylist(PlaylistOptions{Path: req.Playlist})
+	if err != nil {
+		return playlistResponse{}, err
+	}
+
+	sorted, err := geneticSort(ctx, data.Tracks, data.SharedConfig, updates, 0, data.GACtx)
+	if err != nil {
+		return playlistResponse{}, err
+	}
+
+	// A cancelled sort (client gone, job deleted) is dropped; running out of time is the normal end
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return playlistResponse{}, ctx.Err()
+	}
+
+	resp := newPlaylistResponse(req.Playlist, sorted, calculateFitnessWithBreakdown(sorted, data.Config, data.GACtx))
+
+	resp.Skipped = skippedPaths(data.Skipped)
+
+	if req.Output != "" {
+		if err := playlist.WritePlaylist(req.Output, playlist.WithSkipped(sorted, data.Skipped, playlist.SkippedInPlace)); err != nil {
+			return resp, &sortWriteError{err: err}
+		}
+
+		resp.Written = req.Output
+	}
+
+	return resp, nil
+}
+
+// serveSortJob runs a queued sort, which reports no progress
+func serveSortJob(ctx context.Context, req sortRequest) (playlistResponse, error) {
+	return serveSort(ctx, req, nil)
+}
+
+// newPlaylistResponse builds a response listing track paths in order
+func newPlaylistResponse(path string, tracks []playlist.Track, breakdown playlist.Breakdown) playlistResponse {
+	paths := make([]string, len(tracks))
+	for i, t := range tracks {
+		paths[i] = t.Path
+	}
+
+	return playlistResponse{Playlist: path, Tracks: paths, Fitness: breakdown.Total, Breakdown: breakdown}
+}
+
+// skippedPaths lists the paths of skipped tracks
+func skippedPaths(skipped []playlist.SkippedTrack) []string {
+	var paths []string
+	for _, s := range skipped {
+		paths = append(paths, s.Path)
+	}
+
+	return paths
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Warning: failed to write response: %v", err)
+	}
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// runServe implements `playlist-sorter serve [flags]`
+func runServe(args []string) int {
+	fs := newFlagSet("serve", "serve [flags]")
+	addr := fs.String("addr", defaultServeAddr, "listen address (the API can read and write any playlist path this user can)")
+	grpcAddr := fs.String("grpc-addr", "", "also serve the gRPC API on this address, e.g. 127.0.0.1:9090 (empty = REST only)")
+	concurrency := fs.Int("jobs", defaultJobConcurrency, "sort jobs submitted to /api/jobs that optimize at the same time (the rest wait in a queue)")
+
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+
+	errs := make(chan error, 2)
+
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			log.Printf("Serve error: %v", err)
+
+			return 1
+		}
+
+		grpcSrv := newGRPCServer(maxDuration)
+		defer grpcSrv.Stop()
+
+		fmt.Printf("Serving gRPC API on %s (PlaylistSorter: Optimize, StreamProgress, Analyze)\n", *grpcAddr)
+
+		go func() { errs <- grpcSrv.Serve(lis) }()
+	}
+
+	s := &apiServer{maxDuration: maxDuration, jobs: newJobQueue(*concurrency, serveSortJob)}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: serverReadHeaderTime,
+	}
+
+	fmt.Printf("Serving API on http://%s (GET /api/health, GET /api/analyze, POST /api/sort, /api/jobs)\n", *addr)
+
+	go func() { errs <- srv.ListenAndServe() }()
+
+	if err := <-errs; err != nil {
+		log.Printf("Serve error: %v", err)
+
+		return 1
+	}
+
+	return 0
+}
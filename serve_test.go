@@ -0,0 +1,66 @@
+// ABOUTME: Tests for the REST API served by the serve subcommand
+// ABOUTME: Verifies routing, request validation, and JSON error responses
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeHealth(t *testing.T) {
+	s := &apiServer{maxDuration: maxDuration}
+	rec := httptest.NewRecorder()
+
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", ct)
+	}
+}
+
+func TestServeRejectsBadRequests(t *testing.T) {
+	s := &apiServer{maxDuration: maxDuration}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"analyze without playlist", http.MethodGet, "/api/analyze", "", http.StatusBadRequest},
+		{"analyze missing file", http.MethodGet, "/api/analyze?playlist=/nonexistent/set.m3u8", "", http.StatusUnprocessableEntity},
+		{"sort invalid json", http.MethodPost, "/api/sort", "{", http.StatusBadRequest},
+		{"sort without playlist", http.MethodPost, "/api/sort", `{}`, http.StatusBadRequest},
+		{"sort invalid duration", http.MethodPost, "/api/sort", `{"playlist":"set.m3u8","duration":"soon"}`, http.StatusBadRequest},
+		{"sort wrong method", http.MethodGet, "/api/sort", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.handler().ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.want {
+				t.Fatalf("Expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+
+			if tt.want == http.StatusMethodNotAllowed {
+				return
+			}
+
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Error == "" {
+				t.Errorf("Expected JSON error body, got %q", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Plain text dashboard for sort -simple-ui, for terminals and tmux pipes where the TUI misbehaves
+// ABOUTME: Prints the run's state as a block of plain lines every few seconds: no alt screen, raw mode or ANSI codes
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+	"playlist-sorter/termtext"
+)
+
+const (
+	simpleUIRefreshInterval = 2 * time.Second // How often the dashboard is printed
+	simpleUIOpeningTracks   = 5               // Tracks of the best order shown on the dashboard
+)
+
+// simpleDashboard holds what the -simple-ui dashboard shows about a run
+type simpleDashboard struct {
+	Playlist       string
+	Start          time.Time
+	InitialFitness float64
+
+	Update          GAUpdate  // Latest update from the GA (zero before the first)
+	LastImprovement time.Time // When the best fitness last improved (zero before the first update)
+}
+
+// observe records an update, returning whether it improved the best fitness (the first one does)
+func (d *simpleDashboard) observe(update GAUpdate, now time.Time) bool {
+	improved := d.LastImprovement.IsZero() || hasFitnessImproved(update.BestFitness, d.Update.BestFitness, fitnessImprovementEpsilon)
+	if improved {
+		d.LastImprovement = now
+	}
+
+	d.Update = update
+
+	return improved
+}
+
+// render returns the dashboard as of now, as plain lines
+func (d *simpleDashboard) render(now time.Time) string {
+	var b strings.Builder
+
+	u := d.Update
+
+	fmt.Fprintf(&b, "=== %s - %s elapsed ===\n", d.Playlist, now.Sub(d.Start).Round(time.Second))
+
+	if d.LastImprovement.IsZero() {
+		fmt.Fprintf(&b, "Starting... (initial fitness %.6f)\n", d.InitialFitness)
+
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Generation:   %d (%.0f gen/s)\n", u.Generation, u.GenPerSec)
+
+	change := ""
+	if d.InitialFitness > 0 {
+		change = fmt.Sprintf(", %+.1f%%", (u.BestFitness-d.InitialFitness)*100/d.InitialFitness)
+	}
+
+	fmt.Fprintf(&b, "Fitness:      %.6f (initial %.6f%s)\n", u.BestFitness, d.InitialFitness, change)
+	fmt.Fprintf(&b, "Improved:     %s ago\n", now.Sub(d.LastImprovement).Round(time.Second))
+
+	if u.Optimal {
+		fmt.Fprintf(&b, "Optimal:      proven by exhaustive search over all %d! orderings\n", len(u.BestPlaylist))
+	}
+
+	var parts []string
+
+	for _, c := range breakdownComponents(u.Breakdown) {
+		if c.value != 0 {
+			parts = append(parts, fmt.Sprintf("%s %.4f", strings.ToLower(c.name), c.value))
+		}
+	}
+
+	if len(parts) > 0 {
+		fmt.Fprintf(&b, "Components:   %s\n", strings.Join(parts, ", "))
+	}
+
+	for i, t := range u.BestPlaylist[:min(simpleUIOpeningTracks, len(u.BestPlaylist))] {
+		label := "Opening:"
+		if i > 0 {
+			label = ""
+		}
+
+		fmt.Fprintf(&b, "%-13s %d. %s - %s (%s, %.0f BPM)\n", label, i+1, termtext.Fit(t.Artist, 20), t.Title, t.Key, t.BPM)
+	}
+
+	if len(u.BestPlaylist) > simpleUIOpeningTracks {
+		fmt.Fprintf(&b, "%-13s ... %d more\n", "", len(u.BestPlaylist)-simpleUIOpeningTracks)
+	}
+
+	return b.String()
+}
+
+// simpleUIGeneticSort is cliGeneticSort printing the dashboard every simpleUIRefreshInterval and once
+// at the end, instead of a line per improvement
+func simpleUIGeneticSort(ctx context.Context, tracks []playlist.Track, sharedCfg *config.SharedConfig, gaCtx *GAContext, onImprove func([]playlist.Track), out cliPrinter, dash *simpleDashboard) ([]playlist.Track, error) {
+	updates := make(chan GAUpdate, 10)
+
+	type sortResult struct {
+		tracks []playlist.Track
+		err    error
+	}
+
+	done := make(chan sortResult, 1)
+
+	go func() {
+		result, err := geneticSort(ctx, tracks, sharedCfg, updates, 0, gaCtx)
+		done <- sortResult{tracks: result, err: err}
+	}()
+
+	ticker := time.NewTicker(simpleUIRefreshInterval)
+	defer ticker.Stop()
+
+	observe := func(update GAUpdate) {
+		if dash.observe(update, time.Now()) && onImprove != nil {
+			onImprove(update.BestPlaylist)
+		}
+	}
+
+	for {
+		select {
+		case update := <-updates:
+			observe(update)
+
+		case <-ticker.C:
+			out.Println(dash.render(time.Now()))
+
+		case result := <-done:
+			// The final frame shows the last update sent before the GA returned
+			for len(updates) > 0 {
+				observe(<-updates)
+			}
+
+			out.Println(dash.render(time.Now()))
+			out.Printf("Completed %d generations in %v\n", dash.Update.Generation, time.Since(dash.Start).Round(time.Millisecond))
+
+			return result.tracks, result.err
+		}
+	}
+}
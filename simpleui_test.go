@@ -0,0 +1,82 @@
+// ABOUTME: Tests for the -simple-ui dashboard
+// ABOUTME: Checks what each frame shows and that a sort run with it ends with a final frame
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"playlist-sorter/playlist"
+)
+
+func TestSimpleDashboardRender(t *testing.T) {
+	start := time.Date(2026, 1, 1, 22, 0, 0, 0, time.UTC)
+	dash := &simpleDashboard{Playlist: "set.m3u8", Start: start, InitialFitness: 0.5}
+
+	if frame := dash.render(start.Add(time.Second)); !strings.Contains(frame, "Starting... (initial fitness 0.500000)") {
+		t.Errorf("Expected a starting frame, got:\n%s", frame)
+	}
+
+	tracks := make([]playlist.Track, 7)
+	for i := range tracks {
+		tracks[i] = playlist.Track{Artist: "Artist", Title: "Title", Key: "8A", BPM: 124}
+	}
+
+	update := GAUpdate{
+		Generation:   1200,
+		GenPerSec:    400,
+		BestFitness:  0.4,
+		BestPlaylist: tracks,
+		Breakdown:    playlist.Breakdown{Total: 0.4, Harmonic: 0.3, BPMDelta: 0.1},
+	}
+
+	if !dash.observe(update, start.Add(2*time.Second)) {
+		t.Error("Expected the first update to count as an improvement")
+	}
+
+	if dash.observe(update, start.Add(3*time.Second)) {
+		t.Error("Expected an update with the same fitness not to count as an improvement")
+	}
+
+	frame := dash.render(start.Add(12 * time.Second))
+
+	for _, want := range []string{
+		"=== set.m3u8 - 12s elapsed ===",
+		"Generation:   1200 (400 gen/s)",
+		"Fitness:      0.400000 (initial 0.500000, -20.0%)",
+		"Improved:     10s ago",
+		"Components:   harmonic 0.3000, bpm 0.1000\n",
+		"Opening:      1. Artist",
+		"  5. Artist",
+		"... 2 more",
+	} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("Expected %q in frame:\n%s", want, frame)
+		}
+	}
+
+	if strings.Contains(frame, "\033") {
+		t.Errorf("Expected no escape sequences, got:\n%q", frame)
+	}
+}
+
+func TestSortWithSimpleUI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // Keep the user's config out of the run
+
+	result, err := sortPlaylist(context.Background(), RunOptions{
+		PlaylistPath: writeTestPlaylist(t, 5),
+		DryRun:       true,
+		Duration:     time.Second,
+		SimpleUI:     true,
+	})
+	if err != nil {
+		t.Fatalf("sortPlaylist: %v", err)
+	}
+
+	if result.Tracks != 5 || result.FinalFitness > result.InitialFitness {
+		t.Errorf("Expected 5 tracks sorted to no worse fitness, got %d tracks, %v -> %v", result.Tracks, result.InitialFitness, result.FinalFitness)
+	}
+}
@@ -0,0 +1,55 @@
+// ABOUTME: Braille sparkline for the CLI status line showing the recent fitness trajectory
+// ABOUTME: Packs two samples per braille character as bars four dots high
+
+package main
+
+// sparklineSamples is how many status-line ticks of fitness history the sparkline shows
+const sparklineSamples = 24
+
+// Braille dot bits of the left and right column, bottom to top
+var (
+	brailleLeft  = [4]rune{0x40, 0x04, 0x02, 0x01}
+	brailleRight = [4]rune{0x80, 0x20, 0x10, 0x08}
+)
+
+// brailleSparkline draws values as bars scaled between their minimum and maximum, two per
+// character. A run that is still improving slopes down to the right; a flat line has stalled.
+func brailleSparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+
+	// Bar height 1-4: the minimum always shows one dot so the line stays visible when flat
+	height := func(v float64) int {
+		if hi == lo {
+			return 1
+		}
+
+		return 1 + int((v-lo)/(hi-lo)*3+0.5)
+	}
+
+	line := make([]rune, 0, (len(values)+1)/2)
+
+	for i := 0; i < len(values); i += 2 {
+		char := rune(0x2800)
+
+		for dot := range height(values[i]) {
+			char |= brailleLeft[dot]
+		}
+
+		if i+1 < len(values) {
+			for dot := range height(values[i+1]) {
+				char |= brailleRight[dot]
+			}
+		}
+
+		line = append(line, char)
+	}
+
+	return string(line)
+}
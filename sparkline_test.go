@@ -0,0 +1,27 @@
+// ABOUTME: Tests for the braille fitness sparkline
+// ABOUTME: Verifies bar heights, scaling and odd sample counts
+
+package main
+
+import "testing"
+
+func TestBrailleSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"flat", []float64{0.5, 0.5, 0.5}, "⣀⡀"},
+		{"improving", []float64{0.4, 0.3, 0.2, 0.1}, "⣷⣄"}, // Bars 4 and 3 dots high, then 2 and 1
+		{"odd count", []float64{1, 0, 1}, "⣇⡇"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := brailleSparkline(tt.values); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// ABOUTME: Writes optimized positions (and optional transition notes) back into audio file tags
+// ABOUTME: Runs a user-configured tagging command per track since the tag reader is read-only
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"playlist-sorter/playlist"
+)
+
+// Placeholders substituted in the tag write command template
+const (
+	tagPathPlaceholder     = "{path}"     // Absolute path of the audio file
+	tagPositionPlaceholder = "{position}" // 1-based position in the optimized playlist
+	tagTotalPlaceholder    = "{total}"    // Number of tracks in the playlist
+	tagNotePlaceholder     = "{note}"     // Transition note from the previous track (empty unless enabled)
+)
+
+// buildTagCommand splits the command template on whitespace and substitutes placeholders per argument,
+// so values containing spaces (paths, notes) stay a single argument.
+// The path is appended as the last argument if the template has no {path} placeholder.
+func buildTagCommand(template string, vars map[string]string) ([]string, error) {
+	fields := strings.Fields(template)
+	if len(fields) == 0 {
+		return nil, errors.New("tag write command is empty (set tag_write_command in config)")
+	}
+
+	hasPath := false
+
+	for i, field := range fields {
+		if strings.Contains(field, tagPathPlaceholder) {
+			hasPath = true
+		}
+
+		for placeholder, value := range vars {
+			field = strings.ReplaceAll(field, placeholder, value)
+		}
+
+		fields[i] = field
+	}
+
+	if !hasPath {
+		fields = append(fields, vars[tagPathPlaceholder])
+	}
+
+	return fields, nil
+}
+
+// transitionNote describes the transition from prev into cur, e.g. "8A>9A 124>126bpm E5>E6".
+// Returns "opener" for the first track.
+func transitionNote(prev, cur *playlist.Track) string {
+	if prev == nil {
+		return "opener"
+	}
+
+	return fmt.Sprintf("%s>%s %.0f>%.0fbpm E%d>E%d", keyName(prev.OutKey(), prev.Key), keyName(cur.InKey(), cur.Key),
+		prev.BPM, cur.BPM, prev.Energy, cur.Energy)
+}
+
+// keyName returns the Camelot name of key, or fallback when it couldn't be parsed
+func keyName(key *playlist.CamelotKey, fallback string) string {
+	if key == nil {
+		return fallback
+	}
+
+	return key.String()
+}
+
+// writeTags runs the tag write command for every track with its optimized position.
+// Continues past failures and returns the number of tracks written and the first error.
+func writeTags(tracks []playlist.Track, playlistPath, template string, withNotes bool) (int, error) {
+	var firstErr error
+
+	written := 0
+	total := strconv.Itoa(len(tracks))
+
+	for i := range tracks {
+		path := tracks[i].Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(playlistPath), path)
+		}
+
+		note := ""
+		if withNotes {
+			var prev *playlist.Track
+			if i > 0 {
+				prev = &tracks[i-1]
+			}
+
+			note = transitionNote(prev, &tracks[i])
+		}
+
+		args, err := buildTagCommand(template, map[string]string{
+			tagPathPlaceholder:     path,
+			tagPositionPlaceholder: strconv.Itoa(i + 1),
+			tagTotalPlaceholder:    total,
+			tagNotePlaceholder:     note,
+		})
+		if err != nil {
+			return written, err
+		}
+
+		cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // Command is user-configured by design
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("tagging %s: %w: %s", tracks[i].Path, err, strings.TrimSpace(string(out)))
+			}
+
+			continue
+		}
+
+		written++
+	}
+
+	return written, firstErr
+}
@@ -0,0 +1,65 @@
+// ABOUTME: Tests for writing optimized positions back into file tags
+// ABOUTME: Verifies command template substitution and transition note formatting
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func TestBuildTagCommand(t *testing.T) {
+	vars := map[string]string{
+		tagPathPlaceholder:     "/music/My Track.mp3",
+		tagPositionPlaceholder: "3",
+		tagTotalPlaceholder:    "12",
+		tagNotePlaceholder:     "8A>9A 124>126bpm E5>E6",
+	}
+
+	got, err := buildTagCommand("kid3-cli -c set:grouping:{position}/{total} -c set:comment:{note} {path}", vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"kid3-cli", "-c", "set:grouping:3/12", "-c", "set:comment:8A>9A 124>126bpm E5>E6", "/music/My Track.mp3"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got, err = buildTagCommand("id3v2 --TRCK {position}", vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want = []string{"id3v2", "--TRCK", "3", "/music/My Track.mp3"}
+	if !slices.Equal(got, want) {
+		t.Errorf("path should be appended without placeholder: got %q, want %q", got, want)
+	}
+
+	if _, err := buildTagCommand("", vars); err == nil {
+		t.Error("Expected error for empty command")
+	}
+}
+
+func TestTransitionNote(t *testing.T) {
+	prev := &playlist.Track{Key: "8A", BPM: 124, Energy: 5}
+	cur := &playlist.Track{Key: "9A", BPM: 126, Energy: 6}
+
+	if got := transitionNote(prev, cur); got != "8A>9A 124>126bpm E5>E6" {
+		t.Errorf("unexpected note: %s", got)
+	}
+
+	if got := transitionNote(nil, cur); got != "opener" {
+		t.Errorf("first track note = %s, want opener", got)
+	}
+
+	// Modulating tracks: the note shows the end key going out and the start key coming in
+	prev.EndKey = parseKey("10A")
+	cur.StartKey = parseKey("11A")
+
+	if got := transitionNote(prev, cur); got != "10A>11A 124>126bpm E5>E6" {
+		t.Errorf("unexpected note for modulating tracks: %s", got)
+	}
+}
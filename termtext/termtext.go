@@ -0,0 +1,33 @@
+// ABOUTME: Display-width aware string helpers for aligned terminal tables
+// ABOUTME: Truncates and pads by terminal cells so CJK, emoji and accented names keep columns aligned
+
+package termtext
+
+import "github.com/mattn/go-runewidth"
+
+const ellipsis = "..."
+
+// Width returns the number of terminal cells s occupies
+func Width(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// Truncate shortens s to at most width cells, ending with "..." if it was cut.
+// Never splits a multi-byte character.
+func Truncate(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+
+	if width <= len(ellipsis) {
+		return runewidth.Truncate(s, width, "")
+	}
+
+	return runewidth.Truncate(s, width, ellipsis)
+}
+
+// Fit truncates s to width cells and pads it with spaces to exactly width cells.
+// Use it instead of %-Ns, which pads by rune count rather than display width.
+func Fit(s string, width int) string {
+	return runewidth.FillRight(Truncate(s, width), width)
+}
@@ -0,0 +1,51 @@
+// ABOUTME: Tests for display-width aware truncation and padding
+// ABOUTME: Covers ASCII, wide (CJK/emoji) and combining characters
+
+package termtext
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{"fits", "Daft Punk", 20, "Daft Punk"},
+		{"ascii cut", "Boards of Canada", 10, "Boards ..."},
+		{"tiny width", "Boards of Canada", 3, "Boa"},
+		{"accented", "Röyksopp Forever", 8, "Röyks..."},
+		{"wide characters", "坂本龍一 Ryuichi", 8, "坂本..."},
+		{"wide char not split", "坂本龍一", 6, "坂..."},
+		{"emoji", "🔥🔥🔥🔥 Fire", 7, "🔥🔥..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.input, tt.width)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+
+			if !utf8.ValidString(got) {
+				t.Errorf("Truncate(%q, %d) produced invalid UTF-8", tt.input, tt.width)
+			}
+
+			if Width(got) > tt.width {
+				t.Errorf("Truncate(%q, %d) is %d cells wide", tt.input, tt.width, Width(got))
+			}
+		})
+	}
+}
+
+func TestFit(t *testing.T) {
+	for _, s := range []string{"", "Daft Punk", "Röyksopp", "坂本龍一", "🔥 Fire", "A very long artist name indeed"} {
+		if got := Width(Fit(s, 12)); got != 12 {
+			t.Errorf("Fit(%q, 12) is %d cells wide, want 12", s, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Optimizer intensity throttle so sorting can run in the background
+// ABOUTME: Caps the GA worker pool and optionally pauses between generations (toggled live from the TUI)
+
+package main
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// gaThrottle limits optimizer CPU use. Safe for concurrent use: the TUI toggles nice mode while the GA runs.
+type gaThrottle struct {
+	threads atomic.Int32 // GA worker goroutines (0 = one per CPU); read when a run starts
+	nice    atomic.Bool  // Pause after each generation for as long as it took (about half the CPU time)
+}
+
+// throttle is the process-wide optimizer throttle set by -threads/-nice
+var throttle gaThrottle
+
+// set applies the -threads and -nice flags
+func (t *gaThrottle) set(threads int, nice bool) {
+	t.threads.Store(int32(min(max(threads, 0), runtime.NumCPU()))) //nolint:gosec // Clamped to the CPU count
+	t.nice.Store(nice)
+}
+
+// workers returns the worker pool size: the -threads setting, capped at the CPU count
+func (t *gaThrottle) workers() int {
+	n := int(t.threads.Load())
+	if n <= 0 || n > runtime.NumCPU() {
+		return runtime.NumCPU()
+	}
+
+	return n
+}
+
+// toggleNice flips nice mode and returns the new state
+func (t *gaThrottle) toggleNice() bool {
+	for {
+		old := t.nice.Load()
+		if t.nice.CompareAndSwap(old, !old) {
+			return !old
+		}
+	}
+}
+
+// pause sleeps after a generation that took genTime when nice mode is on. Returns early if ctx is cancelled.
+func (t *gaThrottle) pause(ctx context.Context, genTime time.Duration) {
+	if !t.nice.Load() || genTime <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(genTime)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,68 @@
+// ABOUTME: Tests for the optimizer intensity throttle
+// ABOUTME: Verifies worker count clamping, nice toggling and the pause between generations
+
+package main
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestThrottleWorkers(t *testing.T) {
+	var th gaThrottle
+
+	if got := th.workers(); got != runtime.NumCPU() {
+		t.Errorf("Expected all %d CPUs by default, got %d", runtime.NumCPU(), got)
+	}
+
+	th.set(1, false)
+
+	if got := th.workers(); got != 1 {
+		t.Errorf("Expected 1 worker, got %d", got)
+	}
+
+	th.set(runtime.NumCPU()+8, false)
+
+	if got := th.workers(); got != runtime.NumCPU() {
+		t.Errorf("Expected workers capped at %d CPUs, got %d", runtime.NumCPU(), got)
+	}
+}
+
+func TestThrottlePause(t *testing.T) {
+	var th gaThrottle
+
+	start := time.Now()
+	th.pause(context.Background(), time.Second)
+
+	if time.Since(start) > 100*time.Millisecond {
+		t.Fatal("Expected no pause without nice mode")
+	}
+
+	if !th.toggleNice() {
+		t.Fatal("Expected toggle to enable nice mode")
+	}
+
+	start = time.Now()
+	th.pause(context.Background(), 20*time.Millisecond)
+
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("Expected nice mode to pause for the generation time")
+	}
+
+	// Cancellation cuts the pause short
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start = time.Now()
+	th.pause(ctx, time.Minute)
+
+	if time.Since(start) > 100*time.Millisecond {
+		t.Error("Expected a cancelled context to end the pause")
+	}
+
+	if th.toggleNice() {
+		t.Error("Expected second toggle to disable nice mode")
+	}
+}
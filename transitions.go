@@ -0,0 +1,81 @@
+// ABOUTME: Per-transition JSON export of the final ordering for downstream tooling and visualization
+// ABOUTME: Writes every adjacent pair with its raw edge data and weighted cost breakdown
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+// exportedTransition is one adjacent pair of the exported ordering
+type exportedTransition struct {
+	FromPosition     int                `json:"from_position"` // 1-based, like the track table
+	ToPosition       int                `json:"to_position"`
+	From             string             `json:"from"` // Track paths as in the playlist
+	To               string             `json:"to"`
+	HarmonicDistance int                `json:"harmonic_distance"`
+	BPMDelta         float64            `json:"bpm_delta"`
+	EnergyDelta      float64            `json:"energy_delta"`
+	GenreDifference  float64            `json:"genre_difference"` // 0.0 = same, 1.0 = different
+	SameArtist       bool               `json:"same_artist"`
+	SameAlbum        bool               `json:"same_album"`
+	PreviousAdjacent bool               `json:"previous_adjacent"` // Also adjacent in the last saved output
+	MixMismatch      float64            `json:"mix_mismatch"`
+	Cost             playlist.Breakdown `json:"cost"` // Weighted edge components under the run's config
+}
+
+// transitionExport scores every adjacent pair of tracks with the same edge data and weights the
+// optimizer uses (no shuffle jitter)
+func transitionExport(tracks []playlist.Track, cfg config.GAConfig, previousOrder []string) []exportedTransition {
+	scorer := newPathScorer(tracks, cfg, previousOrder)
+	transitions := make([]exportedTransition, 0, max(len(tracks)-1, 0))
+
+	for j := 1; j < len(tracks); j++ {
+		from, to := &tracks[j-1], &tracks[j]
+
+		edge := computeEdge(from, to)
+		edge.PreviousAdjacent = scorer.previous[[2]string{from.Path, to.Path}]
+
+		var cost playlist.Breakdown
+
+		scorer.addEdge(&cost, from, to)
+		cost.Total = cost.Sum()
+
+		transitions = append(transitions, exportedTransition{
+			FromPosition:     j,
+			ToPosition:       j + 1,
+			From:             from.Path,
+			To:               to.Path,
+			HarmonicDistance: edge.HarmonicDistance,
+			BPMDelta:         edge.BPMDelta,
+			EnergyDelta:      edge.EnergyDelta,
+			GenreDifference:  edge.GenreDifference,
+			SameArtist:       edge.SameArtist,
+			SameAlbum:        edge.SameAlbum,
+			PreviousAdjacent: edge.PreviousAdjacent,
+			MixMismatch:      edge.MixMismatch,
+			Cost:             cost,
+		})
+	}
+
+	return transitions
+}
+
+// writeTransitionExport writes the transitions of tracks as an indented JSON array to path
+func writeTransitionExport(path string, tracks []playlist.Track, cfg config.GAConfig, previousOrder []string) error {
+	data, err := json.MarshalIndent(transitionExport(tracks, cfg, previousOrder), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode transitions: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil { //nolint:gosec // Not sensitive
+		return fmt.Errorf("failed to write transitions: %w", err)
+	}
+
+	return nil
+}
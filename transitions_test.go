@@ -0,0 +1,70 @@
+// ABOUTME: Tests for the per-transition JSON export
+// ABOUTME: Verifies exported edge data and costs match the scorer and survive a JSON round trip
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+func TestTransitionExport(t *testing.T) {
+	cfg := config.DefaultConfig()
+	tracks := benchmarkTracks(8)
+	previousOrder := []string{tracks[3].Path, tracks[2].Path}
+
+	path := filepath.Join(t.TempDir(), "transitions.json")
+	if err := writeTransitionExport(path, tracks, cfg, previousOrder); err != nil {
+		t.Fatalf("writeTransitionExport: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var transitions []exportedTransition
+	if err := json.Unmarshal(data, &transitions); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+
+	if len(transitions) != len(tracks)-1 {
+		t.Fatalf("Expected %d transitions, got %d", len(tracks)-1, len(transitions))
+	}
+
+	// Edge costs plus position bias add up to the order's fitness
+	full := newPathScorer(tracks, cfg, previousOrder).breakdown(tracks)
+	sum := full.PositionBias
+
+	for i, tr := range transitions {
+		if tr.FromPosition != i+1 || tr.From != tracks[i].Path || tr.To != tracks[i+1].Path {
+			t.Errorf("Transition %d: expected %s -> %s at %d, got %s -> %s at %d",
+				i, tracks[i].Path, tracks[i+1].Path, i+1, tr.From, tr.To, tr.FromPosition)
+		}
+
+		want := computeEdge(&tracks[i], &tracks[i+1])
+		if tr.HarmonicDistance != want.HarmonicDistance || tr.BPMDelta != want.BPMDelta || tr.EnergyDelta != want.EnergyDelta {
+			t.Errorf("Transition %d: edge data %+v doesn't match %+v", i, tr, want)
+		}
+
+		if tr.PreviousAdjacent != (i == 2) {
+			t.Errorf("Transition %d: expected previous_adjacent %v", i, i == 2)
+		}
+
+		sum += tr.Cost.Total
+	}
+
+	if math.Abs(sum-full.Total) > floatingPointEpsilon {
+		t.Errorf("Transition costs + bias (%.6f) != total (%.6f)", sum, full.Total)
+	}
+
+	if got := transitionExport([]playlist.Track{tracks[0]}, cfg, nil); len(got) != 0 {
+		t.Errorf("Expected no transitions for a single track, got %d", len(got))
+	}
+}
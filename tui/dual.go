@@ -0,0 +1,216 @@
+// ABOUTME: Dual mode: two playlists side by side (e.g. main set and backup crate), each optimized independently
+// ABOUTME: Routes keys to the active playlist and GA messages to their own, and moves tracks between the two
+
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"playlist-sorter/playlist"
+)
+
+// paneMsg carries a message produced by a command of one pane back to that pane
+type paneMsg struct {
+	pane int
+	msg  tea.Msg
+}
+
+// dualModel shows two optimizer models side by side. Each keeps its own GA, undo history and saves;
+// the parameter panel is hidden, so weights are tuned in single-playlist mode.
+type dualModel struct {
+	panes  [2]model
+	active int // Pane receiving keys; the other shows its playlist panel unfocused
+
+	width  int
+	height int
+}
+
+// newDualModel puts two optimizer models side by side, the first one active
+func newDualModel(first, second model) dualModel {
+	d := dualModel{panes: [2]model{first, second}}
+
+	for i := range d.panes {
+		d.panes[i].dual = true
+		d.panes[i].focusedPanel = panelParams // Unfocused: there is no parameter panel to focus
+	}
+
+	d.panes[0].focusedPanel = panelPlaylist
+
+	return d
+}
+
+// wrapPaneCmd tags the messages of a pane's command with the pane, so they reach the model that
+// issued it (both panes produce the same message types, e.g. GA updates)
+func wrapPaneCmd(pane int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case tea.BatchMsg:
+			cmds := make([]tea.Cmd, len(msg))
+			for i, c := range msg {
+				cmds[i] = wrapPaneCmd(pane, c)
+			}
+
+			return tea.BatchMsg(cmds)
+		case tea.QuitMsg:
+			return msg
+		default:
+			return paneMsg{pane: pane, msg: msg}
+		}
+	}
+}
+
+// Init starts both panes
+func (d dualModel) Init() tea.Cmd {
+	return tea.Batch(wrapPaneCmd(0, d.panes[0].Init()), wrapPaneCmd(1, d.panes[1].Init()))
+}
+
+// forward passes a message to a pane, returning its command tagged with the pane
+func (d *dualModel) forward(pane int, msg tea.Msg) tea.Cmd {
+	next, cmd := d.panes[pane].Update(msg)
+	d.panes[pane] = next.(model)
+
+	return wrapPaneCmd(pane, cmd)
+}
+
+// Update routes messages: window sizes to both panes (half the width each), pane messages to
+// their pane and keys to the active pane, except those acting on both
+//
+//nolint:ireturn // Bubble Tea framework requires returning tea.Model interface
+func (d dualModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width, d.height = msg.Width, msg.Height
+		half := tea.WindowSizeMsg{Width: msg.Width / 2, Height: msg.Height}
+
+		return d, tea.Batch(d.forward(0, half), d.forward(1, half))
+
+	case paneMsg:
+		return d, d.forward(msg.pane, msg.msg)
+
+	case tea.KeyMsg:
+		active := &d.panes[d.active]
+
+		// An open prompt captures all keys until it is submitted or cancelled
+		if active.prompt.active() {
+			return d, d.forward(d.active, msg)
+		}
+
+		if key.Matches(msg, keys.Quit) {
+			return d.quit()
+		}
+
+		// Open lists capture navigation keys
+		if active.showSnapshots || active.showQuarantine {
+			return d, d.forward(d.active, msg)
+		}
+
+		switch {
+		case key.Matches(msg, keys.Tab):
+			d.switchPane()
+
+			return d, nil
+
+		case key.Matches(msg, keys.Move):
+			return d, d.move()
+
+		case key.Matches(msg, keys.Presets, keys.Wheel, keys.Stats, keys.Reset):
+			active.setStatusMsg("Not available side by side: tune weights with a single playlist open")
+
+			return d, nil
+		}
+
+		return d, d.forward(d.active, msg)
+	}
+
+	return d, nil
+}
+
+// switchPane makes the other pane active
+func (d *dualModel) switchPane() {
+	d.panes[d.active].focusedPanel = panelParams
+	d.active = 1 - d.active
+	d.panes[d.active].focusedPanel = panelPlaylist
+}
+
+// quit stops both panes (GA, preview, config save) and exits
+//
+//nolint:ireturn // Bubble Tea framework requires returning tea.Model interface
+func (d dualModel) quit() (tea.Model, tea.Cmd) {
+	for i := range d.panes {
+		d.panes[i].handleQuitKey()
+	}
+
+	return d, tea.Quit
+}
+
+// move moves the track at the active pane's cursor into the other playlist, after its cursor. The
+// path is rebased onto the other playlist's directory; both GAs restart and both playlists are saved.
+func (d *dualModel) move() tea.Cmd {
+	from, to := d.active, 1-d.active
+	src, dst := &d.panes[from], &d.panes[to]
+
+	if len(src.displayedTracks) == 0 {
+		return nil
+	}
+
+	track := src.displayedTracks[src.cursorPos]
+	fromDir, toDir := filepath.Dir(src.playlistPath), filepath.Dir(dst.playlistPath)
+	target := filepath.Base(dst.playlistPath)
+
+	id := playlist.EntryID(track.Path, fromDir)
+	if slices.ContainsFunc(dst.displayedTracks, func(t playlist.Track) bool { return t.Identity() == id }) {
+		src.setStatusMsg("Track already in " + target)
+
+		return nil
+	}
+
+	removed := src.deleteTrack()
+	src.setStatusMsg(fmt.Sprintf("Moved %s - %s to %s (Undo: %d, Redo: %d)", track.Artist, track.Title, target, src.undoMgr.UndoSize(), src.undoMgr.RedoSize()))
+
+	track.Path = playlist.RebasePath(track.Path, fromDir, toDir)
+	added := dst.placeTrack(track, "Moved in")
+
+	return tea.Batch(wrapPaneCmd(from, removed), wrapPaneCmd(to, added))
+}
+
+// View renders the two playlists side by side, each with its own status and breakdown line
+func (d dualModel) View() string {
+	if d.panes[0].quitting {
+		return d.panes[0].View()
+	}
+
+	paneWidth := d.width / 2
+	panelHeight := d.height - (statusBarHeight + breakdownHeight + helpHeight + 1)
+
+	panelStyle := lipgloss.NewStyle().
+		Width(paneWidth).
+		Height(panelHeight).
+		Padding(0, 1)
+
+	// Status and breakdown are cut to one line each, so both columns keep the same layout
+	lineStyle := lipgloss.NewStyle().MaxWidth(paneWidth).MaxHeight(1)
+
+	columns := make([]string, len(d.panes))
+	for i := range d.panes {
+		p := d.panes[i]
+		columns[i] = panelStyle.Render(p.renderPlaylistPanel()) + "\n" +
+			lineStyle.Render(p.renderStatus()) + "\n" +
+			lineStyle.Render(p.renderBreakdown())
+	}
+
+	help := helpStyle.Render(" Tab: switch playlist | m: move track to the other playlist | ↑/↓/j/k: navigate | a: add | d: delete | D/z: quarantine/show | e: edit tags | x: mark/swap | u: undo | ctrl+r: redo | p/P: preview/stop | s/S: snapshot/list | f: focus | R: restart GA | q: quit")
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...) + "\n" + help
+}
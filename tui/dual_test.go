@@ -0,0 +1,150 @@
+// ABOUTME: Tests for dual mode (two playlists side by side)
+// ABOUTME: Verifies message routing between the panes, moving tracks between the playlists and saving on exit
+
+package tui
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+)
+
+// createTestDualModel returns two test playlists side by side, in different directories
+func createTestDualModel() dualModel {
+	first, second := createTestModel(createTestTracks(3)), createTestModel(createTestTracks(5)[3:])
+	first.playlistPath, second.playlistPath = "/music/sets/main.m3u8", "/music/crates/backup.m3u8"
+
+	playlist.AssignIDs(first.displayedTracks, "/music/sets")
+	playlist.AssignIDs(second.displayedTracks, "/music/crates")
+
+	return newDualModel(first, second)
+}
+
+func TestWrapPaneCmd(t *testing.T) {
+	update := func() tea.Msg { return Update{Epoch: 3} }
+
+	if msg, ok := wrapPaneCmd(1, update)().(paneMsg); !ok || msg.pane != 1 || msg.msg.(Update).Epoch != 3 {
+		t.Errorf("Expected the update tagged with pane 1, got %#v", msg)
+	}
+
+	batch, ok := wrapPaneCmd(0, tea.Batch(update, update))().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Expected a batch of 2 commands, got %#v", batch)
+	}
+
+	if msg, ok := batch[0]().(paneMsg); !ok || msg.pane != 0 {
+		t.Errorf("Expected batched commands tagged too, got %#v", msg)
+	}
+
+	if _, ok := wrapPaneCmd(0, tea.Quit)().(tea.QuitMsg); !ok {
+		t.Error("Expected quit to pass through untagged")
+	}
+}
+
+func TestDualRoutesKeysAndMessages(t *testing.T) {
+	d := createTestDualModel()
+
+	next, _ := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	d = next.(dualModel)
+
+	if d.panes[0].cursorPos != 1 || d.panes[1].cursorPos != 0 {
+		t.Errorf("Expected j to move the first pane's cursor only, got %d and %d", d.panes[0].cursorPos, d.panes[1].cursorPos)
+	}
+
+	next, _ = d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d = next.(dualModel)
+
+	if d.active != 1 || d.panes[1].focusedPanel != panelPlaylist || d.panes[0].focusedPanel == panelPlaylist {
+		t.Errorf("Expected Tab to activate the second pane, active %d", d.active)
+	}
+
+	// A GA update tagged with the second pane reaches it alone
+	reversed := slices.Clone(d.panes[1].displayedTracks)
+	slices.Reverse(reversed)
+
+	next, _ = d.Update(paneMsg{pane: 1, msg: Update{BestPlaylist: reversed, BestFitness: 0.5, Epoch: d.panes[1].gaEpoch}})
+	d = next.(dualModel)
+
+	if d.panes[1].bestFitness != 0.5 || d.panes[0].bestFitness != 0 {
+		t.Errorf("Expected only the second pane updated, got fitness %v and %v", d.panes[0].bestFitness, d.panes[1].bestFitness)
+	}
+
+	next, _ = d.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	d = next.(dualModel)
+
+	if d.panes[0].width != 100 || !strings.Contains(d.View(), "backup.m3u8: ► ") {
+		t.Errorf("Expected each pane half the width and the active one marked, got width %d", d.panes[0].width)
+	}
+}
+
+func TestDualMoveTrack(t *testing.T) {
+	d := createTestDualModel()
+	d.panes[0].cursorPos = 1
+
+	if cmd := d.move(); cmd == nil {
+		t.Fatal("Expected the move to restart both GAs")
+	}
+
+	if got := trackTitles(d.panes[0].displayedTracks); !slices.Equal(got, []string{"A", "C"}) {
+		t.Errorf("Expected B moved out of the first playlist, got %v", got)
+	}
+
+	if got := trackTitles(d.panes[1].displayedTracks); !slices.Equal(got, []string{"D", "B", "E"}) {
+		t.Errorf("Expected B after the second playlist's cursor, got %v", got)
+	}
+
+	moved := d.panes[1].displayedTracks[1]
+	if moved.Path != filepath.FromSlash("../sets/B") || !d.panes[1].flagsAt(1).Has(playlist.FlagManual) {
+		t.Errorf("Expected the path rebased onto the second playlist and a manual marker, got %q", moved.Path)
+	}
+
+	if d.panes[0].gaEpoch != 1 || d.panes[1].gaEpoch != 1 {
+		t.Errorf("Expected both GAs restarted, got epochs %d and %d", d.panes[0].gaEpoch, d.panes[1].gaEpoch)
+	}
+
+	// Moving it back and forth again: the other playlist already has it
+	d.switchPane()
+	d.panes[1].cursorPos = 1
+	_ = d.move()
+	d.switchPane()
+	d.panes[1].displayedTracks = append(d.panes[1].displayedTracks, d.panes[0].displayedTracks[0])
+
+	before := len(d.panes[0].displayedTracks)
+	d.panes[0].cursorPos = 0
+
+	if cmd := d.move(); cmd != nil || len(d.panes[0].displayedTracks) != before {
+		t.Error("Expected a track already in the other playlist to stay put")
+	}
+}
+
+func TestFinishSavesDisplayedOrderOnlyInDualMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // The undo history is saved to the state directory
+
+	var saved []playlist.Track
+
+	m := createTestModel(createTestTracks(4))
+	m.writePlaylist = func(_ string, tracks []playlist.Track) error {
+		saved = tracks
+
+		return nil
+	}
+
+	m.bestPlaylist = slices.Clone(m.displayedTracks)
+	slices.Reverse(m.bestPlaylist)
+	m.displayedTracks = m.displayedTracks[:3] // A track just moved to the other playlist
+
+	// A single playlist is saved as the GA's best, as it always was
+	if err := m.finish(); err != nil || !slices.Equal(trackPaths(saved), trackPaths(m.bestPlaylist)) {
+		t.Errorf("Expected the best order saved, got %v (%v)", trackPaths(saved), err)
+	}
+
+	m.dual = true
+	if err := m.finish(); err != nil || !slices.Equal(trackPaths(saved), trackPaths(m.displayedTracks)) {
+		t.Errorf("Expected the displayed order saved in dual mode, got %v (%v)", trackPaths(saved), err)
+	}
+}
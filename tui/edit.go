@@ -0,0 +1,161 @@
+// ABOUTME: Inline editing of the key, BPM, energy and genre of the track under the cursor
+// ABOUTME: Edits go to the playlist's overrides file rather than the audio file, then the GA restarts
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+)
+
+// editFieldPattern finds the "field=" markers of a metadata edit; a value runs to the next marker,
+// so genres may contain spaces
+var editFieldPattern = regexp.MustCompile(`(?i)(?:^|\s)(key|bpm|energy|genre)=`)
+
+// formatMetadataEdit renders a track's editable fields as prompt input ("key=8A bpm=174 ...")
+func formatMetadataEdit(t playlist.Track) string {
+	return fmt.Sprintf("key=%s bpm=%s energy=%d genre=%s", t.Key, strconv.FormatFloat(t.BPM, 'f', -1, 64), t.Energy, t.Genre)
+}
+
+// parseMetadataEdit parses "field=value" pairs of key, bpm, energy and genre into an override.
+// Fields left out or empty keep their current value.
+func parseMetadataEdit(input string) (playlist.TrackOverride, error) {
+	var o playlist.TrackOverride
+
+	markers := editFieldPattern.FindAllStringSubmatchIndex(input, -1)
+	if len(markers) == 0 {
+		return o, errors.New("expected field=value pairs of key, bpm, energy and genre")
+	}
+
+	if prefix := strings.TrimSpace(input[:markers[0][0]]); prefix != "" {
+		return o, fmt.Errorf("unexpected %q before the first field", prefix)
+	}
+
+	for i, m := range markers {
+		end := len(input)
+		if i+1 < len(markers) {
+			end = markers[i+1][0]
+		}
+
+		field, value := strings.ToLower(input[m[2]:m[3]]), strings.TrimSpace(input[m[1]:end])
+		if value == "" {
+			continue
+		}
+
+		switch field {
+		case "key":
+			value = strings.ToUpper(value)
+			if _, err := playlist.ParseCamelotKey(value); err != nil {
+				return o, err
+			}
+
+			o.Key = value
+		case "bpm":
+			bpm, err := strconv.ParseFloat(value, 64)
+			if err != nil || bpm <= 0 {
+				return o, fmt.Errorf("invalid BPM %q", value)
+			}
+
+			o.BPM = bpm
+		case "energy":
+			energy, err := strconv.Atoi(value)
+			if err != nil || energy < 1 || energy > 10 {
+				return o, fmt.Errorf("invalid energy %q (expected 1-10)", value)
+			}
+
+			o.Energy = energy
+		case "genre":
+			o.Genre = value
+		}
+	}
+
+	return o, nil
+}
+
+// openMetadataEdit opens the edit prompt for the track under the cursor, filled with its current values
+func (m *model) openMetadataEdit() {
+	if len(m.displayedTracks) == 0 {
+		return
+	}
+
+	m.openPrompt(promptEditMetadata, "Edit (saved to overrides file): ")
+	m.prompt.value = []rune(formatMetadataEdit(m.displayedTracks[m.cursorPos]))
+}
+
+// editMetadata applies an edit to every entry of the track under the cursor, saves it to the
+// overrides file and restarts the GA, whose edge cache recomputes the changed track's transitions
+func (m *model) editMetadata(input string) tea.Cmd {
+	if len(m.displayedTracks) == 0 {
+		return nil
+	}
+
+	override, err := parseMetadataEdit(input)
+	if err != nil {
+		m.setStatusMsg(fmt.Sprintf("Could not edit track: %v", err))
+
+		return nil
+	}
+
+	track := m.displayedTracks[m.cursorPos]
+
+	saved := " (dry run: not saved)"
+	if !m.dryRun {
+		if err := playlist.SaveTrackOverride(m.overridesPath, track.Path, filepath.Dir(m.playlistPath), override); err != nil {
+			m.debugf("[TUI] Saving override for %s failed: %v", track.Path, err)
+			m.setStatusMsg(fmt.Sprintf("Could not save edit: %v", err))
+
+			return nil
+		}
+
+		saved = " (saved to " + filepath.Base(m.overridesPath) + ")"
+	}
+
+	// Every copy of the track's metadata, so undo, snapshots and restarts don't bring back the old values
+	m.originalTracks = withOverride(m.originalTracks, track.Path, override)
+	m.bestPlaylist = withOverride(m.bestPlaylist, track.Path, override)
+
+	for i := range m.undoMgr.history {
+		m.undoMgr.history[i].Tracks = withOverride(m.undoMgr.history[i].Tracks, track.Path, override)
+	}
+
+	for i := range m.snapshots {
+		m.snapshots[i].tracks = withOverride(m.snapshots[i].tracks, track.Path, override)
+	}
+
+	m.setDisplayedTracks(withOverride(m.displayedTracks, track.Path, override))
+
+	m.editMode = true
+	m.gaEpoch++
+
+	m.setStatusMsg(fmt.Sprintf("Edited %s - %s%s", track.Artist, track.Title, saved))
+	m.updateViewportContent()
+
+	return m.restartGA()
+}
+
+// withOverride returns tracks with the override applied to the entries of path. tracks may be shared
+// (undo history, snapshots), so a changed slice is a copy; without a matching entry tracks is returned.
+func withOverride(tracks []playlist.Track, path string, o playlist.TrackOverride) []playlist.Track {
+	if !slices.ContainsFunc(tracks, func(t playlist.Track) bool { return t.Path == path }) {
+		return tracks
+	}
+
+	tracks = slices.Clone(tracks)
+
+	for i := range tracks {
+		if tracks[i].Path == path {
+			o.ApplyTo(&tracks[i])
+		}
+	}
+
+	return tracks
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Tests for inline metadata editing in the TUI
+// ABOUTME: Verifies edit parsing, the saved overrides file and the restarted GA's tracks
+
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func TestParseMetadataEdit(t *testing.T) {
+	o, err := parseMetadataEdit("key=8a bpm=174.5 energy= genre=Drum & Bass; Liquid")
+	if err != nil {
+		t.Fatalf("parseMetadataEdit failed: %v", err)
+	}
+
+	want := playlist.TrackOverride{Key: "8A", BPM: 174.5, Genre: "Drum & Bass; Liquid"}
+	if o != want {
+		t.Errorf("Expected %+v, got %+v", want, o)
+	}
+
+	for _, input := range []string{"", "fast", "key=14A", "bpm=-3", "energy=11", "x key=8A"} {
+		if _, err := parseMetadataEdit(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+
+	track := playlist.Track{Key: "3B", BPM: 128, Energy: 6, Genre: "House"}
+	if o, err := parseMetadataEdit(formatMetadataEdit(track)); err != nil || o != (playlist.TrackOverride{Key: "3B", BPM: 128, Energy: 6, Genre: "House"}) {
+		t.Errorf("Expected the prefilled edit to round-trip, got %+v, %v", o, err)
+	}
+}
+
+func TestEditMetadataSavesOverride(t *testing.T) {
+	dir := t.TempDir()
+	tracks := createTestTracks(3)
+
+	m := createTestModel(tracks)
+	m.playlistPath = filepath.Join(dir, "set.m3u8")
+	m.overridesPath = playlist.OverridesPath(m.playlistPath)
+	m.cursorPos = 1
+	m.pushUndo()
+
+	if cmd := m.editMetadata("bpm=174 genre=Jungle"); cmd == nil {
+		t.Fatal("Expected the GA to restart after an edit")
+	}
+
+	if got := m.displayedTracks[1]; got.BPM != 174 || got.Genre != "Jungle" || got.Key != tracks[1].Key {
+		t.Errorf("Expected BPM and genre edited and the key kept, got %+v", got)
+	}
+
+	if m.undoMgr.history[0].Tracks[1].BPM != 174 || tracks[1].BPM == 174 {
+		t.Error("Expected undo history updated without changing the caller's tracks")
+	}
+
+	overrides, err := playlist.LoadPlaylistOverrides("", m.playlistPath)
+	if err != nil {
+		t.Fatalf("Loading the saved overrides failed: %v", err)
+	}
+
+	reloaded := []playlist.Track{tracks[1]}
+	if overrides.Apply(reloaded, dir) != 1 || reloaded[0].BPM != 174 {
+		t.Errorf("Expected the saved override to apply on the next load, got %+v", reloaded[0])
+	}
+}
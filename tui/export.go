@@ -0,0 +1,65 @@
+// ABOUTME: Export of the TUI's current view to a timestamped markdown file
+// ABOUTME: Archives the displayed order with its fitness breakdown and statistics
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"playlist-sorter/playlist"
+)
+
+// exportPath returns the markdown file an export taken at now is written to: next to the playlist,
+// named after it with a timestamp so repeated exports don't overwrite each other
+func exportPath(playlistPath string, now time.Time) string {
+	base := strings.TrimSuffix(playlistPath, filepath.Ext(playlistPath))
+
+	return base + now.Format("-20060102-150405") + ".md"
+}
+
+// exportMarkdown renders the displayed order, its fitness breakdown and statistics as markdown
+func (m model) exportMarkdown(now time.Time) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", filepath.Base(m.playlistPath))
+	fmt.Fprintf(&b, "Exported %s at generation %d, fitness %.6f.\n\n", now.Format("2006-01-02 15:04:05"), m.generation, m.bestFitness)
+
+	b.WriteString("## Tracklist\n\n")
+	b.WriteString(playlist.MarkdownTracklist(m.displayedTracks))
+
+	b.WriteString("\n## Fitness breakdown\n\n")
+	b.WriteString("| Component | Score |\n")
+	b.WriteString("|-----------|------:|\n")
+
+	for _, c := range breakdownComponents {
+		if value := c.value(m.breakdown); !c.optional || value != 0 {
+			fmt.Fprintf(&b, "| %s | %.6f |\n", c.name, value)
+		}
+	}
+
+	fmt.Fprintf(&b, "| **Total** | **%.6f** |\n", m.breakdown.Total)
+
+	b.WriteString("\n## Statistics\n\n```\n")
+	b.WriteString(strings.TrimRight(renderStats(m.displayedTracks), "\n"))
+	b.WriteString("\n```\n")
+
+	return b.String()
+}
+
+// exportView writes the current view to a timestamped markdown file next to the playlist
+func (m *model) exportView() {
+	now := time.Now()
+	path := exportPath(m.playlistPath, now)
+
+	if err := os.WriteFile(path, []byte(m.exportMarkdown(now)), 0o644); err != nil {
+		m.setStatusMsg(fmt.Sprintf("Could not export: %v", err))
+
+		return
+	}
+
+	m.setStatusMsg("Exported to " + path)
+}
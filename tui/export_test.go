@@ -0,0 +1,62 @@
+// ABOUTME: Tests for exporting the TUI's current view to markdown
+// ABOUTME: Verifies the timestamped file name and the tracklist, breakdown and statistics sections
+
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportPath(t *testing.T) {
+	now := time.Date(2024, 3, 1, 21, 30, 5, 0, time.UTC)
+
+	if got, want := exportPath("sets/friday.m3u8", now), "sets/friday-20240301-213005.md"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestExportView(t *testing.T) {
+	m := createTestModel(createTestTracks(3))
+	m.playlistPath = filepath.Join(t.TempDir(), "set.m3u8")
+	m.breakdown.Harmonic = 0.25
+	m.breakdown.Total = 0.25
+
+	m.exportView()
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(m.playlistPath), "set-*.md"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Expected one export next to the playlist, got %v (%v); status %q", matches, err, m.statusMsg)
+	}
+
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	out := string(data)
+
+	for _, want := range []string{
+		"# set.m3u8",
+		"| 1 | Test Artist | A | 1A | 120.0 | 50 |",
+		"| 3 | Test Artist | C |",
+		"| Harmonic | 0.250000 |",
+		"| **Total** | **0.250000** |",
+		"## Statistics\n\n```\nTracks: 3\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in export:\n%s", want, out)
+		}
+	}
+
+	if strings.Contains(out, "| Shuffle |") {
+		t.Errorf("Expected inactive optional components to be left out:\n%s", out)
+	}
+
+	if !strings.Contains(m.statusMsg, matches[0]) {
+		t.Errorf("Expected the status to name the export, got %q", m.statusMsg)
+	}
+}
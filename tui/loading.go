@@ -0,0 +1,185 @@
+// ABOUTME: Startup screen showing metadata loading progress before the optimizer view
+// ABOUTME: Loading runs under a cancellable context; the screen hands over to the optimizer model when done
+
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+	"playlist-sorter/termtext"
+)
+
+// loadingBarWidth is the width of the loading progress bar in cells
+const loadingBarWidth = 40
+
+// errLoadCancelled is returned by Run when the user quits while the playlist is loading
+var errLoadCancelled = errors.New("loading cancelled")
+
+// loadProgressMsg carries the latest metadata loading progress
+type loadProgressMsg playlist.LoadProgress
+
+// loadDoneMsg carries the loaded playlist (or the error that stopped loading)
+type loadDoneMsg struct {
+	tracks  []playlist.Track
+	skipped []playlist.SkippedTrack
+	err     error
+}
+
+// loadingModel is the Bubble Tea model shown while the playlist's metadata loads.
+// When loading finishes it returns the model built by start in its place (the optimizer, or in dual
+// mode the loading screen of the second playlist).
+type loadingModel struct {
+	path     string
+	load     func(context.Context, string, func(playlist.LoadProgress)) ([]playlist.Track, []playlist.SkippedTrack, error)
+	start    func([]playlist.Track, []playlist.SkippedTrack) tea.Model
+	progress chan playlist.LoadProgress
+
+	// Framework exception: see model.ctx
+	ctx    context.Context //nolint:containedctx // Cancelled by quitting during loading
+	cancel context.CancelFunc
+
+	latest    playlist.LoadProgress
+	startedAt time.Time
+	width     int
+	height    int
+	err       error // Why loading stopped (errLoadCancelled when the user quit)
+}
+
+// newLoadingModel creates the loading screen for path. start builds the optimizer model from the
+// loaded tracks and the entries skipped for unreadable metadata.
+func newLoadingModel(path string, load func(context.Context, string, func(playlist.LoadProgress)) ([]playlist.Track, []playlist.SkippedTrack, error), start func([]playlist.Track, []playlist.SkippedTrack) tea.Model) loadingModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return loadingModel{
+		path:      path,
+		load:      load,
+		start:     start,
+		progress:  make(chan playlist.LoadProgress, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+		startedAt: time.Now(),
+	}
+}
+
+// Init starts loading in the background
+func (m loadingModel) Init() tea.Cmd {
+	load := func() tea.Msg {
+		tracks, skipped, err := m.load(m.ctx, m.path, func(p playlist.LoadProgress) {
+			// Drop progress the screen hasn't picked up yet in favour of the newest
+			select {
+			case <-m.progress:
+			default:
+			}
+
+			m.progress <- p
+		})
+
+		return loadDoneMsg{tracks: tracks, skipped: skipped, err: err}
+	}
+
+	return tea.Batch(load, m.waitForProgress(), tea.EnterAltScreen)
+}
+
+// waitForProgress waits for the next progress report
+func (m loadingModel) waitForProgress() tea.Cmd {
+	progress, ctx := m.progress, m.ctx
+
+	return func() tea.Msg {
+		select {
+		case p := <-progress:
+			return loadProgressMsg(p)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Update handles progress, the end of loading and cancellation
+func (m loadingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Quit) || msg.Type == tea.KeyEsc {
+			m.cancel()
+			m.err = errLoadCancelled
+
+			return m, tea.Quit
+		}
+
+	case loadProgressMsg:
+		m.latest = playlist.LoadProgress(msg)
+
+		return m, m.waitForProgress()
+
+	case loadDoneMsg:
+		if msg.err != nil {
+			m.cancel()
+
+			if m.err == nil {
+				m.err = msg.err
+			}
+
+			return m, tea.Quit
+		}
+
+		// The optimizer takes over; stop waiting for progress
+		m.cancel()
+
+		next := m.start(msg.tracks, msg.skipped)
+		cmds := []tea.Cmd{next.Init()}
+
+		if m.width > 0 {
+			size := tea.WindowSizeMsg{Width: m.width, Height: m.height}
+			cmds = append(cmds, func() tea.Msg { return size })
+		}
+
+		return next, tea.Batch(cmds...)
+	}
+
+	return m, nil
+}
+
+// View renders the loading progress
+func (m loadingModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Loading " + filepath.Base(m.path)))
+	b.WriteString("\n\n")
+
+	p := m.latest
+	if p.Total == 0 {
+		b.WriteString("Reading playlist...\n")
+	} else {
+		filled := loadingBarWidth * p.Done / p.Total
+		fmt.Fprintf(&b, "[%s%s] %d/%d tracks (%.0f%%)\n",
+			strings.Repeat("#", filled), strings.Repeat("-", loadingBarWidth-filled),
+			p.Done, p.Total, 100*float64(p.Done)/float64(p.Total))
+
+		fmt.Fprintf(&b, "Skipped (unreadable metadata): %d\n", p.Skipped)
+		fmt.Fprintf(&b, "Elapsed: %s\n", time.Since(m.startedAt).Truncate(time.Second))
+
+		path := p.Path
+		if m.width > 0 {
+			path = termtext.Truncate(path, m.width)
+		}
+
+		b.WriteString(paramStyle.Render(path))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("q/esc: cancel"))
+
+	return b.String()
+}
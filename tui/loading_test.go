@@ -0,0 +1,139 @@
+// ABOUTME: Tests for the startup loading screen
+// ABOUTME: Verifies progress display, hand-over to the optimizer, load reports and cancellation
+
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+)
+
+// newTestLoadingModel creates a loading screen whose loader blocks until ctx is cancelled
+func newTestLoadingModel(tracks []playlist.Track) loadingModel {
+	load := func(ctx context.Context, _ string, _ func(playlist.LoadProgress)) ([]playlist.Track, []playlist.SkippedTrack, error) {
+		<-ctx.Done()
+
+		return nil, nil, ctx.Err()
+	}
+
+	return newLoadingModel("sets/friday.m3u8", load, func(_ []playlist.Track, skipped []playlist.SkippedTrack) tea.Model {
+		m := createTestModel(tracks)
+		m.reportSkipped(skipped)
+		m.reportBPMCorrections()
+
+		return m
+	})
+}
+
+func TestLoadingShowsProgress(t *testing.T) {
+	m := newTestLoadingModel(nil)
+
+	if !strings.Contains(m.View(), "Reading playlist") {
+		t.Errorf("Expected the reading message before any progress:\n%s", m.View())
+	}
+
+	next, _ := m.Update(loadProgressMsg{Done: 25, Total: 100, Skipped: 3, Path: "Artist/track.mp3"})
+	view := next.View()
+
+	for _, want := range []string{"friday.m3u8", "25/100 tracks (25%)", "Skipped (unreadable metadata): 3", "Artist/track.mp3"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Loading view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestLoadingHandsOverToOptimizer(t *testing.T) {
+	tracks := createTestTracks(5)
+	m := newTestLoadingModel(tracks)
+
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	next, cmd := next.Update(loadDoneMsg{tracks: tracks})
+
+	optimizer, ok := next.(model)
+	if !ok {
+		t.Fatalf("Expected the optimizer model after loading, got %T", next)
+	}
+
+	if len(optimizer.displayedTracks) != len(tracks) {
+		t.Errorf("Optimizer shows %d tracks, want %d", len(optimizer.displayedTracks), len(tracks))
+	}
+
+	if cmd == nil {
+		t.Error("Expected the optimizer's startup commands")
+	}
+
+	optimizer.cancel()
+}
+
+func TestLoadingReportsSkippedTracks(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := newTestLoadingModel(tracks)
+
+	skipped := []playlist.SkippedTrack{{Path: "music/broken.mp3", Position: 1, Err: errors.New("no tags")}}
+
+	next, _ := m.Update(loadDoneMsg{tracks: tracks, skipped: skipped})
+	optimizer := next.(model)
+	defer optimizer.cancel()
+
+	if !strings.Contains(optimizer.statusMsg, "1 tracks skipped") || !strings.Contains(optimizer.statusMsg, "broken.mp3") {
+		t.Errorf("Expected a skipped-track status message, got %q", optimizer.statusMsg)
+	}
+
+	optimizer.statusMsg = ""
+	if status := optimizer.renderStatus(); !strings.Contains(status, "[1 SKIPPED]") {
+		t.Errorf("Expected the status bar to flag skipped tracks, got %q", status)
+	}
+}
+
+func TestLoadingReportsBPMCorrections(t *testing.T) {
+	tracks := createTestTracks(3)
+	tracks[2].BPMCorrected = true
+	m := newTestLoadingModel(tracks)
+
+	skipped := []playlist.SkippedTrack{{Path: "music/broken.mp3", Position: 1, Err: errors.New("no tags")}}
+
+	next, _ := m.Update(loadDoneMsg{tracks: tracks, skipped: skipped})
+	optimizer := next.(model)
+	defer optimizer.cancel()
+
+	// Announced after the skipped tracks rather than replacing them
+	if !strings.Contains(optimizer.statusMsg, "1 tracks skipped") || !strings.Contains(optimizer.statusMsg, "BPM of 1 tracks") {
+		t.Errorf("Expected skipped and corrected tracks in the status message, got %q", optimizer.statusMsg)
+	}
+}
+
+func TestLoadingCancel(t *testing.T) {
+	m := newTestLoadingModel(nil)
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	l := next.(loadingModel)
+	if !errors.Is(l.err, errLoadCancelled) || l.ctx.Err() == nil {
+		t.Errorf("Expected cancelled loading, got err=%v ctx=%v", l.err, l.ctx.Err())
+	}
+
+	if cmd == nil {
+		t.Error("Expected quit command")
+	}
+
+	// The loader returning afterwards must not replace the cancellation
+	next, _ = l.Update(loadDoneMsg{err: context.Canceled})
+	if !errors.Is(next.(loadingModel).err, errLoadCancelled) {
+		t.Errorf("Expected errLoadCancelled to be kept, got %v", next.(loadingModel).err)
+	}
+}
+
+func TestLoadingError(t *testing.T) {
+	m := newTestLoadingModel(nil)
+
+	next, _ := m.Update(loadDoneMsg{err: errors.New("playlist is empty")})
+	if err := next.(loadingModel).err; err == nil || err.Error() != "playlist is empty" {
+		t.Errorf("Expected the load error to be kept, got %v", err)
+	}
+}
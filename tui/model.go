@@ -6,14 +6,26 @@ package tui
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
 	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 
 	"playlist-sorter/config"
 	"playlist-sorter/playlist"
@@ -27,8 +39,9 @@ const (
 
 // Layout constants for UI dimensions
 const (
-	paramPanelWidth = 45 // Left panel width for parameter controls
-	panelPadding    = 2  // Horizontal spacing between panels
+	paramPanelWidth = 45  // Left panel width for parameter controls
+	panelPadding    = 2   // Horizontal spacing between panels
+	compactWidth    = 100 // Below this width: compact layout (fewer columns, parameters below the playlist)
 
 	// UI chrome heights (elements that reduce available viewport space)
 	titleHeight     = 2 // Panel title bars
@@ -46,9 +59,10 @@ const (
 
 // Navigation and interaction constants
 const (
-	pageJumpSize          = 10              // Number of tracks to jump on PageUp/PageDown
-	statusMessageDuration = 5 * time.Second // How long to show transient status messages
-	maxUndoStackSize      = 50              // Maximum undo/redo history items
+	pageJumpSize          = 10               // Number of tracks to jump on PageUp/PageDown
+	statusMessageDuration = 5 * time.Second  // How long to show transient status messages
+	maxUndoStackSize      = 50               // Maximum undo/redo history items
+	breakdownSettleTime   = 10 * time.Second // Without improvement for this long, a run counts as settled (breakdown deltas)
 )
 
 // Parameter represents a tunable GA parameter with constraints
@@ -65,13 +79,19 @@ type Parameter struct {
 // gaRestartMsg signals that GA should restart with new tracks
 type gaRestartMsg struct{}
 
+// gaFinishedMsg signals that a GA run returned (time cap reached, or cancelled by a restart)
+type gaFinishedMsg struct {
+	epoch int
+}
+
 // model holds the TUI state
 type model struct {
 	// Dependencies (concrete types following Go philosophy)
 	sharedConfig  *config.SharedConfig
-	runGA         func(context.Context, []playlist.Track, chan<- Update, int)
+	runGA         func(context.Context, []playlist.Track, map[string]playlist.TrackFlags, chan<- Update, int)
 	loadPlaylist  func(string, bool) ([]playlist.Track, error)
 	writePlaylist func(string, []playlist.Track) error
+	loadTrack     func(string) (*playlist.Track, error)
 	debugf        func(string, ...interface{})
 
 	// Configuration
@@ -96,51 +116,117 @@ type model struct {
 	// Framework exception: Context stored in struct because Bubble Tea's Init/Update/View
 	// pattern doesn't allow passing context through function parameters. The framework owns
 	// the model lifecycle, making context-in-struct the idiomatic pattern for cancellation.
-	ctx        context.Context    //nolint:containedctx // See framework exception above
-	cancel     context.CancelFunc // Cancel function for ctx
-	updateChan chan Update        // Channel for GA updates
-	gaEpoch    int                // Increments each GA restart to track stale updates
+	ctx        context.Context     //nolint:containedctx // See framework exception above
+	cancel     context.CancelFunc  // Cancel function for ctx
+	updateChan chan Update         // Channel for GA updates
+	gaEpoch    int                 // Increments each GA restart to track stale updates
+	shownEpoch int                 // Epoch of the GA result on display; behind gaEpoch until the new run reports
+	gaFinished bool                // Current GA run stopped on its own (e.g. time cap) - R restarts it
+	optimal    bool                // Current order is provably optimal (tiny playlist solved exactly)
+	deltaBase  *playlist.Breakdown // Settled breakdown before the last weight change (nil = none)
+	gaRuns     *gaLifecycle        // Shared across model copies: orders run teardown/startup, counts active runs
 
 	// File I/O
-	playlistPath string // Playlist file path for reading
-	outputPath   string // Output path for saving (may differ from playlistPath)
-	dryRun       bool   // If true, don't save changes
+	playlistPath  string    // Playlist file path for reading
+	outputPath    string    // Output path for saving (may differ from playlistPath)
+	overridesPath string    // Metadata overrides file inline edits are saved to
+	dryRun        bool      // If true, don't save changes
+	diskModTime   time.Time // Playlist file modification time last seen (external change detection)
 
 	// UI state
 	width        int
 	height       int
+	compact      bool // Narrow terminal: compact layout (see compactWidth)
+	dual         bool // One of two playlists side by side (see dualModel): no parameter panel
+	debugStats   bool // Show GA lifecycle counters in the status bar
+	nice         bool // Optimizer is in nice (background) mode
+	toggleNice   func() bool
 	quitting     bool
 	statusMsg    string    // Temporary status message (e.g., "Playlist saved")
 	statusMsgAge time.Time // When status message was set
 	focusedPanel string    // "params" or "playlist" - which panel has focus
+	prompt       prompt    // Active status bar prompt (e.g. track path to insert)
 
 	// Track browsing and editing
-	cursorPos       int              // Current cursor position in track list
-	viewport        viewport.Model   // Viewport for scrolling track list
-	undoMgr         *UndoManager     // Undo/redo history manager
-	editMode        bool             // True when user is manually editing (GA paused)
-	displayedTracks []playlist.Track // Tracks shown to user (updated by GA or manual edits)
+	cursorPos           int                            // Current cursor position in track list
+	viewport            viewport.Model                 // Viewport for scrolling track list
+	undoMgr             *UndoManager                   // Undo/redo history manager
+	sessionCheckpointed bool                           // True once the loaded order has been saved as an undo checkpoint
+	editMode            bool                           // True when user is manually editing (GA paused)
+	displayedTracks     []playlist.Track               // Tracks shown to user (updated by GA or manual edits)
+	displayedFlags      []playlist.TrackFlags          // Per position of displayedTracks (markers column)
+	trackFlags          map[string]playlist.TrackFlags // Sticky flags by track Identity (e.g. manually inserted), sent to the GA
+	focused             bool                           // Focus mode: the GA only reorders positions focusLo to focusHi
+	focusLo, focusHi    int                            // Focus window (inclusive positions), set when focus mode is turned on
+	swapMark            string                         // Identity of the track marked for a swap (empty = none)
+
+	// Audio preview
+	preview *previewer // External player for auditioning the track under the cursor
+
+	// Quarantine: tracks soft-removed from the playlist (and the GA), restorable any time
+	quarantine       []playlist.Track // In quarantine order
+	showQuarantine   bool             // True when the quarantine section is expanded (captures navigation keys)
+	quarantineCursor int              // Selected track in the expanded section
+
+	// Tracks left out of optimization because their metadata could not be read
+	skipped          []playlist.SkippedTrack
+	skippedPlacement playlist.SkippedPlacement // Where saves put them
+
+	// Named snapshots
+	snapshots      []snapshot // Saved orderings, in save order
+	showSnapshots  bool       // True when the snapshot list replaces the playlist panel
+	showWheel      bool       // True when the Camelot wheel replaces the parameter panel
+	showStats      bool       // True when the statistics panel replaces the parameter panel
+	showPresets    bool       // True when the preset menu replaces the parameter panel
+	presetCursor   int        // Selected entry in the preset menu
+	snapshotCursor int        // Selected snapshot in the list
 }
 
 // Key bindings
 type keyMap struct {
-	Up    key.Binding
-	Down  key.Binding
-	Left  key.Binding
-	Right key.Binding
-	Reset key.Binding
-	Quit  key.Binding
+	Up      key.Binding
+	Down    key.Binding
+	Left    key.Binding
+	Right   key.Binding
+	Reset   key.Binding
+	Restart key.Binding
+	Nice    key.Binding
+	Quit    key.Binding
 	// Track navigation
 	PageUp   key.Binding
 	PageDown key.Binding
 	Home     key.Binding
 	End      key.Binding
 	// Track editing
+	Insert key.Binding
 	Delete key.Binding
+	Edit   key.Binding
 	Undo   key.Binding
 	Redo   key.Binding
+	Swap   key.Binding
 	// Panel switching
 	Tab key.Binding
+	// Audio preview
+	Preview     key.Binding
+	StopPreview key.Binding
+	// Snapshots
+	SaveSnapshot key.Binding
+	Snapshots    key.Binding
+	// Camelot wheel
+	Wheel key.Binding
+	// Statistics panel
+	Stats key.Binding
+	// Markdown export
+	Export key.Binding
+	// Weight presets
+	Presets key.Binding
+	// Focus mode
+	Focus key.Binding
+	// Quarantine
+	SoftDelete key.Binding
+	Quarantine key.Binding
+	// Dual mode
+	Move key.Binding
 }
 
 var keys = keyMap{
@@ -164,6 +250,14 @@ var keys = keyMap{
 		key.WithKeys("r"),
 		key.WithHelp("r", "reset params"),
 	),
+	Restart: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "restart optimization"),
+	),
+	Nice: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "toggle nice (background) mode"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q", "quit"),
@@ -184,10 +278,18 @@ var keys = keyMap{
 		key.WithKeys("end", "G"),
 		key.WithHelp("end/G", "last track"),
 	),
+	Insert: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add track"),
+	),
 	Delete: key.NewBinding(
 		key.WithKeys("d"),
 		key.WithHelp("d", "delete track"),
 	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit key/bpm/energy/genre"),
+	),
 	Undo: key.NewBinding(
 		key.WithKeys("u"),
 		key.WithHelp("u", "undo"),
@@ -196,10 +298,62 @@ var keys = keyMap{
 		key.WithKeys("ctrl+r"),
 		key.WithHelp("ctrl+r", "redo"),
 	),
+	Swap: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "mark track, then swap with it"),
+	),
 	Tab: key.NewBinding(
 		key.WithKeys("tab"),
 		key.WithHelp("tab", "switch panel"),
 	),
+	Preview: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "preview track"),
+	),
+	StopPreview: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "stop preview"),
+	),
+	SaveSnapshot: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "save snapshot"),
+	),
+	Snapshots: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "list snapshots"),
+	),
+	Wheel: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "camelot wheel"),
+	),
+	Stats: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "playlist statistics"),
+	),
+	Export: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "export view to markdown"),
+	),
+	Presets: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "weight presets"),
+	),
+	Focus: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "focus GA around cursor"),
+	),
+	SoftDelete: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "quarantine track"),
+	),
+	Quarantine: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "show/hide quarantine"),
+	),
+	Move: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "move track to the other playlist"),
+	),
 }
 
 // Styles
@@ -234,31 +388,138 @@ var (
 			Foreground(lipgloss.Color("15"))
 )
 
+// usePlainStyles drops all colors and text attributes (NO_COLOR / -no-color).
+// The cursor row can no longer be highlighted, so it gets a ">" marker instead.
+func usePlainStyles() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	cursorStyle = lipgloss.NewStyle().
+		Border(lipgloss.Border{Left: ">"}, false, false, false, true)
+
+	usePlainWheelStyles()
+}
+
 // Run starts the TUI mode with injected dependencies
-func Run(opts Options, sharedConfig *config.SharedConfig, runGA func(context.Context, []playlist.Track, chan<- Update, int), loadPlaylist func(string, bool) ([]playlist.Track, error), writePlaylist func(string, []playlist.Track) error, debugf func(string, ...interface{}), configPath string) error {
-	// Load and validate playlist
-	tracks, err := loadPlaylist(opts.PlaylistPath, true)
-	if err != nil {
-		return err
+func Run(opts Options, sharedConfig *config.SharedConfig, runGA func(context.Context, []playlist.Track, map[string]playlist.TrackFlags, chan<- Update, int), loadPlaylist func(string, bool) ([]playlist.Track, error), writePlaylist func(string, []playlist.Track) error, debugf func(string, ...interface{}), configPath string) error {
+	if opts.NoColor {
+		usePlainStyles()
+	}
+
+	load := opts.LoadWithProgress
+	if load == nil {
+		load = func(_ context.Context, path string, _ func(playlist.LoadProgress)) ([]playlist.Track, []playlist.SkippedTrack, error) {
+			tracks, err := loadPlaylist(path, true)
+
+			return tracks, nil, err
+		}
+	}
+
+	// Builds the optimizer model of a playlist once it has loaded
+	start := func(opts Options, runGA func(context.Context, []playlist.Track, map[string]playlist.TrackFlags, chan<- Update, int)) func([]playlist.Track, []playlist.SkippedTrack) model {
+		return func(tracks []playlist.Track, skipped []playlist.SkippedTrack) model {
+			// Skipped tracks aren't optimized, but every save puts them back
+			write := func(path string, tracks []playlist.Track) error {
+				return writePlaylist(path, playlist.WithSkipped(tracks, skipped, opts.SkippedPlacement))
+			}
+
+			// Create model with injected dependencies
+			m := initModel(tracks, opts, sharedConfig, runGA, loadPlaylist, write, debugf, configPath)
+			m.reportSkipped(skipped)
+			m.reportBPMCorrections()
+
+			// Restore undo history from previous sessions; deleted tracks are reloaded from disk
+			if err := m.undoMgr.Load(undoHistoryPath(opts.PlaylistPath), m.resolveTrack); err != nil {
+				debugf("[TUI] Failed to load undo history: %v", err)
+			}
+
+			// Tracks quarantined in previous sessions aren't in the saved playlist, so they come from disk too
+			if err := m.loadQuarantine(); err != nil {
+				debugf("[TUI] Failed to load quarantine: %v", err)
+			}
+
+			return m
+		}
 	}
 
-	// Create model with injected dependencies
-	m := initModel(tracks, opts, sharedConfig, runGA, loadPlaylist, writePlaylist, debugf, configPath)
+	// The loading screen shows metadata progress, then hands over to the optimizer model
+	startFirst := start(opts, runGA)
+	loading := newLoadingModel(opts.PlaylistPath, load, func(tracks []playlist.Track, skipped []playlist.SkippedTrack) tea.Model {
+		return startFirst(tracks, skipped)
+	})
+
+	// Dual mode: the second playlist loads next, then both are shown side by side
+	if opts.DualPath != "" {
+		dualOpts := opts
+		dualOpts.PlaylistPath = opts.DualPath
+		dualOpts.OutputPath = ""
+		startSecond := start(dualOpts, opts.DualRunGA)
+
+		loading = newLoadingModel(opts.PlaylistPath, load, func(tracks []playlist.Track, skipped []playlist.SkippedTrack) tea.Model {
+			first := startFirst(tracks, skipped)
+
+			return newLoadingModel(opts.DualPath, load, func(tracks []playlist.Track, skipped []playlist.SkippedTrack) tea.Model {
+				return newDualModel(first, startSecond(tracks, skipped))
+			})
+		})
+	}
 
 	// Run program
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := tea.NewProgram(loading, tea.WithAltScreen())
 
 	finalModel, err := p.Run()
 	if err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
 
-	// Save the optimized playlist on exit (unless dry-run mode)
-	if m, ok := finalModel.(model); ok && len(m.bestPlaylist) > 0 {
+	// Quit (or failed) before loading finished: the optimizer never started
+	if l, ok := finalModel.(loadingModel); ok {
+		if errors.Is(l.err, errLoadCancelled) {
+			fmt.Println("Loading cancelled: playlist not modified")
+
+			return nil
+		}
+
+		return l.err
+	}
+
+	switch m := finalModel.(type) {
+	case model:
+		return m.finish()
+	case dualModel:
+		return errors.Join(m.panes[0].finish(), m.panes[1].finish())
+	}
+
+	return nil
+}
+
+// finish wraps up a session once the TUI has exited: stops the preview, saves the undo history and
+// the optimized playlist (unless dry-run)
+func (m model) finish() error {
+	historyPath := undoHistoryPath(m.playlistPath)
+
+	// Don't leave the player running after the TUI exits
+	m.preview.stop()
+
+	// Persist undo history for the next session (dry-run leaves the playlist untouched, so skip it)
+	if !m.dryRun {
+		if err := m.undoMgr.Save(historyPath, m.playlistPath); err != nil {
+			m.debugf("[TUI] Failed to save undo history: %v", err)
+		}
+	}
+
+	// Save the optimized playlist on exit (unless dry-run mode). In dual mode that is the displayed
+	// order, the GA's best plus any move it hasn't reported on yet: a track just moved to the other
+	// playlist must not be saved in both.
+	tracks := m.bestPlaylist
+	if m.dual {
+		tracks = m.displayedTracks
+	}
+
+	if len(tracks) > 0 {
 		if m.dryRun {
 			fmt.Println("\n--dry-run mode: playlist not modified")
 		} else {
-			if err := writePlaylist(m.outputPath, m.bestPlaylist); err != nil {
+			if err := m.writePlaylist(m.outputPath, tracks); err != nil {
 				return fmt.Errorf("failed to save playlist: %w", err)
 			}
 
@@ -270,7 +531,7 @@ func Run(opts Options, sharedConfig *config.SharedConfig, runGA func(context.Con
 }
 
 // initModel creates the initial model with injected dependencies
-func initModel(tracks []playlist.Track, opts Options, sharedConfig *config.SharedConfig, runGA func(context.Context, []playlist.Track, chan<- Update, int), loadPlaylist func(string, bool) ([]playlist.Track, error), writePlaylist func(string, []playlist.Track) error, debugf func(string, ...interface{}), configPath string) model {
+func initModel(tracks []playlist.Track, opts Options, sharedConfig *config.SharedConfig, runGA func(context.Context, []playlist.Track, map[string]playlist.TrackFlags, chan<- Update, int), loadPlaylist func(string, bool) ([]playlist.Track, error), writePlaylist func(string, []playlist.Track) error, debugf func(string, ...interface{}), configPath string) model {
 	// Get config from provider
 	cfg := sharedConfig.Get()
 
@@ -286,13 +547,37 @@ func initModel(tracks []playlist.Track, opts Options, sharedConfig *config.Share
 		outputPath = opts.OutputPath
 	}
 
+	overridesPath := opts.OverridesPath
+	if overridesPath == "" {
+		overridesPath = playlist.OverridesPath(opts.PlaylistPath)
+	}
+
 	m := model{
 		// Injected dependencies (concrete types)
 		sharedConfig:  sharedConfig,
 		runGA:         runGA,
 		loadPlaylist:  loadPlaylist,
 		writePlaylist: writePlaylist,
-		debugf:        debugf,
+		loadTrack: func(path string) (*playlist.Track, error) {
+			// New tracks are entered relative to the playlist, like existing entries
+			track, err := playlist.GetTrackMetadata(path, filepath.Dir(opts.PlaylistPath))
+			if err != nil {
+				return nil, err
+			}
+
+			tracks := []playlist.Track{*track}
+
+			// Corrections apply to tracks added in the session too (an unreadable file just doesn't apply)
+			if overrides, err := playlist.LoadPlaylistOverrides(overridesPath, ""); err == nil {
+				overrides.Apply(tracks, filepath.Dir(opts.PlaylistPath))
+			}
+
+			playlist.MarkFavorites(tracks, localConfig.FavoriteMinRating, localConfig.Favorites)
+			playlist.MarkFresh(tracks, time.Now(), localConfig.FreshDays)
+
+			return &tracks[0], nil
+		},
+		debugf: debugf,
 
 		// Configuration
 		localConfig: localConfig,
@@ -313,11 +598,16 @@ func initModel(tracks []playlist.Track, opts Options, sharedConfig *config.Share
 		// - select-default in converter drops updates when full (prevents blocking GA)
 		updateChan: make(chan Update, 10),
 		gaEpoch:    0,
+		gaRuns:     &gaLifecycle{},
+		debugStats: opts.DebugLog,
+		nice:       opts.Nice,
+		toggleNice: opts.ToggleNice,
 
 		// File I/O
-		playlistPath: opts.PlaylistPath,
-		outputPath:   outputPath,
-		dryRun:       opts.DryRun,
+		playlistPath:  opts.PlaylistPath,
+		outputPath:    outputPath,
+		overridesPath: overridesPath,
+		dryRun:        opts.DryRun,
 
 		// UI state
 		viewport:     viewport.New(0, 0), // Width and height set on first WindowSizeMsg
@@ -326,8 +616,14 @@ func initModel(tracks []playlist.Track, opts Options, sharedConfig *config.Share
 		// Track editing
 		cursorPos:       0,
 		displayedTracks: tracks,
+		displayedFlags:  playlist.PositionFlags(tracks, nil),
 		undoMgr:         NewUndoManager(maxUndoStackSize),
 		editMode:        false,
+
+		// Audio preview
+		preview: &previewer{},
+
+		skippedPlacement: opts.SkippedPlacement,
 	}
 
 	// Build parameter list with pointers to localConfig fields
@@ -341,6 +637,19 @@ func initModel(tracks []playlist.Track, opts Options, sharedConfig *config.Share
 		{"Same Album Penalty", &localConfig.SameAlbumPenalty, nil, 0, 1, 0.01, false},
 		{"Low Energy Bias Portion", &localConfig.LowEnergyBiasPortion, nil, 0, 1, 0.01, false},
 		{"Low Energy Bias Weight", &localConfig.LowEnergyBiasWeight, nil, 0, 1, 0.01, false},
+		{"Shuffle Temperature", &localConfig.ShuffleTemperature, nil, 0, 1, 0.01, false},
+		{"Novelty Weight", &localConfig.NoveltyWeight, nil, 0, 1, 0.01, false},
+		{"Mix Length Weight", &localConfig.MixLengthWeight, nil, 0, 1, 0.01, false},
+		{"Energy Wave Weight", &localConfig.EnergyWaveWeight, nil, 0, 1, 0.01, false},
+		{"Energy Wave Amplitude", &localConfig.EnergyWaveAmplitude, nil, 0, 5, 0.5, false},
+		{"Energy Wave Period", nil, &localConfig.EnergyWavePeriod, 0, 64, 1, true},
+		{"BPM Band Weight", &localConfig.BPMBandWeight, nil, 0, 1, 0.01, false},
+		{"BPM Band Width", &localConfig.BPMBandWidth, nil, 0.5, 10, 0.5, false},
+		{"Favorite Weight", &localConfig.FavoriteWeight, nil, 0, 1, 0.01, false},
+		{"Prime Start", &localConfig.PrimeStart, nil, 0, 1, 0.01, false},
+		{"Prime End", &localConfig.PrimeEnd, nil, 0, 1, 0.01, false},
+		{"Freshness Weight", &localConfig.FreshnessWeight, nil, 0, 1, 0.01, false},
+		{"Bookend Weight", &localConfig.BookendWeight, nil, 0, 1, 0.01, false},
 	}
 	m.selectedParam = 0
 
@@ -351,39 +660,44 @@ func initModel(tracks []playlist.Track, opts Options, sharedConfig *config.Share
 
 // ========== Helpers ==========
 
-// truncate shortens a string to maxLen, adding "..." if truncated
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-
-	if maxLen <= 3 {
-		return s[:maxLen]
-	}
-
-	return s[:maxLen-3] + "..."
-}
-
 // ========== Types and Dependencies ==========
 
 // Update represents a progress update from the GA
 type Update struct {
 	BestPlaylist []playlist.Track
 	BestFitness  float64
-	Breakdown    playlist.Breakdown // Using shared type from playlist package
+	Breakdown    playlist.Breakdown    // Using shared type from playlist package
+	Flags        []playlist.TrackFlags // Per position of BestPlaylist (locked, manual, estimated)
 	Generation   int
 	GenPerSec    float64
 	Epoch        int
+	Optimal      bool // BestPlaylist is provably optimal; the run ends after this update
 }
 
 // ========== Options ==========
 
 // Options contains configuration for running the TUI
 type Options struct {
-	PlaylistPath string // Path to input playlist
-	OutputPath   string // Path for saving (defaults to PlaylistPath)
-	DryRun       bool   // If true, don't save changes to disk
-	DebugLog     bool   // Enable debug logging to file
+	PlaylistPath string      // Path to input playlist
+	OutputPath   string      // Path for saving (defaults to PlaylistPath)
+	DryRun       bool        // If true, don't save changes to disk
+	DebugLog     bool        // Enable debug logging to file
+	NoColor      bool        // Render without colors or text attributes
+	Nice         bool        // Optimizer starts in nice (background) mode
+	ToggleNice   func() bool // Flips nice mode, returning the new state (nil: no toggle)
+
+	// Loads the playlist at startup, reporting progress for the loading screen and stopping when
+	// ctx is cancelled (nil: the loadPlaylist passed to Run, without progress)
+	LoadWithProgress func(ctx context.Context, path string, progress func(playlist.LoadProgress)) ([]playlist.Track, []playlist.SkippedTrack, error)
+
+	SkippedPlacement playlist.SkippedPlacement // Where tracks with unreadable metadata go in saved playlists
+
+	OverridesPath string // Metadata overrides file read on load and written by inline edits (empty = <playlist>.overrides.json)
+
+	// Dual mode: a second playlist opened side by side, optimized independently by DualRunGA and
+	// saved to its own path (empty = single playlist)
+	DualPath  string
+	DualRunGA func(context.Context, []playlist.Track, map[string]playlist.TrackFlags, chan<- Update, int)
 }
 
 // ========== Parameter Manager ==========
@@ -459,6 +773,32 @@ func resetParamsToDefaults(params []Parameter, defaults config.GAConfig) {
 			*p.Value = defaults.LowEnergyBiasPortion
 		case "Low Energy Bias Weight":
 			*p.Value = defaults.LowEnergyBiasWeight
+		case "Shuffle Temperature":
+			*p.Value = defaults.ShuffleTemperature
+		case "Novelty Weight":
+			*p.Value = defaults.NoveltyWeight
+		case "Mix Length Weight":
+			*p.Value = defaults.MixLengthWeight
+		case "Energy Wave Weight":
+			*p.Value = defaults.EnergyWaveWeight
+		case "Energy Wave Amplitude":
+			*p.Value = defaults.EnergyWaveAmplitude
+		case "Energy Wave Period":
+			*p.IntValue = defaults.EnergyWavePeriod
+		case "BPM Band Weight":
+			*p.Value = defaults.BPMBandWeight
+		case "BPM Band Width":
+			*p.Value = defaults.BPMBandWidth
+		case "Favorite Weight":
+			*p.Value = defaults.FavoriteWeight
+		case "Prime Start":
+			*p.Value = defaults.PrimeStart
+		case "Prime End":
+			*p.Value = defaults.PrimeEnd
+		case "Freshness Weight":
+			*p.Value = defaults.FreshnessWeight
+		case "Bookend Weight":
+			*p.Value = defaults.BookendWeight
 		}
 	}
 }
@@ -469,6 +809,7 @@ func resetParamsToDefaults(params []Parameter, defaults config.GAConfig) {
 type PlaylistState struct {
 	Tracks    []playlist.Track
 	CursorPos int
+	Timestamp time.Time // When the checkpoint was taken (persisted across sessions)
 }
 
 // UndoManager manages undo/redo stacks with maximum size limit
@@ -493,6 +834,7 @@ func (um *UndoManager) Push(state PlaylistState) {
 	stateCopy := PlaylistState{
 		Tracks:    append([]playlist.Track{}, state.Tracks...),
 		CursorPos: state.CursorPos,
+		Timestamp: state.Timestamp,
 	}
 
 	// Truncate history at cursor (clears redo states)
@@ -520,6 +862,7 @@ func (um *UndoManager) Undo(currentState PlaylistState) (PlaylistState, bool) {
 	stateCopy := PlaylistState{
 		Tracks:    append([]playlist.Track{}, currentState.Tracks...),
 		CursorPos: currentState.CursorPos,
+		Timestamp: currentState.Timestamp,
 	}
 
 	// Extend history if needed to store current state
@@ -547,6 +890,7 @@ func (um *UndoManager) Redo(currentState PlaylistState) (PlaylistState, bool) {
 	stateCopy := PlaylistState{
 		Tracks:    append([]playlist.Track{}, currentState.Tracks...),
 		CursorPos: currentState.CursorPos,
+		Timestamp: currentState.Timestamp,
 	}
 	um.history[um.cursor] = stateCopy
 
@@ -579,6 +923,129 @@ func (um *UndoManager) Clear() {
 	um.cursor = 0
 }
 
+// undoHistoryFile is the on-disk form of the undo stack.
+// Only track paths and IDs are stored; metadata is re-resolved when the history is loaded.
+type undoHistoryFile struct {
+	Playlist string             `json:"playlist"`
+	States   []undoHistoryState `json:"states"`
+}
+
+// undoHistoryState is one persisted undo checkpoint
+type undoHistoryState struct {
+	Paths     []string  `json:"paths"`
+	IDs       []string  `json:"ids,omitempty"` // Track.ID per path (absent in older histories)
+	CursorPos int       `json:"cursor_pos"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Save writes the undoable checkpoints to path so a later session can continue undoing.
+// Redo states are not persisted - the playlist file itself holds the latest state.
+func (um *UndoManager) Save(path, playlistPath string) error {
+	file := undoHistoryFile{Playlist: playlistPath}
+
+	for _, state := range um.history[:um.cursor] {
+		paths := make([]string, len(state.Tracks))
+		ids := make([]string, len(state.Tracks))
+
+		for i, track := range state.Tracks {
+			paths[i], ids[i] = track.Path, track.ID
+		}
+
+		file.States = append(file.States, undoHistoryState{
+			Paths:     paths,
+			IDs:       ids,
+			CursorPos: state.CursorPos,
+			Timestamp: state.Timestamp,
+		})
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode undo history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create undo history directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // Not sensitive
+		return fmt.Errorf("failed to write undo history: %w", err)
+	}
+
+	return nil
+}
+
+// Load replaces the history with checkpoints saved by a previous session.
+// resolve maps a track path and saved ID (empty in older histories) to its metadata; tracks it can't
+// resolve are dropped from that checkpoint. A missing history file is not an error.
+func (um *UndoManager) Load(path string, resolve func(path, id string) (playlist.Track, bool)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read undo history: %w", err)
+	}
+
+	var file undoHistoryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse undo history: %w", err)
+	}
+
+	um.Clear()
+
+	for _, saved := range file.States {
+		state := PlaylistState{
+			Tracks:    make([]playlist.Track, 0, len(saved.Paths)),
+			CursorPos: saved.CursorPos,
+			Timestamp: saved.Timestamp,
+		}
+
+		hasIDs := len(saved.IDs) == len(saved.Paths)
+
+		for i, p := range saved.Paths {
+			id := ""
+			if hasIDs {
+				id = saved.IDs[i]
+			}
+
+			if track, ok := resolve(p, id); ok {
+				if hasIDs {
+					track.ID = id
+				}
+
+				state.Tracks = append(state.Tracks, track)
+			}
+		}
+
+		if len(state.Tracks) == 0 {
+			continue
+		}
+
+		// Older histories have no IDs, and resolve returns the same entry for a path listed twice
+		playlist.AssignIDs(state.Tracks, "")
+
+		state.CursorPos = min(max(state.CursorPos, 0), len(state.Tracks)-1)
+		um.Push(state)
+	}
+
+	return nil
+}
+
+// undoHistoryPath returns the per-playlist undo history file in the state directory
+func undoHistoryPath(playlistPath string) string {
+	absPath, err := filepath.Abs(playlistPath)
+	if err != nil {
+		absPath = playlistPath
+	}
+
+	sum := sha256.Sum256([]byte(absPath))
+	name := fmt.Sprintf("%s-%s.undo.json", filepath.Base(absPath), hex.EncodeToString(sum[:8]))
+
+	return filepath.Join(config.GetStateDir(), name)
+}
+
 // ========== Viewport Manager ==========
 
 // ViewportManager handles cursor visibility and viewport scrolling
@@ -651,15 +1118,42 @@ func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		m.startGA(m.ctx, m.originalTracks, m.gaEpoch),
 		waitForUpdate(m.updateChan),
+		m.pollDisk(),
 		tea.EnterAltScreen,
 	)
 }
 
 // ========== Helper Methods ==========
 
-// startGA starts the GA in a goroutine and returns a command
+// gaLifecycle sequences GA runs: each run starts only after the previous one has fully exited,
+// so restarts during rapid tuning never leave several CPU-bound runs competing
+type gaLifecycle struct {
+	mu     sync.Mutex
+	last   chan struct{} // Closed when the most recently started run exits
+	active atomic.Int32  // Runs currently executing (0 or 1 when teardown works)
+}
+
+// next registers a new run. The run must wait on prev before starting and call done when it exits.
+func (l *gaLifecycle) next() (prev <-chan struct{}, done func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	prev = l.last
+	finished := make(chan struct{})
+	l.last = finished
+
+	return prev, func() { close(finished) }
+}
+
+// startGA starts the GA in a goroutine and returns a command.
+// The run waits for the previous run to exit and is skipped if it was superseded meanwhile.
 func (m *model) startGA(ctx context.Context, tracks []playlist.Track, epoch int) tea.Cmd {
+	prev, done := m.gaRuns.next()
+	flags := m.gaFlags(tracks) // A copy: the GA goroutine must not share the map with later edits
+
 	return func() tea.Msg {
+		defer done()
+
 		defer func() {
 			if r := recover(); r != nil {
 				m.debugf("[PANIC] startGA panic: %v", r)
@@ -668,13 +1162,49 @@ func (m *model) startGA(ctx context.Context, tracks []playlist.Track, epoch int)
 			}
 		}()
 
+		if prev != nil {
+			<-prev
+		}
+
+		// Superseded by another restart while waiting for the previous run to exit
+		if ctx.Err() != nil {
+			return gaFinishedMsg{epoch: epoch}
+		}
+
+		m.gaRuns.active.Add(1)
+		defer m.gaRuns.active.Add(-1)
+
 		// Run GA via injected function (blocks until context cancelled or GA completes)
-		m.runGA(ctx, tracks, m.updateChan, epoch)
+		m.runGA(ctx, tracks, flags, m.updateChan, epoch)
 
-		return nil
+		return gaFinishedMsg{epoch: epoch}
 	}
 }
 
+// toggleNiceMode switches the optimizer between full speed and nice (background) mode.
+// Takes effect from the next generation; no restart needed.
+func (m *model) toggleNiceMode() {
+	if m.toggleNice == nil {
+		return
+	}
+
+	m.nice = m.toggleNice()
+	if m.nice {
+		m.setStatusMsg("Nice mode: optimizer pauses between generations")
+	} else {
+		m.setStatusMsg("Full speed")
+	}
+}
+
+// restartOptimization starts a fresh GA run on the displayed tracks (e.g. after it hit its time cap)
+func (m *model) restartOptimization() tea.Cmd {
+	m.gaEpoch++
+	m.setStatusMsg("Optimization restarted")
+	m.debugf("[TUI] Manual GA restart with epoch %d", m.gaEpoch)
+
+	return m.restartGA()
+}
+
 // waitForUpdate waits for GA updates and returns them as messages
 func waitForUpdate(updateChan <-chan Update) tea.Cmd {
 	return func() tea.Msg {
@@ -740,6 +1270,13 @@ func (m *model) syncConfigToGA() tea.Cmd {
 
 	m.sharedConfig.Update(*m.localConfig)
 
+	// Compare the next settled breakdown against this one. While the run for an earlier change is
+	// still improving, keep comparing against the last settled one.
+	if m.converged() && m.breakdown.Total != 0 {
+		base := m.breakdown
+		m.deltaBase = &base
+	}
+
 	// Increment epoch immediately to invalidate any pending GA updates with old weights
 	m.gaEpoch++
 
@@ -749,12 +1286,51 @@ func (m *model) syncConfigToGA() tea.Cmd {
 	return m.restartGA()
 }
 
+// staleResult reports whether the order, fitness and breakdown on display come from a GA run
+// that has since been replaced (e.g. after a weight change) and the new run hasn't reported yet
+func (m model) staleResult() bool {
+	return m.bestFitness != 0 && m.shownEpoch != m.gaEpoch
+}
+
+// converged reports whether the current GA run has settled: finished, solved exactly or without
+// an improvement for breakdownSettleTime
+func (m model) converged() bool {
+	return m.gaFinished || m.optimal || time.Since(m.lastImprovementTime) >= breakdownSettleTime
+}
+
 // setStatusMsg sets a transient status message with current timestamp
 func (m *model) setStatusMsg(msg string) {
 	m.statusMsg = msg
 	m.statusMsgAge = time.Now()
 }
 
+// reportSkipped remembers the tracks skipped for unreadable metadata and announces them
+func (m *model) reportSkipped(skipped []playlist.SkippedTrack) {
+	m.skipped = skipped
+	if len(skipped) == 0 {
+		return
+	}
+
+	m.setStatusMsg(fmt.Sprintf("%d tracks skipped (metadata could not be read, e.g. %s): not optimized, %s",
+		len(skipped), filepath.Base(skipped[0].Path), m.skippedPlacement.Description()))
+}
+
+// reportBPMCorrections announces the tracks whose half/double-time BPM was corrected on load,
+// after the skipped tracks if those were announced too
+func (m *model) reportBPMCorrections() {
+	n := playlist.CountBPMCorrected(m.displayedTracks)
+	if n == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("Corrected half/double-time BPM of %d tracks (bpm_ranges)", n)
+	if m.statusMsg != "" {
+		msg = m.statusMsg + "; " + msg
+	}
+
+	m.setStatusMsg(msg)
+}
+
 // ensureCursorVisible adjusts viewport offset to keep cursor visible with middle-of-screen scrolling
 // Implements vim/less style scrolling using ViewportManager
 func (m *model) ensureCursorVisible() {
@@ -768,8 +1344,12 @@ func (m *model) pushUndo() {
 	state := PlaylistState{
 		Tracks:    m.displayedTracks,
 		CursorPos: m.cursorPos,
+		Timestamp: time.Now(),
 	}
 	m.undoMgr.Push(state)
+
+	// Any manual checkpoint precedes GA changes, so the loaded order is already covered
+	m.sessionCheckpointed = true
 }
 
 // deleteTrack removes the track at cursor position and restarts GA
@@ -782,7 +1362,7 @@ func (m *model) deleteTrack() tea.Cmd {
 	m.pushUndo()
 
 	// Remove track at cursor
-	m.displayedTracks = append(m.displayedTracks[:m.cursorPos], m.displayedTracks[m.cursorPos+1:]...)
+	m.setDisplayedTracks(append(m.displayedTracks[:m.cursorPos], m.displayedTracks[m.cursorPos+1:]...))
 
 	// Set edit mode
 	m.editMode = true
@@ -808,11 +1388,241 @@ func (m *model) deleteTrack() tea.Cmd {
 	return m.restartGA()
 }
 
+// swapTracks marks the track under the cursor for a swap, or swaps it with the marked one (x twice).
+// The swap is one undoable edit; pressing x on the marked track again clears the mark.
+func (m *model) swapTracks() tea.Cmd {
+	if len(m.displayedTracks) == 0 {
+		return nil
+	}
+
+	cursorID := m.displayedTracks[m.cursorPos].Identity()
+
+	marked := m.swapMarkPos()
+
+	switch {
+	case marked < 0:
+		// No mark yet, or the marked track was deleted meanwhile
+		m.swapMark = cursorID
+		m.setStatusMsg(fmt.Sprintf("Marked %s - x on another track swaps them", m.displayedTracks[m.cursorPos].Title))
+
+		return nil
+
+	case marked == m.cursorPos:
+		m.swapMark = ""
+		m.setStatusMsg("Swap mark cleared")
+
+		return nil
+	}
+
+	// Save current state to undo stack
+	m.pushUndo()
+
+	tracks := slices.Clone(m.displayedTracks)
+	tracks[marked], tracks[m.cursorPos] = tracks[m.cursorPos], tracks[marked]
+	m.setDisplayedTracks(tracks)
+	m.swapMark = ""
+
+	// Set edit mode
+	m.editMode = true
+
+	// Increment epoch immediately to invalidate any pending GA updates
+	m.gaEpoch++
+
+	m.setStatusMsg(fmt.Sprintf("Swapped tracks %d and %d (Undo: %d, Redo: %d)", marked+1, m.cursorPos+1, m.undoMgr.UndoSize(), m.undoMgr.RedoSize()))
+
+	// Update viewport
+	m.updateViewportContent()
+
+	// Auto-save the edited playlist
+	m.autoSave()
+
+	// Restart GA with edited track list
+	return m.restartGA()
+}
+
+// swapMarkPos returns the position of the track marked for a swap, or -1 when none is (or it was
+// deleted). The GA may have moved it since it was marked, so it is looked up by identity.
+func (m model) swapMarkPos() int {
+	if m.swapMark == "" {
+		return -1
+	}
+
+	return slices.IndexFunc(m.displayedTracks, func(t playlist.Track) bool { return t.Identity() == m.swapMark })
+}
+
+// setDisplayedTracks replaces the displayed order after a local change (edit, undo, snapshot, reload)
+// and derives its flags; GA updates carry their own flags instead. The track set may have changed,
+// so tracks are reindexed (on a copy: undo history and snapshots share their slices) to match the
+// cache the restarted GA builds from them.
+func (m *model) setDisplayedTracks(tracks []playlist.Track) {
+	tracks = slices.Clone(tracks)
+	playlist.ReindexTracks(tracks)
+
+	m.displayedTracks = tracks
+	m.displayedFlags = playlist.PositionFlags(tracks, m.gaFlags(tracks))
+	m.pruneQuarantine(tracks)
+}
+
+// gaFlags returns a copy of the sticky flags for a GA run on tracks: trackFlags, plus in focus mode
+// FlagLocked on every track outside the focus window, which the GA keeps in place
+func (m *model) gaFlags(tracks []playlist.Track) map[string]playlist.TrackFlags {
+	flags := maps.Clone(m.trackFlags)
+	if !m.focused {
+		return flags
+	}
+
+	if flags == nil {
+		flags = make(map[string]playlist.TrackFlags)
+	}
+
+	for i := range tracks {
+		if i < m.focusLo || i > m.focusHi {
+			flags[tracks[i].Identity()] |= playlist.FlagLocked
+		}
+	}
+
+	return flags
+}
+
+// toggleFocus turns focus mode on around the cursor (FocusRadius tracks either side) or back off,
+// and restarts the GA: in focus mode it only reorders the window, to fix one rough patch of a set
+func (m *model) toggleFocus() tea.Cmd {
+	if len(m.displayedTracks) == 0 {
+		return nil
+	}
+
+	m.focused = !m.focused
+
+	if m.focused {
+		radius := max(m.localConfig.FocusRadius, 1)
+		m.focusLo = max(m.cursorPos-radius, 0)
+		m.focusHi = min(m.cursorPos+radius, len(m.displayedTracks)-1)
+		m.setStatusMsg(fmt.Sprintf("Focus: optimizing tracks %d-%d only (f: whole playlist)", m.focusLo+1, m.focusHi+1))
+	} else {
+		m.setStatusMsg("Focus off: optimizing the whole playlist")
+	}
+
+	m.displayedFlags = playlist.PositionFlags(m.displayedTracks, m.gaFlags(m.displayedTracks))
+	m.updateViewportContent()
+
+	// Increment epoch immediately to invalidate any pending GA updates
+	m.gaEpoch++
+
+	return m.restartGA()
+}
+
+// insertTrack loads metadata for the track at path and inserts it after the cursor, then restarts GA
+func (m *model) insertTrack(path string) tea.Cmd {
+	path = expandHome(strings.TrimSpace(path))
+	if path == "" {
+		m.setStatusMsg("No track path given")
+
+		return nil
+	}
+
+	id := playlist.EntryID(path, filepath.Dir(m.playlistPath))
+	if slices.ContainsFunc(m.displayedTracks, func(t playlist.Track) bool { return t.Identity() == id }) {
+		m.setStatusMsg("Track already in playlist: " + path)
+
+		return nil
+	}
+
+	track, err := m.loadTrack(path)
+	if err != nil {
+		m.debugf("[TUI] Insert failed for %s: %v", path, err)
+		m.setStatusMsg(fmt.Sprintf("Could not add track: %v", err))
+
+		return nil
+	}
+
+	return m.placeTrack(*track, "Added")
+}
+
+// placeTrack inserts a track added by hand (loaded, or moved in from the other playlist) after the
+// cursor, flagged as manually placed, and restarts GA. verb starts the status message.
+func (m *model) placeTrack(track playlist.Track, verb string) tea.Cmd {
+	// Save current state to undo stack
+	m.pushUndo()
+
+	// Insert after the cursor so the end of the list is reachable; the cursor follows the new track
+	insertPos := 0
+	if len(m.displayedTracks) > 0 {
+		insertPos = m.cursorPos + 1
+	}
+
+	tracks := slices.Insert(slices.Clone(m.displayedTracks), insertPos, track)
+	playlist.AssignIDs(tracks, filepath.Dir(m.playlistPath))
+
+	if m.trackFlags == nil {
+		m.trackFlags = make(map[string]playlist.TrackFlags)
+	}
+
+	m.trackFlags[tracks[insertPos].Identity()] |= playlist.FlagManual
+
+	m.setDisplayedTracks(tracks)
+	m.cursorPos = insertPos
+
+	// Set edit mode
+	m.editMode = true
+
+	// Increment epoch immediately to invalidate any pending GA updates
+	m.gaEpoch++
+
+	m.setStatusMsg(fmt.Sprintf("%s %s - %s (Undo: %d, Redo: %d)", verb, track.Artist, track.Title, m.undoMgr.UndoSize(), m.undoMgr.RedoSize()))
+
+	m.ensureCursorVisible()
+	m.updateViewportContent()
+
+	// Auto-save the edited playlist
+	m.autoSave()
+
+	// Restart GA with edited track list
+	return m.restartGA()
+}
+
+// resolveTrack finds metadata for a saved track, preferring the already-loaded entry with the same
+// identity over disk reads. Older histories have no IDs; their paths get the identity AssignIDs gives.
+func (m *model) resolveTrack(path, id string) (playlist.Track, bool) {
+	if id == "" {
+		id = playlist.EntryID(path, filepath.Dir(m.playlistPath))
+	}
+
+	for _, track := range m.originalTracks {
+		if track.Identity() == id {
+			return track, true
+		}
+	}
+
+	track, err := m.loadTrack(path)
+	if err != nil {
+		m.debugf("[TUI] Dropping %s from undo history: %v", path, err)
+
+		return playlist.Track{}, false
+	}
+
+	return *track, true
+}
+
+// expandHome replaces a leading "~/" with the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[2:])
+}
+
 // undo restores previous state from undo stack using UndoManager
 func (m *model) undo() tea.Cmd {
 	currentState := PlaylistState{
 		Tracks:    m.displayedTracks,
 		CursorPos: m.cursorPos,
+		Timestamp: time.Now(),
 	}
 
 	state, ok := m.undoMgr.Undo(currentState)
@@ -823,7 +1633,7 @@ func (m *model) undo() tea.Cmd {
 	}
 
 	// Restore state
-	m.displayedTracks = state.Tracks
+	m.setDisplayedTracks(state.Tracks)
 	m.cursorPos = state.CursorPos
 	m.ensureCursorVisible()
 
@@ -831,7 +1641,7 @@ func (m *model) undo() tea.Cmd {
 	m.gaEpoch++
 
 	// Update status message
-	m.setStatusMsg(fmt.Sprintf("Undo (Undo: %d, Redo: %d)", m.undoMgr.UndoSize(), m.undoMgr.RedoSize()))
+	m.setStatusMsg(fmt.Sprintf("Undo to %s (Undo: %d, Redo: %d)", formatCheckpointTime(state.Timestamp), m.undoMgr.UndoSize(), m.undoMgr.RedoSize()))
 
 	// Update viewport
 	m.updateViewportContent()
@@ -848,6 +1658,7 @@ func (m *model) redo() tea.Cmd {
 	currentState := PlaylistState{
 		Tracks:    m.displayedTracks,
 		CursorPos: m.cursorPos,
+		Timestamp: time.Now(),
 	}
 
 	state, ok := m.undoMgr.Redo(currentState)
@@ -858,7 +1669,7 @@ func (m *model) redo() tea.Cmd {
 	}
 
 	// Restore state
-	m.displayedTracks = state.Tracks
+	m.setDisplayedTracks(state.Tracks)
 	m.cursorPos = state.CursorPos
 	m.ensureCursorVisible()
 
@@ -878,9 +1689,76 @@ func (m *model) redo() tea.Cmd {
 	return m.restartGA()
 }
 
+// previewTrack launches the configured external player for the track under the cursor
+func (m *model) previewTrack() {
+	if m.cursorPos < 0 || m.cursorPos >= len(m.displayedTracks) {
+		return
+	}
+
+	track := m.displayedTracks[m.cursorPos]
+
+	template := m.localConfig.PreviewCommand
+	if template == "" {
+		template = config.DefaultPreviewCommand
+	}
+
+	args, err := buildPreviewCommand(template, resolveTrackPath(m.playlistPath, track.Path))
+	if err != nil {
+		m.setStatusMsg(fmt.Sprintf("Preview failed: %v", err))
+
+		return
+	}
+
+	if err := m.preview.start(args); err != nil {
+		m.debugf("[TUI] Preview failed: %v", err)
+		m.setStatusMsg(fmt.Sprintf("Preview failed: %v", err))
+
+		return
+	}
+
+	m.debugf("[TUI] Previewing %s with %q", track.Path, args)
+	m.setStatusMsg(fmt.Sprintf("Previewing: %s - %s (P to stop)", track.Artist, track.Title))
+}
+
+// stopPreview stops the running external player, if any
+func (m *model) stopPreview() {
+	if m.preview.stop() {
+		m.setStatusMsg("Preview stopped")
+	}
+}
+
+// formatCheckpointTime formats an undo checkpoint time, including the date for earlier days
+func formatCheckpointTime(t time.Time) string {
+	if t.IsZero() {
+		return "previous state"
+	}
+
+	now := time.Now()
+	if t.YearDay() != now.YearDay() || t.Year() != now.Year() {
+		return t.Format("2006-01-02 15:04")
+	}
+
+	return t.Format("15:04:05")
+}
+
+// checkpointGAOverwrite saves the loaded order as an undo checkpoint the first time the GA
+// replaces it, so the pre-optimization order can be restored (also in later sessions)
+func (m *model) checkpointGAOverwrite() {
+	if m.sessionCheckpointed {
+		return
+	}
+
+	m.sessionCheckpointed = true
+	m.undoMgr.Push(PlaylistState{
+		Tracks:    m.originalTracks,
+		CursorPos: 0,
+		Timestamp: time.Now(),
+	})
+}
+
 // autoSave writes current tracks to disk
 func (m *model) autoSave() {
-	if m.dryRun {
+	if m.dryRun || m.reloadPending() {
 		return
 	}
 
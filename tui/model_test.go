@@ -5,7 +5,14 @@ package tui
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 
 	"playlist-sorter/config"
 	"playlist-sorter/playlist"
@@ -23,7 +30,7 @@ func createTestModel(tracks []playlist.Track) model {
 	sharedCfg.Update(config.DefaultConfig())
 
 	// Mock functions for testing
-	mockRunGA := func(_ context.Context, _ []playlist.Track, _ chan<- Update, _ int) {
+	mockRunGA := func(_ context.Context, _ []playlist.Track, _ map[string]playlist.TrackFlags, _ chan<- Update, _ int) {
 		// Don't send any updates in tests
 	}
 
@@ -73,8 +80,8 @@ func TestModelInitialization(t *testing.T) {
 		t.Errorf("Expected 5 original tracks, got %d", len(m.originalTracks))
 	}
 
-	if len(m.params) != 8 {
-		t.Errorf("Expected 8 parameters, got %d", len(m.params))
+	if len(m.params) != 21 {
+		t.Errorf("Expected 21 parameters, got %d", len(m.params))
 	}
 
 	if m.selectedParam != 0 {
@@ -155,6 +162,50 @@ func TestUndo(t *testing.T) {
 	}
 }
 
+func TestSwapTracks(t *testing.T) {
+	m := createTestModel(createTestTracks(5))
+
+	m.cursorPos = 1
+	if cmd := m.swapTracks(); cmd != nil || m.swapMarkPos() != 1 {
+		t.Fatalf("Expected the first x to mark track 2 without a restart, got mark at %d", m.swapMarkPos())
+	}
+
+	// The GA moves the marked track before the second x: the swap follows it
+	reordered := []playlist.Track{m.displayedTracks[1], m.displayedTracks[0], m.displayedTracks[2], m.displayedTracks[3], m.displayedTracks[4]}
+	updated, _ := m.Update(Update{BestPlaylist: reordered, BestFitness: 0.5, Epoch: m.gaEpoch})
+	m = updated.(model)
+	undoBefore := m.undoMgr.UndoSize()
+
+	m.cursorPos = 3
+	epoch := m.gaEpoch
+
+	if cmd := m.swapTracks(); cmd == nil || m.gaEpoch != epoch+1 {
+		t.Fatal("Expected the second x to swap and restart the GA")
+	}
+
+	if got := trackTitles(m.displayedTracks); !slices.Equal(got, []string{"D", "A", "C", "B", "E"}) {
+		t.Errorf("Expected B and D swapped, got %v", got)
+	}
+
+	if m.swapMarkPos() != -1 || m.undoMgr.UndoSize() != undoBefore+1 {
+		t.Errorf("Expected the mark cleared and one undo entry, got mark %d and %d entries", m.swapMarkPos(), m.undoMgr.UndoSize()-undoBefore)
+	}
+
+	_ = m.undo()
+
+	if got := trackTitles(m.displayedTracks); !slices.Equal(got, []string{"B", "A", "C", "D", "E"}) {
+		t.Errorf("Expected one undo to revert the swap, got %v", got)
+	}
+
+	// x twice on the same track clears the mark
+	_ = m.swapTracks()
+	_ = m.swapTracks()
+
+	if m.swapMarkPos() != -1 {
+		t.Errorf("Expected the mark cleared, got %d", m.swapMarkPos())
+	}
+}
+
 func TestRedo(t *testing.T) {
 	tracks := createTestTracks(5)
 	m := createTestModel(tracks)
@@ -220,6 +271,96 @@ func TestParameterAdjustment(t *testing.T) {
 	}
 }
 
+func TestBreakdownDeltasAfterWeightChange(t *testing.T) {
+	m := createTestModel(createTestTracks(5))
+
+	settled := playlist.Breakdown{Harmonic: 0.2, EnergyDelta: 0.1, Total: 0.3}
+	m.breakdown = settled
+	m.gaFinished = true
+
+	_ = m.increaseSelectedParam()
+
+	if m.deltaBase == nil || *m.deltaBase != settled {
+		t.Fatalf("Expected the settled breakdown to become the comparison base, got %+v", m.deltaBase)
+	}
+
+	// A second change before the new run settles keeps comparing against the settled breakdown
+	m.gaFinished = false
+	m.lastImprovementTime = time.Now()
+	m.breakdown = playlist.Breakdown{Harmonic: 0.5, Total: 0.5}
+
+	_ = m.increaseSelectedParam()
+
+	if *m.deltaBase != settled {
+		t.Errorf("Expected an unsettled breakdown not to replace the base, got %+v", *m.deltaBase)
+	}
+
+	m.breakdown = playlist.Breakdown{Harmonic: 0.15, EnergyDelta: 0.1, Novelty: 0.05, Total: 0.3}
+
+	if line := m.renderBreakdown(); strings.Contains(line, "(") {
+		t.Errorf("Expected no deltas while the run is still improving, got %q", line)
+	}
+
+	m.gaFinished = true
+
+	line := m.renderBreakdown()
+	if !strings.Contains(line, "Harmonic: 0.1500 (-0.0500)") || !strings.Contains(line, "Novelty: 0.0500 (+0.0500)") {
+		t.Errorf("Expected moved components annotated, got %q", line)
+	}
+
+	if !strings.Contains(line, "Energy: 0.1000 |") {
+		t.Errorf("Expected unchanged components without annotation, got %q", line)
+	}
+}
+
+func TestStatusTemplate(t *testing.T) {
+	m := createTestModel(createTestTracks(5))
+	m.generation = 1200
+	m.genPerSec = 41.25
+	m.bestFitness = 0.125
+	m.lastImprovementDelta = 0.5
+	m.timeSinceImprovement = 90 * time.Second
+	m.editMode = true
+
+	m.localConfig.StatusTemplate = "G{gen} {gen/s}/s F{fitness} {delta} {since} #{tracks} {unknown}"
+
+	want := "[EDIT] G1200 41.2/s F0.12500000 -0.50000000 1m30s #5 {unknown}"
+	if status := m.renderStatus(); !strings.Contains(status, want) || strings.Contains(status, "Epoch") {
+		t.Errorf("Expected %q, got %q", want, status)
+	}
+
+	m.localConfig.StatusTemplate = ""
+
+	if status := m.renderStatus(); !strings.Contains(status, "Gen: 1200 (41.2 gen/s)") {
+		t.Errorf("Expected the default status line without a template, got %q", status)
+	}
+}
+
+func TestStaleResultIndicator(t *testing.T) {
+	m := createTestModel(createTestTracks(5))
+	tracks := slices.Clone(m.displayedTracks)
+
+	updated, _ := m.Update(Update{BestPlaylist: tracks, BestFitness: 0.5, Epoch: m.gaEpoch})
+	m = updated.(model)
+
+	if status := m.renderStatus(); strings.Contains(status, "STALE") || !strings.Contains(status, "Epoch: 0") {
+		t.Fatalf("Expected a current result with its epoch, got %q", status)
+	}
+
+	_ = m.increaseSelectedParam()
+
+	if status := m.renderStatus(); !strings.Contains(status, "[STALE: epoch 0 result]") || !strings.Contains(status, "Epoch: 1") {
+		t.Errorf("Expected the result to be flagged stale after a weight change, got %q", status)
+	}
+
+	updated, _ = m.Update(Update{BestPlaylist: tracks, BestFitness: 0.4, Epoch: m.gaEpoch})
+	m = updated.(model)
+
+	if status := m.renderStatus(); strings.Contains(status, "STALE") {
+		t.Errorf("Expected the new run's first update to clear the stale flag, got %q", status)
+	}
+}
+
 func TestParameterBoundaries(t *testing.T) {
 	tracks := createTestTracks(5)
 	m := createTestModel(tracks)
@@ -265,3 +406,408 @@ func TestResetToDefaults(t *testing.T) {
 		t.Errorf("Parameter 1 not reset to default: got %.2f, want %.2f", *m.params[1].Value, defaults.EnergyDeltaWeight)
 	}
 }
+
+func TestInsertTrack(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := createTestModel(tracks)
+
+	m.loadTrack = func(path string) (*playlist.Track, error) {
+		return &playlist.Track{Path: path, Title: "New", Artist: "Test Artist"}, nil
+	}
+
+	m.cursorPos = 1
+	_ = m.insertTrack("new.mp3")
+
+	if len(m.displayedTracks) != 4 {
+		t.Fatalf("Expected 4 tracks after insert, got %d", len(m.displayedTracks))
+	}
+
+	if m.displayedTracks[2].Path != "new.mp3" {
+		t.Errorf("Expected new track after cursor at position 2, got %s", m.displayedTracks[2].Path)
+	}
+
+	if m.cursorPos != 2 {
+		t.Errorf("Expected cursor on inserted track (2), got %d", m.cursorPos)
+	}
+
+	if m.undoMgr.UndoSize() != 1 {
+		t.Errorf("Expected 1 item in undo stack, got %d", m.undoMgr.UndoSize())
+	}
+
+	// Undo removes the inserted track again
+	_ = m.undo()
+
+	if len(m.displayedTracks) != 3 {
+		t.Errorf("Expected 3 tracks after undo, got %d", len(m.displayedTracks))
+	}
+}
+
+func TestInsertTrackMarksManualPlacement(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := createTestModel(tracks)
+
+	m.loadTrack = func(path string) (*playlist.Track, error) {
+		return &playlist.Track{Path: path, Title: "New", Artist: "Test Artist"}, nil
+	}
+
+	_ = m.insertTrack("new.mp3")
+
+	if !m.flagsAt(m.cursorPos).Has(playlist.FlagManual) {
+		t.Errorf("Expected inserted track to be flagged as manually placed, got %v", m.flagsAt(m.cursorPos))
+	}
+
+	if m.flagsAt(0) != 0 {
+		t.Errorf("Expected existing tracks unflagged, got %v", m.flagsAt(0))
+	}
+
+	// GA updates carry the flags per position, so reordering keeps the marker on the track
+	reordered := []playlist.Track{m.displayedTracks[1], m.displayedTracks[0], m.displayedTracks[2], m.displayedTracks[3]}
+	updated, _ := m.Update(Update{
+		BestPlaylist: reordered,
+		BestFitness:  0.5,
+		Flags:        playlist.PositionFlags(reordered, m.trackFlags),
+		Epoch:        m.gaEpoch,
+	})
+	m = updated.(model)
+
+	if !m.flagsAt(0).Has(playlist.FlagManual) {
+		t.Errorf("Expected the marker to follow the track to position 0, got %v", m.flagsAt(0))
+	}
+
+	if line := formatTrackLine(0, &m.displayedTracks[0], m.flagsAt(0), false); !strings.HasPrefix(line, "1  +") {
+		t.Errorf("Expected manual marker after the position number, got %q", line)
+	}
+}
+
+func TestEditsKeepIndicesContiguous(t *testing.T) {
+	m := createTestModel(createTestTracks(5))
+
+	m.loadTrack = func(path string) (*playlist.Track, error) {
+		return &playlist.Track{Path: path, Title: "New", Artist: "Test Artist", Index: 42}, nil
+	}
+
+	m.cursorPos = 1
+	_ = m.deleteTrack()
+
+	if err := playlist.CheckIndices(m.displayedTracks, len(m.displayedTracks)); err != nil {
+		t.Errorf("After delete: %v", err)
+	}
+
+	_ = m.insertTrack("new.mp3")
+
+	if err := playlist.CheckIndices(m.displayedTracks, len(m.displayedTracks)); err != nil {
+		t.Errorf("After insert: %v", err)
+	}
+
+	_ = m.undo()
+
+	if err := playlist.CheckIndices(m.displayedTracks, len(m.displayedTracks)); err != nil {
+		t.Errorf("After undo: %v", err)
+	}
+}
+
+func TestInsertTrackRejectsDuplicateAndErrors(t *testing.T) {
+	tracks := createTestTracks(3)
+	playlist.AssignIDs(tracks, ".") // As loaded from test.m3u8
+	m := createTestModel(tracks)
+
+	m.loadTrack = func(_ string) (*playlist.Track, error) {
+		return nil, errors.New("no such file")
+	}
+
+	_ = m.insertTrack(tracks[0].Path)
+	_ = m.insertTrack("./" + tracks[1].Path) // Same entry, spelled differently
+
+	if !strings.HasPrefix(m.statusMsg, "Track already in playlist") {
+		t.Errorf("Expected the respelled path rejected as a duplicate, got %q", m.statusMsg)
+	}
+
+	_ = m.insertTrack("missing.mp3")
+
+	if len(m.displayedTracks) != 3 {
+		t.Errorf("Expected track list unchanged, got %d tracks", len(m.displayedTracks))
+	}
+
+	if m.undoMgr.UndoSize() != 0 {
+		t.Errorf("Expected no undo state for rejected inserts, got %d", m.undoMgr.UndoSize())
+	}
+}
+
+func TestResolveTrackMatchesIdentity(t *testing.T) {
+	// The same file listed twice, with a different override per entry
+	tracks := []playlist.Track{{Path: "A", Energy: 3}, {Path: "A", Energy: 8}}
+	playlist.AssignIDs(tracks, ".")
+	m := createTestModel(tracks)
+
+	m.loadTrack = func(path string) (*playlist.Track, error) {
+		return &playlist.Track{Path: path}, nil
+	}
+
+	if track, ok := m.resolveTrack("A", tracks[1].ID); !ok || track.Energy != 8 {
+		t.Errorf("Expected the second entry (energy 8), got %+v", track)
+	}
+
+	// Older histories have no IDs: the path resolves to the first entry
+	if track, ok := m.resolveTrack("A", ""); !ok || track.Energy != 3 {
+		t.Errorf("Expected the first entry (energy 3), got %+v", track)
+	}
+
+	if track, ok := m.resolveTrack("B", ""); !ok || track.Path != "B" || track.Energy != 0 {
+		t.Errorf("Expected B loaded from disk, got %+v", track)
+	}
+}
+
+func TestInsertPromptInput(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := createTestModel(tracks)
+
+	var loaded string
+
+	m.loadTrack = func(path string) (*playlist.Track, error) {
+		loaded = path
+
+		return &playlist.Track{Path: path}, nil
+	}
+
+	m.openPrompt(promptInsertTrack, "Add: ")
+	_ = m.handlePromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a b")})
+	_ = m.handlePromptKey(tea.KeyMsg{Type: tea.KeyBackspace})
+	_ = m.handlePromptKey(tea.KeyMsg{Type: tea.KeySpace})
+	_ = m.handlePromptKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c.mp3")})
+	_ = m.handlePromptKey(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.prompt.active() {
+		t.Error("Expected prompt to close after submit")
+	}
+
+	if loaded != "a  c.mp3" {
+		t.Errorf("Expected submitted path %q, got %q", "a  c.mp3", loaded)
+	}
+}
+
+func TestGAOverwriteCheckpoint(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := createTestModel(tracks)
+
+	reordered := []playlist.Track{tracks[2], tracks[0], tracks[1]}
+
+	updated, _ := m.Update(Update{BestPlaylist: reordered, BestFitness: 1.0, Epoch: m.gaEpoch})
+	m = updated.(model)
+
+	if m.undoMgr.UndoSize() != 1 {
+		t.Fatalf("Expected loaded order checkpointed on first GA reorder, got %d undo states", m.undoMgr.UndoSize())
+	}
+
+	// Further GA improvements don't add checkpoints
+	updated, _ = m.Update(Update{BestPlaylist: tracks, BestFitness: 0.5, Epoch: m.gaEpoch})
+	m = updated.(model)
+
+	if m.undoMgr.UndoSize() != 1 {
+		t.Errorf("Expected a single GA checkpoint, got %d", m.undoMgr.UndoSize())
+	}
+
+	_ = m.undo()
+
+	if m.displayedTracks[0].Path != tracks[0].Path {
+		t.Errorf("Expected undo to restore loaded order, got first track %s", m.displayedTracks[0].Path)
+	}
+}
+
+func TestCompactLayout(t *testing.T) {
+	m := createTestModel(createTestTracks(3))
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	wide := updated.(model)
+
+	updated, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 50})
+	narrow := updated.(model)
+
+	if wide.compact || !narrow.compact {
+		t.Fatalf("Expected compact layout only below %d columns", compactWidth)
+	}
+
+	// Parameters are stacked below the playlist, leaving it fewer lines
+	if narrow.viewport.Height != wide.viewport.Height-narrow.paramsPanelHeight() {
+		t.Errorf("Expected compact viewport height %d, got %d",
+			wide.viewport.Height-narrow.paramsPanelHeight(), narrow.viewport.Height)
+	}
+
+	if !strings.Contains(wide.View(), "Test Album") {
+		t.Error("Expected Album column in the wide layout")
+	}
+
+	if strings.Contains(narrow.View(), "Test Album") {
+		t.Error("Expected no Album column in the compact layout")
+	}
+}
+
+func TestDurationRunningTotal(t *testing.T) {
+	tracks := createTestTracks(3)
+	tracks[0].Duration = 4 * time.Minute
+	tracks[1].Duration = 5*time.Minute + 30*time.Second
+
+	m := createTestModel(tracks)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 50})
+	m = updated.(model)
+	m.cursorPos = 2
+
+	if got := m.cursorStartTime(); got != 9*time.Minute+30*time.Second {
+		t.Errorf("Expected cursor track to start at 9:30, got %v", got)
+	}
+
+	if status := m.renderStatus(); !strings.Contains(status, "Track 3/3 @ 9:30/9:30 (1 unknown)") {
+		t.Errorf("Expected running and total time in status bar, got %q", status)
+	}
+
+	// Page down on an empty playlist leaves the cursor at -1
+	m.cursorPos = -1
+	if got := m.cursorStartTime(); got != 0 {
+		t.Errorf("Expected a cursor before the first track to start at 0, got %v", got)
+	}
+
+	if line := formatTrackLine(1, &m.displayedTracks[1], 0, false); !strings.Contains(line, " 5:30 ") {
+		t.Errorf("Expected duration column in track line, got %q", line)
+	}
+}
+
+func TestGAFinishedAndRestart(t *testing.T) {
+	m := createTestModel(createTestTracks(3))
+
+	// A run replaced by a restart (older epoch) is not "finished"
+	updated, _ := m.Update(gaFinishedMsg{epoch: m.gaEpoch - 1})
+	m = updated.(model)
+
+	if m.gaFinished {
+		t.Fatal("Expected stale GA run ending to be ignored")
+	}
+
+	updated, _ = m.Update(gaFinishedMsg{epoch: m.gaEpoch})
+	m = updated.(model)
+
+	if !m.gaFinished {
+		t.Fatal("Expected current GA run ending to show the finished state")
+	}
+
+	epoch := m.gaEpoch
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = updated.(model)
+
+	if m.gaEpoch != epoch+1 || cmd == nil {
+		t.Fatalf("Expected R to queue a GA restart with a new epoch, got epoch %d", m.gaEpoch)
+	}
+
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+
+	if m.gaFinished {
+		t.Error("Expected restart to clear the finished state")
+	}
+}
+
+func TestGARestartWaitsForPreviousRun(t *testing.T) {
+	m := createTestModel(createTestTracks(3))
+
+	var running, maxRunning atomic.Int32
+
+	started := make(chan int, 3)
+
+	m.runGA = func(ctx context.Context, _ []playlist.Track, _ map[string]playlist.TrackFlags, _ chan<- Update, epoch int) {
+		n := running.Add(1)
+		if n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+
+		started <- epoch
+
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond) // Slow teardown
+		running.Add(-1)
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	ctx3, cancel3 := context.WithCancel(context.Background())
+
+	results := make(chan tea.Msg, 3)
+	for _, run := range []tea.Cmd{
+		m.startGA(ctx1, m.displayedTracks, 1),
+		m.startGA(ctx2, m.displayedTracks, 2),
+		m.startGA(ctx3, m.displayedTracks, 3),
+	} {
+		go func() { results <- run() }()
+	}
+
+	if epoch := <-started; epoch != 1 {
+		t.Fatalf("Expected run 1 to start first, got %d", epoch)
+	}
+
+	// Run 2 is superseded before run 1 exits, so it must never start
+	cancel2()
+	cancel1()
+
+	if epoch := <-started; epoch != 3 {
+		t.Fatalf("Expected run 3 to start after run 1 exited, got %d", epoch)
+	}
+
+	cancel3()
+
+	for range 3 {
+		<-results
+	}
+
+	if got := maxRunning.Load(); got != 1 {
+		t.Errorf("Expected at most one GA run at a time, got %d", got)
+	}
+
+	if got := m.gaRuns.active.Load(); got != 0 {
+		t.Errorf("Expected no active runs after teardown, got %d", got)
+	}
+}
+
+func TestFocusModeLocksOutsideWindow(t *testing.T) {
+	m := createTestModel(createTestTracks(20))
+	m.localConfig.FocusRadius = 3
+	m.cursorPos = 10
+
+	if cmd := m.toggleFocus(); cmd == nil || m.gaEpoch != 1 {
+		t.Fatalf("Expected focus mode to restart the GA, got epoch %d", m.gaEpoch)
+	}
+
+	flags := m.gaFlags(m.displayedTracks)
+	for i, track := range m.displayedTracks {
+		if locked := flags[track.Identity()].Has(playlist.FlagLocked); locked != (i < 7 || i > 13) {
+			t.Errorf("Position %d: expected locked %v, got %v", i, !locked, locked)
+		}
+	}
+
+	if !m.flagsAt(0).Has(playlist.FlagLocked) || m.flagsAt(10).Has(playlist.FlagLocked) {
+		t.Error("Expected the markers to show the tracks outside the window as locked")
+	}
+
+	m.statusMsg = "" // Shown instead of the status line while fresh
+
+	if status := m.renderStatus(); !strings.Contains(status, "[FOCUS 8-14]") {
+		t.Errorf("Expected the focus window in the status bar, got %q", status)
+	}
+
+	if m.trackFlags[m.displayedTracks[0].Identity()].Has(playlist.FlagLocked) {
+		t.Error("Expected focus locks to stay out of the sticky flags")
+	}
+
+	_ = m.toggleFocus()
+
+	if flags := m.gaFlags(m.displayedTracks); flags[m.displayedTracks[0].Identity()].Has(playlist.FlagLocked) || m.flagsAt(0) != 0 {
+		t.Error("Expected no locks after leaving focus mode")
+	}
+}
+
+// trackTitles returns the titles of tracks in order
+func trackTitles(tracks []playlist.Track) []string {
+	titles := make([]string, len(tracks))
+	for i := range tracks {
+		titles[i] = tracks[i].Title
+	}
+
+	return titles
+}
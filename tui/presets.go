@@ -0,0 +1,83 @@
+// ABOUTME: Preset menu applying a built-in weight preset to the live parameters
+// ABOUTME: Replaces the parameter panel while open; the GA restarts with the preset's weights
+
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/config"
+)
+
+// togglePresetMenu shows or hides the preset menu in place of the parameter panel
+func (m *model) togglePresetMenu() {
+	m.showPresets = !m.showPresets
+	m.presetCursor = min(m.presetCursor, max(len(config.Presets())-1, 0))
+}
+
+// handlePresetKey handles keys while the preset menu is open
+func (m *model) handlePresetKey(msg tea.KeyMsg) tea.Cmd {
+	presets := config.Presets()
+
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.presetCursor > 0 {
+			m.presetCursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.presetCursor < len(presets)-1 {
+			m.presetCursor++
+		}
+
+	case msg.Type == tea.KeyEnter:
+		m.showPresets = false
+
+		if m.presetCursor < len(presets) {
+			return m.applyPreset(presets[m.presetCursor].Name)
+		}
+
+	case msg.Type == tea.KeyEsc, key.Matches(msg, keys.Presets):
+		m.showPresets = false
+	}
+
+	return nil
+}
+
+// applyPreset sets the parameters to a preset's weights and restarts the GA with them
+func (m *model) applyPreset(name string) tea.Cmd {
+	cfg, err := config.ApplyPreset(*m.localConfig, name)
+	if err != nil {
+		m.setStatusMsg(err.Error())
+
+		return nil
+	}
+
+	// Parameters point into localConfig, so they follow
+	*m.localConfig = cfg
+
+	m.setStatusMsg("Applied preset: " + name)
+
+	return m.syncConfigToGA()
+}
+
+// renderPresets renders the preset menu
+func (m model) renderPresets() string {
+	var s string
+
+	s += titleStyle.Render("► Presets (enter: apply, esc: close)") + "\n\n"
+
+	for i, p := range config.Presets() {
+		name := p.Name
+		if i == m.presetCursor {
+			name = cursorStyle.Render(name)
+		}
+
+		s += fmt.Sprintf("%s\n  %s\n", name, helpStyle.Render(p.Description))
+	}
+
+	return s
+}
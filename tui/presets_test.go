@@ -0,0 +1,47 @@
+// ABOUTME: Tests for the TUI preset menu
+// ABOUTME: Verifies applying a preset updates the live parameters and restarts the GA
+
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/config"
+)
+
+func TestPresetMenuAppliesPreset(t *testing.T) {
+	m := createTestModel(createTestTracks(5))
+
+	m.togglePresetMenu()
+
+	if !m.showPresets {
+		t.Fatal("Expected the preset menu to open")
+	}
+
+	presets := config.Presets()
+
+	// Move to harmonic-strict (last) and apply it
+	for range presets {
+		m.handlePresetKey(tea.KeyMsg{Type: tea.KeyDown})
+	}
+
+	m.handlePresetKey(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.showPresets {
+		t.Error("Expected the menu to close after applying")
+	}
+
+	want, _ := config.ApplyPreset(config.DefaultConfig(), presets[len(presets)-1].Name)
+
+	// The parameter panel shows the preset's values and the GA runs with them
+	if *m.params[0].Value != want.HarmonicWeight || m.sharedConfig.Get().HarmonicWeight != want.HarmonicWeight {
+		t.Errorf("Expected harmonic weight %.2f, got param %.2f, shared %.2f",
+			want.HarmonicWeight, *m.params[0].Value, m.sharedConfig.Get().HarmonicWeight)
+	}
+
+	if m.gaEpoch != 1 {
+		t.Errorf("Expected the GA to restart, epoch %d", m.gaEpoch)
+	}
+}
@@ -0,0 +1,105 @@
+// ABOUTME: External audio preview for auditioning tracks without leaving the TUI
+// ABOUTME: Launches a configurable player command in the background for the track under the cursor
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// previewPathPlaceholder is replaced with the track path in preview command templates
+const previewPathPlaceholder = "{path}"
+
+// previewer owns the external player process so only one preview plays at a time.
+// Shared by pointer because Bubble Tea copies the model on every Update.
+type previewer struct {
+	mu  sync.Mutex
+	cmd *exec.Cmd
+}
+
+// buildPreviewCommand splits the command template on whitespace and inserts the track path.
+// The path replaces a "{path}" argument if present, otherwise it is appended as the last argument.
+func buildPreviewCommand(template, trackPath string) ([]string, error) {
+	fields := strings.Fields(template)
+	if len(fields) == 0 {
+		return nil, errors.New("preview command is empty")
+	}
+
+	substituted := false
+
+	for i, field := range fields {
+		if strings.Contains(field, previewPathPlaceholder) {
+			fields[i] = strings.ReplaceAll(field, previewPathPlaceholder, trackPath)
+			substituted = true
+		}
+	}
+
+	if !substituted {
+		fields = append(fields, trackPath)
+	}
+
+	return fields, nil
+}
+
+// resolveTrackPath resolves a playlist-relative track path against the playlist's directory
+func resolveTrackPath(playlistPath, trackPath string) string {
+	if filepath.IsAbs(trackPath) {
+		return trackPath
+	}
+
+	return filepath.Join(filepath.Dir(playlistPath), trackPath)
+}
+
+// start stops any running preview and launches a new player process in the background
+func (p *previewer) start(args []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopLocked()
+
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // Command is user-configured by design
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start preview: %w", err)
+	}
+
+	p.cmd = cmd
+
+	// Reap the process when it exits on its own so it doesn't linger as a zombie
+	go func() {
+		_ = cmd.Wait()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if p.cmd == cmd {
+			p.cmd = nil
+		}
+	}()
+
+	return nil
+}
+
+// stop terminates the running preview, if any. Returns true if a preview was stopped.
+func (p *previewer) stop() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stopLocked()
+}
+
+// stopLocked terminates the running preview (caller must hold p.mu)
+func (p *previewer) stopLocked() bool {
+	if p.cmd == nil || p.cmd.Process == nil {
+		return false
+	}
+
+	_ = p.cmd.Process.Kill() // Process may already have exited
+	p.cmd = nil
+
+	return true
+}
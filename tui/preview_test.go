@@ -0,0 +1,85 @@
+// ABOUTME: Unit tests for external audio preview command building
+// ABOUTME: Verifies placeholder substitution, path appending, and relative path resolution
+
+package tui
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBuildPreviewCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		want     []string
+	}{
+		{
+			name:     "appends path",
+			template: "mpv --no-video --start=60",
+			path:     "/music/a.mp3",
+			want:     []string{"mpv", "--no-video", "--start=60", "/music/a.mp3"},
+		},
+		{
+			name:     "substitutes placeholder",
+			template: "ffplay {path} -nodisp",
+			path:     "/music/a.mp3",
+			want:     []string{"ffplay", "/music/a.mp3", "-nodisp"},
+		},
+		{
+			name:     "substitutes placeholder inside argument",
+			template: "player --file={path}",
+			path:     "/music/a.mp3",
+			want:     []string{"player", "--file=/music/a.mp3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildPreviewCommand(tt.template, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPreviewCommandEmpty(t *testing.T) {
+	if _, err := buildPreviewCommand("   ", "/music/a.mp3"); err == nil {
+		t.Error("Expected error for empty preview command")
+	}
+}
+
+func TestResolveTrackPath(t *testing.T) {
+	playlistPath := filepath.Join("/playlists", "set.m3u8")
+
+	if got := resolveTrackPath(playlistPath, "Artist/01 Track.mp3"); got != filepath.Join("/playlists", "Artist/01 Track.mp3") {
+		t.Errorf("Relative path not resolved against playlist dir: %s", got)
+	}
+
+	if got := resolveTrackPath(playlistPath, "/music/a.mp3"); got != "/music/a.mp3" {
+		t.Errorf("Absolute path should be unchanged: %s", got)
+	}
+}
+
+func TestPreviewTrackFailureSetsStatus(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := createTestModel(tracks)
+	m.localConfig.PreviewCommand = "playlist-sorter-nonexistent-player"
+
+	m.previewTrack()
+
+	if m.statusMsg == "" {
+		t.Error("Expected status message when preview command fails to start")
+	}
+
+	if m.preview.stop() {
+		t.Error("Expected no running preview after failed start")
+	}
+}
@@ -0,0 +1,94 @@
+// ABOUTME: Single-line text prompt shown in the status bar for commands that need input
+// ABOUTME: Handles rune entry, backspace, submit and cancel without external input widgets
+
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptKind identifies which command a prompt's input is for
+type promptKind int
+
+// Prompt kinds. promptNone means no prompt is active.
+const (
+	promptNone           promptKind = iota
+	promptInsertTrack               // Path of a track to insert after the cursor
+	promptSnapshotName              // Name to save the displayed order under
+	promptReloadPlaylist            // Whether to reload the playlist after an external change (y/N)
+	promptEditMetadata              // Key, BPM, energy and genre of the track under the cursor
+)
+
+// prompt holds the state of the active status bar prompt
+type prompt struct {
+	kind  promptKind
+	label string
+	value []rune
+}
+
+// active reports whether a prompt is currently accepting input
+func (p prompt) active() bool {
+	return p.kind != promptNone
+}
+
+// openPrompt starts collecting input for the given command
+func (m *model) openPrompt(kind promptKind, label string) {
+	m.prompt = prompt{kind: kind, label: label}
+}
+
+// closePrompt discards the active prompt
+func (m *model) closePrompt() {
+	m.prompt = prompt{}
+}
+
+// handlePromptKey routes key presses to the active prompt.
+// Returns a command when the submitted input triggers one (e.g. GA restart).
+func (m *model) handlePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type { //nolint:exhaustive // Only editing keys are relevant to the prompt
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.closePrompt()
+		m.setStatusMsg("Cancelled")
+
+	case tea.KeyEnter:
+		kind := m.prompt.kind
+		input := string(m.prompt.value)
+		m.closePrompt()
+
+		return m.submitPrompt(kind, input)
+
+	case tea.KeyBackspace:
+		if len(m.prompt.value) > 0 {
+			m.prompt.value = m.prompt.value[:len(m.prompt.value)-1]
+		}
+
+	case tea.KeySpace:
+		m.prompt.value = append(m.prompt.value, ' ')
+
+	case tea.KeyRunes:
+		m.prompt.value = append(m.prompt.value, msg.Runes...)
+	}
+
+	return nil
+}
+
+// submitPrompt dispatches submitted input to the command that opened the prompt
+func (m *model) submitPrompt(kind promptKind, input string) tea.Cmd {
+	switch kind {
+	case promptInsertTrack:
+		return m.insertTrack(input)
+	case promptSnapshotName:
+		m.saveSnapshot(input)
+	case promptReloadPlaylist:
+		return m.answerReload(input)
+	case promptEditMetadata:
+		return m.editMetadata(input)
+	case promptNone:
+	}
+
+	return nil
+}
+
+// render renders the prompt line with a trailing cursor
+func (p prompt) render() string {
+	return p.label + string(p.value) + "█"
+}
@@ -0,0 +1,294 @@
+// ABOUTME: Track quarantine: a soft remove that parks tracks outside the playlist and the GA
+// ABOUTME: Quarantined tracks are listed in a collapsible section below the playlist, kept across sessions and restorable any time
+
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+)
+
+const quarantineMaxRows = 5 // Quarantined tracks shown at once in the expanded section
+
+// quarantineFile is the on-disk form of a playlist's quarantine, so the tracks it holds (no longer
+// in the saved playlist) are still there in the next session
+type quarantineFile struct {
+	Playlist string   `json:"playlist"`
+	Paths    []string `json:"paths"`
+	IDs      []string `json:"ids"`
+}
+
+// quarantinePath returns where a playlist's quarantine is kept: next to its undo history
+func quarantinePath(playlistPath string) string {
+	return strings.TrimSuffix(undoHistoryPath(playlistPath), ".undo.json") + ".quarantine.json"
+}
+
+// quarantineTrack moves the track at the cursor from the playlist to the quarantine and restarts GA.
+// Undo puts the track back in the playlist, as does restoring it from the quarantine section.
+func (m *model) quarantineTrack() tea.Cmd {
+	if len(m.displayedTracks) == 0 {
+		return nil
+	}
+
+	// Save current state to undo stack
+	m.pushUndo()
+
+	track := m.displayedTracks[m.cursorPos]
+	m.quarantine = append(m.quarantine, track)
+	m.saveQuarantine()
+
+	m.setDisplayedTracks(slices.Delete(slices.Clone(m.displayedTracks), m.cursorPos, m.cursorPos+1))
+
+	// Set edit mode
+	m.editMode = true
+
+	// Increment epoch immediately to invalidate any pending GA updates
+	m.gaEpoch++
+
+	// Adjust cursor if needed
+	if m.cursorPos >= len(m.displayedTracks) && len(m.displayedTracks) > 0 {
+		m.cursorPos = len(m.displayedTracks) - 1
+	}
+
+	m.setStatusMsg(fmt.Sprintf("Quarantined %s (%d in quarantine, z: show)", track.Title, len(m.quarantine)))
+
+	// The section below the playlist may have grown
+	m.resize()
+
+	// Auto-save the edited playlist
+	m.autoSave()
+
+	// Restart GA without the quarantined track
+	return m.restartGA()
+}
+
+// toggleQuarantine expands or collapses the quarantine section
+func (m *model) toggleQuarantine() {
+	if len(m.quarantine) == 0 && !m.showQuarantine {
+		m.setStatusMsg("Quarantine is empty (D to quarantine a track)")
+
+		return
+	}
+
+	m.showQuarantine = !m.showQuarantine
+	m.quarantineCursor = min(m.quarantineCursor, max(len(m.quarantine)-1, 0))
+	m.resize()
+}
+
+// handleQuarantineKey handles keys while the quarantine section is expanded
+func (m *model) handleQuarantineKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.quarantineCursor > 0 {
+			m.quarantineCursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.quarantineCursor < len(m.quarantine)-1 {
+			m.quarantineCursor++
+		}
+
+	case msg.Type == tea.KeyEnter:
+		return m.restoreQuarantined(m.quarantineCursor)
+
+	case msg.Type == tea.KeyEsc, key.Matches(msg, keys.Quarantine):
+		m.toggleQuarantine()
+	}
+
+	return nil
+}
+
+// restoreQuarantined moves a quarantined track back into the playlist after the cursor and restarts GA
+func (m *model) restoreQuarantined(idx int) tea.Cmd {
+	if idx < 0 || idx >= len(m.quarantine) {
+		return nil
+	}
+
+	track := m.quarantine[idx]
+	m.quarantine = slices.Delete(m.quarantine, idx, idx+1)
+	m.saveQuarantine()
+
+	// Insert after the cursor, like an added track; the cursor follows it
+	insertPos := 0
+	if len(m.displayedTracks) > 0 {
+		insertPos = m.cursorPos + 1
+	}
+
+	m.setDisplayedTracks(slices.Insert(slices.Clone(m.displayedTracks), insertPos, track))
+	m.cursorPos = insertPos
+
+	// Set edit mode
+	m.editMode = true
+
+	// Increment epoch immediately to invalidate any pending GA updates
+	m.gaEpoch++
+
+	if len(m.quarantine) == 0 {
+		m.showQuarantine = false
+	}
+
+	m.quarantineCursor = min(m.quarantineCursor, max(len(m.quarantine)-1, 0))
+
+	m.setStatusMsg(fmt.Sprintf("Restored %s (%d left in quarantine)", track.Title, len(m.quarantine)))
+
+	// The section below the playlist may have shrunk
+	m.resize()
+
+	// Auto-save the edited playlist
+	m.autoSave()
+
+	// Restart GA with the restored track
+	return m.restartGA()
+}
+
+// pruneQuarantine drops quarantined tracks that are back in tracks (e.g. after an undo to an order
+// from before they were quarantined), so a track is never both in the playlist and quarantined
+func (m *model) pruneQuarantine(tracks []playlist.Track) {
+	if len(m.quarantine) == 0 {
+		return
+	}
+
+	present := make(map[string]bool, len(tracks))
+	for i := range tracks {
+		present[tracks[i].Identity()] = true
+	}
+
+	before := len(m.quarantine)
+	m.quarantine = slices.DeleteFunc(m.quarantine, func(t playlist.Track) bool { return present[t.Identity()] })
+
+	if len(m.quarantine) == before {
+		return
+	}
+
+	m.saveQuarantine()
+
+	if len(m.quarantine) == 0 {
+		m.showQuarantine = false
+	}
+
+	m.quarantineCursor = min(m.quarantineCursor, max(len(m.quarantine)-1, 0))
+}
+
+// saveQuarantine writes the quarantine to its state file, or removes the file once the quarantine
+// is empty. It is saved on every change, like the playlist the tracks were taken out of.
+func (m *model) saveQuarantine() {
+	if m.dryRun {
+		return
+	}
+
+	path := quarantinePath(m.playlistPath)
+
+	if len(m.quarantine) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			m.debugf("[TUI] Failed to remove quarantine: %v", err)
+		}
+
+		return
+	}
+
+	file := quarantineFile{Playlist: m.playlistPath}
+	for _, track := range m.quarantine {
+		file.Paths = append(file.Paths, track.Path)
+		file.IDs = append(file.IDs, track.ID)
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+	}
+
+	if err == nil {
+		err = os.WriteFile(path, data, 0o644) //nolint:gosec // Not sensitive
+	}
+
+	if err != nil {
+		m.debugf("[TUI] Failed to save quarantine: %v", err)
+	}
+}
+
+// loadQuarantine restores the quarantine saved by a previous session. Tracks that can't be loaded
+// any more or are back in the playlist are dropped. A missing file is not an error.
+func (m *model) loadQuarantine() error {
+	data, err := os.ReadFile(quarantinePath(m.playlistPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read quarantine: %w", err)
+	}
+
+	var file quarantineFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse quarantine: %w", err)
+	}
+
+	for i, path := range file.Paths {
+		id := ""
+		if len(file.IDs) == len(file.Paths) {
+			id = file.IDs[i]
+		}
+
+		if track, ok := m.resolveTrack(path, id); ok {
+			if id != "" {
+				track.ID = id
+			}
+
+			m.quarantine = append(m.quarantine, track)
+		}
+	}
+
+	m.pruneQuarantine(m.displayedTracks)
+
+	return nil
+}
+
+// quarantineHeight returns the number of lines the quarantine section takes below the playlist
+func (m model) quarantineHeight() int {
+	switch {
+	case len(m.quarantine) == 0:
+		return 0
+	case !m.showQuarantine:
+		return 1
+	default:
+		return 1 + min(len(m.quarantine), quarantineMaxRows)
+	}
+}
+
+// renderQuarantine renders the quarantine section: a one-line summary when collapsed, the
+// quarantined tracks around the selection when expanded (empty without quarantined tracks)
+func (m model) renderQuarantine() string {
+	if len(m.quarantine) == 0 {
+		return ""
+	}
+
+	if !m.showQuarantine {
+		return titleStyle.Render(fmt.Sprintf("▸ Quarantine: %d tracks (z: expand)", len(m.quarantine))) + "\n"
+	}
+
+	s := titleStyle.Render(fmt.Sprintf("▾ Quarantine: %d tracks (enter: restore after cursor, z/esc: collapse)", len(m.quarantine))) + "\n"
+
+	start := max(0, m.quarantineCursor-quarantineMaxRows+1)
+	end := min(len(m.quarantine), start+quarantineMaxRows)
+
+	for i := start; i < end; i++ {
+		line := formatTrackLine(i, &m.quarantine[i], 0, m.compact)
+
+		if i == m.quarantineCursor {
+			line = cursorStyle.Render(line)
+		}
+
+		s += line + "\n"
+	}
+
+	return s
+}
@@ -0,0 +1,129 @@
+// ABOUTME: Tests for the TUI track quarantine
+// ABOUTME: Verifies quarantined tracks leave the playlist, show in the section, can be restored and persist
+
+package tui
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+)
+
+func TestQuarantineAndRestore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // The quarantine is saved to the state directory
+
+	m := createTestModel(createTestTracks(5))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	m = updated.(model)
+	fullHeight := m.viewport.Height
+
+	m.cursorPos = 1
+	if cmd := m.quarantineTrack(); cmd == nil || m.gaEpoch != 1 {
+		t.Fatal("Expected quarantining to restart the GA")
+	}
+
+	if got := trackTitles(m.displayedTracks); !slices.Equal(got, []string{"A", "C", "D", "E"}) {
+		t.Errorf("Expected B out of the playlist, got %v", got)
+	}
+
+	if len(m.quarantine) != 1 || m.undoMgr.UndoSize() != 1 {
+		t.Errorf("Expected B quarantined with an undo entry, got %d quarantined, %d undo", len(m.quarantine), m.undoMgr.UndoSize())
+	}
+
+	if view := m.View(); !strings.Contains(view, "▸ Quarantine: 1 tracks") {
+		t.Errorf("Expected the collapsed section, got:\n%s", view)
+	}
+
+	// Expanded, the section takes room from the playlist and captures the keys
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	m = updated.(model)
+
+	if !m.showQuarantine || m.viewport.Height != fullHeight-2 {
+		t.Fatalf("Expected the section expanded over 2 lines, viewport %d of %d", m.viewport.Height, fullHeight)
+	}
+
+	m.cursorPos = 2
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if got := trackTitles(m.displayedTracks); !slices.Equal(got, []string{"A", "C", "D", "B", "E"}) {
+		t.Errorf("Expected B restored after the cursor, got %v", got)
+	}
+
+	if len(m.quarantine) != 0 || m.showQuarantine || m.cursorPos != 3 || m.viewport.Height != fullHeight {
+		t.Errorf("Expected an empty, collapsed quarantine with the cursor on B, got %d quarantined, cursor %d", len(m.quarantine), m.cursorPos)
+	}
+}
+
+func TestUndoPrunesQuarantine(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := createTestModel(createTestTracks(5))
+
+	m.cursorPos = 4
+	_ = m.deleteTrack()
+
+	m.cursorPos = 0
+	_ = m.quarantineTrack()
+
+	// Undoing back to before the delete also undoes the quarantine: A comes back and so leaves it
+	_ = m.undo()
+	_ = m.undo()
+
+	if len(m.displayedTracks) != 5 || len(m.quarantine) != 0 {
+		t.Errorf("Expected all 5 tracks back and none quarantined, got %d and %d", len(m.displayedTracks), len(m.quarantine))
+	}
+}
+
+func TestUndoQuarantine(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := createTestModel(createTestTracks(3))
+
+	m.cursorPos = 1
+	_ = m.quarantineTrack()
+	_ = m.undo()
+
+	if got := trackTitles(m.displayedTracks); !slices.Equal(got, []string{"A", "B", "C"}) || len(m.quarantine) != 0 {
+		t.Errorf("Expected undo to put B back, got %v with %d quarantined", got, len(m.quarantine))
+	}
+}
+
+func TestQuarantinePersists(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tracks := createTestTracks(4)
+	for i := range tracks {
+		tracks[i].ID = tracks[i].Path
+	}
+
+	m := createTestModel(tracks)
+	m.cursorPos = 2
+	_ = m.quarantineTrack()
+
+	// The next session loads the saved playlist, without C; C itself is read from disk again
+	next := createTestModel(slices.Clone(m.displayedTracks))
+	next.loadTrack = func(path string) (*playlist.Track, error) {
+		return &tracks[slices.IndexFunc(tracks, func(t playlist.Track) bool { return t.Path == path })], nil
+	}
+
+	if err := next.loadQuarantine(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := trackTitles(next.quarantine); !slices.Equal(got, []string{"C"}) {
+		t.Fatalf("Expected C still quarantined, got %v", got)
+	}
+
+	// Restoring the last track leaves nothing for a third session
+	_ = next.restoreQuarantined(0)
+
+	if _, err := os.Stat(quarantinePath(next.playlistPath)); !os.IsNotExist(err) {
+		t.Errorf("Expected the empty quarantine's file removed, got %v", err)
+	}
+}
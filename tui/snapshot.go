@@ -0,0 +1,221 @@
+// ABOUTME: Named in-memory snapshots of playlist orderings for comparing weight experiments
+// ABOUTME: Supports saving, listing, diffing against the current order, and restoring snapshots
+
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+	"playlist-sorter/termtext"
+)
+
+// snapshot is a named copy of a playlist ordering with the fitness it had when saved
+type snapshot struct {
+	name    string
+	tracks  []playlist.Track
+	fitness float64
+	taken   time.Time
+}
+
+// orderDiff summarizes how two orderings of (mostly) the same tracks differ
+type orderDiff struct {
+	moved            int // Tracks present in both but at a different position
+	added            int // Tracks only in the current order
+	removed          int // Tracks only in the snapshot
+	sharedNeighbours int // Adjacent pairs that appear in both orders
+	totalNeighbours  int // Adjacent pairs in the current order
+}
+
+// diffOrders compares a snapshot order against the current order, matching tracks by Identity
+func diffOrders(snap, current []playlist.Track) orderDiff {
+	var diff orderDiff
+
+	snapPos := make(map[string]int, len(snap))
+	for i := range snap {
+		snapPos[snap[i].Identity()] = i
+	}
+
+	currentKeys := make(map[string]bool, len(current))
+
+	for i := range current {
+		currentKeys[current[i].Identity()] = true
+
+		pos, ok := snapPos[current[i].Identity()]
+		switch {
+		case !ok:
+			diff.added++
+		case pos != i:
+			diff.moved++
+		}
+	}
+
+	for i := range snap {
+		if !currentKeys[snap[i].Identity()] {
+			diff.removed++
+		}
+	}
+
+	snapPairs := make(map[[2]string]bool, len(snap))
+	for i := 1; i < len(snap); i++ {
+		snapPairs[[2]string{snap[i-1].Identity(), snap[i].Identity()}] = true
+	}
+
+	for i := 1; i < len(current); i++ {
+		diff.totalNeighbours++
+
+		if snapPairs[[2]string{current[i-1].Identity(), current[i].Identity()}] {
+			diff.sharedNeighbours++
+		}
+	}
+
+	return diff
+}
+
+// String formats the diff for the snapshot list
+func (d orderDiff) String() string {
+	parts := []string{fmt.Sprintf("%d moved", d.moved)}
+
+	if d.added > 0 || d.removed > 0 {
+		parts = append(parts, fmt.Sprintf("+%d/-%d", d.added, d.removed))
+	}
+
+	if d.totalNeighbours > 0 {
+		parts = append(parts, fmt.Sprintf("%d%% pairs shared", d.sharedNeighbours*100/d.totalNeighbours))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// saveSnapshot stores the displayed order under name, replacing any snapshot with the same name
+func (m *model) saveSnapshot(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fmt.Sprintf("snapshot %d", len(m.snapshots)+1)
+	}
+
+	snap := snapshot{
+		name:    name,
+		tracks:  slices.Clone(m.displayedTracks),
+		fitness: m.bestFitness,
+		taken:   time.Now(),
+	}
+
+	if i := slices.IndexFunc(m.snapshots, func(s snapshot) bool { return s.name == name }); i >= 0 {
+		m.snapshots[i] = snap
+		m.setStatusMsg("Updated snapshot: " + name)
+
+		return
+	}
+
+	m.snapshots = append(m.snapshots, snap)
+	m.setStatusMsg(fmt.Sprintf("Saved snapshot: %s (%d total)", name, len(m.snapshots)))
+}
+
+// toggleSnapshotList shows or hides the snapshot list in place of the playlist panel
+func (m *model) toggleSnapshotList() {
+	if len(m.snapshots) == 0 && !m.showSnapshots {
+		m.setStatusMsg("No snapshots yet (s to save one)")
+
+		return
+	}
+
+	m.showSnapshots = !m.showSnapshots
+	m.snapshotCursor = min(m.snapshotCursor, max(len(m.snapshots)-1, 0))
+}
+
+// handleSnapshotKey handles keys while the snapshot list is open
+func (m *model) handleSnapshotKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.snapshotCursor > 0 {
+			m.snapshotCursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.snapshotCursor < len(m.snapshots)-1 {
+			m.snapshotCursor++
+		}
+
+	case msg.Type == tea.KeyEnter:
+		m.showSnapshots = false
+
+		return m.restoreSnapshot(m.snapshotCursor)
+
+	case msg.Type == tea.KeyEsc, key.Matches(msg, keys.Snapshots):
+		m.showSnapshots = false
+	}
+
+	return nil
+}
+
+// restoreSnapshot replaces the displayed order with a snapshot (undoable) and restarts GA
+func (m *model) restoreSnapshot(idx int) tea.Cmd {
+	if idx < 0 || idx >= len(m.snapshots) {
+		return nil
+	}
+
+	snap := m.snapshots[idx]
+
+	// A snapshot of an empty playlist would leave nothing to optimize
+	if len(snap.tracks) == 0 {
+		m.setStatusMsg(fmt.Sprintf("Snapshot %s is empty: not restored", snap.name))
+
+		return nil
+	}
+
+	// Save current state to undo stack
+	m.pushUndo()
+
+	m.setDisplayedTracks(slices.Clone(snap.tracks))
+	m.cursorPos = max(min(m.cursorPos, len(m.displayedTracks)-1), 0)
+	m.editMode = true
+
+	// Increment epoch immediately to invalidate any pending GA updates
+	m.gaEpoch++
+
+	m.setStatusMsg(fmt.Sprintf("Restored snapshot: %s (Undo: %d, Redo: %d)", snap.name, m.undoMgr.UndoSize(), m.undoMgr.RedoSize()))
+
+	m.ensureCursorVisible()
+	m.updateViewportContent()
+
+	// Auto-save the restored playlist
+	m.autoSave()
+
+	// Restart GA with restored tracks
+	return m.restartGA()
+}
+
+// renderSnapshots renders the snapshot list with a diff against the displayed order
+func (m model) renderSnapshots() string {
+	var s string
+
+	s += titleStyle.Render("► Snapshots (enter: restore, esc: close)") + "\n\n"
+
+	header := fmt.Sprintf("%-3s %-20s %-8s %-12s %s", "#", "Name", "Saved", "Fitness", "Diff vs current")
+	s += playlistHeaderStyle.Render(header) + "\n"
+
+	for i, snap := range m.snapshots {
+		line := fmt.Sprintf("%-3d %s %-8s %-12.8f %s",
+			i+1,
+			termtext.Fit(snap.name, 20),
+			snap.taken.Format("15:04:05"),
+			snap.fitness,
+			diffOrders(snap.tracks, m.displayedTracks),
+		)
+
+		if i == m.snapshotCursor {
+			line = cursorStyle.Render(line)
+		}
+
+		s += line + "\n"
+	}
+
+	return s
+}
@@ -0,0 +1,95 @@
+// ABOUTME: Unit tests for named playlist snapshots
+// ABOUTME: Verifies saving, replacing, diffing and restoring snapshot orderings
+
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+)
+
+func TestDiffOrders(t *testing.T) {
+	tracks := createTestTracks(4) // A B C D
+
+	same := diffOrders(tracks, tracks)
+	if same.moved != 0 || same.sharedNeighbours != 3 || same.totalNeighbours != 3 {
+		t.Errorf("Identical orders: got %+v", same)
+	}
+
+	// Swap first two: A B C D -> B A C D
+	swapped := []playlist.Track{tracks[1], tracks[0], tracks[2], tracks[3]}
+
+	diff := diffOrders(tracks, swapped)
+	if diff.moved != 2 {
+		t.Errorf("Expected 2 moved tracks, got %d", diff.moved)
+	}
+
+	// Only C->D survives as an adjacent pair
+	if diff.sharedNeighbours != 1 {
+		t.Errorf("Expected 1 shared pair, got %d", diff.sharedNeighbours)
+	}
+
+	// Snapshot without D, current has extra E
+	extra := playlist.Track{Path: "E"}
+	changed := diffOrders(tracks[:3], []playlist.Track{tracks[0], tracks[1], tracks[2], extra})
+
+	if changed.added != 1 || changed.removed != 0 {
+		t.Errorf("Expected +1/-0, got +%d/-%d", changed.added, changed.removed)
+	}
+}
+
+func TestSaveSnapshotReplacesByName(t *testing.T) {
+	m := createTestModel(createTestTracks(3))
+
+	m.saveSnapshot("")
+	m.saveSnapshot("experiment")
+	m.saveSnapshot("experiment")
+
+	if len(m.snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(m.snapshots))
+	}
+
+	if m.snapshots[0].name != "snapshot 1" {
+		t.Errorf("Expected default name 'snapshot 1', got %q", m.snapshots[0].name)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := createTestModel(tracks)
+
+	m.saveSnapshot("original")
+
+	m.displayedTracks = []playlist.Track{tracks[2], tracks[1], tracks[0]}
+
+	m.showSnapshots = true
+	_ = m.handleSnapshotKey(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.showSnapshots {
+		t.Error("Expected snapshot list to close after restore")
+	}
+
+	if m.displayedTracks[0].Path != tracks[0].Path {
+		t.Errorf("Expected restored order to start with %s, got %s", tracks[0].Path, m.displayedTracks[0].Path)
+	}
+
+	if m.undoMgr.UndoSize() != 1 {
+		t.Errorf("Expected restore to be undoable, got %d undo states", m.undoMgr.UndoSize())
+	}
+}
+
+func TestRestoreEmptySnapshot(t *testing.T) {
+	m := createTestModel(createTestTracks(3))
+	m.snapshots = []snapshot{{name: "empty"}}
+
+	if cmd := m.restoreSnapshot(0); cmd != nil {
+		t.Error("Expected an empty snapshot not to restart the GA")
+	}
+
+	if len(m.displayedTracks) != 3 || m.cursorPos != 0 || m.undoMgr.UndoSize() != 0 {
+		t.Errorf("Expected the playlist untouched, got %d tracks, cursor %d, %d undo states", len(m.displayedTracks), m.cursorPos, m.undoMgr.UndoSize())
+	}
+}
@@ -0,0 +1,159 @@
+// ABOUTME: Statistics panel summarizing the current order: tempo, energy, genres and key clashes
+// ABOUTME: Recomputed from the displayed tracks on every render, so it follows the GA live
+
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"playlist-sorter/playlist"
+)
+
+const (
+	statsBarWidth = 24 // Width of the longest energy histogram bar
+	statsGenres   = 6  // Genres listed before the rest are summed up
+)
+
+// renderStats summarizes tracks in order: BPM range and average, a histogram of energy levels,
+// the most common genres and the number of harmonically incompatible transitions
+func renderStats(tracks []playlist.Track) string {
+	if len(tracks) == 0 {
+		return "No tracks"
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Tracks: %d\n", len(tracks))
+	b.WriteString(statsBPM(tracks) + "\n\n")
+	b.WriteString(statsEnergy(tracks) + "\n")
+	b.WriteString(statsGenreCounts(tracks) + "\n")
+	b.WriteString(statsClashes(tracks))
+
+	return b.String()
+}
+
+// statsBPM returns the minimum, average and maximum BPM of the tracks that have one
+func statsBPM(tracks []playlist.Track) string {
+	var lo, hi, sum float64
+
+	n := 0
+
+	for _, t := range tracks {
+		if t.BPM <= 0 {
+			continue
+		}
+
+		if n == 0 || t.BPM < lo {
+			lo = t.BPM
+		}
+
+		hi = max(hi, t.BPM)
+		sum += t.BPM
+		n++
+	}
+
+	if n == 0 {
+		return "BPM: -"
+	}
+
+	return fmt.Sprintf("BPM: %.1f min, %.1f avg, %.1f max", lo, sum/float64(n), hi)
+}
+
+// statsEnergy draws a histogram of energy levels, one row per level from the lowest to the highest
+// present, plus a "?" row for tracks without an energy
+func statsEnergy(tracks []playlist.Track) string {
+	counts := make(map[int]int)
+	lo, hi, most := 0, 0, 0
+
+	for _, t := range tracks {
+		counts[t.Energy]++
+		most = max(most, counts[t.Energy])
+
+		if t.Energy > 0 {
+			if lo == 0 || t.Energy < lo {
+				lo = t.Energy
+			}
+
+			hi = max(hi, t.Energy)
+		}
+	}
+
+	var b strings.Builder
+
+	b.WriteString("Energy:\n")
+
+	row := func(label string, count int) {
+		bar := strings.Repeat("█", (count*statsBarWidth+most-1)/most)
+		fmt.Fprintf(&b, "%3s %-*s %d\n", label, statsBarWidth, bar, count)
+	}
+
+	for e := lo; e > 0 && e <= hi; e++ {
+		row(fmt.Sprint(e), counts[e])
+	}
+
+	if counts[0] > 0 {
+		row("?", counts[0])
+	}
+
+	return b.String()
+}
+
+// statsGenreCounts lists the most common genres with their track counts, most common first
+func statsGenreCounts(tracks []playlist.Track) string {
+	counts := make(map[string]int)
+
+	for _, t := range tracks {
+		genre := t.Genre
+		if genre == "" {
+			genre = "(none)"
+		}
+
+		counts[genre]++
+	}
+
+	genres := make([]string, 0, len(counts))
+	for g := range counts {
+		genres = append(genres, g)
+	}
+
+	slices.SortFunc(genres, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+
+	var b strings.Builder
+
+	b.WriteString("Genres:\n")
+
+	for i, g := range genres {
+		if i == statsGenres {
+			rest := 0
+			for _, other := range genres[i:] {
+				rest += counts[other]
+			}
+
+			fmt.Fprintf(&b, "  %d more genres: %d\n", len(genres)-i, rest)
+
+			break
+		}
+
+		fmt.Fprintf(&b, "  %s: %d\n", g, counts[g])
+	}
+
+	return b.String()
+}
+
+// statsClashes counts the harmonically incompatible transitions
+func statsClashes(tracks []playlist.Track) string {
+	clashes := 0
+
+	for i := 1; i < len(tracks); i++ {
+		if playlist.IsHarmonicClash(playlist.HarmonicDistanceParsed(tracks[i-1].OutKey(), tracks[i].InKey())) {
+			clashes++
+		}
+	}
+
+	return fmt.Sprintf("Key clashes: %d of %d transitions", clashes, len(tracks)-1)
+}
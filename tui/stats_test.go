@@ -0,0 +1,65 @@
+// ABOUTME: Tests for the statistics panel
+// ABOUTME: Verifies BPM summary, energy histogram, genre counts and clash counting
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func TestRenderStats(t *testing.T) {
+	track := func(key string, bpm float64, energy int, genre string) playlist.Track {
+		tr := wheelTrack(key)
+		tr.BPM, tr.Energy, tr.Genre = bpm, energy, genre
+
+		return tr
+	}
+
+	tracks := []playlist.Track{
+		track("8A", 120, 4, "House"),
+		track("9A", 124, 6, "House"),
+		track("3B", 0, 6, "Techno"), // 9A -> 3B clashes
+		track("3B", 128, 0, ""),
+	}
+
+	out := renderStats(tracks)
+
+	for _, want := range []string{
+		"Tracks: 4",
+		"BPM: 120.0 min, 124.0 avg, 128.0 max",
+		"  4 " + strings.Repeat("█", statsBarWidth/2),
+		"  5 " + strings.Repeat(" ", statsBarWidth) + " 0",
+		"  6 " + strings.Repeat("█", statsBarWidth) + " 2",
+		"  ? ",
+		"  House: 2\n  (none): 1\n  Techno: 1",
+		"Key clashes: 1 of 3 transitions",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in stats:\n%s", want, out)
+		}
+	}
+
+	if got := renderStats(nil); got != "No tracks" {
+		t.Errorf("Expected a placeholder for no tracks, got %q", got)
+	}
+}
+
+func TestStatsGenreCountsSummarizesRest(t *testing.T) {
+	var tracks []playlist.Track
+	for _, g := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "H"} {
+		tracks = append(tracks, playlist.Track{Genre: g})
+	}
+
+	out := statsGenreCounts(tracks)
+
+	if !strings.HasPrefix(out, "Genres:\n  H: 2\n") {
+		t.Errorf("Expected the most common genre first:\n%s", out)
+	}
+
+	if !strings.Contains(out, "2 more genres: 2") {
+		t.Errorf("Expected genres past the first %d summed up:\n%s", statsGenres, out)
+	}
+}
@@ -4,6 +4,7 @@
 package tui
 
 import (
+	"path/filepath"
 	"testing"
 
 	"playlist-sorter/playlist"
@@ -14,6 +15,7 @@ func createTestState(trackCount, cursorPos int) PlaylistState {
 	for i := range tracks {
 		tracks[i] = playlist.Track{
 			Index: i,
+			Path:  string(rune('A' + i)),
 			Title: string(rune('A' + i)),
 		}
 	}
@@ -308,3 +310,63 @@ func TestUndoManager_SizeTracking(t *testing.T) {
 		t.Errorf("After undo, redo size = %d, want 1", um.RedoSize())
 	}
 }
+
+func TestUndoManager_SaveAndLoad(t *testing.T) {
+	um := NewUndoManager(50)
+	um.Push(createTestState(5, 2))
+	um.Push(createTestState(4, 1))
+
+	// Undo once - the undone state becomes redo history and is not persisted
+	_, _ = um.Undo(createTestState(3, 0))
+
+	path := filepath.Join(t.TempDir(), "history.undo.json")
+	if err := um.Save(path, "set.m3u8"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Resolve every title except "E", simulating a track that no longer exists
+	resolve := func(p, _ string) (playlist.Track, bool) {
+		if p == "E" {
+			return playlist.Track{}, false
+		}
+
+		return playlist.Track{Path: p, Title: p}, true
+	}
+
+	loaded := NewUndoManager(50)
+	if err := loaded.Load(path, resolve); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if loaded.UndoSize() != 1 {
+		t.Fatalf("Expected 1 undoable state after load, got %d", loaded.UndoSize())
+	}
+
+	restored, ok := loaded.Undo(createTestState(3, 0))
+	if !ok {
+		t.Fatal("Undo should succeed after load")
+	}
+
+	if len(restored.Tracks) != 4 {
+		t.Errorf("Expected 4 tracks (unresolvable track dropped), got %d", len(restored.Tracks))
+	}
+
+	if restored.CursorPos != 2 {
+		t.Errorf("Expected cursor 2, got %d", restored.CursorPos)
+	}
+}
+
+func TestUndoManager_LoadMissingFile(t *testing.T) {
+	um := NewUndoManager(50)
+
+	err := um.Load(filepath.Join(t.TempDir(), "missing.json"), func(_, _ string) (playlist.Track, bool) {
+		return playlist.Track{}, false
+	})
+	if err != nil {
+		t.Errorf("Expected no error for missing history file, got %v", err)
+	}
+
+	if um.UndoSize() != 0 {
+		t.Errorf("Expected empty history, got %d", um.UndoSize())
+	}
+}
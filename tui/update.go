@@ -5,6 +5,8 @@ package tui
 
 import (
 	"context"
+	"fmt"
+	"runtime"
 	"runtime/debug"
 	"time"
 
@@ -30,29 +32,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-
-		// Calculate viewport dimensions
-		// Right panel width: total width - left panel - padding
-		viewportWidth := msg.Width - paramPanelWidth - panelPadding
-		if viewportWidth < minViewportWidth {
-			viewportWidth = minViewportWidth
-		}
-
-		// Height: total height minus all UI chrome (title, header, status, breakdown, help, spacing)
-		viewportHeight := msg.Height - totalUIChrome
-		if viewportHeight < minViewportHeight {
-			viewportHeight = minViewportHeight
-		}
-
-		m.viewport.Width = viewportWidth
-		m.viewport.Height = viewportHeight
-
-		// Ensure viewport starts at top
-		m.viewport.YOffset = 0
-		m.ensureCursorVisible()
-
-		// Update viewport content
-		m.updateViewportContent()
+		m.compact = msg.Width < compactWidth
+		m.resize()
 
 		return m, nil
 
@@ -78,6 +59,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		fitnessImproved := false
 
 		if tracksChanged {
+			// Keep the order the GA is about to overwrite undoable
+			m.checkpointGAOverwrite()
+
 			// Track order changed - this is a real improvement
 			oldFitness := m.bestFitness
 			if oldFitness == 0 {
@@ -100,12 +84,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.bestPlaylist = msg.BestPlaylist
 		m.bestFitness = msg.BestFitness
 		m.breakdown = msg.Breakdown
+		m.shownEpoch = msg.Epoch
 		m.generation = msg.Generation
 		m.genPerSec = msg.GenPerSec
+		m.optimal = msg.Optimal
 		m.timeSinceImprovement = time.Since(m.lastImprovementTime)
 
 		// Update m.displayedTracks with GA results (always show latest improvements)
 		m.displayedTracks = msg.BestPlaylist
+		m.displayedFlags = msg.Flags
 		m.updateViewportContent()
 
 		if fitnessImproved {
@@ -113,7 +100,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Auto-save the best playlist to disk (unless dry-run mode)
-		if !m.dryRun && len(m.bestPlaylist) > 0 {
+		if !m.dryRun && !m.reloadPending() && len(m.bestPlaylist) > 0 {
 			if err := m.writePlaylist(m.outputPath, m.bestPlaylist); err != nil {
 				m.debugf("[TUI] Auto-save FAILED: %v", err)
 			} else if fitnessImproved {
@@ -124,9 +111,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Queue next update
 		return m, waitForUpdate(m.updateChan)
 
+	case diskPollMsg:
+		return m, m.handleDiskPoll(msg)
+
+	case diskReadMsg:
+		return m, m.handleDiskRead(msg)
+
+	case playlistReloadedMsg:
+		return m, m.applyReload(msg)
+
+	case gaFinishedMsg:
+		// Runs replaced by a restart are expected to end; only the current run stopping on its
+		// own (e.g. the GA's time cap) leaves the TUI without updates
+		if msg.epoch == m.gaEpoch && m.ctx.Err() == nil {
+			m.gaFinished = true
+			m.genPerSec = 0
+			m.debugf("[TUI] GA finished on its own (epoch %d, gen %d)", msg.epoch, m.generation)
+		}
+
+		return m, nil
+
 	case gaRestartMsg:
 		// GA restart requested - cancel old GA and start new one
 		m.cancel()
+		m.gaFinished = false
+		m.optimal = false
 		ctx, cancel := context.WithCancel(context.Background())
 		m.ctx = ctx
 		m.cancel = cancel
@@ -134,14 +143,33 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.genPerSec = 0
 		m.lastImprovementTime = time.Now()
 		// Note: gaEpoch already incremented in delete/undo/redo before queuing restart
-		// Note: Reuse existing m.updateChan - the converter goroutine runs for the entire TUI session
+		// Note: Reuse existing m.updateChan - its reader (waitForUpdate) re-queues itself on every
+		// Update for the whole session, so queuing another here would leak a blocked goroutine per restart
+		m.debugf("[TUI] Restarting GA (epoch %d, active runs %d, goroutines %d)", m.gaEpoch, m.gaRuns.active.Load(), runtime.NumGoroutine())
 
-		return m, tea.Batch(
-			m.startGA(m.ctx, m.displayedTracks, m.gaEpoch),
-			waitForUpdate(m.updateChan),
-		)
+		return m, m.startGA(m.ctx, m.displayedTracks, m.gaEpoch)
 
 	case tea.KeyMsg:
+		// An open prompt captures all keys until it is submitted or cancelled
+		if m.prompt.active() {
+			return m, m.handlePromptKey(msg)
+		}
+
+		// The snapshot list captures navigation keys while open (quit still works)
+		if m.showSnapshots && !key.Matches(msg, keys.Quit) {
+			return m, m.handleSnapshotKey(msg)
+		}
+
+		// Likewise the preset menu
+		if m.showPresets && !key.Matches(msg, keys.Quit) {
+			return m, m.handlePresetKey(msg)
+		}
+
+		// And the expanded quarantine section
+		if m.showQuarantine && !key.Matches(msg, keys.Quit) {
+			return m, m.handleQuarantineKey(msg)
+		}
+
 		switch {
 		case key.Matches(msg, keys.Quit):
 			return m.handleQuitKey()
@@ -182,25 +210,115 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, keys.Reset):
 			return m, m.resetToDefaults()
 
+		case key.Matches(msg, keys.Restart):
+			return m, m.restartOptimization()
+
+		case key.Matches(msg, keys.Nice):
+			m.toggleNiceMode()
+
+		case key.Matches(msg, keys.Insert):
+			m.openPrompt(promptInsertTrack, "Add track (path relative to playlist): ")
+
 		case key.Matches(msg, keys.Delete):
 			return m, m.deleteTrack()
 
+		case key.Matches(msg, keys.Edit):
+			m.openMetadataEdit()
+
 		case key.Matches(msg, keys.Undo):
 			return m, m.undo()
 
 		case key.Matches(msg, keys.Redo):
 			return m, m.redo()
+
+		case key.Matches(msg, keys.Swap):
+			return m, m.swapTracks()
+
+		case key.Matches(msg, keys.Preview):
+			m.previewTrack()
+
+		case key.Matches(msg, keys.StopPreview):
+			m.stopPreview()
+
+		case key.Matches(msg, keys.SaveSnapshot):
+			m.openPrompt(promptSnapshotName, fmt.Sprintf("Snapshot name (default: snapshot %d): ", len(m.snapshots)+1))
+
+		case key.Matches(msg, keys.Snapshots):
+			m.toggleSnapshotList()
+
+		case key.Matches(msg, keys.Wheel):
+			m.showWheel = !m.showWheel
+			m.showStats = false
+
+		case key.Matches(msg, keys.Stats):
+			m.showStats = !m.showStats
+			m.showWheel = false
+
+		case key.Matches(msg, keys.Export):
+			m.exportView()
+
+		case key.Matches(msg, keys.Presets):
+			m.togglePresetMenu()
+
+		case key.Matches(msg, keys.Focus):
+			return m, m.toggleFocus()
+
+		case key.Matches(msg, keys.SoftDelete):
+			return m, m.quarantineTrack()
+
+		case key.Matches(msg, keys.Quarantine):
+			m.toggleQuarantine()
 		}
 	}
 
 	return m, nil
 }
 
+// resize fits the playlist viewport to the terminal size and the quarantine section below it
+func (m *model) resize() {
+	// Calculate viewport dimensions
+	// Right panel width: total width - left panel - padding
+	viewportWidth := m.width - paramPanelWidth - panelPadding
+
+	// Height: total height minus all UI chrome (title, header, status, breakdown, help, spacing)
+	viewportHeight := m.height - totalUIChrome - m.quarantineHeight()
+
+	// Compact layout: the playlist gets the full width and the parameters are stacked below it.
+	// In dual mode there is no parameter panel at all.
+	if m.compact || m.dual {
+		viewportWidth = m.width - panelPadding
+	}
+
+	if m.compact && !m.dual {
+		viewportHeight -= m.paramsPanelHeight()
+	}
+
+	if viewportWidth < minViewportWidth {
+		viewportWidth = minViewportWidth
+	}
+
+	if viewportHeight < minViewportHeight {
+		viewportHeight = minViewportHeight
+	}
+
+	m.viewport.Width = viewportWidth
+	m.viewport.Height = viewportHeight
+
+	// Ensure viewport starts at top
+	m.viewport.YOffset = 0
+	m.ensureCursorVisible()
+
+	// Update viewport content
+	m.updateViewportContent()
+}
+
 // handleQuitKey handles the quit key press
 func (m *model) handleQuitKey() (model, tea.Cmd) {
 	m.quitting = true
 	// Cancel GA context
 	m.cancel()
+	// Stop any running audio preview
+	m.preview.stop()
 	// Save config on quit (don't block quit on failure)
 	if err := config.SaveConfig(m.configPath, m.sharedConfig.Get()); err != nil {
 		m.debugf("[TUI] Failed to save config on quit: %v", err)
@@ -5,11 +5,18 @@ package tui
 
 import (
 	"fmt"
+	"math"
+	"path/filepath"
+	"runtime"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"playlist-sorter/playlist"
+	"playlist-sorter/termtext"
 )
 
 // View renders the TUI
@@ -28,7 +35,23 @@ func (m model) View() string {
 
 	// Build the UI in two columns
 	leftPanel := m.renderParameters()
-	rightPanel := m.renderPlaylist()
+	if m.showWheel {
+		leftPanel = m.renderWheel()
+	}
+
+	if m.showStats {
+		leftPanel = m.renderStats()
+	}
+
+	if m.showPresets {
+		leftPanel = m.renderPresets()
+	}
+
+	rightPanel := m.renderPlaylistPanel()
+
+	if m.compact {
+		return m.renderCompact(leftPanel, rightPanel)
+	}
 
 	// Create styles for the two panels with fixed widths
 	// Both panels should have same height for proper horizontal joining
@@ -64,6 +87,26 @@ func (m model) View() string {
 	return combined + "\n" + statusBar + "\n" + breakdown + "\n" + m.renderHelp()
 }
 
+// renderCompact stacks the parameter panel below the playlist for narrow terminals
+func (m model) renderCompact(paramsPanel, playlistPanel string) string {
+	panelStyle := lipgloss.NewStyle().
+		Width(m.width).
+		Padding(0, 1)
+
+	combined := lipgloss.JoinVertical(
+		lipgloss.Left,
+		panelStyle.Render(playlistPanel),
+		panelStyle.Render(paramsPanel),
+	)
+
+	return combined + "\n" + m.renderStatus() + "\n" + m.renderBreakdown() + "\n" + m.renderHelp()
+}
+
+// paramsPanelHeight returns the number of lines renderParameters uses
+func (m model) paramsPanelHeight() int {
+	return titleHeight + len(m.params)
+}
+
 // renderParameters renders the parameter control panel
 func (m model) renderParameters() string {
 	var s string
@@ -105,6 +148,25 @@ func (m model) renderParameters() string {
 	return s
 }
 
+// renderWheel renders the Camelot wheel for the displayed order, following the cursor
+func (m model) renderWheel() string {
+	return titleStyle.Render("Camelot wheel") + "\n\n" + renderCamelotWheel(m.displayedTracks, m.cursorPos)
+}
+
+// renderStats renders the statistics of the displayed order
+func (m model) renderStats() string {
+	return titleStyle.Render("Playlist statistics") + "\n\n" + renderStats(m.displayedTracks)
+}
+
+// renderPlaylistPanel renders the playlist panel: the playlist, or the snapshot list while it is open
+func (m model) renderPlaylistPanel() string {
+	if m.showSnapshots {
+		return m.renderSnapshots()
+	}
+
+	return m.renderPlaylist()
+}
+
 // renderPlaylist renders the playlist preview with viewport scrolling
 func (m model) renderPlaylist() string {
 	var s string
@@ -118,16 +180,23 @@ func (m model) renderPlaylist() string {
 		title = "► " + title + " [FOCUSED]"
 	}
 
+	title += " - " + playlist.TotalDurationLabel(m.displayedTracks)
+
+	if m.dual {
+		title = filepath.Base(m.playlistPath) + ": " + title
+	}
+
 	s += titleStyle.Render(title) + "\n\n"
 
-	// Header
-	header := fmt.Sprintf("%-3s %-4s %-4s %-3s %-20s %-30s %-20s %-15s",
-		"#", "Key", "BPM", "Eng", "Artist", "Title", "Album", "Genre")
-	s += playlistHeaderStyle.Render(header) + "\n"
+	s += playlistHeaderStyle.Render(trackTableHeader(m.compact)) + "\n"
 
 	// Render viewport (content should be set in Update())
 	s += m.viewport.View()
 
+	if section := m.renderQuarantine(); section != "" {
+		s += "\n" + section
+	}
+
 	return s
 }
 
@@ -137,22 +206,8 @@ func (m *model) updateViewportContent() {
 	var content string
 
 	// Render all tracks - viewport will handle scrolling via YOffset
-	for i, track := range m.displayedTracks {
-		artist := truncate(track.Artist, 20)
-		title := truncate(track.Title, 30)
-		album := truncate(track.Album, 20)
-		genre := truncate(track.Genre, 15)
-
-		line := fmt.Sprintf("%-3d %-4s %-4.0f %-3d %-20s %-30s %-20s %-15s",
-			i+1,
-			track.Key,
-			track.BPM,
-			track.Energy,
-			artist,
-			title,
-			album,
-			genre,
-		)
+	for i := range m.displayedTracks {
+		line := formatTrackLine(i, &m.displayedTracks[i], m.flagsAt(i), m.compact)
 
 		// Highlight cursor line
 		if i == m.cursorPos {
@@ -165,8 +220,63 @@ func (m *model) updateViewportContent() {
 	m.viewport.SetContent(content)
 }
 
+// flagsAt returns the flags of the displayed track at position i (none if unknown)
+func (m model) flagsAt(i int) playlist.TrackFlags {
+	if i < len(m.displayedFlags) {
+		return m.displayedFlags[i]
+	}
+
+	return 0
+}
+
+// trackTableHeader returns the column header matching formatTrackLine
+func trackTableHeader(compact bool) string {
+	if compact {
+		return fmt.Sprintf("%-4s %-4s %-4s %-3s %5s %-20s %-30s",
+			"#", "Key", "BPM", "Eng", "Time", "Artist", "Title")
+	}
+
+	return fmt.Sprintf("%-4s %-4s %-4s %-3s %5s %-20s %-30s %-20s %-15s",
+		"#", "Key", "BPM", "Eng", "Time", "Artist", "Title", "Album", "Genre")
+}
+
+// formatTrackLine formats one playlist row (i is the 0-based position), with the flag marker after the number.
+// The compact row drops the Album and Genre columns for narrow terminals.
+func formatTrackLine(i int, track *playlist.Track, flags playlist.TrackFlags, compact bool) string {
+	if compact {
+		return fmt.Sprintf("%-3d%s %-4s %-4.0f %-3s %5s %s %s",
+			i+1,
+			flags.Marker(),
+			track.Key,
+			track.BPM,
+			track.EnergyLabel(),
+			track.DurationLabel(),
+			termtext.Fit(track.Artist, 20),
+			termtext.Truncate(track.Title, 30),
+		)
+	}
+
+	return fmt.Sprintf("%-3d%s %-4s %-4.0f %-3s %5s %s %s %s %s",
+		i+1,
+		flags.Marker(),
+		track.Key,
+		track.BPM,
+		track.EnergyLabel(),
+		track.DurationLabel(),
+		termtext.Fit(track.Artist, 20),
+		termtext.Fit(track.Title, 30),
+		termtext.Fit(track.Album, 20),
+		termtext.Fit(track.Genre, 15),
+	)
+}
+
 // renderStatus renders the status bar
 func (m model) renderStatus() string {
+	// An active prompt takes over the status bar
+	if m.prompt.active() {
+		return statusStyle.Width(m.width).Render(m.prompt.render())
+	}
+
 	// Show status message if recent
 	if m.statusMsg != "" && time.Since(m.statusMsgAge) < statusMessageDuration {
 		return statusStyle.Width(m.width).Render(m.statusMsg)
@@ -181,11 +291,13 @@ func (m model) renderStatus() string {
 		deltaStr = fmt.Sprintf(" | -%0.8f", m.lastImprovementDelta)
 	}
 
-	// Track info
-	trackInfo := fmt.Sprintf("%d tracks | Track %d/%d",
+	// Track info, with when the cursor track starts out of the total time
+	trackInfo := fmt.Sprintf("%d tracks | Track %d/%d @ %s/%s",
 		len(m.displayedTracks),
 		m.cursorPos+1,
 		len(m.displayedTracks),
+		playlist.FormatDuration(m.cursorStartTime()),
+		playlist.TotalDurationLabel(m.displayedTracks),
 	)
 
 	// Undo/redo info
@@ -197,10 +309,38 @@ func (m model) renderStatus() string {
 		editFlag = "[EDIT] "
 	}
 
-	status := fmt.Sprintf("%s%s | %s | Gen: %d (%.1f gen/s) | Fitness: %.8f | %s ago%s",
+	if m.nice {
+		editFlag += "[NICE] "
+	}
+
+	if marked := m.swapMarkPos(); marked >= 0 {
+		editFlag += fmt.Sprintf("[SWAP FROM %d] ", marked+1)
+	}
+
+	if m.focused {
+		editFlag += fmt.Sprintf("[FOCUS %d-%d] ", m.focusLo+1, m.focusHi+1)
+	}
+
+	switch {
+	case m.optimal:
+		editFlag += "[OPTIMAL] "
+	case m.gaFinished:
+		editFlag += "[GA FINISHED - R: restart] "
+	}
+
+	if len(m.skipped) > 0 {
+		editFlag += fmt.Sprintf("[%d SKIPPED] ", len(m.skipped))
+	}
+
+	if m.staleResult() {
+		editFlag += fmt.Sprintf("[STALE: epoch %d result] ", m.shownEpoch)
+	}
+
+	status := fmt.Sprintf("%s%s | %s | Epoch: %d | Gen: %d (%.1f gen/s) | Fitness: %.8f | %s ago%s",
 		editFlag,
 		trackInfo,
 		undoInfo,
+		m.gaEpoch,
 		m.generation,
 		m.genPerSec,
 		m.bestFitness,
@@ -208,9 +348,42 @@ func (m model) renderStatus() string {
 		deltaStr,
 	)
 
+	if m.localConfig != nil && m.localConfig.StatusTemplate != "" {
+		status = editFlag + m.expandStatusTemplate(m.localConfig.StatusTemplate)
+	}
+
+	if m.debugStats {
+		status += fmt.Sprintf(" | GA runs: %d, goroutines: %d", m.gaRuns.active.Load(), runtime.NumGoroutine())
+	}
+
 	return statusStyle.Width(m.width).Render(status)
 }
 
+// expandStatusTemplate fills in the placeholders of a user-defined status line (see
+// config.GAConfig.StatusTemplate); unknown ones are left as they are
+func (m model) expandStatusTemplate(template string) string {
+	delta := ""
+	if m.lastImprovementDelta != 0 {
+		delta = fmt.Sprintf("-%0.8f", m.lastImprovementDelta)
+	}
+
+	return strings.NewReplacer(
+		"{gen}", strconv.Itoa(m.generation),
+		"{gen/s}", fmt.Sprintf("%.1f", m.genPerSec),
+		"{fitness}", fmt.Sprintf("%.8f", m.bestFitness),
+		"{delta}", delta,
+		"{since}", m.timeSinceImprovement.Round(time.Second).String(),
+		"{tracks}", strconv.Itoa(len(m.displayedTracks)),
+	).Replace(template)
+}
+
+// cursorStartTime returns the running total at the cursor: the known lengths of the tracks before it
+func (m model) cursorStartTime() time.Duration {
+	total, _ := playlist.TotalDuration(m.displayedTracks[:min(max(m.cursorPos, 0), len(m.displayedTracks))])
+
+	return total
+}
+
 // renderBreakdown renders the fitness breakdown showing individual components
 func (m model) renderBreakdown() string {
 	if m.breakdown.Total == 0 {
@@ -218,20 +391,72 @@ func (m model) renderBreakdown() string {
 		return ""
 	}
 
-	breakdown := fmt.Sprintf(" Harmonic: %.4f | Energy: %.4f | BPM: %.4f | Genre: %.4f | Artist: %.4f | Album: %.4f | Bias: %.4f",
-		m.breakdown.Harmonic,
-		m.breakdown.EnergyDelta,
-		m.breakdown.BPMDelta,
-		m.breakdown.GenreChange,
-		m.breakdown.SameArtist,
-		m.breakdown.SameAlbum,
-		m.breakdown.PositionBias,
-	)
+	// Once the run after a weight change has settled, show how each component moved
+	var base *playlist.Breakdown
+	if m.deltaBase != nil && m.converged() {
+		base = m.deltaBase
+	}
+
+	return helpStyle.Render(formatBreakdown(m.breakdown, base))
+}
+
+// breakdownComponents lists the components shown in the breakdown line, in order. Optional ones
+// are only shown when active.
+var breakdownComponents = []struct {
+	name     string
+	value    func(playlist.Breakdown) float64
+	optional bool
+}{
+	{"Harmonic", func(b playlist.Breakdown) float64 { return b.Harmonic }, false},
+	{"Energy", func(b playlist.Breakdown) float64 { return b.EnergyDelta }, false},
+	{"BPM", func(b playlist.Breakdown) float64 { return b.BPMDelta }, false},
+	{"Genre", func(b playlist.Breakdown) float64 { return b.GenreChange }, false},
+	{"Artist", func(b playlist.Breakdown) float64 { return b.SameArtist }, false},
+	{"Album", func(b playlist.Breakdown) float64 { return b.SameAlbum }, false},
+	{"Bias", func(b playlist.Breakdown) float64 { return b.PositionBias }, false},
+	{"Shuffle", func(b playlist.Breakdown) float64 { return b.Shuffle }, true},
+	{"Novelty", func(b playlist.Breakdown) float64 { return b.Novelty }, true},
+	{"Wave", func(b playlist.Breakdown) float64 { return b.EnergyWave }, true},
+	{"Bands", func(b playlist.Breakdown) float64 { return b.BPMBand }, true},
+	{"Mix", func(b playlist.Breakdown) float64 { return b.MixLength }, true},
+	{"Favorites", func(b playlist.Breakdown) float64 { return b.Favorites }, true},
+	{"Fresh", func(b playlist.Breakdown) float64 { return b.Freshness }, true},
+	{"Bookends", func(b playlist.Breakdown) float64 { return b.Bookends }, true},
+}
+
+// breakdownDeltaMin is the smallest component change annotated (smaller ones round to +0.0000)
+const breakdownDeltaMin = 0.00005
+
+// formatBreakdown formats the fitness components. With a base (the breakdown under the previous
+// weights), components that moved are annotated with the change, e.g. "Harmonic: 0.1200 (-0.0300)";
+// optional components are then also shown when they just dropped to 0.
+func formatBreakdown(b playlist.Breakdown, base *playlist.Breakdown) string {
+	parts := make([]string, 0, len(breakdownComponents))
+
+	for _, c := range breakdownComponents {
+		value := c.value(b)
+
+		delta := 0.0
+		if base != nil {
+			delta = value - c.value(*base)
+		}
+
+		if c.optional && value == 0 && delta == 0 {
+			continue
+		}
+
+		part := fmt.Sprintf("%s: %.4f", c.name, value)
+		if math.Abs(delta) >= breakdownDeltaMin {
+			part += fmt.Sprintf(" (%+.4f)", delta)
+		}
+
+		parts = append(parts, part)
+	}
 
-	return helpStyle.Render(breakdown)
+	return " " + strings.Join(parts, " | ")
 }
 
 // renderHelp renders the help text
 func (m model) renderHelp() string {
-	return helpStyle.Render(" Tab: switch panel | ↑/↓/j/k: navigate | ←/→/h/l: adjust param (params panel) | Shift+↑/↓: select param | d: delete | u: undo | ctrl+r: redo | r: reset | q: quit")
+	return helpStyle.Render(" Tab: switch panel | ↑/↓/j/k: navigate | ←/→/h/l: adjust param (params panel) | Shift+↑/↓: select param | a: add | d: delete | D/z: quarantine/show | e: edit tags | u: undo | ctrl+r: redo | x: mark/swap | p/P: preview/stop | s/S: snapshot/list | w: wheel | i: stats | X: export | c: presets | f: focus | r: reset | R: restart GA | n: nice | q: quit")
 }
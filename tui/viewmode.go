@@ -0,0 +1,351 @@
+// ABOUTME: Read-only live view of a playlist file being optimized by another process
+// ABOUTME: Follows the run's live socket while it publishes, otherwise reloads the file when it changes
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/live"
+	"playlist-sorter/playlist"
+)
+
+// defaultViewPollInterval is how often view mode checks the playlist file for changes
+const defaultViewPollInterval = time.Second
+
+// viewChrome is the number of lines view mode uses outside the track viewport
+// (title, header, status, breakdown, help, spacing)
+const viewChrome = titleHeight + headerHeight + statusBarHeight + breakdownHeight + helpHeight + 1
+
+// ViewOptions configures the read-only view mode
+type ViewOptions struct {
+	PlaylistPath string        // Playlist file to watch
+	PollInterval time.Duration // How often to check for changes (defaults to 1s)
+	NoColor      bool          // Render without colors or text attributes
+
+	// Subscribe follows a sort run's live updates until the run ends, returning an error straight
+	// away when no run is publishing (nil: only watch the file). Retried at every poll.
+	Subscribe func(chan<- live.Update) error
+}
+
+// liveUpdateMsg carries an update published by the sort run
+type liveUpdateMsg live.Update
+
+// liveEndedMsg reports that following the run stopped (no run publishing, or it ended)
+type liveEndedMsg struct{}
+
+// viewPollMsg reports the watched file's modification time at a poll
+type viewPollMsg struct {
+	path    string // Working copy of a running sort (see playlist.WorkingCopyPath), or "" for the playlist
+	modTime time.Time
+	err     error
+}
+
+// viewLoadedMsg carries a freshly loaded and scored playlist
+type viewLoadedMsg struct {
+	path      string // File loaded, as in viewPollMsg
+	tracks    []playlist.Track
+	breakdown playlist.Breakdown
+	modTime   time.Time
+	err       error
+}
+
+// viewModel is the Bubble Tea model for view mode
+type viewModel struct {
+	opts  ViewOptions
+	load  func(string) ([]playlist.Track, error)
+	score func([]playlist.Track) playlist.Breakdown
+
+	tracks    []playlist.Track
+	breakdown playlist.Breakdown
+	viewed    string // Working copy shown, or "" for the playlist itself
+	following bool   // Subscribed to a sort run (possibly without an update yet)
+	live      bool   // Showing the run's live updates rather than the file
+	updates   chan live.Update
+	modTime   time.Time // Modification time of the loaded version
+	loadedAt  time.Time
+	reloads   int
+	err       error
+	viewport  viewport.Model
+	width     int
+	height    int
+	compact   bool // Narrow terminal: fewer columns (see compactWidth)
+	quitting  bool
+}
+
+// RunViewMode shows a live, read-only view of a playlist file, reloading it whenever it changes on disk.
+// Pair it with `sort` running in another terminal, which writes every improvement to the playlist's
+// working copy; the working copy is shown while it exists, the playlist itself otherwise.
+func RunViewMode(opts ViewOptions, load func(string) ([]playlist.Track, error), score func([]playlist.Track) playlist.Breakdown) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultViewPollInterval
+	}
+
+	if opts.NoColor {
+		usePlainStyles()
+	}
+
+	m := newViewModel(opts, load, score)
+
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("TUI error: %w", err)
+	}
+
+	return nil
+}
+
+// newViewModel creates a view mode model with injected loading and scoring
+func newViewModel(opts ViewOptions, load func(string) ([]playlist.Track, error), score func([]playlist.Track) playlist.Breakdown) viewModel {
+	return viewModel{
+		opts:     opts,
+		load:     load,
+		score:    score,
+		updates:  make(chan live.Update, 1),
+		viewport: viewport.New(minViewportWidth, minViewportHeight),
+	}
+}
+
+// Init loads the playlist immediately and looks for a run to follow
+func (m viewModel) Init() tea.Cmd {
+	return tea.Batch(m.pollNow(), m.waitForLive())
+}
+
+// follow subscribes to the sort run's live updates, reporting when that stops
+func (m viewModel) follow() tea.Cmd {
+	subscribe, updates := m.opts.Subscribe, m.updates
+
+	return func() tea.Msg {
+		_ = subscribe(updates)
+
+		return liveEndedMsg{}
+	}
+}
+
+// waitForLive waits for the next live update (from any subscription)
+func (m viewModel) waitForLive() tea.Cmd {
+	updates := m.updates
+
+	return func() tea.Msg {
+		return liveUpdateMsg(<-updates)
+	}
+}
+
+// pollNow stats the playlist file right away
+func (m viewModel) pollNow() tea.Cmd {
+	path := m.opts.PlaylistPath
+
+	return func() tea.Msg {
+		return statViewed(path)
+	}
+}
+
+// pollLater stats the playlist file after the poll interval
+func (m viewModel) pollLater() tea.Cmd {
+	path := m.opts.PlaylistPath
+
+	return tea.Tick(m.opts.PollInterval, func(time.Time) tea.Msg {
+		return statViewed(path)
+	})
+}
+
+// statViewed stats the file view mode shows for path: its working copy while a sort run has one,
+// the playlist otherwise
+func statViewed(path string) viewPollMsg {
+	working := playlist.WorkingCopyPath(path)
+	if modTime, err := statPlaylist(working); err == nil {
+		return viewPollMsg{path: working, modTime: modTime}
+	}
+
+	modTime, err := statPlaylist(path)
+
+	return viewPollMsg{modTime: modTime, err: err}
+}
+
+// statPlaylist returns a playlist file's modification time
+func statPlaylist(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
+
+// reload loads and scores the file a poll found changed (viewed: as in viewPollMsg) in the background
+func (m viewModel) reload(viewed string, modTime time.Time) tea.Cmd {
+	path, load, score := m.opts.PlaylistPath, m.load, m.score
+	if viewed != "" {
+		path = viewed
+	}
+
+	return func() tea.Msg {
+		tracks, err := load(path)
+		if err != nil {
+			return viewLoadedMsg{path: viewed, modTime: modTime, err: err}
+		}
+
+		return viewLoadedMsg{path: viewed, tracks: tracks, breakdown: score(tracks), modTime: modTime}
+	}
+}
+
+// Update handles polling, reloads, resizing and navigation
+func (m viewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.viewport.Width = max(msg.Width, minViewportWidth)
+		m.viewport.Height = max(msg.Height-viewChrome, minViewportHeight)
+		m.compact = msg.Width < compactWidth
+		m.updateViewportContent()
+
+		return m, nil
+
+	case viewPollMsg:
+		var follow tea.Cmd
+		if m.opts.Subscribe != nil && !m.following {
+			m.following = true
+			follow = m.follow()
+		}
+
+		if msg.err != nil {
+			m.err = msg.err
+
+			return m, tea.Batch(m.pollLater(), follow)
+		}
+
+		// Unchanged since the last load - keep polling
+		if msg.modTime.Equal(m.modTime) && msg.path == m.viewed {
+			return m, tea.Batch(m.pollLater(), follow)
+		}
+
+		return m, tea.Batch(m.reload(msg.path, msg.modTime), follow)
+
+	case liveUpdateMsg:
+		m.err = nil
+		m.live = true
+		m.tracks = msg.Tracks
+		m.breakdown = msg.Breakdown
+		m.loadedAt = time.Now()
+		m.reloads++
+		m.updateViewportContent()
+
+		return m, m.waitForLive()
+
+	case liveEndedMsg:
+		// The run wrote its result (or there was none): back to the file, which the next poll reloads
+		m.following = false
+
+		if m.live {
+			m.live = false
+			m.modTime = time.Time{}
+		}
+
+		return m, nil
+
+	case viewLoadedMsg:
+		// A failed load (e.g. file caught mid-write) is retried on the next poll since modTime isn't advanced
+		m.err = msg.err
+
+		switch {
+		case msg.err != nil:
+		case m.live:
+			// The run's updates are newer than the file; just note it as seen
+			m.viewed, m.modTime = msg.path, msg.modTime
+		default:
+			m.tracks = msg.tracks
+			m.breakdown = msg.breakdown
+			m.viewed = msg.path
+			m.modTime = msg.modTime
+			m.loadedAt = time.Now()
+			m.reloads++
+			m.updateViewportContent()
+		}
+
+		return m, m.pollLater()
+
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Quit) {
+			m.quitting = true
+
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+
+	return m, cmd
+}
+
+// updateViewportContent renders all tracks into the viewport
+func (m *viewModel) updateViewportContent() {
+	var content string
+
+	flags := playlist.PositionFlags(m.tracks, nil)
+
+	for i := range m.tracks {
+		content += formatTrackLine(i, &m.tracks[i], flags[i], m.compact) + "\n"
+	}
+
+	m.viewport.SetContent(content)
+}
+
+// View renders the read-only playlist view
+func (m viewModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	source := "read-only"
+
+	switch {
+	case m.live:
+		source = "live from sort run, read-only"
+	case m.viewed != "":
+		source = "live working copy, read-only"
+	}
+
+	s := titleStyle.Render("Watching: "+m.opts.PlaylistPath+" ("+source+")") + "\n\n"
+	s += playlistHeaderStyle.Render(trackTableHeader(m.compact)) + "\n"
+	s += m.viewport.View() + "\n"
+
+	var status string
+
+	switch {
+	case m.err != nil:
+		status = "Error: " + m.err.Error()
+	case m.reloads == 0:
+		status = "Loading..."
+	default:
+		updated := m.modTime
+		if m.live {
+			updated = m.loadedAt
+		}
+
+		status = fmt.Sprintf("%d tracks (%s) | Fitness: %.8f | Updated %s (%s ago) | Reloads: %d",
+			len(m.tracks),
+			playlist.TotalDurationLabel(m.tracks),
+			m.breakdown.Total,
+			updated.Format("15:04:05"),
+			time.Since(m.loadedAt).Round(time.Second),
+			m.reloads,
+		)
+	}
+
+	s += statusStyle.Width(m.width).Render(status) + "\n"
+
+	if m.breakdown.Total != 0 {
+		s += helpStyle.Render(formatBreakdown(m.breakdown, nil))
+	}
+
+	s += "\n" + helpStyle.Render(" ↑/↓/j/k: scroll | PgUp/PgDn: page | q: quit")
+
+	return s
+}
@@ -0,0 +1,221 @@
+// ABOUTME: Unit tests for the read-only view mode model
+// ABOUTME: Verifies reloads happen only when the file's modification time changes
+
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/live"
+	"playlist-sorter/playlist"
+)
+
+func TestViewModeReloadsOnlyOnChange(t *testing.T) {
+	tracks := createTestTracks(3)
+	loads := 0
+
+	load := func(_ string) ([]playlist.Track, error) {
+		loads++
+
+		return tracks, nil
+	}
+	score := func(_ []playlist.Track) playlist.Breakdown {
+		return playlist.Breakdown{Total: 1.5}
+	}
+
+	m := newViewModel(ViewOptions{PlaylistPath: "set.m3u8", PollInterval: time.Millisecond}, load, score)
+	modTime := time.Now()
+
+	// First poll sees a new modification time and triggers a reload
+	updated, cmd := m.Update(viewPollMsg{modTime: modTime})
+	m = updated.(viewModel)
+
+	loaded, ok := cmd().(viewLoadedMsg)
+	if !ok {
+		t.Fatal("Expected reload command after modification time changed")
+	}
+
+	updated, _ = m.Update(loaded)
+	m = updated.(viewModel)
+
+	if len(m.tracks) != 3 || m.breakdown.Total != 1.5 || m.reloads != 1 {
+		t.Errorf("Expected 3 tracks scored 1.5 after one reload, got %d tracks, %.1f, %d reloads", len(m.tracks), m.breakdown.Total, m.reloads)
+	}
+
+	// Polling the same modification time doesn't reload
+	updated, _ = m.Update(viewPollMsg{modTime: modTime})
+	m = updated.(viewModel)
+
+	if loads != 1 || m.reloads != 1 {
+		t.Errorf("Expected no reload for unchanged file, got %d loads", loads)
+	}
+}
+
+func TestViewModeKeepsLastGoodLoadOnError(t *testing.T) {
+	m := newViewModel(ViewOptions{PlaylistPath: "set.m3u8"}, nil, nil)
+	m.tracks = createTestTracks(2)
+	m.modTime = time.Unix(100, 0)
+
+	updated, _ := m.Update(viewLoadedMsg{modTime: time.Unix(200, 0), err: errors.New("partial write")})
+	m = updated.(viewModel)
+
+	if len(m.tracks) != 2 {
+		t.Errorf("Expected previous tracks to be kept, got %d", len(m.tracks))
+	}
+
+	if !m.modTime.Equal(time.Unix(100, 0)) {
+		t.Error("Modification time should not advance on a failed load, so the next poll retries")
+	}
+
+	if m.err == nil {
+		t.Error("Expected load error to be shown")
+	}
+}
+
+func TestViewModePrefersWorkingCopy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "set.m3u8")
+	if err := os.WriteFile(path, []byte("a.mp3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := statViewed(path); msg.err != nil || msg.path != "" {
+		t.Fatalf("Expected the playlist itself without a working copy, got %+v", msg)
+	}
+
+	working := playlist.WorkingCopyPath(path)
+	if err := os.WriteFile(working, []byte("b.mp3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := statViewed(path)
+	if msg.path != working {
+		t.Fatalf("Expected the working copy while a run has one, got %+v", msg)
+	}
+
+	var loaded string
+
+	load := func(p string) ([]playlist.Track, error) {
+		loaded = p
+
+		return createTestTracks(1), nil
+	}
+
+	m := newViewModel(ViewOptions{PlaylistPath: path}, load, func([]playlist.Track) playlist.Breakdown { return playlist.Breakdown{} })
+	m.modTime = msg.modTime // Same time as the playlist, but a different file: still a change
+
+	updated, cmd := m.Update(msg)
+	m = updated.(viewModel)
+
+	if cmd == nil {
+		t.Fatal("Expected switching to the working copy to reload")
+	}
+
+	updated, _ = m.Update(cmd())
+	m = updated.(viewModel)
+
+	if loaded != working || m.viewed != working {
+		t.Errorf("Expected the working copy to be loaded and shown, loaded %q", loaded)
+	}
+}
+
+func TestViewModeFollowsLiveRun(t *testing.T) {
+	load := func(_ string) ([]playlist.Track, error) { return createTestTracks(2), nil }
+	score := func(_ []playlist.Track) playlist.Breakdown { return playlist.Breakdown{Total: 2} }
+
+	subscribed := 0
+	opts := ViewOptions{PlaylistPath: "set.m3u8", PollInterval: time.Millisecond}
+	opts.Subscribe = func(chan<- live.Update) error {
+		subscribed++
+
+		return nil
+	}
+
+	m := newViewModel(opts, load, score)
+
+	// A poll starts following the run (once) besides loading the file
+	updated, _ := m.Update(viewPollMsg{modTime: time.Unix(100, 0)})
+	m = updated.(viewModel)
+
+	updated, _ = m.Update(viewPollMsg{modTime: time.Unix(100, 0)})
+	m = updated.(viewModel)
+
+	if !m.following {
+		t.Fatal("Expected a poll to start following the run")
+	}
+
+	updated, _ = m.Update(liveUpdateMsg{Tracks: createTestTracks(3), Breakdown: playlist.Breakdown{Total: 0.5}})
+	m = updated.(viewModel)
+
+	if !m.live || len(m.tracks) != 3 || m.breakdown.Total != 0.5 {
+		t.Fatalf("Expected the live update on display, got %d tracks scored %.1f", len(m.tracks), m.breakdown.Total)
+	}
+
+	// The unchanged file loading late doesn't replace the newer live order
+	updated, _ = m.Update(viewLoadedMsg{tracks: createTestTracks(2), breakdown: playlist.Breakdown{Total: 2}, modTime: time.Unix(100, 0)})
+	m = updated.(viewModel)
+
+	if len(m.tracks) != 3 {
+		t.Errorf("Expected the live order to stay, got %d tracks", len(m.tracks))
+	}
+
+	if !strings.Contains(m.View(), "live from sort run") {
+		t.Error("Expected the title to say the view follows the run")
+	}
+
+	// When the run ends, the file (holding its result) is reloaded at the next poll
+	updated, _ = m.Update(liveEndedMsg{})
+	m = updated.(viewModel)
+
+	if m.live || m.following {
+		t.Fatal("Expected following to stop when the run ends")
+	}
+
+	loads := 0
+	m.load = func(_ string) ([]playlist.Track, error) {
+		loads++
+
+		return createTestTracks(2), nil
+	}
+
+	updated, cmd := m.Update(viewPollMsg{modTime: time.Unix(100, 0)})
+	m = updated.(viewModel)
+
+	for _, msg := range batchMsgs(cmd) {
+		if loaded, ok := msg.(viewLoadedMsg); ok {
+			updated, _ = m.Update(loaded)
+			m = updated.(viewModel)
+		}
+	}
+
+	if loads != 1 || len(m.tracks) != 2 {
+		t.Errorf("Expected the file to be reloaded after the run ended, got %d loads", loads)
+	}
+}
+
+// batchMsgs runs cmd and, for a batch, each of its commands, returning the messages produced
+func batchMsgs(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+
+	msg := cmd()
+
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, batchMsgs(c)...)
+	}
+
+	return msgs
+}
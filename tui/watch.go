@@ -0,0 +1,175 @@
+// ABOUTME: Detects external changes to the playlist file while the TUI is running
+// ABOUTME: Polls the file's modification time, ignores the TUI's own auto-saves, and offers to reload
+
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+)
+
+// diskPollInterval is how often the TUI checks the playlist file for external changes
+const diskPollInterval = 2 * time.Second
+
+// diskPollMsg reports the playlist file's modification time at a poll
+type diskPollMsg struct {
+	modTime time.Time
+	err     error
+}
+
+// diskReadMsg carries the track paths found in the playlist file after its modification time changed
+type diskReadMsg struct {
+	paths   []string
+	modTime time.Time
+	err     error
+}
+
+// playlistReloadedMsg carries the tracks reloaded from disk after the user accepted an external change
+type playlistReloadedMsg struct {
+	tracks  []playlist.Track
+	modTime time.Time
+	err     error
+}
+
+// pollDisk stats the playlist file after the poll interval
+func (m model) pollDisk() tea.Cmd {
+	path := m.playlistPath
+
+	return tea.Tick(diskPollInterval, func(time.Time) tea.Msg {
+		modTime, err := statPlaylist(path)
+
+		return diskPollMsg{modTime: modTime, err: err}
+	})
+}
+
+// readDiskPaths reads the track paths (without metadata) from the playlist file
+func (m model) readDiskPaths(modTime time.Time) tea.Cmd {
+	path := m.playlistPath
+
+	return func() tea.Msg {
+		tracks, err := playlist.ReadPlaylist(path)
+		if err != nil {
+			return diskReadMsg{modTime: modTime, err: err}
+		}
+
+		return diskReadMsg{paths: trackPaths(tracks), modTime: modTime}
+	}
+}
+
+// handleDiskPoll checks whether the playlist file changed since it was last seen
+func (m *model) handleDiskPoll(msg diskPollMsg) tea.Cmd {
+	switch {
+	case msg.err != nil:
+		return m.pollDisk()
+	case m.diskModTime.IsZero():
+		// First poll: this is the version the session started from
+		m.diskModTime = msg.modTime
+
+		return m.pollDisk()
+	case msg.modTime.Equal(m.diskModTime):
+		return m.pollDisk()
+	}
+
+	return m.readDiskPaths(msg.modTime)
+}
+
+// handleDiskRead decides whether a changed file is one of the TUI's own auto-saves or an external edit
+func (m *model) handleDiskRead(msg diskReadMsg) tea.Cmd {
+	if msg.err != nil {
+		// Possibly caught mid-write - diskModTime isn't advanced, so the next poll retries
+		return m.pollDisk()
+	}
+
+	// Auto-saves write the displayed order, so matching content is our own write
+	if slices.Equal(msg.paths, trackPaths(m.displayedTracks)) {
+		m.diskModTime = msg.modTime
+
+		return m.pollDisk()
+	}
+
+	// Don't interrupt input in progress; the change is picked up again on the next poll
+	if m.prompt.active() || m.showSnapshots {
+		return m.pollDisk()
+	}
+
+	m.diskModTime = msg.modTime
+
+	label := fmt.Sprintf("Playlist changed on disk (%d tracks) - reload and restart optimization? [y/N]: ", len(msg.paths))
+	if m.editMode {
+		label = fmt.Sprintf("Playlist changed on disk (%d tracks) - reload, replacing your edits (u undoes)? [y/N]: ", len(msg.paths))
+	}
+
+	m.openPrompt(promptReloadPlaylist, label)
+	m.debugf("[TUI] External change detected in %s", m.playlistPath)
+
+	return m.pollDisk()
+}
+
+// answerReload handles the reload prompt: "y" reloads the playlist from disk, anything else keeps the current order
+func (m *model) answerReload(input string) tea.Cmd {
+	if answer := strings.ToLower(strings.TrimSpace(input)); answer != "y" && answer != "yes" {
+		m.setStatusMsg("Kept current playlist (the next save overwrites the external change)")
+
+		return nil
+	}
+
+	path, modTime, load := m.playlistPath, m.diskModTime, m.loadPlaylist
+
+	return func() tea.Msg {
+		tracks, err := load(path, true)
+
+		return playlistReloadedMsg{tracks: tracks, modTime: modTime, err: err}
+	}
+}
+
+// applyReload replaces the session's tracks with the reloaded playlist and restarts the GA.
+// The previous order is pushed to the undo stack, so local edits aren't lost.
+func (m *model) applyReload(msg playlistReloadedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.setStatusMsg(fmt.Sprintf("Reload failed: %v", msg.err))
+
+		return nil
+	}
+
+	m.pushUndo()
+
+	m.originalTracks = msg.tracks
+	m.setDisplayedTracks(msg.tracks)
+	m.bestPlaylist = nil
+	m.bestFitness = 0
+	m.deltaBase = nil // Different tracks: the old breakdown isn't comparable
+	m.editMode = false
+	m.diskModTime = msg.modTime
+	m.cursorPos = min(m.cursorPos, max(len(msg.tracks)-1, 0))
+
+	// Invalidate pending updates from the GA run on the old tracks
+	m.gaEpoch++
+
+	m.ensureCursorVisible()
+	m.updateViewportContent()
+	m.setStatusMsg(fmt.Sprintf("Reloaded %d tracks from disk (u: back to previous order)", len(msg.tracks)))
+
+	return m.restartGA()
+}
+
+// reloadPending reports whether the user is being asked about an external change.
+// Auto-saves are held back meanwhile so they don't overwrite it.
+func (m model) reloadPending() bool {
+	return m.prompt.kind == promptReloadPlaylist
+}
+
+// trackPaths returns the paths of tracks in order
+func trackPaths(tracks []playlist.Track) []string {
+	paths := make([]string, len(tracks))
+	for i, t := range tracks {
+		paths[i] = t.Path
+	}
+
+	return paths
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Tests for external playlist change detection in the TUI
+// ABOUTME: Verifies own auto-saves are ignored, external edits prompt, and reloads are undoable
+
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"playlist-sorter/playlist"
+)
+
+func TestDiskPollIgnoresOwnSaves(t *testing.T) {
+	m := createTestModel(createTestTracks(3))
+	start := time.Now()
+
+	updated, _ := m.Update(diskPollMsg{modTime: start})
+	m = updated.(model)
+
+	if !m.diskModTime.Equal(start) {
+		t.Fatalf("Expected first poll to record the modification time")
+	}
+
+	// Changed mtime triggers a read of the file
+	updated, cmd := m.Update(diskPollMsg{modTime: start.Add(time.Second)})
+	m = updated.(model)
+
+	if cmd == nil {
+		t.Fatal("Expected a changed modification time to read the file")
+	}
+
+	// The file holds the displayed order: our own auto-save
+	updated, _ = m.Update(diskReadMsg{paths: trackPaths(m.displayedTracks), modTime: start.Add(time.Second)})
+	m = updated.(model)
+
+	if m.prompt.active() {
+		t.Error("Expected own auto-save not to prompt")
+	}
+
+	if !m.diskModTime.Equal(start.Add(time.Second)) {
+		t.Error("Expected own auto-save to advance the known modification time")
+	}
+}
+
+func TestExternalChangeReload(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := createTestModel(tracks)
+	epoch := m.gaEpoch
+
+	changed := []playlist.Track{tracks[2], tracks[1]}
+	m.loadPlaylist = func(_ string, _ bool) ([]playlist.Track, error) {
+		return changed, nil
+	}
+
+	updated, _ := m.Update(diskReadMsg{paths: trackPaths(changed), modTime: time.Now()})
+	m = updated.(model)
+
+	if m.prompt.kind != promptReloadPlaylist {
+		t.Fatal("Expected external change to open the reload prompt")
+	}
+
+	if !m.reloadPending() {
+		t.Error("Expected auto-saves to be held back while the prompt is open")
+	}
+
+	m.prompt.value = []rune("y")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if cmd == nil {
+		t.Fatal("Expected accepting the prompt to reload the playlist")
+	}
+
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+
+	if len(m.displayedTracks) != 2 || m.displayedTracks[0].Path != tracks[2].Path {
+		t.Fatalf("Expected reloaded tracks to be displayed, got %d tracks", len(m.displayedTracks))
+	}
+
+	if m.gaEpoch == epoch {
+		t.Error("Expected reload to start a new GA epoch")
+	}
+
+	// The previous order stays reachable with undo
+	_ = m.undo()
+
+	if len(m.displayedTracks) != 3 {
+		t.Errorf("Expected undo to restore the pre-reload order, got %d tracks", len(m.displayedTracks))
+	}
+}
+
+func TestExternalChangeDeclined(t *testing.T) {
+	tracks := createTestTracks(3)
+	m := createTestModel(tracks)
+
+	updated, _ := m.Update(diskReadMsg{paths: []string{"other.mp3"}, modTime: time.Now()})
+	m = updated.(model)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if cmd != nil || m.prompt.active() {
+		t.Error("Expected an empty answer to keep the current playlist")
+	}
+
+	if len(m.displayedTracks) != 3 {
+		t.Errorf("Expected current tracks to be kept, got %d", len(m.displayedTracks))
+	}
+}
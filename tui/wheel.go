@@ -0,0 +1,204 @@
+// ABOUTME: Camelot wheel widget for a quick harmonic sanity check of the current order
+// ABOUTME: Highlights the keys present in the playlist and the key path around the cursor
+
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"playlist-sorter/playlist"
+)
+
+// Camelot wheel layout: B (major) keys on the outer ring, A (minor) keys on the inner ring.
+// Radii are in cells; columns are about half as wide as rows are tall.
+const (
+	wheelWidth       = 41
+	wheelRows        = 11
+	wheelOuterRadius = 17
+	wheelInnerRadius = 10
+	wheelPathSpan    = 2 // Tracks shown on each side of the cursor in the path line
+)
+
+var (
+	wheelKeyStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("10"))
+
+	wheelCursorKeyStyle = lipgloss.NewStyle().
+				Bold(true).
+				Background(lipgloss.Color("240")).
+				Foreground(lipgloss.Color("15"))
+
+	wheelAbsentKeyStyle = helpStyle
+
+	wheelClashStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9"))
+)
+
+// usePlainWheelStyles keeps the wheel readable without colors: absent keys become dots and the
+// cursor's key is bracketed
+func usePlainWheelStyles() {
+	wheelAbsentKeyStyle = lipgloss.NewStyle().Transform(func(string) string { return "·" })
+	wheelCursorKeyStyle = lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+}
+
+// wheelCell returns the grid position of the first character of a key label
+func wheelCell(key playlist.CamelotKey) (row, col int) {
+	rx, ry := float64(wheelOuterRadius), float64(wheelRows/2)
+	if key.Letter == 'A' {
+		rx, ry = wheelInnerRadius, float64(wheelRows/2-2)
+	}
+
+	angle := float64(key.Number%12) * math.Pi / 6
+	col = int(math.Round(float64(wheelWidth-1)/2+rx*math.Sin(angle))) - 1
+	row = int(math.Round(float64(wheelRows-1)/2 - ry*math.Cos(angle)))
+
+	return row, col
+}
+
+// renderCamelotWheel draws the wheel with keys present in tracks highlighted (the key of the track
+// at cursor stands out) and absent keys dimmed, followed by the key path around the cursor and a
+// count of transition kinds over the whole order
+func renderCamelotWheel(tracks []playlist.Track, cursor int) string {
+	present := make(map[playlist.CamelotKey]bool)
+
+	for i := range tracks {
+		for _, k := range []*playlist.CamelotKey{tracks[i].InKey(), tracks[i].OutKey()} {
+			if k != nil {
+				present[*k] = true
+			}
+		}
+	}
+
+	var cursorKey *playlist.CamelotKey
+	if cursor >= 0 && cursor < len(tracks) {
+		cursorKey = tracks[cursor].InKey()
+	}
+
+	// Labels are collected per row and padded by display width (styling adds invisible bytes)
+	type label struct {
+		col  int
+		text string
+	}
+
+	rows := make([][]label, wheelRows)
+
+	for _, letter := range []byte{'A', 'B'} {
+		for number := 1; number <= 12; number++ {
+			key := playlist.CamelotKey{Letter: letter, Number: number}
+			row, col := wheelCell(key)
+
+			text := key.String()
+
+			switch {
+			case cursorKey != nil && *cursorKey == key:
+				text = wheelCursorKeyStyle.Render(text)
+			case present[key]:
+				text = wheelKeyStyle.Render(text)
+			default:
+				text = wheelAbsentKeyStyle.Render(text)
+			}
+
+			rows[row] = append(rows[row], label{col: col, text: text})
+		}
+	}
+
+	var b strings.Builder
+
+	for _, labels := range rows {
+		slices.SortFunc(labels, func(a, b label) int { return cmp.Compare(a.col, b.col) })
+
+		pos := 0
+
+		for _, l := range labels {
+			b.WriteString(strings.Repeat(" ", max(l.col-pos, 0)))
+			b.WriteString(l.text)
+
+			pos = max(l.col, pos) + lipgloss.Width(l.text)
+		}
+
+		b.WriteString("\n")
+	}
+
+	b.WriteString(wheelPath(tracks, cursor) + "\n")
+	b.WriteString(wheelMoves(tracks))
+
+	return b.String()
+}
+
+// wheelPath renders the keys of the tracks around cursor, joined by the kind of move between them:
+// ">" smooth (same key, neighbour or relative), "~" mood shift (parallel key), "!" clash
+func wheelPath(tracks []playlist.Track, cursor int) string {
+	if len(tracks) == 0 {
+		return "Path: -"
+	}
+
+	start, end := max(cursor-wheelPathSpan, 0), min(cursor+wheelPathSpan+1, len(tracks))
+
+	var b strings.Builder
+
+	b.WriteString("Path: ")
+
+	if start > 0 {
+		b.WriteString("… ")
+	}
+
+	for i := start; i < end; i++ {
+		if i > start {
+			b.WriteString(" " + moveSymbol(&tracks[i-1], &tracks[i]) + " ")
+		}
+
+		text := tracks[i].Key
+		if text == "" {
+			text = "?"
+		}
+
+		if i == cursor {
+			text = wheelCursorKeyStyle.Render(text)
+		}
+
+		b.WriteString(text)
+	}
+
+	if end < len(tracks) {
+		b.WriteString(" …")
+	}
+
+	return b.String()
+}
+
+// moveSymbol classifies the harmonic transition from a to b
+func moveSymbol(a, b *playlist.Track) string {
+	switch d := playlist.HarmonicDistanceParsed(a.OutKey(), b.InKey()); {
+	case d <= 1:
+		return ">"
+	case d == 2:
+		return "~"
+	default:
+		return wheelClashStyle.Render("!")
+	}
+}
+
+// wheelMoves counts the kinds of harmonic transitions over the whole order
+func wheelMoves(tracks []playlist.Track) string {
+	var smooth, mood, clash int
+
+	for i := 1; i < len(tracks); i++ {
+		switch d := playlist.HarmonicDistanceParsed(tracks[i-1].OutKey(), tracks[i].InKey()); {
+		case d <= 1:
+			smooth++
+		case d == 2:
+			mood++
+		default:
+			clash++
+		}
+	}
+
+	return fmt.Sprintf("Moves: %d smooth, %d mood shift, %d clash", smooth, mood, clash)
+}
@@ -0,0 +1,73 @@
+// ABOUTME: Tests for the Camelot wheel widget
+// ABOUTME: Verifies the wheel layout doesn't overlap and the path and move counts follow the order
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func wheelTrack(key string) playlist.Track {
+	parsed, _ := playlist.ParseCamelotKey(key)
+
+	return playlist.Track{Path: key + ".mp3", Key: key, ParsedKey: parsed}
+}
+
+func TestCamelotWheelLayout(t *testing.T) {
+	occupied := make(map[[2]int]string)
+
+	for _, letter := range []byte{'A', 'B'} {
+		for number := 1; number <= 12; number++ {
+			key := playlist.CamelotKey{Letter: letter, Number: number}
+			row, col := wheelCell(key)
+
+			if row < 0 || row >= wheelRows || col < 0 || col+len(key.String()) > wheelWidth {
+				t.Errorf("%s placed outside the wheel at row %d, col %d", key.String(), row, col)
+			}
+
+			// Labels plus a separating space must not touch
+			for c := col - 1; c <= col+len(key.String()); c++ {
+				if other, ok := occupied[[2]int{row, c}]; ok {
+					t.Errorf("%s overlaps %s at row %d", key.String(), other, row)
+				}
+			}
+
+			for c := col; c < col+len(key.String()); c++ {
+				occupied[[2]int{row, c}] = key.String()
+			}
+		}
+	}
+}
+
+func TestRenderCamelotWheel(t *testing.T) {
+	tracks := []playlist.Track{wheelTrack("8A"), wheelTrack("9A"), wheelTrack("9B"), wheelTrack("6A"), wheelTrack("3B"), wheelTrack("4B")}
+
+	out := renderCamelotWheel(tracks, 4)
+
+	for _, key := range []string{"12B", "1A", "6B", "8A"} {
+		if !strings.Contains(out, key) {
+			t.Errorf("Expected %s on the wheel:\n%s", key, out)
+		}
+	}
+
+	lines := strings.Split(out, "\n")
+	if len(lines) != wheelRows+2 {
+		t.Fatalf("Expected %d lines, got %d:\n%s", wheelRows+2, len(lines), out)
+	}
+
+	// 8A>9A and 9A>9B are smooth, 9B~6A a mood shift (parallel), 6A!3B a clash, 3B>4B smooth
+	if got, want := lines[wheelRows], "Path: … 9B ~ 6A ! 3B > 4B"; got != want {
+		t.Errorf("Expected path %q, got %q", want, got)
+	}
+
+	if got, want := lines[wheelRows+1], "Moves: 3 smooth, 1 mood shift, 1 clash"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if got := wheelPath(nil, 0); got != "Path: -" {
+		t.Errorf("Expected an empty path for no tracks, got %q", got)
+	}
+}
@@ -0,0 +1,218 @@
+// ABOUTME: Validate subcommand reporting metadata quality of a playlist
+// ABOUTME: Lists tracks with missing key/BPM/energy/genre and fails when coverage is below a threshold
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"playlist-sorter/config"
+	"playlist-sorter/playlist"
+)
+
+const defaultMinCoverage = 80.0 // Default minimum per-field coverage (percent) for validate to pass
+
+// validatedFields lists the metadata fields checked by validate, in report order
+var validatedFields = []string{"key", "bpm", "energy", "genre"}
+
+// trackValidation holds the validation result for one playlist entry
+type trackValidation struct {
+	Path    string
+	Missing []string // Names of missing fields (see validatedFields)
+	Err     error    // Set if the track's metadata could not be read at all
+}
+
+// validationReport summarizes metadata quality across a playlist
+type validationReport struct {
+	Tracks  []trackValidation
+	Missing map[string]int // Field name -> number of readable tracks missing it
+}
+
+// missingFields returns the names of metadata fields the track lacks
+func missingFields(t *playlist.Track) []string {
+	var missing []string
+
+	if t.Key == "" {
+		missing = append(missing, "key")
+	} else if _, err := playlist.ParseCamelotKey(t.Key); err != nil {
+		missing = append(missing, "key")
+	}
+
+	if t.BPM <= 0 {
+		missing = append(missing, "bpm")
+	}
+
+	if t.Energy <= 0 {
+		missing = append(missing, "energy")
+	}
+
+	if strings.TrimSpace(t.Genre) == "" {
+		missing = append(missing, "genre")
+	}
+
+	return missing
+}
+
+// newValidationReport builds a report from per-track results
+func newValidationReport(results []trackValidation) validationReport {
+	report := validationReport{Tracks: results, Missing: make(map[string]int, len(validatedFields))}
+
+	for _, r := range results {
+		for _, field := range r.Missing {
+			report.Missing[field]++
+		}
+	}
+
+	return report
+}
+
+// readable returns the number of tracks whose metadata could be read
+func (r validationReport) readable() int {
+	n := 0
+
+	for _, t := range r.Tracks {
+		if t.Err == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// coverage returns the percentage of all tracks that have the field.
+// Unreadable tracks count as missing every field.
+func (r validationReport) coverage(field string) float64 {
+	if len(r.Tracks) == 0 {
+		return 0
+	}
+
+	present := r.readable() - r.Missing[field]
+
+	return float64(present) * 100 / float64(len(r.Tracks))
+}
+
+// failingFields returns fields whose coverage is below minCoverage
+func (r validationReport) failingFields(minCoverage float64) []string {
+	var failing []string
+
+	for _, field := range validatedFields {
+		if r.coverage(field) < minCoverage {
+			failing = append(failing, field)
+		}
+	}
+
+	return failing
+}
+
+// validatePlaylist reads every track in the playlist and checks its metadata
+func validatePlaylist(path string) (validationReport, error) {
+	entries, err := playlist.ReadPlaylist(path)
+	if err != nil {
+		return validationReport{}, err
+	}
+
+	playlistDir := filepath.Dir(path)
+	results := make([]trackValidation, 0, len(entries))
+
+	for _, entry := range entries {
+		track, err := playlist.GetTrackMetadata(entry.Path, playlistDir)
+		if err != nil {
+			results = append(results, trackValidation{Path: entry.Path, Err: err})
+
+			continue
+		}
+
+		results = append(results, trackValidation{Path: entry.Path, Missing: missingFields(track)})
+	}
+
+	return newValidationReport(results), nil
+}
+
+// runValidate implements `playlist-sorter validate [flags] <playlist>`
+func runValidate(args []string) int {
+	fs := newFlagSet("validate", "validate [flags] <playlist.m3u8>")
+	minCoverage := fs.Float64("min-coverage", defaultMinCoverage, "minimum coverage percentage per field; exit non-zero below it")
+	verbose := fs.Bool("v", false, "list every track, not only those with problems")
+	pathMap := pathMapFlag(fs)
+
+	playlistPath, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	cfg, _ := config.LoadConfig(config.GetConfigPath())
+	if err := setPathMap(cfg, *pathMap); err != nil {
+		log.Printf("Validate error: %v", err)
+
+		return 2
+	}
+
+	report, err := validatePlaylist(playlistPath)
+	if err != nil {
+		log.Printf("Validate error: %v", err)
+
+		return 1
+	}
+
+	if len(report.Tracks) == 0 {
+		fmt.Println("Playlist is empty")
+
+		return 1
+	}
+
+	printValidationReport(os.Stdout, report, *verbose)
+
+	if failing := report.failingFields(*minCoverage); len(failing) > 0 {
+		fmt.Printf("\nFAIL: coverage below %.0f%% for: %s\n", *minCoverage, strings.Join(failing, ", "))
+
+		return 1
+	}
+
+	fmt.Printf("\nOK: all fields at or above %.0f%% coverage\n", *minCoverage)
+
+	return 0
+}
+
+// printValidationReport prints per-track problems followed by a coverage summary
+func printValidationReport(out io.Writer, report validationReport, verbose bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	if _, err := fmt.Fprintln(w, "#\tMissing\tPath"); err != nil {
+		log.Printf("Warning: failed to write header: %v", err)
+	}
+
+	for i, t := range report.Tracks {
+		var problem string
+
+		switch {
+		case t.Err != nil:
+			problem = "unreadable: " + t.Err.Error()
+		case len(t.Missing) > 0:
+			problem = strings.Join(t.Missing, ",")
+		case verbose:
+			problem = "-"
+		default:
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%d\t%s\t%s\n", i+1, problem, t.Path); err != nil {
+			log.Printf("Warning: failed to write track %d: %v", i+1, err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Printf("Warning: failed to flush output: %v", err)
+	}
+
+	fmt.Fprintf(out, "\nCoverage (%d tracks, %d unreadable):\n", len(report.Tracks), len(report.Tracks)-report.readable())
+
+	for _, field := range validatedFields {
+		fmt.Fprintf(out, "  %-7s %5.1f%%\n", field, report.coverage(field))
+	}
+}
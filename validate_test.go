@@ -0,0 +1,69 @@
+// ABOUTME: Tests for the validate subcommand's metadata checks
+// ABOUTME: Verifies missing field detection, coverage percentages, and threshold failures
+
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"playlist-sorter/playlist"
+)
+
+func TestMissingFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		track playlist.Track
+		want  []string
+	}{
+		{
+			name:  "complete",
+			track: playlist.Track{Key: "8A", BPM: 128, Energy: 6, Genre: "House"},
+			want:  nil,
+		},
+		{
+			name:  "all missing",
+			track: playlist.Track{},
+			want:  []string{"key", "bpm", "energy", "genre"},
+		},
+		{
+			name:  "invalid key counts as missing",
+			track: playlist.Track{Key: "Am", BPM: 128, Energy: 6, Genre: "House"},
+			want:  []string{"key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingFields(&tt.track); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationReportCoverage(t *testing.T) {
+	report := newValidationReport([]trackValidation{
+		{Path: "a"},
+		{Path: "b", Missing: []string{"energy"}},
+		{Path: "c", Missing: []string{"energy", "genre"}},
+		{Path: "d", Err: errors.New("no tags")},
+	})
+
+	if got := report.coverage("key"); got != 75 {
+		t.Errorf("key coverage = %.1f, want 75 (unreadable track counts as missing)", got)
+	}
+
+	if got := report.coverage("energy"); got != 25 {
+		t.Errorf("energy coverage = %.1f, want 25", got)
+	}
+
+	if got := report.failingFields(70); !slices.Equal(got, []string{"energy", "genre"}) {
+		t.Errorf("failing fields = %v, want [energy genre]", got)
+	}
+
+	if got := report.failingFields(0); len(got) != 0 {
+		t.Errorf("expected no failing fields at 0%% threshold, got %v", got)
+	}
+}
@@ -0,0 +1,47 @@
+// ABOUTME: View subcommand showing a live read-only view of a playlist file
+// ABOUTME: Wires the cached track loader and fitness scoring into the TUI view mode
+
+package main
+
+import (
+	"context"
+	"log"
+
+	"playlist-sorter/config"
+	"playlist-sorter/live"
+	"playlist-sorter/playlist"
+	"playlist-sorter/tui"
+)
+
+// runView implements `playlist-sorter view [flags] <playlist>`
+func runView(args []string) int {
+	fs := newFlagSet("view", "view [flags] <playlist.m3u8>")
+	interval := fs.Duration("interval", 0, "how often to check the playlist for changes (default 1s)")
+	noColorFlag := fs.Bool("no-color", false, "plain rendering without colors (also set by NO_COLOR)")
+
+	playlistPath, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+
+	cfg, _ := config.LoadConfig(config.GetConfigPath())
+	playlist.SetMixTags(cfg.IntroTag, cfg.OutroTag)
+	playlist.SetGenreAliases(cfg.GenreAliases)
+
+	score := func(tracks []playlist.Track) playlist.Breakdown {
+		return scorePlaylist(tracks, cfg)
+	}
+
+	opts := tui.ViewOptions{PlaylistPath: playlistPath, PollInterval: *interval, NoColor: noColor(*noColorFlag)}
+	opts.Subscribe = func(updates chan<- live.Update) error {
+		return live.Subscribe(context.Background(), playlistPath, updates)
+	}
+
+	if err := tui.RunViewMode(opts, newCachedTrackLoader(cfg), score); err != nil {
+		log.Printf("View error: %v", err)
+
+		return 1
+	}
+
+	return 0
+}